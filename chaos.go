@@ -0,0 +1,48 @@
+package minivalkey
+
+import (
+	"github.com/mickamy/minivalkey/internal/server"
+)
+
+// Truncation is a registered reply truncation rule.
+type Truncation struct {
+	s  *MiniValkey
+	id int
+}
+
+// TruncateReplies sends only the first n bytes of the reply to matching
+// commands and then closes the connection, like a server dying mid-reply:
+//
+//	s.TruncateReplies(3, minivalkey.OnCommand("GET"), minivalkey.Once())
+//
+// The command itself still runs. With n at 0 the connection closes without a reply.
+func (s *MiniValkey) TruncateReplies(n int, opts ...RuleOption) *Truncation {
+	return &Truncation{s: s, id: s.srv.AddTruncation(server.TruncateRule{CommandRule: commandRule(opts), Bytes: n})}
+}
+
+// Remove unregisters the truncation rule.
+func (t *Truncation) Remove() {
+	t.s.srv.RemoveTruncation(t.id)
+}
+
+// ClearTruncations removes every reply truncation rule.
+func (s *MiniValkey) ClearTruncations() {
+	s.srv.ClearTruncations()
+}
+
+// CloseClients closes the open client connections match selects, or all of
+// them when match is nil, and returns how many it closed:
+//
+//	s.CloseClients(func(c minivalkey.ClientInfo) bool { return c.Name == "worker" })
+func (s *MiniValkey) CloseClients(match func(ClientInfo) bool) int {
+	if match == nil {
+		return s.srv.CloseClients(nil)
+	}
+	return s.srv.CloseClients(func(c server.ClientInfo) bool { return match(ClientInfo(c)) })
+}
+
+// DropNextConnections closes the next n connections as soon as they are
+// accepted, before any command is read.
+func (s *MiniValkey) DropNextConnections(n int) {
+	s.srv.DropConnections(n)
+}
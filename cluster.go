@@ -0,0 +1,117 @@
+package minivalkey
+
+import (
+	"errors"
+	"net"
+	"time"
+
+	"github.com/mickamy/minivalkey/internal/cluster"
+	"github.com/mickamy/minivalkey/internal/server"
+)
+
+// NumSlots is the number of hash slots a cluster splits between its nodes.
+const NumSlots = cluster.NumSlots
+
+// Cluster is a set of servers emulating a Valkey Cluster. The nodes share
+// one slot map and answer commands on keys they do not own with a MOVED
+// redirect, so cluster-aware clients can be tested against it.
+type Cluster struct {
+	nodes []*MiniValkey
+	topo  *cluster.Topology
+}
+
+// RunCluster starts n nodes on ephemeral ports and splits the 16384 hash
+// slots evenly between them, in order. Each node only holds database 0.
+// Persistence options are not supported in cluster mode.
+func RunCluster(n int, opts ...Option) (*Cluster, error) {
+	if n < 1 {
+		return nil, errors.New("minivalkey: cluster needs at least one node")
+	}
+	o := newOptions(opts)
+	if o.rdbFile != "" || o.aofFile != "" || o.fixtureFile != "" {
+		return nil, errors.New("minivalkey: persistence options are not supported in cluster mode")
+	}
+
+	lns := make([]net.Listener, 0, n)
+	closeListeners := func() {
+		for _, ln := range lns {
+			_ = ln.Close()
+		}
+	}
+	addrs := make([]string, 0, n)
+	for range n {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			closeListeners()
+			return nil, err
+		}
+		lns = append(lns, ln)
+		addrs = append(addrs, ln.Addr().String())
+	}
+	topo, err := cluster.New(addrs)
+	if err != nil {
+		closeListeners()
+		return nil, err
+	}
+
+	c := &Cluster{topo: topo}
+	for i, ln := range lns {
+		node, err := start(ln, o, server.Options{Cluster: topo, ClusterNodeID: topo.Nodes[i].ID})
+		if err != nil {
+			_ = c.Close()
+			for _, rest := range lns[i+1:] {
+				_ = rest.Close()
+			}
+			return nil, err
+		}
+		c.nodes = append(c.nodes, node)
+	}
+	return c, nil
+}
+
+// Nodes returns the nodes in slot order: the first node owns slot 0.
+func (c *Cluster) Nodes() []*MiniValkey {
+	return append([]*MiniValkey(nil), c.nodes...)
+}
+
+// Addrs returns the TCP addresses of the nodes, in the same order as Nodes.
+func (c *Cluster) Addrs() []string {
+	addrs := make([]string, len(c.nodes))
+	for i, n := range c.nodes {
+		addrs[i] = n.Addr()
+	}
+	return addrs
+}
+
+// NodeForKey returns the node that owns the hash slot of key.
+func (c *Cluster) NodeForKey(key string) *MiniValkey {
+	owner := c.topo.Owner(KeySlot(key))
+	for i, n := range c.topo.Nodes {
+		if n == owner {
+			return c.nodes[i]
+		}
+	}
+	return nil
+}
+
+// Close stops every node and releases resources.
+func (c *Cluster) Close() error {
+	var errs []error
+	for _, n := range c.nodes {
+		errs = append(errs, n.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// FastForward advances the internal clock of every node by d.
+func (c *Cluster) FastForward(d time.Duration) {
+	for _, n := range c.nodes {
+		n.FastForward(d)
+	}
+}
+
+// KeySlot returns the hash slot of key. Only the hash tag is hashed when key
+// has one, so keys such as {user:1}:name and {user:1}:email share a slot.
+func KeySlot(key string) int {
+	return cluster.KeySlot(key)
+}
@@ -1,7 +1,9 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"log/slog"
 	"os"
 	"os/signal"
 	"syscall"
@@ -10,7 +12,36 @@ import (
 )
 
 func main() {
-	s, err := minivalkey.Run()
+	rdbFile := flag.String("rdb", "", "RDB file to load at startup and to write on SAVE/BGSAVE")
+	fixtureFile := flag.String("fixture", "", "JSON fixture file to seed the dataset with at startup")
+	appendOnly := flag.Bool("appendonly", false, "log write commands to an append-only file and replay it at startup")
+	appendFilename := flag.String("appendfilename", "appendonly.aof", "append-only file used with -appendonly")
+	appendFsync := flag.String("appendfsync", "everysec", "append-only file fsync policy: always, everysec or no")
+	logLevel := flag.String("loglevel", "info", "log level: debug, info, warn or error")
+	flag.Parse()
+
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(*logLevel)); err != nil {
+		fmt.Println("invalid -loglevel:", err)
+		os.Exit(2)
+	}
+
+	// Nothing reads the command history of a standalone server, so don't grow it.
+	opts := []minivalkey.Option{minivalkey.WithLogLevel(level), minivalkey.WithHistoryLimit(-1)}
+	if *rdbFile != "" {
+		opts = append(opts, minivalkey.WithRDBFile(*rdbFile))
+	}
+	if *fixtureFile != "" {
+		opts = append(opts, minivalkey.WithFixtureFile(*fixtureFile))
+	}
+	if *appendOnly {
+		opts = append(opts,
+			minivalkey.WithAppendOnly(*appendFilename),
+			minivalkey.WithAppendFsync(minivalkey.AppendFsync(*appendFsync)),
+		)
+	}
+
+	s, err := minivalkey.Run(opts...)
 	if err != nil {
 		fmt.Println("failed to start:", err)
 		os.Exit(1)
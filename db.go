@@ -0,0 +1,426 @@
+package minivalkey
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"time"
+
+	"github.com/mickamy/minivalkey/internal/db"
+)
+
+var (
+	// ErrKeyNotFound is returned when a key, or a hash field, does not exist.
+	ErrKeyNotFound = errors.New("minivalkey: key not found")
+	// ErrWrongType is returned when a key holds another kind of value.
+	ErrWrongType = errors.New("minivalkey: wrong kind of value")
+	// ErrNoTTL is returned by TTL when the key exists but does not expire.
+	ErrNoTTL = errors.New("minivalkey: key has no TTL")
+	// ErrDBIndexOutOfRange is returned by DB for an index the server does not have.
+	ErrDBIndexOutOfRange = errors.New("minivalkey: database index is out of range")
+	// ErrFieldValuePairs is returned by HSet when a field has no value.
+	ErrFieldValuePairs = errors.New("minivalkey: HSet needs field/value pairs")
+)
+
+// ZMember is a sorted set member with its score.
+type ZMember struct {
+	Member string
+	Score  float64
+}
+
+// DB gives direct access to one numbered database, without going through a
+// network client. It works on the same data and simulated clock as the
+// server, but its writes are not logged to the append-only file.
+type DB struct {
+	s   *MiniValkey
+	idx int
+}
+
+// DB returns direct access to database idx.
+// Returns ErrDBIndexOutOfRange if the server has no such database.
+func (s *MiniValkey) DB(idx int) (*DB, error) {
+	if _, ok := s.srv.DB(idx); !ok {
+		return nil, fmt.Errorf("%w: %d", ErrDBIndexOutOfRange, idx)
+	}
+	return &DB{s: s, idx: idx}, nil
+}
+
+// db0 returns database 0, which the methods of MiniValkey work on.
+func (s *MiniValkey) db0() *DB {
+	return &DB{s: s}
+}
+
+// db looks the database up on every call, since Restore and the loaders replace it.
+func (d *DB) db() (*db.DB, time.Time) {
+	st, _ := d.s.srv.DB(d.idx)
+	return st, d.s.srv.Now()
+}
+
+// wrapError maps internal errors to the exported ones.
+func wrapError(err error) error {
+	if errors.Is(err, db.ErrWrongType) {
+		return ErrWrongType
+	}
+	return err
+}
+
+// Set stores a string value at k, replacing any value and TTL it held.
+func (d *DB) Set(k, v string) {
+	st, _ := d.db()
+	st.SetString(k, v, time.Time{})
+}
+
+// Get returns the string value at k.
+func (d *DB) Get(k string) (string, error) {
+	st, now := d.db()
+	v, ok, err := st.LookupString(now, k)
+	if err != nil {
+		return "", wrapError(err)
+	}
+	if !ok {
+		return "", ErrKeyNotFound
+	}
+	return v, nil
+}
+
+// Del removes k and reports whether it existed.
+func (d *DB) Del(k string) bool {
+	st, now := d.db()
+	return st.Del(now, k) == 1
+}
+
+// Exists reports whether k exists.
+func (d *DB) Exists(k string) bool {
+	st, now := d.db()
+	return st.Exists(now, k) == 1
+}
+
+// Type returns the type of the value at k as TYPE reports it, or "none".
+func (d *DB) Type(k string) string {
+	st, now := d.db()
+	t, ok := st.Type(now, k)
+	if !ok {
+		return "none"
+	}
+	return t.String()
+}
+
+// Keys returns every key, sorted.
+func (d *DB) Keys() []string {
+	st, now := d.db()
+	return st.Keys(now)
+}
+
+// TTL returns the time left before k expires.
+// Returns ErrKeyNotFound if k does not exist and ErrNoTTL if it does not expire.
+func (d *DB) TTL(k string) (time.Duration, error) {
+	st, now := d.db()
+	at, ok := st.ExpireTime(now, k)
+	if !ok {
+		return 0, ErrKeyNotFound
+	}
+	if at.IsZero() {
+		return 0, ErrNoTTL
+	}
+	return at.Sub(now), nil
+}
+
+// SetTTL makes k expire after ttl on the simulated clock; a ttl that is not
+// positive removes the expiry. Missing keys are left alone.
+func (d *DB) SetTTL(k string, ttl time.Duration) {
+	st, now := d.db()
+	if ttl <= 0 {
+		st.Persist(now, k)
+		return
+	}
+	st.ExpireAt(now, k, now.Add(ttl), db.ExpireOptions{})
+}
+
+// HSet sets fields of the hash at k from alternating field/value pairs.
+// Returns ErrFieldValuePairs, without writing anything, if fieldValues has an odd length.
+func (d *DB) HSet(k string, fieldValues ...string) error {
+	if len(fieldValues)%2 != 0 {
+		return ErrFieldValuePairs
+	}
+	st, now := d.db()
+	_, err := st.HSet(now, k, fieldValues...)
+	return wrapError(err)
+}
+
+// HGet returns the value of field in the hash at k.
+func (d *DB) HGet(k, field string) (string, error) {
+	st, now := d.db()
+	v, ok, err := st.HGet(now, k, field)
+	if err != nil {
+		return "", wrapError(err)
+	}
+	if !ok {
+		return "", ErrKeyNotFound
+	}
+	return v, nil
+}
+
+// HDel removes fields from the hash at k and returns how many existed.
+func (d *DB) HDel(k string, fields ...string) (int, error) {
+	st, now := d.db()
+	n, err := st.HDel(now, k, fields...)
+	return n, wrapError(err)
+}
+
+// HGetAll returns the fields of the hash at k.
+func (d *DB) HGetAll(k string) (map[string]string, error) {
+	st, now := d.db()
+	m, ok, err := st.HGetAll(now, k)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+	return m, nil
+}
+
+// HKeys returns the field names of the hash at k, sorted.
+func (d *DB) HKeys(k string) ([]string, error) {
+	m, err := d.HGetAll(k)
+	if err != nil {
+		return nil, err
+	}
+	return sortedKeys(m), nil
+}
+
+// LPush prepends vals to the list at k and returns its new length.
+func (d *DB) LPush(k string, vals ...string) (int, error) {
+	st, now := d.db()
+	n, err := st.LPush(now, k, vals...)
+	return n, wrapError(err)
+}
+
+// RPush appends vals to the list at k and returns its new length.
+func (d *DB) RPush(k string, vals ...string) (int, error) {
+	st, now := d.db()
+	n, err := st.RPush(now, k, vals...)
+	return n, wrapError(err)
+}
+
+// LPop removes and returns the first element of the list at k.
+func (d *DB) LPop(k string) (string, error) {
+	st, now := d.db()
+	return popResult(st.LPop(now, k))
+}
+
+// RPop removes and returns the last element of the list at k.
+func (d *DB) RPop(k string) (string, error) {
+	st, now := d.db()
+	return popResult(st.RPop(now, k))
+}
+
+func popResult(v string, ok bool, err error) (string, error) {
+	if err != nil {
+		return "", wrapError(err)
+	}
+	if !ok {
+		return "", ErrKeyNotFound
+	}
+	return v, nil
+}
+
+// List returns the elements of the list at k from head to tail.
+func (d *DB) List(k string) ([]string, error) {
+	st, now := d.db()
+	items, ok, err := st.ListItems(now, k)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+	return items, nil
+}
+
+// SAdd adds members to the set at k and returns how many were new.
+func (d *DB) SAdd(k string, members ...string) (int, error) {
+	st, now := d.db()
+	n, err := st.SAdd(now, k, members...)
+	return n, wrapError(err)
+}
+
+// SRem removes members from the set at k and returns how many existed.
+func (d *DB) SRem(k string, members ...string) (int, error) {
+	st, now := d.db()
+	n, err := st.SRem(now, k, members...)
+	return n, wrapError(err)
+}
+
+// SMembers returns the members of the set at k, sorted.
+func (d *DB) SMembers(k string) ([]string, error) {
+	st, now := d.db()
+	members, ok, err := st.SMembers(now, k)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+	return members, nil
+}
+
+// SIsMember reports whether member belongs to the set at k.
+func (d *DB) SIsMember(k, member string) (bool, error) {
+	st, now := d.db()
+	ok, err := st.SIsMember(now, k, member)
+	return ok, wrapError(err)
+}
+
+// ZAdd adds member to the sorted set at k, or updates its score, and reports whether it was new.
+func (d *DB) ZAdd(k string, score float64, member string) (bool, error) {
+	st, now := d.db()
+	n, err := st.ZAdd(now, k, db.ZAddOptions{}, db.ZMember{Member: member, Score: score})
+	return n == 1, wrapError(err)
+}
+
+// ZRem removes members from the sorted set at k and returns how many existed.
+func (d *DB) ZRem(k string, members ...string) (int, error) {
+	st, now := d.db()
+	n, err := st.ZRem(now, k, members...)
+	return n, wrapError(err)
+}
+
+// ZScore returns the score of member in the sorted set at k.
+func (d *DB) ZScore(k, member string) (float64, error) {
+	st, now := d.db()
+	score, ok, err := st.ZScore(now, k, member)
+	if err != nil {
+		return 0, wrapError(err)
+	}
+	if !ok {
+		return 0, ErrKeyNotFound
+	}
+	return score, nil
+}
+
+// ZMembers returns the members of the sorted set at k ordered by score, then member.
+func (d *DB) ZMembers(k string) ([]ZMember, error) {
+	st, now := d.db()
+	members, ok, err := st.ZMembers(now, k)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+	out := make([]ZMember, len(members))
+	for i, m := range members {
+		out[i] = ZMember{Member: m.Member, Score: m.Score}
+	}
+	return out, nil
+}
+
+// All iterates over every key of every database as (database index, key),
+// ordered by index and then by key. Keys are collected before the first
+// yield, so the loop body may modify the dataset.
+func (s *MiniValkey) All() iter.Seq2[int, string] {
+	return func(yield func(int, string) bool) {
+		for _, d := range s.srv.Databases() {
+			for _, e := range d.Entries {
+				if !yield(d.Index, e.Key) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// The methods below work on database 0.
+
+// Set stores a string value at k in database 0. See DB.Set.
+func (s *MiniValkey) Set(k, v string) { s.db0().Set(k, v) }
+
+// Get returns the string value at k in database 0. See DB.Get.
+func (s *MiniValkey) Get(k string) (string, error) { return s.db0().Get(k) }
+
+// Del removes k from database 0. See DB.Del.
+func (s *MiniValkey) Del(k string) bool { return s.db0().Del(k) }
+
+// Exists reports whether k exists in database 0.
+func (s *MiniValkey) Exists(k string) bool { return s.db0().Exists(k) }
+
+// Type returns the type of the value at k in database 0. See DB.Type.
+func (s *MiniValkey) Type(k string) string { return s.db0().Type(k) }
+
+// Keys returns every key of database 0, sorted.
+func (s *MiniValkey) Keys() []string { return s.db0().Keys() }
+
+// TTL returns the time left before k expires in database 0. See DB.TTL.
+func (s *MiniValkey) TTL(k string) (time.Duration, error) { return s.db0().TTL(k) }
+
+// SetTTL sets the TTL of k in database 0. See DB.SetTTL.
+func (s *MiniValkey) SetTTL(k string, ttl time.Duration) { s.db0().SetTTL(k, ttl) }
+
+// HSet sets hash fields at k in database 0. See DB.HSet.
+func (s *MiniValkey) HSet(k string, fieldValues ...string) error {
+	return s.db0().HSet(k, fieldValues...)
+}
+
+// HGet returns a hash field at k in database 0. See DB.HGet.
+func (s *MiniValkey) HGet(k, field string) (string, error) { return s.db0().HGet(k, field) }
+
+// HDel removes hash fields at k in database 0. See DB.HDel.
+func (s *MiniValkey) HDel(k string, fields ...string) (int, error) {
+	return s.db0().HDel(k, fields...)
+}
+
+// HGetAll returns the hash at k in database 0. See DB.HGetAll.
+func (s *MiniValkey) HGetAll(k string) (map[string]string, error) { return s.db0().HGetAll(k) }
+
+// HKeys returns the hash field names at k in database 0. See DB.HKeys.
+func (s *MiniValkey) HKeys(k string) ([]string, error) { return s.db0().HKeys(k) }
+
+// LPush prepends to the list at k in database 0. See DB.LPush.
+func (s *MiniValkey) LPush(k string, vals ...string) (int, error) { return s.db0().LPush(k, vals...) }
+
+// RPush appends to the list at k in database 0. See DB.RPush.
+func (s *MiniValkey) RPush(k string, vals ...string) (int, error) { return s.db0().RPush(k, vals...) }
+
+// LPop pops the head of the list at k in database 0. See DB.LPop.
+func (s *MiniValkey) LPop(k string) (string, error) { return s.db0().LPop(k) }
+
+// RPop pops the tail of the list at k in database 0. See DB.RPop.
+func (s *MiniValkey) RPop(k string) (string, error) { return s.db0().RPop(k) }
+
+// List returns the list at k in database 0. See DB.List.
+func (s *MiniValkey) List(k string) ([]string, error) { return s.db0().List(k) }
+
+// SAdd adds set members at k in database 0. See DB.SAdd.
+func (s *MiniValkey) SAdd(k string, members ...string) (int, error) {
+	return s.db0().SAdd(k, members...)
+}
+
+// SRem removes set members at k in database 0. See DB.SRem.
+func (s *MiniValkey) SRem(k string, members ...string) (int, error) {
+	return s.db0().SRem(k, members...)
+}
+
+// SMembers returns the set at k in database 0. See DB.SMembers.
+func (s *MiniValkey) SMembers(k string) ([]string, error) { return s.db0().SMembers(k) }
+
+// SIsMember reports set membership at k in database 0. See DB.SIsMember.
+func (s *MiniValkey) SIsMember(k, member string) (bool, error) {
+	return s.db0().SIsMember(k, member)
+}
+
+// ZAdd adds a sorted set member at k in database 0. See DB.ZAdd.
+func (s *MiniValkey) ZAdd(k string, score float64, member string) (bool, error) {
+	return s.db0().ZAdd(k, score, member)
+}
+
+// ZRem removes sorted set members at k in database 0. See DB.ZRem.
+func (s *MiniValkey) ZRem(k string, members ...string) (int, error) {
+	return s.db0().ZRem(k, members...)
+}
+
+// ZScore returns a sorted set score at k in database 0. See DB.ZScore.
+func (s *MiniValkey) ZScore(k, member string) (float64, error) { return s.db0().ZScore(k, member) }
+
+// ZMembers returns the sorted set at k in database 0. See DB.ZMembers.
+func (s *MiniValkey) ZMembers(k string) ([]ZMember, error) { return s.db0().ZMembers(k) }
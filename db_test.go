@@ -0,0 +1,288 @@
+package minivalkey
+
+import (
+	"errors"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mickamy/minivalkey/internal/db"
+)
+
+// newTestServer runs a server that is closed when the test ends.
+func newTestServer(t *testing.T, opts ...Option) *MiniValkey {
+	t.Helper()
+
+	s, err := Run(opts...)
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = s.Close()
+	})
+	return s
+}
+
+func TestMiniValkey_DB(t *testing.T) {
+	t.Parallel()
+
+	tcs := []struct {
+		name    string
+		idx     int
+		wantErr error
+	}{
+		{name: "first database", idx: 0},
+		{name: "last database", idx: 15},
+		{name: "past the last database", idx: 16, wantErr: ErrDBIndexOutOfRange},
+		{name: "negative index", idx: -1, wantErr: ErrDBIndexOutOfRange},
+	}
+
+	s := newTestServer(t)
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			d, err := s.DB(tc.idx)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("expected error %v, got %v", tc.wantErr, err)
+			}
+			if (d == nil) != (tc.wantErr != nil) {
+				t.Fatalf("expected a DB only without an error, got %v", d)
+			}
+		})
+	}
+}
+
+func TestDB_TTL(t *testing.T) {
+	t.Parallel()
+
+	tcs := []struct {
+		name    string
+		arrange func(s *MiniValkey)
+		want    time.Duration
+		wantErr error
+	}{
+		{
+			name:    "missing key",
+			arrange: func(s *MiniValkey) {},
+			wantErr: ErrKeyNotFound,
+		},
+		{
+			name:    "key without expiry",
+			arrange: func(s *MiniValkey) { s.Set("k", "v") },
+			wantErr: ErrNoTTL,
+		},
+		{
+			name: "key with expiry",
+			arrange: func(s *MiniValkey) {
+				s.Set("k", "v")
+				s.SetTTL("k", time.Minute)
+				s.FastForward(10 * time.Second)
+			},
+			want: 50 * time.Second,
+		},
+		{
+			name: "expired key",
+			arrange: func(s *MiniValkey) {
+				s.Set("k", "v")
+				s.SetTTL("k", time.Minute)
+				s.FastForward(2 * time.Minute)
+			},
+			wantErr: ErrKeyNotFound,
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			s := newTestServer(t)
+			tc.arrange(s)
+			got, err := s.TTL("k")
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("expected error %v, got %v", tc.wantErr, err)
+			}
+			if got != tc.want {
+				t.Fatalf("expected ttl %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestDB_HSet(t *testing.T) {
+	t.Parallel()
+
+	tcs := []struct {
+		name        string
+		arrange     func(s *MiniValkey)
+		fieldValues []string
+		wantErr     error
+		want        map[string]string
+	}{
+		{
+			name:        "sets field value pairs",
+			arrange:     func(s *MiniValkey) {},
+			fieldValues: []string{"name", "Ann", "age", "30"},
+			want:        map[string]string{"name": "Ann", "age": "30"},
+		},
+		{
+			name:        "rejects a field without a value",
+			arrange:     func(s *MiniValkey) {},
+			fieldValues: []string{"name", "Ann", "age"},
+			wantErr:     ErrFieldValuePairs,
+		},
+		{
+			name:        "rejects another type",
+			arrange:     func(s *MiniValkey) { s.Set("h", "v") },
+			fieldValues: []string{"name", "Ann"},
+			wantErr:     ErrWrongType,
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			s := newTestServer(t)
+			tc.arrange(s)
+			if err := s.HSet("h", tc.fieldValues...); !errors.Is(err, tc.wantErr) {
+				t.Fatalf("expected error %v, got %v", tc.wantErr, err)
+			}
+			got, err := s.HGetAll("h")
+			if tc.want == nil {
+				if err == nil {
+					t.Fatalf("expected nothing to be written, got %v", got)
+				}
+				return
+			}
+			if err != nil || !maps.Equal(got, tc.want) {
+				t.Fatalf("expected %v, got %v (%v)", tc.want, got, err)
+			}
+		})
+	}
+}
+
+func TestDB_Del(t *testing.T) {
+	t.Parallel()
+
+	tcs := []struct {
+		name    string
+		arrange func(s *MiniValkey)
+		want    bool
+	}{
+		{
+			name:    "existing key",
+			arrange: func(s *MiniValkey) { s.Set("k", "v") },
+			want:    true,
+		},
+		{
+			name:    "missing key",
+			arrange: func(s *MiniValkey) {},
+			want:    false,
+		},
+		{
+			name: "expired key",
+			arrange: func(s *MiniValkey) {
+				s.Set("k", "v")
+				s.SetTTL("k", time.Second)
+				s.FastForward(2 * time.Second)
+			},
+			want: false,
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			s := newTestServer(t)
+			tc.arrange(s)
+			if got := s.Del("k"); got != tc.want {
+				t.Fatalf("expected Del to report %v, got %v", tc.want, got)
+			}
+			if s.Exists("k") {
+				t.Fatalf("expected k to be gone")
+			}
+		})
+	}
+}
+
+func TestMiniValkey_All(t *testing.T) {
+	t.Parallel()
+
+	s := newTestServer(t)
+	db3, err := s.DB(3)
+	if err != nil {
+		t.Fatalf("DB returned error: %v", err)
+	}
+	db3.Set("c", "v")
+	s.Set("b", "v")
+	s.Set("a", "v")
+
+	type item struct {
+		idx int
+		key string
+	}
+	var got []item
+	for idx, k := range s.All() {
+		got = append(got, item{idx, k})
+		// Writing during the loop does not disturb it.
+		s.Set("z", "v")
+	}
+	want := []item{{0, "a"}, {0, "b"}, {3, "c"}}
+	if !slices.Equal(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+
+	var first []item
+	for idx, k := range s.All() {
+		first = append(first, item{idx, k})
+		break
+	}
+	if want := []item{{0, "a"}}; !slices.Equal(first, want) {
+		t.Fatalf("expected the loop to stop after %v, got %v", want, first)
+	}
+}
+
+func TestMiniValkey_Dump(t *testing.T) {
+	t.Parallel()
+
+	s := newTestServer(t)
+	s.Set("greeting", "hello")
+	s.SetTTL("greeting", 90*time.Second)
+	s.Set("long", strings.Repeat("x", 70))
+	_, _ = s.RPush("queue", "a", "b")
+	_, _ = s.SAdd("tags", "y", "x")
+	_, _ = s.ZAdd("scores", 1.5, "alice")
+	_ = s.HSet("user:1", "name", "Ann", "token", "t")
+	d0, _ := s.srv.DB(0)
+	d0.HExpireAt(s.srv.Now(), "user:1", s.srv.Now().Add(10*time.Second), db.ExpireOptions{}, "token")
+	db2, _ := s.DB(2)
+	db2.Set("other", "v")
+
+	want := "db 0\n" +
+		`  "greeting" = "hello" (ttl 1m30s)` + "\n" +
+		`  "long" = "` + strings.Repeat("x", 60) + `"...(70 bytes)` + "\n" +
+		`  "queue" = list ["a" "b"]` + "\n" +
+		`  "scores" = zset ["alice"=1.5]` + "\n" +
+		`  "tags" = set ["x" "y"]` + "\n" +
+		`  "user:1" = hash {"name"="Ann" "token"="t" (ttl 10s)}` + "\n" +
+		"db 2\n" +
+		`  "other" = "v"` + "\n"
+	if got := s.Dump(); got != want {
+		t.Fatalf("unexpected dump:\nwant %s\ngot  %s", want, got)
+	}
+	if got, want := db2.Dump(), "db 2\n  \"other\" = \"v\"\n"; got != want {
+		t.Fatalf("unexpected dump of db 2:\nwant %s\ngot  %s", want, got)
+	}
+	empty, _ := s.DB(5)
+	if got := empty.Dump(); got != "" {
+		t.Fatalf("expected an empty dump, got %q", got)
+	}
+}
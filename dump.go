@@ -0,0 +1,103 @@
+package minivalkey
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mickamy/minivalkey/internal/rdb"
+)
+
+// dumpValueLen is how many bytes of a value Dump prints before truncating it.
+const dumpValueLen = 60
+
+// Dump returns a human-readable listing of every key, meant for test failure messages:
+//
+//	db 0
+//	  "greeting" = "hello" (ttl 1m30s)
+//	  "queue" = list ["a" "b"]
+//	  "user:1" = hash {"name"="Ann" "token"="t" (ttl 10s)}
+//
+// Long values are truncated.
+func (s *MiniValkey) Dump() string {
+	return s.dump(-1)
+}
+
+// Dump returns a human-readable listing of the keys of this database.
+// See MiniValkey.Dump for the format.
+func (d *DB) Dump() string {
+	return d.s.dump(d.idx)
+}
+
+// dump lists the keys of database idx, or of every database when idx is negative.
+func (s *MiniValkey) dump(idx int) string {
+	now := s.srv.Now()
+	var b strings.Builder
+	for _, d := range s.srv.Databases() {
+		if len(d.Entries) == 0 || (idx >= 0 && d.Index != idx) {
+			continue
+		}
+		fmt.Fprintf(&b, "db %d\n", d.Index)
+		for _, e := range d.Entries {
+			fmt.Fprintf(&b, "  %s = %s%s\n", dumpString(e.Key), dumpValue(e.Value, now), dumpTTL(e.ExpireAt, now))
+		}
+	}
+	return b.String()
+}
+
+// dumpValue formats a value with its type.
+func dumpValue(v rdb.Value, now time.Time) string {
+	var parts []string
+	switch v.Kind {
+	case rdb.KindList:
+		for _, item := range v.List {
+			parts = append(parts, dumpString(item))
+		}
+		return "list [" + strings.Join(parts, " ") + "]"
+	case rdb.KindSet:
+		for _, m := range slices.Sorted(slices.Values(v.Set)) {
+			parts = append(parts, dumpString(m))
+		}
+		return "set [" + strings.Join(parts, " ") + "]"
+	case rdb.KindZSet:
+		for _, m := range v.ZSet {
+			parts = append(parts, dumpString(m.Member)+"="+strconv.FormatFloat(m.Score, 'g', -1, 64))
+		}
+		return "zset [" + strings.Join(parts, " ") + "]"
+	case rdb.KindHash:
+		for _, f := range v.Hash {
+			parts = append(parts, dumpString(f.Field)+"="+dumpString(f.Value)+dumpTTL(f.ExpireAt, now))
+		}
+		return "hash {" + strings.Join(parts, " ") + "}"
+	default:
+		return dumpString(v.String)
+	}
+}
+
+// dumpString quotes v, truncating it past dumpValueLen bytes.
+func dumpString(v string) string {
+	if len(v) <= dumpValueLen {
+		return strconv.Quote(v)
+	}
+	return fmt.Sprintf("%s...(%d bytes)", strconv.Quote(v[:dumpValueLen]), len(v))
+}
+
+// dumpTTL formats the time left until at, or nothing when there is no expiry.
+func dumpTTL(at, now time.Time) string {
+	if at.IsZero() {
+		return ""
+	}
+	return fmt.Sprintf(" (ttl %s)", at.Sub(now))
+}
+
+// sortedKeys returns the keys of m in order.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,85 @@
+package e2e
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valkey-io/valkey-go"
+
+	"github.com/mickamy/minivalkey"
+)
+
+// E2E tests using valkey-go's cluster client.
+// Verifies slot discovery, MOVED-free routing of keys across nodes, hash tags and simulated time.
+func TestCluster_WithValkeyGo(t *testing.T) {
+	c, err := minivalkey.RunCluster(3)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = c.Close() })
+
+	client, err := valkey.NewClient(valkey.ClientOption{
+		InitAddress:           c.Addrs(),
+		DisableCache:          true,
+		DisableAutoPipelining: true,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+
+	ctx := t.Context()
+
+	// --- SET / GET across every node ---
+	keys := make([]string, 30)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("user:%d", i)
+		require.NoError(t, client.Do(ctx, client.B().Set().Key(keys[i]).Value(keys[i]).Build()).Error())
+	}
+	owners := map[string]bool{}
+	for _, k := range keys {
+		str, err := client.Do(ctx, client.B().Get().Key(k).Build()).ToString()
+		require.NoError(t, err)
+		assert.Equal(t, k, str)
+
+		// The key lives on the node owning its slot, and only there.
+		node := c.NodeForKey(k)
+		owners[node.Addr()] = true
+		got, err := node.Get(k)
+		require.NoError(t, err)
+		assert.Equal(t, k, got)
+		for _, other := range c.Nodes() {
+			if other != node {
+				assert.False(t, other.Exists(k), "key %q on a node that does not own it", k)
+			}
+		}
+	}
+	assert.Len(t, owners, 3, "expected the keys to spread over every node")
+
+	// --- multi-key commands on a hash tag ---
+	{
+		require.NoError(t, client.Do(ctx, client.B().Mset().KeyValue().KeyValue("{order:1}:id", "1").KeyValue("{order:1}:total", "42").Build()).Error())
+		vals, err := client.Do(ctx, client.B().Mget().Key("{order:1}:id", "{order:1}:total").Build()).AsStrSlice()
+		require.NoError(t, err)
+		assert.Equal(t, []string{"1", "42"}, vals)
+	}
+
+	// --- COPY to another database ---
+	{
+		err := client.Do(ctx, client.B().Copy().Source("{order:1}:id").Destination("{order:1}:copy").Db(1).Build()).Error()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Copying to another database is not allowed in cluster mode")
+	}
+
+	// --- TTL and simulated time on every node ---
+	{
+		for _, k := range keys {
+			require.NoError(t, client.Do(ctx, client.B().Expire().Key(k).Seconds(10).Build()).Error())
+		}
+		c.FastForward(11 * time.Second)
+		for _, k := range keys {
+			n, err := client.Do(ctx, client.B().Exists().Key(k).Build()).AsInt64()
+			require.NoError(t, err)
+			assert.Zero(t, n, "expected %q to expire", k)
+		}
+	}
+}
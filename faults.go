@@ -0,0 +1,58 @@
+package minivalkey
+
+import (
+	"github.com/mickamy/minivalkey/internal/server"
+)
+
+// Error replies a healthy server never sends, for use with InjectFault.
+const (
+	FaultLoading     = "LOADING Valkey is loading the dataset in memory"
+	FaultBusy        = "BUSY Valkey is busy running a script. You can only call SCRIPT KILL or FUNCTION KILL."
+	FaultOOM         = "OOM command not allowed when used memory > 'maxmemory'."
+	FaultReadOnly    = "READONLY You can't write against a read only replica."
+	FaultTryAgain    = "TRYAGAIN Multiple keys request during rehashing of slot"
+	FaultMisconf     = "MISCONF Valkey is configured to save RDB snapshots, but it's currently unable to persist to disk. Commands that may modify the data set are disabled, because this instance is configured to report errors during writes if RDB snapshotting fails (stop-writes-on-bgsave-error option). Please check the Valkey logs for details about the RDB error."
+	FaultClusterDown = "CLUSTERDOWN The cluster is down"
+)
+
+// FaultOption narrows down which commands a fault applies to, or how often.
+//
+// Deprecated: Use RuleOption, which latency and truncation rules share.
+type FaultOption = RuleOption
+
+// WithFaultSeed seeds the random source of faults injected with Probability,
+// so the same commands fail on every run.
+//
+// Deprecated: Use WithSeed, which also makes latency jitter repeatable.
+func WithFaultSeed(seed uint64) Option {
+	return WithSeed(seed)
+}
+
+// Fault is a registered fault rule.
+type Fault struct {
+	s  *MiniValkey
+	id int
+}
+
+// InjectFault makes commands fail with the error reply instead of running.
+// Without options, every command fails until the fault is removed:
+//
+//	s.InjectFault(minivalkey.FaultLoading, minivalkey.OnCommand("GET"), minivalkey.Times(2))
+//	s.InjectFault("ERR custom", minivalkey.OnKey("user:*"), minivalkey.Probability(0.1))
+//
+// Rules are checked in the order they were added and the first one that
+// fires wins. Faults are decided before the command runs, so a failed
+// command has no effect.
+func (s *MiniValkey) InjectFault(reply string, opts ...RuleOption) *Fault {
+	return &Fault{s: s, id: s.srv.AddFault(server.FaultRule{CommandRule: commandRule(opts), Reply: reply})}
+}
+
+// Remove unregisters the fault. It is safe to call after the fault used up its Times.
+func (f *Fault) Remove() {
+	f.s.srv.RemoveFault(f.id)
+}
+
+// ClearFaults removes every injected fault.
+func (s *MiniValkey) ClearFaults() {
+	s.srv.ClearFaults()
+}
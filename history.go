@@ -0,0 +1,57 @@
+package minivalkey
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+// Command is a command the server executed, as recorded in its history.
+type Command struct {
+	Time     time.Time // simulated time the command ran at
+	ClientID int64
+	DB       int // database selected when the command ran
+	Name     string
+	Args     []string // arguments after the command name
+	Keys     []string // the arguments that are keys
+	Err      string   // error reply, empty on success
+}
+
+// HistoryFilter selects commands from the history.
+type HistoryFilter func(Command) bool
+
+// ByName selects commands named name, in any case.
+func ByName(name string) HistoryFilter {
+	return func(c Command) bool {
+		return strings.EqualFold(c.Name, name)
+	}
+}
+
+// ByKey selects commands that take k as a key.
+func ByKey(k string) HistoryFilter {
+	return func(c Command) bool {
+		return slices.Contains(c.Keys, k)
+	}
+}
+
+// History returns the commands clients ran, oldest first, keeping those every filter selects:
+//
+//	sets := s.History(minivalkey.ByName("SET"), minivalkey.ByKey("session"))
+//
+// Commands that failed are included, with Err set. Use WithHistoryLimit to bound
+// how many commands are kept.
+func (s *MiniValkey) History(filters ...HistoryFilter) []Command {
+	var out []Command
+	for _, e := range s.srv.History() {
+		c := Command(e)
+		if !slices.ContainsFunc(filters, func(f HistoryFilter) bool { return !f(c) }) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// ResetHistory forgets the commands recorded so far.
+func (s *MiniValkey) ResetHistory() {
+	s.srv.ResetHistory()
+}
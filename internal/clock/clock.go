@@ -39,3 +39,17 @@ func (c *Clock) Base() time.Time {
 	defer c.mu.RUnlock()
 	return c.base
 }
+
+// Offset returns how far the clock has been advanced from its base.
+func (c *Clock) Offset() time.Duration {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.offset
+}
+
+// SetOffset moves the clock to base+d, which may lie before the current time.
+func (c *Clock) SetOffset(d time.Duration) {
+	c.mu.Lock()
+	c.offset = d
+	c.mu.Unlock()
+}
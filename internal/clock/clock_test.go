@@ -76,3 +76,18 @@ func TestClock_ConcurrentAccess(t *testing.T) {
 		t.Fatalf("Final Now() = %v, want %v", got, expectedTime)
 	}
 }
+
+func TestClock_SetOffset(t *testing.T) {
+	t.Parallel()
+
+	c := clock.New(base)
+	c.Advance(10 * time.Second)
+	if got := c.Offset(); got != 10*time.Second {
+		t.Fatalf("Offset() = %v, want %v", got, 10*time.Second)
+	}
+
+	c.SetOffset(3 * time.Second)
+	if got, want := c.Now(), base.Add(3*time.Second); !got.Equal(want) {
+		t.Fatalf("Now() after SetOffset = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,119 @@
+// Package cluster maps keys to Valkey Cluster hash slots and splits the
+// slots between the nodes of an emulated cluster.
+package cluster
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// NumSlots is the number of hash slots in a Valkey Cluster.
+const NumSlots = 16384
+
+// KeySlot returns the hash slot of key. Like Valkey, only the part between
+// the first { and the next } is hashed when that part is not empty, so keys
+// sharing a hash tag such as {user:1} land in the same slot.
+func KeySlot(key string) int {
+	if start := strings.IndexByte(key, '{'); start >= 0 {
+		if end := strings.IndexByte(key[start+1:], '}'); end > 0 {
+			key = key[start+1 : start+1+end]
+		}
+	}
+	return int(crc16(key) % NumSlots)
+}
+
+// crc16 implements CRC16-CCITT (XModem), the checksum Valkey Cluster hashes keys with.
+func crc16(s string) uint16 {
+	var crc uint16
+	for i := 0; i < len(s); i++ {
+		crc ^= uint16(s[i]) << 8
+		for range 8 {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
+
+// SlotRange is an inclusive range of hash slots.
+type SlotRange struct {
+	Start, End int
+}
+
+// Node is one primary of the cluster.
+type Node struct {
+	ID    string // 40 hex characters, derived from the address
+	Host  string
+	Port  int
+	Epoch int
+	Slots []SlotRange
+}
+
+// Addr returns the host:port clients connect to.
+func (n *Node) Addr() string {
+	return net.JoinHostPort(n.Host, strconv.Itoa(n.Port))
+}
+
+// Topology is the slot map every node of a cluster shares.
+type Topology struct {
+	Nodes  []*Node
+	owners [NumSlots]*Node
+}
+
+// New splits the slots evenly between nodes listening on addrs, in order.
+func New(addrs []string) (*Topology, error) {
+	if len(addrs) == 0 {
+		return nil, errors.New("cluster needs at least one node")
+	}
+	if len(addrs) > NumSlots {
+		return nil, errors.New("cluster has more nodes than slots")
+	}
+
+	t := &Topology{}
+	for i, addr := range addrs {
+		host, portStr, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+		port, err := strconv.Atoi(portStr)
+		if err != nil {
+			return nil, err
+		}
+		sum := sha1.Sum([]byte(addr))
+		start, end := i*NumSlots/len(addrs), (i+1)*NumSlots/len(addrs)-1
+		n := &Node{
+			ID:    hex.EncodeToString(sum[:]),
+			Host:  host,
+			Port:  port,
+			Epoch: i + 1,
+			Slots: []SlotRange{{Start: start, End: end}},
+		}
+		for slot := start; slot <= end; slot++ {
+			t.owners[slot] = n
+		}
+		t.Nodes = append(t.Nodes, n)
+	}
+	return t, nil
+}
+
+// Owner returns the node serving slot.
+func (t *Topology) Owner(slot int) *Node {
+	return t.owners[slot]
+}
+
+// Node returns the node with the given ID, or nil.
+func (t *Topology) Node(id string) *Node {
+	for _, n := range t.Nodes {
+		if n.ID == id {
+			return n
+		}
+	}
+	return nil
+}
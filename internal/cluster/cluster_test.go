@@ -0,0 +1,94 @@
+package cluster
+
+import (
+	"testing"
+)
+
+func TestKeySlot(t *testing.T) {
+	t.Parallel()
+
+	tcs := []struct {
+		key  string
+		want int
+	}{
+		// Expected slots as reported by CLUSTER KEYSLOT on Valkey.
+		{key: "", want: 0},
+		{key: "foo", want: 12182},
+		{key: "bar", want: 5061},
+		{key: "123456789", want: 12739},
+		{key: "{user1000}.following", want: 3443},
+		{key: "{user1000}.followers", want: 3443},
+		{key: "user1000", want: 3443},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.key, func(t *testing.T) {
+			t.Parallel()
+
+			if got := KeySlot(tc.key); got != tc.want {
+				t.Fatalf("expected slot %d, got %d", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestKeySlot_HashTags(t *testing.T) {
+	t.Parallel()
+
+	tcs := []struct {
+		key    string
+		hashed string
+	}{
+		{key: "{user1000}.following", hashed: "user1000"},
+		{key: "foo{}{bar}", hashed: "foo{}{bar}"},
+		{key: "foo{{bar}}zap", hashed: "{bar"},
+		{key: "foo{bar}{zap}", hashed: "bar"},
+		{key: "foo{bar", hashed: "foo{bar"},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.key, func(t *testing.T) {
+			t.Parallel()
+
+			if got, want := KeySlot(tc.key), int(crc16(tc.hashed)%NumSlots); got != want {
+				t.Fatalf("expected %q to hash as %q (slot %d), got slot %d", tc.key, tc.hashed, want, got)
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	topo, err := New([]string{"127.0.0.1:7000", "127.0.0.1:7001", "127.0.0.1:7002"})
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+
+	want := []SlotRange{{0, 5460}, {5461, 10921}, {10922, 16383}}
+	for i, n := range topo.Nodes {
+		if len(n.Slots) != 1 || n.Slots[0] != want[i] {
+			t.Fatalf("node %d: expected slots %v, got %v", i, want[i], n.Slots)
+		}
+		if len(n.ID) != 40 || n.Addr() != "127.0.0.1:700"+string(rune('0'+i)) {
+			t.Fatalf("node %d: unexpected id %q or addr %q", i, n.ID, n.Addr())
+		}
+		if topo.Node(n.ID) != n {
+			t.Fatalf("node %d: expected lookup by id to find it", i)
+		}
+	}
+	for _, slot := range []int{0, 5460, 5461, 16383} {
+		if owner := topo.Owner(slot); owner.Slots[0].Start > slot || owner.Slots[0].End < slot {
+			t.Fatalf("slot %d: unexpected owner %v", slot, owner.Slots)
+		}
+	}
+
+	if _, err := New(nil); err == nil {
+		t.Fatalf("expected an error for an empty cluster")
+	}
+	if _, err := New([]string{"nohost"}); err == nil {
+		t.Fatalf("expected an error for a bad address")
+	}
+}
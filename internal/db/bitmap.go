@@ -0,0 +1,326 @@
+package db
+
+import (
+	"math"
+	"time"
+)
+
+// MaxBitOffset is the highest bit offset a string can address (MaxStringLen bytes).
+const MaxBitOffset = MaxStringLen*8 - 1
+
+// BitOp enumerates BITOP operations.
+type BitOp int
+
+const (
+	BitOpAnd BitOp = iota
+	BitOpOr
+	BitOpXor
+	BitOpNot
+	BitOpDiff // First source AND NOT any of the others
+	BitOpOne  // Bits set in exactly one source
+)
+
+// BitFieldOpKind enumerates BITFIELD subcommands.
+type BitFieldOpKind int
+
+const (
+	BitFieldGet BitFieldOpKind = iota
+	BitFieldSet
+	BitFieldIncrBy
+)
+
+// BitFieldOverflow enumerates BITFIELD overflow behaviors.
+type BitFieldOverflow int
+
+const (
+	OverflowWrap BitFieldOverflow = iota
+	OverflowSat
+	OverflowFail
+)
+
+// BitFieldOp is one GET/SET/INCRBY operation of a BITFIELD call.
+type BitFieldOp struct {
+	Kind     BitFieldOpKind
+	Signed   bool
+	Bits     uint   // 1..64 for signed, 1..63 for unsigned
+	Offset   uint64 // Bit offset of the most significant bit
+	Value    int64  // SET value or INCRBY increment
+	Overflow BitFieldOverflow
+}
+
+// SetBit sets or clears the bit at offset in the string at k, growing it as needed.
+// Returns the previous bit value.
+func (db *DB) SetBit(now time.Time, k string, offset uint64, on bool) (int, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	e, err := db.growString(now, k, offset)
+	if err != nil {
+		return 0, err
+	}
+	b := []byte(e.s)
+	prev := GetBit(b, offset)
+	byteIdx := offset >> 3
+	mask := byte(1 << (7 - offset&7))
+	if on {
+		b[byteIdx] |= mask
+	} else {
+		b[byteIdx] &^= mask
+	}
+	e.s = string(b)
+	return prev, nil
+}
+
+// BitOpStore performs op over the strings at srcs and stores the result at dst,
+// clearing any TTL. Missing sources count as empty strings; an empty result
+// deletes dst. Returns the length of the stored string.
+func (db *DB) BitOpStore(now time.Time, op BitOp, dst string, srcs ...string) (int, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	vals := make([]string, len(srcs))
+	maxLen := 0
+	for i, k := range srcs {
+		e, err := db.lookupString(now, k)
+		if err != nil {
+			return 0, err
+		}
+		if e != nil {
+			vals[i] = e.s
+		}
+		maxLen = max(maxLen, len(vals[i]))
+	}
+
+	if maxLen == 0 {
+		delete(db.entries, dst)
+		return 0, nil
+	}
+
+	byteAt := func(v string, i int) byte {
+		if i < len(v) {
+			return v[i]
+		}
+		return 0
+	}
+	out := make([]byte, maxLen)
+	for i := range out {
+		b := byteAt(vals[0], i)
+		switch op {
+		case BitOpNot:
+			b = ^b
+		case BitOpAnd:
+			for _, v := range vals[1:] {
+				b &= byteAt(v, i)
+			}
+		case BitOpOr:
+			for _, v := range vals[1:] {
+				b |= byteAt(v, i)
+			}
+		case BitOpXor:
+			for _, v := range vals[1:] {
+				b ^= byteAt(v, i)
+			}
+		case BitOpDiff:
+			var others byte
+			for _, v := range vals[1:] {
+				others |= byteAt(v, i)
+			}
+			b &^= others
+		case BitOpOne:
+			// A bit survives if it was seen exactly once: track bits seen at
+			// least once (b) and bits seen more than once (many).
+			var many byte
+			for _, v := range vals[1:] {
+				c := byteAt(v, i)
+				many |= b & c
+				b |= c
+			}
+			b &^= many
+		}
+		out[i] = b
+	}
+	db.entries[dst] = &entry{typ: TString, s: string(out), lastAccess: now}
+	return maxLen, nil
+}
+
+// BitField runs ops against the string at k, in order.
+// Returns one result per operation; a nil result marks a SET/INCRBY skipped
+// because of an OVERFLOW FAIL. The key is only created when a SET or INCRBY is present.
+func (db *DB) BitField(now time.Time, k string, ops []BitFieldOp) ([]*int64, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	var highest uint64
+	writes := false
+	for _, op := range ops {
+		if op.Kind != BitFieldGet {
+			writes = true
+			highest = max(highest, op.Offset+uint64(op.Bits)-1)
+		}
+	}
+
+	var b []byte
+	var e *entry
+	if writes {
+		var err error
+		if e, err = db.growString(now, k, highest); err != nil {
+			return nil, err
+		}
+		b = []byte(e.s)
+	} else {
+		found, err := db.lookupString(now, k)
+		if err != nil {
+			return nil, err
+		}
+		if found != nil {
+			found.lastAccess = now
+			b = []byte(found.s)
+		}
+	}
+
+	results := make([]*int64, len(ops))
+	for i, op := range ops {
+		var v int64
+		switch op.Kind {
+		case BitFieldGet:
+			v = readBitField(b, op)
+		case BitFieldSet, BitFieldIncrBy:
+			old := readBitField(b, op)
+			var next int64
+			var overflow bool
+			if op.Kind == BitFieldSet {
+				v = old
+				next, overflow = bitFieldAdd(op, op.Value, 0)
+			} else {
+				next, overflow = bitFieldAdd(op, old, op.Value)
+				v = next
+			}
+			if overflow && op.Overflow == OverflowFail {
+				continue
+			}
+			writeBitField(b, op.Offset, op.Bits, uint64(next))
+		}
+		results[i] = &v
+	}
+	if e != nil {
+		e.s = string(b)
+	}
+	return results, nil
+}
+
+// GetBit returns the bit at offset in b, treating bytes past the end as zero.
+func GetBit[T ~string | ~[]byte](b T, offset uint64) int {
+	byteIdx := offset >> 3
+	if byteIdx >= uint64(len(b)) {
+		return 0
+	}
+	if b[byteIdx]&(1<<(7-offset&7)) != 0 {
+		return 1
+	}
+	return 0
+}
+
+// growString returns the string entry at k, creating it and zero-padding it
+// so that bit offset is addressable. The entry keeps its TTL.
+// Callers must hold the write lock.
+func (db *DB) growString(now time.Time, k string, offset uint64) (*entry, error) {
+	e, err := db.lookupString(now, k)
+	if err != nil {
+		return nil, err
+	}
+	if e == nil {
+		e = &entry{typ: TString}
+		db.entries[k] = e
+	}
+	e.lastAccess = now
+	if need := int(offset>>3) + 1; len(e.s) < need {
+		e.s = overwrite(e.s, need-1, "\x00")
+	}
+	return e, nil
+}
+
+// readBitField reads an op.Bits wide integer at op.Offset, sign-extending for signed types.
+func readBitField(b []byte, op BitFieldOp) int64 {
+	var u uint64
+	for i := uint64(0); i < uint64(op.Bits); i++ {
+		u = u<<1 | uint64(GetBit(b, op.Offset+i))
+	}
+	if op.Signed && op.Bits < 64 && u&(1<<(op.Bits-1)) != 0 {
+		u |= math.MaxUint64 << op.Bits
+	}
+	return int64(u)
+}
+
+// writeBitField writes the low "bits" bits of v at offset, most significant bit first.
+// b must be long enough to hold the field.
+func writeBitField(b []byte, offset uint64, bits uint, v uint64) {
+	for i := uint64(0); i < uint64(bits); i++ {
+		pos := offset + i
+		mask := byte(1 << (7 - pos&7))
+		if v&(1<<(uint64(bits)-1-i)) != 0 {
+			b[pos>>3] |= mask
+		} else {
+			b[pos>>3] &^= mask
+		}
+	}
+}
+
+// bitFieldAdd computes value+incr for the op's type and reports whether it
+// overflowed, returning the wrapped or saturated result per op.Overflow.
+// It mirrors Valkey's check{Signed,Unsigned}BitfieldOverflow.
+func bitFieldAdd(op BitFieldOp, value, incr int64) (int64, bool) {
+	bits := op.Bits
+	if !op.Signed {
+		uval := uint64(value)
+		maxv := uint64(1)<<bits - 1
+		maxincr := int64(maxv - uval)
+		minincr := -int64(uval)
+		wrap := func() int64 { return int64((uval + uint64(incr)) &^ (math.MaxUint64 << bits)) }
+		switch {
+		case uval > maxv || (incr > 0 && incr > maxincr):
+			if op.Overflow == OverflowSat {
+				return int64(maxv), true
+			}
+			return wrap(), true
+		case incr < 0 && incr < minincr:
+			if op.Overflow == OverflowSat {
+				return 0, true
+			}
+			return wrap(), true
+		}
+		return value + incr, false
+	}
+
+	maxv := int64(math.MaxInt64)
+	if bits < 64 {
+		maxv = int64(1)<<(bits-1) - 1
+	}
+	minv := -maxv - 1
+	maxincr := int64(uint64(maxv) - uint64(value))
+	minincr := minv - value
+	wrap := func() int64 {
+		c := uint64(value) + uint64(incr)
+		if bits < 64 {
+			mask := uint64(math.MaxUint64) << bits
+			if c&(1<<(bits-1)) != 0 {
+				c |= mask
+			} else {
+				c &^= mask
+			}
+		}
+		return int64(c)
+	}
+	switch {
+	case value > maxv || (bits != 64 && incr > maxincr) || (value >= 0 && incr > 0 && incr > maxincr):
+		if op.Overflow == OverflowSat {
+			return maxv, true
+		}
+		return wrap(), true
+	case value < minv || (bits != 64 && incr < minincr) || (value < 0 && incr < 0 && incr < minincr):
+		if op.Overflow == OverflowSat {
+			return minv, true
+		}
+		return wrap(), true
+	}
+	return value + incr, false
+}
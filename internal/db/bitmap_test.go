@@ -0,0 +1,223 @@
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStore_SetBit(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(0, 0)
+
+	tcs := []struct {
+		name    string
+		arrange func(*DB)
+		offset  uint64
+		on      bool
+		want    int
+		wantVal string
+	}{
+		{
+			name:    "creates zero padded string",
+			offset:  7,
+			on:      true,
+			want:    0,
+			wantVal: "\x01",
+		},
+		{
+			name: "returns previous bit when clearing",
+			arrange: func(st *DB) {
+				st.SetString("k", "\xff", time.Time{})
+			},
+			offset:  0,
+			want:    1,
+			wantVal: "\x7f",
+		},
+		{
+			name: "grows existing value",
+			arrange: func(st *DB) {
+				st.SetString("k", "a", time.Time{})
+			},
+			offset:  17,
+			on:      true,
+			wantVal: "a\x00\x40",
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			st := New()
+			if tc.arrange != nil {
+				tc.arrange(st)
+			}
+			got, err := st.SetBit(now, "k", tc.offset, tc.on)
+			if err != nil {
+				t.Fatalf("SetBit returned error: %v", err)
+			}
+			if got != tc.want {
+				t.Fatalf("expected %d, got %d", tc.want, got)
+			}
+			if v, _ := st.GetString(now, "k"); v != tc.wantVal {
+				t.Fatalf("expected %q, got %q", tc.wantVal, v)
+			}
+		})
+	}
+}
+
+func TestStore_BitOpStore(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(0, 0)
+
+	tcs := []struct {
+		name    string
+		op      BitOp
+		srcs    []string
+		want    int
+		wantVal string
+		wantOK  bool
+	}{
+		{name: "and", op: BitOpAnd, srcs: []string{"a", "b"}, want: 6, wantVal: "`bc`ab", wantOK: true},
+		{name: "or pads shorter source", op: BitOpOr, srcs: []string{"x", "y"}, want: 2, wantVal: "\xf0\x0f", wantOK: true},
+		{name: "and pads shorter source with zeros", op: BitOpAnd, srcs: []string{"x", "y"}, want: 2, wantVal: "\x00\x00", wantOK: true},
+		{name: "xor", op: BitOpXor, srcs: []string{"x", "x"}, want: 1, wantVal: "\x00", wantOK: true},
+		{name: "not", op: BitOpNot, srcs: []string{"x"}, want: 1, wantVal: "\x0f", wantOK: true},
+		{name: "diff", op: BitOpDiff, srcs: []string{"z", "x", "y"}, want: 2, wantVal: "\x0f\xf0", wantOK: true},
+		{name: "one", op: BitOpOne, srcs: []string{"z", "x", "y"}, want: 2, wantVal: "\x0f\xf0", wantOK: true},
+		{name: "missing sources delete destination", op: BitOpOr, srcs: []string{"missing"}},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			st := New()
+			st.SetString("a", "foobar", time.Time{})
+			st.SetString("b", "abcdef", time.Time{})
+			st.SetString("x", "\xf0", time.Time{})
+			st.SetString("y", "\x00\x0f", time.Time{})
+			st.SetString("z", "\xff\xff", time.Time{})
+			st.SetString("dst", "old", now.Add(time.Hour))
+
+			got, err := st.BitOpStore(now, tc.op, "dst", tc.srcs...)
+			if err != nil {
+				t.Fatalf("BitOpStore returned error: %v", err)
+			}
+			if got != tc.want {
+				t.Fatalf("expected %d, got %d", tc.want, got)
+			}
+			v, ok := st.GetString(now, "dst")
+			if ok != tc.wantOK || v != tc.wantVal {
+				t.Fatalf("expected (%q, %v), got (%q, %v)", tc.wantVal, tc.wantOK, v, ok)
+			}
+			if ok && st.PTTL(now, "dst") != -1 {
+				t.Fatalf("expected destination TTL to be cleared")
+			}
+		})
+	}
+}
+
+func TestStore_BitField(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(0, 0)
+
+	ptr := func(v int64) *int64 { return &v }
+
+	tcs := []struct {
+		name    string
+		arrange func(*DB)
+		ops     []BitFieldOp
+		want    []*int64
+		wantVal string
+		wantOK  bool
+	}{
+		{
+			name: "get on missing key does not create it",
+			ops:  []BitFieldOp{{Kind: BitFieldGet, Bits: 8}},
+			want: []*int64{ptr(0)},
+		},
+		{
+			name: "set returns previous value and reads back signed and unsigned",
+			ops: []BitFieldOp{
+				{Kind: BitFieldSet, Signed: true, Bits: 8, Value: -100},
+				{Kind: BitFieldGet, Signed: true, Bits: 8},
+				{Kind: BitFieldGet, Bits: 8},
+			},
+			want:    []*int64{ptr(0), ptr(-100), ptr(156)},
+			wantVal: "\x9c",
+			wantOK:  true,
+		},
+		{
+			name: "incrby wraps and saturates",
+			arrange: func(st *DB) {
+				st.SetString("k", "\xc0", time.Time{})
+			},
+			ops: []BitFieldOp{
+				{Kind: BitFieldIncrBy, Bits: 2, Value: 1},
+				{Kind: BitFieldIncrBy, Bits: 2, Offset: 2, Value: 5, Overflow: OverflowSat},
+				{Kind: BitFieldIncrBy, Signed: true, Bits: 4, Offset: 4, Value: -9},
+			},
+			want:    []*int64{ptr(0), ptr(3), ptr(7)},
+			wantVal: "\x37",
+			wantOK:  true,
+		},
+		{
+			name: "fail skips the write",
+			arrange: func(st *DB) {
+				st.SetString("k", "\x7f", time.Time{})
+			},
+			ops: []BitFieldOp{
+				{Kind: BitFieldIncrBy, Signed: true, Bits: 8, Value: 1, Overflow: OverflowFail},
+				{Kind: BitFieldSet, Bits: 8, Offset: 8, Value: 256, Overflow: OverflowFail},
+			},
+			want:    []*int64{nil, nil},
+			wantVal: "\x7f\x00",
+			wantOK:  true,
+		},
+		{
+			name: "i64 and u63 fields",
+			ops: []BitFieldOp{
+				{Kind: BitFieldSet, Signed: true, Bits: 64, Value: -1},
+				{Kind: BitFieldGet, Bits: 63},
+				{Kind: BitFieldIncrBy, Signed: true, Bits: 64, Value: -9223372036854775807, Overflow: OverflowSat},
+			},
+			want:    []*int64{ptr(0), ptr(9223372036854775807), ptr(-9223372036854775808)},
+			wantVal: "\x80\x00\x00\x00\x00\x00\x00\x00",
+			wantOK:  true,
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			st := New()
+			if tc.arrange != nil {
+				tc.arrange(st)
+			}
+			got, err := st.BitField(now, "k", tc.ops)
+			if err != nil {
+				t.Fatalf("BitField returned error: %v", err)
+			}
+			if len(got) != len(tc.want) {
+				t.Fatalf("expected %d results, got %d", len(tc.want), len(got))
+			}
+			for i := range got {
+				if (got[i] == nil) != (tc.want[i] == nil) || (got[i] != nil && *got[i] != *tc.want[i]) {
+					t.Fatalf("result %d: expected %v, got %v", i, tc.want[i], got[i])
+				}
+			}
+			v, ok := st.GetString(now, "k")
+			if ok != tc.wantOK || v != tc.wantVal {
+				t.Fatalf("expected (%q, %v), got (%q, %v)", tc.wantVal, tc.wantOK, v, ok)
+			}
+		})
+	}
+}
@@ -1,23 +1,80 @@
 package db
 
 import (
+	"maps"
+	"slices"
 	"sync"
 	"time"
 )
 
-// ValueType enumerates supported types (only string for MVP).
+// ValueType enumerates supported types.
 type ValueType int
 
 const (
 	TString ValueType = iota
+	TZSet
+	TList
+	TSet
+	THash
 )
 
+// String returns the type name as reported by the TYPE command.
+func (t ValueType) String() string {
+	switch t {
+	case TString:
+		return "string"
+	case TZSet:
+		return "zset"
+	case TList:
+		return "list"
+	case TSet:
+		return "set"
+	case THash:
+		return "hash"
+	default:
+		return "none"
+	}
+}
+
 // entry holds one key's payload & metadata.
-// For simplicity, we keep a typed field per supported kind (s for string).
+// For simplicity, we keep a typed field per supported kind
+// (s for string, z for zset, l for list, m for set and h for hash).
 type entry struct {
-	typ      ValueType
-	s        string
-	expireAt time.Time // zero => no expiry
+	typ        ValueType
+	s          string
+	z          *zset
+	l          *list
+	m          *set
+	h          *hash
+	expireAt   time.Time // zero => no expiry
+	lastAccess time.Time // zero => never touched through a clocked path
+}
+
+// expired reports whether the entry has an expiry that lies before "now".
+// A hash also expires once every one of its fields has.
+func (e *entry) expired(now time.Time) bool {
+	if !e.expireAt.IsZero() && now.After(e.expireAt) {
+		return true
+	}
+	return e.typ == THash && e.h.expired(now)
+}
+
+// clone returns a copy of the entry that shares no mutable state with e.
+func (e *entry) clone() *entry {
+	cp := *e
+	if e.z != nil {
+		cp.z = e.z.clone()
+	}
+	if e.l != nil {
+		cp.l = e.l.clone()
+	}
+	if e.m != nil {
+		cp.m = e.m.clone()
+	}
+	if e.h != nil {
+		cp.h = e.h.clone()
+	}
+	return &cp
 }
 
 // DB is a minimal in-memory KV with TTL support.
@@ -54,9 +111,27 @@ func (db *DB) SetStringWithOptions(now time.Time, k, v string, opts SetOptions)
 	db.mu.Lock()
 	defer db.mu.Unlock()
 
+	return db.setString(now, k, v, opts)
+}
+
+// SetStringAndGet is SetStringWithOptions for SET ... GET: it returns
+// ErrWrongType without writing anything when k holds another type.
+func (db *DB) SetStringAndGet(now time.Time, k, v string, opts SetOptions) (bool, string, bool, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, err := db.lookupString(now, k); err != nil {
+		return false, "", false, err
+	}
+	stored, prev, prevExists := db.setString(now, k, v, opts)
+	return stored, prev, prevExists, nil
+}
+
+// setString implements SetStringWithOptions. Callers must hold the write lock.
+func (db *DB) setString(now time.Time, k, v string, opts SetOptions) (bool, string, bool) {
 	e, exists := db.entries[k]
 	// Drop stale entries so existence checks match read paths.
-	if exists && !e.expireAt.IsZero() && now.After(e.expireAt) {
+	if exists && e.expired(now) {
 		delete(db.entries, k)
 		exists = false
 		e = nil
@@ -84,7 +159,7 @@ func (db *DB) SetStringWithOptions(now time.Time, k, v string, opts SetOptions)
 		expireAt = opts.ExpireAt
 	}
 
-	db.entries[k] = &entry{typ: TString, s: v, expireAt: expireAt}
+	db.entries[k] = &entry{typ: TString, s: v, expireAt: expireAt, lastAccess: now}
 	return true, prev, prevExists
 }
 
@@ -111,12 +186,12 @@ func (db *DB) GetString(now time.Time, k string) (string, bool) {
 }
 
 // Del deletes given keys and returns the number of removed entries.
-func (db *DB) Del(keys ...string) int {
+func (db *DB) Del(now time.Time, keys ...string) int {
 	db.mu.Lock()
 	defer db.mu.Unlock()
 	n := 0
 	for _, k := range keys {
-		if _, ok := db.entries[k]; ok {
+		if db.lookup(now, k) != nil {
 			delete(db.entries, k)
 			n++
 		}
@@ -134,7 +209,7 @@ func (db *DB) Exists(now time.Time, keys ...string) int {
 		if !ok {
 			continue
 		}
-		if !e.expireAt.IsZero() && now.After(e.expireAt) {
+		if e.expired(now) {
 			delete(db.entries, k)
 			continue
 		}
@@ -143,47 +218,101 @@ func (db *DB) Exists(now time.Time, keys ...string) int {
 	return n
 }
 
-// Expire sets a TTL in seconds for a key.
-// sec < 0 removes expiration (persist).
-// Returns false if key does not exist.
-func (db *DB) Expire(now time.Time, k string, sec int64) bool {
+// ExpireOptions tweaks ExpireAt behavior (NX/XX/GT/LT).
+type ExpireOptions struct {
+	NX bool // Only set if key has no expiry
+	XX bool // Only set if key has an expiry
+	GT bool // Only set if new expiry is greater than current (no expiry counts as infinite)
+	LT bool // Only set if new expiry is less than current (no expiry counts as infinite)
+}
+
+// ExpireAt sets the absolute expiration time of a key honouring NX/XX/GT/LT.
+// As in Valkey, an expiration time that is not after "now" deletes the key.
+// Returns false if key does not exist or a condition prevented the update.
+func (db *DB) ExpireAt(now time.Time, k string, at time.Time, opts ExpireOptions) bool {
 	db.mu.Lock()
 	defer db.mu.Unlock()
-	e, ok := db.entries[k]
-	if !ok {
+
+	e := db.lookup(now, k)
+	if e == nil {
 		return false
 	}
-	if sec < 0 {
-		e.expireAt = time.Time{}
+	e.lastAccess = now
+
+	cur := e.expireAt
+	if opts.NX && !cur.IsZero() {
+		return false
+	}
+	if opts.XX && cur.IsZero() {
+		return false
+	}
+	if opts.GT && (cur.IsZero() || !at.After(cur)) {
+		return false
+	}
+	if opts.LT && !cur.IsZero() && !at.Before(cur) {
+		return false
+	}
+
+	if !at.After(now) {
+		delete(db.entries, k)
 		return true
 	}
-	e.expireAt = now.Add(time.Duration(sec) * time.Second)
+	e.expireAt = at
 	return true
 }
 
-// TTL returns remaining time-to-live in seconds.
+// Persist removes the expiration of a key.
+// Returns false if key does not exist or has no expiration.
+func (db *DB) Persist(now time.Time, k string) bool {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	e := db.lookup(now, k)
+	if e == nil || e.expireAt.IsZero() {
+		return false
+	}
+	e.lastAccess = now
+	e.expireAt = time.Time{}
+	return true
+}
+
+// ExpireTime returns the absolute expiration time of a key.
+// Returns (zero, true) if the key has no expiration and (zero, false) if it does not exist.
+func (db *DB) ExpireTime(now time.Time, k string) (time.Time, bool) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	e := db.lookup(now, k)
+	if e == nil {
+		return time.Time{}, false
+	}
+	return e.expireAt, true
+}
+
+// TTL returns remaining time-to-live in seconds, rounded to the nearest second like Valkey.
 //
 // Redis semantics:
 //   - -2: key does not exist
 //   - -1: key exists but has no associated expire
 func (db *DB) TTL(now time.Time, k string) int64 {
-	db.mu.RLock()
-	e, ok := db.entries[k]
-	db.mu.RUnlock()
+	ttl := db.PTTL(now, k)
+	if ttl < 0 {
+		return ttl
+	}
+	return (ttl + 500) / 1000
+}
+
+// PTTL returns remaining time-to-live in milliseconds, with the same
+// -2/-1 semantics as TTL.
+func (db *DB) PTTL(now time.Time, k string) int64 {
+	at, ok := db.ExpireTime(now, k)
 	if !ok {
 		return -2
 	}
-	if e.expireAt.IsZero() {
+	if at.IsZero() {
 		return -1
 	}
-	if now.After(e.expireAt) {
-		// Ensure consistent view by removing expired entry.
-		db.mu.Lock()
-		delete(db.entries, k)
-		db.mu.Unlock()
-		return -2
-	}
-	return int64(e.expireAt.Sub(now).Seconds())
+	return at.Sub(now).Milliseconds()
 }
 
 // Stats returns simple keyspace stats at "now".
@@ -199,7 +328,7 @@ func (db *DB) Stats(now time.Time) (keys int, expires int, avgTTLms int64) {
 
 	for _, e := range db.entries {
 		// Skip already expired ones (lazy deletion will remove them later)
-		if !e.expireAt.IsZero() && now.After(e.expireAt) {
+		if e.expired(now) {
 			continue
 		}
 		keys++
@@ -218,14 +347,66 @@ func (db *DB) Stats(now time.Time) (keys int, expires int, avgTTLms int64) {
 	return
 }
 
+// Clone returns a deep copy of the db, including expiries and access times.
+// Only db is locked while copying, so other databases stay available.
+func (db *DB) Clone() *DB {
+	return CloneAll(map[int]*DB{0: db})[0]
+}
+
+// CloneAll deep-copies every database in dbs while holding all of their
+// locks, taken in index order, so the copies reflect a single point in time.
+func CloneAll(dbs map[int]*DB) map[int]*DB {
+	idxs := slices.Sorted(maps.Keys(dbs))
+	for _, idx := range idxs {
+		dbs[idx].mu.RLock()
+	}
+	defer func() {
+		for _, idx := range idxs {
+			dbs[idx].mu.RUnlock()
+		}
+	}()
+
+	out := make(map[int]*DB, len(dbs))
+	for idx, d := range dbs {
+		cp := &DB{entries: make(map[string]*entry, len(d.entries))}
+		for k, e := range d.entries {
+			cp.entries[k] = e.clone()
+		}
+		out[idx] = cp
+	}
+	return out
+}
+
 // CleanUpExpired scans entire map and removes expired entries.
 // It's fine for test workloads (small maps). No fancy wheels required.
 func (db *DB) CleanUpExpired(now time.Time) {
 	db.mu.Lock()
 	for k, e := range db.entries {
-		if !e.expireAt.IsZero() && now.After(e.expireAt) {
+		if e.expired(now) {
 			delete(db.entries, k)
+			continue
+		}
+		if e.typ == THash {
+			e.h.purge(now)
 		}
 	}
 	db.mu.Unlock()
 }
+
+// lookup returns the live entry for k, dropping it first if it has expired.
+// Expired hash fields are dropped as well.
+// Callers must hold the write lock.
+func (db *DB) lookup(now time.Time, k string) *entry {
+	e, ok := db.entries[k]
+	if !ok {
+		return nil
+	}
+	if e.expired(now) {
+		delete(db.entries, k)
+		return nil
+	}
+	if e.typ == THash {
+		e.h.purge(now)
+	}
+	return e
+}
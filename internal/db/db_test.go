@@ -1,6 +1,7 @@
 package db
 
 import (
+	"errors"
 	"testing"
 	"time"
 )
@@ -85,6 +86,14 @@ func TestStore_Del(t *testing.T) {
 			keys: []string{"nope"},
 			want: 0,
 		},
+		{
+			name: "does not count expired keys",
+			arrange: func(st *DB) {
+				st.SetString("gone", "v", time.Unix(-1, 0))
+			},
+			keys: []string{"gone"},
+			want: 0,
+		},
 	}
 
 	for _, tc := range tcs {
@@ -96,7 +105,7 @@ func TestStore_Del(t *testing.T) {
 			if tc.arrange != nil {
 				tc.arrange(st)
 			}
-			if got := st.Del(tc.keys...); got != tc.want {
+			if got := st.Del(time.Unix(0, 0), tc.keys...); got != tc.want {
 				t.Fatalf("Del(%v) = %d; want %d", tc.keys, got, tc.want)
 			}
 			for _, k := range tc.keys {
@@ -160,7 +169,7 @@ func TestStore_Exists(t *testing.T) {
 	}
 }
 
-func TestStore_Expire(t *testing.T) {
+func TestStore_ExpireAt(t *testing.T) {
 	t.Parallel()
 
 	now := time.Unix(0, 0)
@@ -169,7 +178,8 @@ func TestStore_Expire(t *testing.T) {
 		name    string
 		arrange func(*DB)
 		key     string
-		sec     int64
+		at      time.Time
+		opts    ExpireOptions
 		want    bool
 		check   func(*testing.T, *DB)
 	}{
@@ -179,7 +189,7 @@ func TestStore_Expire(t *testing.T) {
 				st.SetString("foo", "bar", time.Time{})
 			},
 			key:  "foo",
-			sec:  10,
+			at:   now.Add(10 * time.Second),
 			want: true,
 			check: func(t *testing.T, st *DB) {
 				if ttl := st.TTL(now.Add(5*time.Second), "foo"); ttl != 5 {
@@ -188,23 +198,97 @@ func TestStore_Expire(t *testing.T) {
 			},
 		},
 		{
-			name: "removes expiration when seconds negative",
+			name: "keeps millisecond precision",
+			arrange: func(st *DB) {
+				st.SetString("foo", "bar", time.Time{})
+			},
+			key:  "foo",
+			at:   now.Add(1500 * time.Millisecond),
+			want: true,
+			check: func(t *testing.T, st *DB) {
+				if ttl := st.PTTL(now, "foo"); ttl != 1500 {
+					t.Fatalf("expected pttl 1500, got %d", ttl)
+				}
+			},
+		},
+		{
+			name: "deletes key when time is in the past",
 			arrange: func(st *DB) {
 				st.SetString("foo", "bar", now.Add(10*time.Second))
 			},
 			key:  "foo",
-			sec:  -1,
+			at:   now.Add(-time.Second),
 			want: true,
 			check: func(t *testing.T, st *DB) {
-				if ttl := st.TTL(now, "foo"); ttl != -1 {
-					t.Fatalf("expected ttl -1, got %d", ttl)
+				if _, exists := st.entries["foo"]; exists {
+					t.Fatalf("expected key to be deleted")
 				}
 			},
 		},
+		{
+			name: "NX skips keys with expiry",
+			arrange: func(st *DB) {
+				st.SetString("foo", "bar", now.Add(10*time.Second))
+			},
+			key:  "foo",
+			at:   now.Add(20 * time.Second),
+			opts: ExpireOptions{NX: true},
+			want: false,
+		},
+		{
+			name: "XX skips keys without expiry",
+			arrange: func(st *DB) {
+				st.SetString("foo", "bar", time.Time{})
+			},
+			key:  "foo",
+			at:   now.Add(20 * time.Second),
+			opts: ExpireOptions{XX: true},
+			want: false,
+		},
+		{
+			name: "GT treats missing expiry as infinite",
+			arrange: func(st *DB) {
+				st.SetString("foo", "bar", time.Time{})
+			},
+			key:  "foo",
+			at:   now.Add(20 * time.Second),
+			opts: ExpireOptions{GT: true},
+			want: false,
+		},
+		{
+			name: "GT extends expiry",
+			arrange: func(st *DB) {
+				st.SetString("foo", "bar", now.Add(10*time.Second))
+			},
+			key:  "foo",
+			at:   now.Add(20 * time.Second),
+			opts: ExpireOptions{GT: true},
+			want: true,
+		},
+		{
+			name: "LT applies to keys without expiry",
+			arrange: func(st *DB) {
+				st.SetString("foo", "bar", time.Time{})
+			},
+			key:  "foo",
+			at:   now.Add(20 * time.Second),
+			opts: ExpireOptions{LT: true},
+			want: true,
+		},
+		{
+			name: "LT refuses to extend expiry",
+			arrange: func(st *DB) {
+				st.SetString("foo", "bar", now.Add(10*time.Second))
+			},
+			key:  "foo",
+			at:   now.Add(20 * time.Second),
+			opts: ExpireOptions{LT: true},
+			want: false,
+		},
 		{
 			name: "returns false when key missing",
 			key:  "missing",
-			sec:  5,
+			at:   now.Add(5 * time.Second),
 			want: false,
 		},
 	}
@@ -218,8 +302,8 @@ func TestStore_Expire(t *testing.T) {
 			if tc.arrange != nil {
 				tc.arrange(st)
 			}
-			if got := st.Expire(now, tc.key, tc.sec); got != tc.want {
-				t.Fatalf("Expire(%q,%d) = %v; want %v", tc.key, tc.sec, got, tc.want)
+			if got := st.ExpireAt(now, tc.key, tc.at, tc.opts); got != tc.want {
+				t.Fatalf("ExpireAt(%q,%v,%+v) = %v; want %v", tc.key, tc.at, tc.opts, got, tc.want)
 			}
 			if tc.check != nil {
 				tc.check(t, st)
@@ -228,6 +312,50 @@ func TestStore_Expire(t *testing.T) {
 	}
 }
 
+func TestStore_Persist(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(0, 0)
+
+	st := New()
+	st.SetString("volatile", "x", now.Add(10*time.Second))
+	st.SetString("plain", "y", time.Time{})
+
+	if !st.Persist(now, "volatile") {
+		t.Fatalf("expected Persist to remove expiry")
+	}
+	if ttl := st.TTL(now, "volatile"); ttl != -1 {
+		t.Fatalf("expected ttl -1, got %d", ttl)
+	}
+	if st.Persist(now, "plain") {
+		t.Fatalf("expected Persist to report no expiry")
+	}
+	if st.Persist(now, "missing") {
+		t.Fatalf("expected Persist to report missing key")
+	}
+}
+
+func TestStore_ExpireTime(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(0, 0)
+	exp := now.Add(10 * time.Second)
+
+	st := New()
+	st.SetString("volatile", "x", exp)
+	st.SetString("plain", "y", time.Time{})
+
+	if at, ok := st.ExpireTime(now, "volatile"); !ok || !at.Equal(exp) {
+		t.Fatalf("ExpireTime(volatile) = (%v,%v); want (%v,true)", at, ok, exp)
+	}
+	if at, ok := st.ExpireTime(now, "plain"); !ok || !at.IsZero() {
+		t.Fatalf("ExpireTime(plain) = (%v,%v); want (zero,true)", at, ok)
+	}
+	if _, ok := st.ExpireTime(now, "missing"); ok {
+		t.Fatalf("expected missing key to be reported")
+	}
+}
+
 func TestStore_SetStringWithOptions(t *testing.T) {
 	t.Parallel()
 
@@ -305,6 +433,28 @@ func TestStore_SetStringWithOptions(t *testing.T) {
 	})
 }
 
+func TestStore_SetStringAndGet(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(0, 0)
+
+	st := New()
+	st.SetString("foo", "old", time.Time{})
+	if ok, prev, prevExists, err := st.SetStringAndGet(now, "foo", "new", SetOptions{}); err != nil || !ok || !prevExists || prev != "old" {
+		t.Fatalf("SetStringAndGet = (%v,%q,%v,%v); want (true,old,true,nil)", ok, prev, prevExists, err)
+	}
+
+	if _, err := st.RPush(now, "list", "a"); err != nil {
+		t.Fatalf("RPush returned error: %v", err)
+	}
+	if _, _, _, err := st.SetStringAndGet(now, "list", "v", SetOptions{}); !errors.Is(err, ErrWrongType) {
+		t.Fatalf("SetStringAndGet(list) err = %v; want ErrWrongType", err)
+	}
+	if typ, _ := st.Type(now, "list"); typ != TList {
+		t.Fatalf("expected list to be left alone, got type %v", typ)
+	}
+}
+
 func TestStore_TTL(t *testing.T) {
 	t.Parallel()
 
@@ -324,6 +474,14 @@ func TestStore_TTL(t *testing.T) {
 			key:  "foo",
 			want: 10,
 		},
+		{
+			name: "rounds remaining seconds to the nearest second",
+			arrange: func(st *DB) {
+				st.SetString("foo", "bar", now.Add(1600*time.Millisecond))
+			},
+			key:  "foo",
+			want: 2,
+		},
 		{
 			name: "returns minus one when key has no expiry",
 			arrange: func(st *DB) {
@@ -409,3 +567,89 @@ func TestStore_CleanUpExpired(t *testing.T) {
 		t.Fatalf("expected fresh key to remain")
 	}
 }
+
+func TestStore_Clone(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(0, 0)
+
+	st := New()
+	st.SetString("s", "1", now.Add(time.Minute))
+	if _, err := st.RPush(now, "l", "a", "b"); err != nil {
+		t.Fatalf("RPush returned error: %v", err)
+	}
+
+	cp := st.Clone()
+	st.SetString("s", "2", time.Time{})
+	if _, err := st.RPush(now, "l", "c"); err != nil {
+		t.Fatalf("RPush returned error: %v", err)
+	}
+
+	if v, _ := cp.GetString(now, "s"); v != "1" {
+		t.Fatalf("clone s = %q; want %q", v, "1")
+	}
+	if ttl := cp.TTL(now, "s"); ttl != 60 {
+		t.Fatalf("clone TTL = %d; want 60", ttl)
+	}
+	if got, _, _ := cp.ListItems(now, "l"); len(got) != 2 {
+		t.Fatalf("clone list = %v; want [a b]", got)
+	}
+}
+
+func TestCloneAll(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(0, 0)
+
+	d0, d1 := New(), New()
+	d0.SetString("a", "1", time.Time{})
+	d1.SetString("b", "1", time.Time{})
+
+	// While database 1 is busy, CloneAll must already hold database 0 so
+	// nothing can change it before database 1 is copied too.
+	d1.mu.Lock()
+	done := make(chan map[int]*DB)
+	go func() { done <- CloneAll(map[int]*DB{0: d0, 1: d1}) }()
+	for d0.mu.TryLock() {
+		d0.mu.Unlock()
+		time.Sleep(time.Millisecond)
+	}
+	d1.entries["b"].s = "2"
+	d1.mu.Unlock()
+	cps := <-done
+
+	d0.SetString("a", "changed", time.Time{})
+	if v, _ := cps[0].GetString(now, "a"); v != "1" {
+		t.Fatalf("clone a = %q; want %q", v, "1")
+	}
+	if v, _ := cps[1].GetString(now, "b"); v != "2" {
+		t.Fatalf("clone b = %q; want %q", v, "2")
+	}
+}
+
+func TestCloneAll_WithCopyTo(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(0, 0)
+
+	d0, d1 := New(), New()
+	d1.SetString("k", "v", time.Time{})
+
+	// A copy from a higher index to a lower one must take the locks in the
+	// same order as CloneAll, or the two deadlock.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range 1000 {
+			d1.CopyTo(now, "k", 1, d0, 0, "k", true)
+			d0.Del(now, "k")
+		}
+	}()
+	for range 1000 {
+		cps := CloneAll(map[int]*DB{0: d0, 1: d1})
+		if v, ok := cps[0].GetString(now, "k"); ok && v != "v" {
+			t.Fatalf("clone k = %q; want %q", v, "v")
+		}
+	}
+	<-done
+}
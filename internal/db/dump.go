@@ -0,0 +1,120 @@
+package db
+
+import (
+	"cmp"
+	"slices"
+	"time"
+
+	"github.com/mickamy/minivalkey/internal/rdb"
+)
+
+// RestoreOptions tweaks RestoreValue behavior.
+type RestoreOptions struct {
+	Replace  bool          // Overwrite an existing key
+	ExpireAt time.Time     // zero => no expiry
+	Idle     time.Duration // How long ago the value was last accessed
+}
+
+// DumpValue returns the value stored at k, with its encoding, for serialization.
+// Returns (rdb.Value{}, false) if the key does not exist.
+func (db *DB) DumpValue(now time.Time, k string) (rdb.Value, bool) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	e := db.lookup(now, k)
+	if e == nil {
+		return rdb.Value{}, false
+	}
+	e.lastAccess = now
+	return e.value(), true
+}
+
+// Entries returns every live key with its value and expiry, ordered by key.
+// Looking keys up through Entries does not count as an access.
+func (db *DB) Entries(now time.Time) []rdb.Entry {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	out := make([]rdb.Entry, 0, len(db.entries))
+	for k := range db.entries {
+		e := db.lookup(now, k)
+		if e == nil {
+			continue
+		}
+		out = append(out, rdb.Entry{Key: k, Value: e.value(), ExpireAt: e.expireAt})
+	}
+	slices.SortFunc(out, func(a, b rdb.Entry) int {
+		return cmp.Compare(a.Key, b.Key)
+	})
+	return out
+}
+
+// value converts e to its serializable form.
+func (e *entry) value() rdb.Value {
+	switch e.typ {
+	case TZSet:
+		members := e.z.sorted()
+		v := rdb.Value{Kind: rdb.KindZSet, Encoding: e.z.encoding(), ZSet: make([]rdb.ZMember, len(members))}
+		for i, m := range members {
+			v.ZSet[i] = rdb.ZMember{Member: m.Member, Score: m.Score}
+		}
+		return v
+	case TList:
+		return rdb.Value{Kind: rdb.KindList, Encoding: e.l.encoding(), List: slices.Clone(e.l.items)}
+	case TSet:
+		return rdb.Value{Kind: rdb.KindSet, Encoding: e.m.encoding(), Set: e.m.sorted()}
+	case THash:
+		v := rdb.Value{Kind: rdb.KindHash, Encoding: e.h.encoding()}
+		for f, val := range e.h.fields {
+			v.Hash = append(v.Hash, rdb.HashField{Field: f, Value: val, ExpireAt: e.h.expires[f]})
+		}
+		slices.SortFunc(v.Hash, func(a, b rdb.HashField) int {
+			return cmp.Compare(a.Field, b.Field)
+		})
+		return v
+	default:
+		return rdb.Value{Kind: rdb.KindString, Encoding: stringEncoding(e.s), String: e.s}
+	}
+}
+
+// RestoreValue stores v at k with the given expiry and idle time.
+// A value whose expiry already passed is not stored, though it still replaces k.
+// Returns false if k exists and opts.Replace is not set.
+func (db *DB) RestoreValue(now time.Time, k string, v rdb.Value, opts RestoreOptions) bool {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if db.lookup(now, k) != nil && !opts.Replace {
+		return false
+	}
+	delete(db.entries, k)
+	if !opts.ExpireAt.IsZero() && !opts.ExpireAt.After(now) {
+		return true
+	}
+
+	e := &entry{expireAt: opts.ExpireAt, lastAccess: now.Add(-opts.Idle)}
+	switch v.Kind {
+	case rdb.KindZSet:
+		e.typ, e.z = TZSet, newZSet()
+		for _, m := range v.ZSet {
+			e.z.scores[m.Member] = m.Score
+		}
+	case rdb.KindList:
+		e.typ, e.l = TList, &list{items: slices.Clone(v.List)}
+	case rdb.KindSet:
+		e.typ, e.m = TSet, newSet()
+		for _, m := range v.Set {
+			e.m.members[m] = struct{}{}
+		}
+	case rdb.KindHash:
+		e.typ, e.h = THash, newHash()
+		for _, f := range v.Hash {
+			e.h.fields[f.Field] = f.Value
+			e.h.setExpire(f.Field, f.ExpireAt)
+		}
+	default:
+		e.typ, e.s = TString, v.String
+	}
+	db.entries[k] = e
+	return true
+}
@@ -0,0 +1,125 @@
+package db
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mickamy/minivalkey/internal/rdb"
+)
+
+func TestStore_DumpValue(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(0, 0)
+
+	st := New()
+	st.SetString("s", "42", time.Time{})
+	st.RPush(now, "l", "a", "b")
+	st.SAdd(now, "set", "3", "1", "2")
+	st.ZAdd(now, "z", ZAddOptions{}, ZMember{Member: "b", Score: 2}, ZMember{Member: "a", Score: 1})
+	st.HSet(now, "h", "y", "2", "x", "1")
+
+	tcs := []struct {
+		key  string
+		want rdb.Value
+	}{
+		{key: "s", want: rdb.Value{Kind: rdb.KindString, Encoding: "int", String: "42"}},
+		{key: "l", want: rdb.Value{Kind: rdb.KindList, Encoding: "listpack", List: []string{"a", "b"}}},
+		{key: "set", want: rdb.Value{Kind: rdb.KindSet, Encoding: "intset", Set: []string{"1", "2", "3"}}},
+		{key: "z", want: rdb.Value{Kind: rdb.KindZSet, Encoding: "listpack", ZSet: []rdb.ZMember{{Member: "a", Score: 1}, {Member: "b", Score: 2}}}},
+		{key: "h", want: rdb.Value{Kind: rdb.KindHash, Encoding: "listpack", Hash: []rdb.HashField{{Field: "x", Value: "1"}, {Field: "y", Value: "2"}}}},
+	}
+	for _, tc := range tcs {
+		got, ok := st.DumpValue(now, tc.key)
+		if !ok {
+			t.Fatalf("expected %s to exist", tc.key)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Fatalf("%s: expected %+v, got %+v", tc.key, tc.want, got)
+		}
+
+		// Restoring the value under another key yields an identical copy.
+		if !st.RestoreValue(now, tc.key+"-copy", got, RestoreOptions{}) {
+			t.Fatalf("expected %s-copy to be restored", tc.key)
+		}
+		if cp, _ := st.DumpValue(now, tc.key+"-copy"); !reflect.DeepEqual(cp, tc.want) {
+			t.Fatalf("%s-copy: expected %+v, got %+v", tc.key, tc.want, cp)
+		}
+	}
+
+	if _, ok := st.DumpValue(now, "missing"); ok {
+		t.Fatalf("expected missing key")
+	}
+}
+
+func TestStore_RestoreValue(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+	v := rdb.Value{Kind: rdb.KindString, String: "new"}
+
+	t.Run("refuses existing key without replace", func(t *testing.T) {
+		t.Parallel()
+
+		st := New()
+		st.SetString("k", "old", time.Time{})
+		if st.RestoreValue(now, "k", v, RestoreOptions{}) {
+			t.Fatalf("expected restore to be refused")
+		}
+		if got, _ := st.GetString(now, "k"); got != "old" {
+			t.Fatalf("expected old, got %q", got)
+		}
+		if !st.RestoreValue(now, "k", v, RestoreOptions{Replace: true}) {
+			t.Fatalf("expected restore with replace to succeed")
+		}
+		if got, _ := st.GetString(now, "k"); got != "new" {
+			t.Fatalf("expected new, got %q", got)
+		}
+	})
+
+	t.Run("sets expiry and idle time", func(t *testing.T) {
+		t.Parallel()
+
+		st := New()
+		st.RestoreValue(now, "k", v, RestoreOptions{ExpireAt: now.Add(10 * time.Second), Idle: time.Minute})
+		if ttl := st.TTL(now, "k"); ttl != 10 {
+			t.Fatalf("expected ttl 10, got %d", ttl)
+		}
+		if info, _ := st.Object(now, "k"); info.Idle != time.Minute {
+			t.Fatalf("expected idle 1m, got %v", info.Idle)
+		}
+	})
+
+	t.Run("drops value whose expiry passed", func(t *testing.T) {
+		t.Parallel()
+
+		st := New()
+		st.SetString("k", "old", time.Time{})
+		if !st.RestoreValue(now, "k", v, RestoreOptions{Replace: true, ExpireAt: now}) {
+			t.Fatalf("expected restore to succeed")
+		}
+		if st.Exists(now, "k") != 0 {
+			t.Fatalf("expected k to be deleted")
+		}
+	})
+}
+
+func TestStore_Entries(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+
+	st := New()
+	st.SetString("b", "2", now.Add(time.Minute))
+	st.SetString("a", "1", time.Time{})
+	st.SetString("expired", "x", now.Add(-time.Second))
+
+	want := []rdb.Entry{
+		{Key: "a", Value: rdb.Value{Kind: rdb.KindString, Encoding: "int", String: "1"}},
+		{Key: "b", Value: rdb.Value{Kind: rdb.KindString, Encoding: "int", String: "2"}, ExpireAt: now.Add(time.Minute)},
+	}
+	if got := st.Entries(now); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %+v, got %+v", want, got)
+	}
+}
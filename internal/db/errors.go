@@ -0,0 +1,24 @@
+package db
+
+import (
+	"errors"
+)
+
+var (
+	// ErrWrongType indicates an operation against a key holding another kind of value.
+	ErrWrongType = errors.New("db: wrong kind of value")
+	// ErrNotInteger indicates a stored value that cannot be parsed as a 64-bit integer.
+	ErrNotInteger = errors.New("db: value is not an integer")
+	// ErrNotFloat indicates a stored value that cannot be parsed as a float.
+	ErrNotFloat = errors.New("db: value is not a valid float")
+	// ErrOverflow indicates an increment that would overflow a 64-bit integer.
+	ErrOverflow = errors.New("db: increment or decrement would overflow")
+	// ErrNaNOrInf indicates a float increment producing NaN or Infinity.
+	ErrNaNOrInf = errors.New("db: increment would produce NaN or Infinity")
+	// ErrStringTooLong indicates a string growing beyond MaxStringLen.
+	ErrStringTooLong = errors.New("db: string exceeds maximum allowed size")
+	// ErrInvalidHLL indicates a string value that is not a HyperLogLog.
+	ErrInvalidHLL = errors.New("db: value is not a valid HyperLogLog")
+	// ErrCorruptHLL indicates a HyperLogLog whose registers cannot be decoded.
+	ErrCorruptHLL = errors.New("db: corrupted HyperLogLog")
+)
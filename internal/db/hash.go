@@ -0,0 +1,385 @@
+package db
+
+import (
+	"maps"
+	"time"
+)
+
+// Valkey's default hash-max-listpack-entries and hash-max-listpack-value.
+const (
+	hashListpackEntries = 128
+	hashListpackValue   = 64
+)
+
+// hash holds the fields of a hash, their values and per-field expirations.
+type hash struct {
+	fields  map[string]string
+	expires map[string]time.Time // fields without an entry never expire
+}
+
+func newHash() *hash {
+	return &hash{fields: make(map[string]string)}
+}
+
+// clone returns a deep copy of h.
+func (h *hash) clone() *hash {
+	cp := newHash()
+	for f, v := range h.fields {
+		cp.fields[f] = v
+	}
+	for f, at := range h.expires {
+		cp.setExpire(f, at)
+	}
+	return cp
+}
+
+// setExpire sets the expiration of field, or clears it when at is zero.
+func (h *hash) setExpire(field string, at time.Time) {
+	if at.IsZero() {
+		delete(h.expires, field)
+		return
+	}
+	if h.expires == nil {
+		h.expires = make(map[string]time.Time)
+	}
+	h.expires[field] = at
+}
+
+// del removes field and its expiration.
+func (h *hash) del(field string) {
+	delete(h.fields, field)
+	delete(h.expires, field)
+}
+
+// expired reports whether every field of h has expired at "now".
+func (h *hash) expired(now time.Time) bool {
+	if len(h.expires) < len(h.fields) {
+		return false
+	}
+	for _, at := range h.expires {
+		if !now.After(at) {
+			return false
+		}
+	}
+	return true
+}
+
+// purge drops the fields that have expired at "now".
+func (h *hash) purge(now time.Time) {
+	for f, at := range h.expires {
+		if now.After(at) {
+			h.del(f)
+		}
+	}
+}
+
+// encoding mimics how Valkey picks a hash encoding.
+// Listpacks cannot hold field expirations.
+func (h *hash) encoding() string {
+	if len(h.fields) > hashListpackEntries || len(h.expires) > 0 {
+		return "hashtable"
+	}
+	for f, v := range h.fields {
+		if len(f) > hashListpackValue || len(v) > hashListpackValue {
+			return "hashtable"
+		}
+	}
+	return "listpack"
+}
+
+// HSet sets fields of the hash at k from alternating field/value pairs, creating it if needed.
+// Overwritten fields lose their expiration.
+// Returns the number of fields that were added.
+func (db *DB) HSet(now time.Time, k string, fieldValues ...string) (int, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	e, err := db.lookupHash(now, k)
+	if err != nil {
+		return 0, err
+	}
+	if e == nil {
+		e = &entry{typ: THash, h: newHash()}
+		db.entries[k] = e
+	}
+	e.lastAccess = now
+
+	n := 0
+	for i := 0; i+1 < len(fieldValues); i += 2 {
+		if _, ok := e.h.fields[fieldValues[i]]; !ok {
+			n++
+		}
+		e.h.fields[fieldValues[i]] = fieldValues[i+1]
+		e.h.setExpire(fieldValues[i], time.Time{})
+	}
+	return n, nil
+}
+
+// HGet returns the value of field in the hash at k.
+// Returns ("", false, nil) if the key or the field does not exist.
+func (db *DB) HGet(now time.Time, k, field string) (string, bool, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	e, err := db.lookupHash(now, k)
+	if err != nil || e == nil {
+		return "", false, err
+	}
+	e.lastAccess = now
+	v, ok := e.h.fields[field]
+	return v, ok, nil
+}
+
+// HDel removes fields from the hash at k, deleting k once it is empty.
+// Returns the number of fields that were removed.
+func (db *DB) HDel(now time.Time, k string, fields ...string) (int, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	e, err := db.lookupHash(now, k)
+	if err != nil || e == nil {
+		return 0, err
+	}
+	e.lastAccess = now
+
+	n := 0
+	for _, f := range fields {
+		if _, ok := e.h.fields[f]; ok {
+			delete(e.h.fields, f)
+			e.h.setExpire(f, time.Time{})
+			n++
+		}
+	}
+	if len(e.h.fields) == 0 {
+		delete(db.entries, k)
+	}
+	return n, nil
+}
+
+// HGetAll returns a copy of the fields of the hash at k.
+// Returns (nil, false, nil) if the key does not exist.
+func (db *DB) HGetAll(now time.Time, k string) (map[string]string, bool, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	e, err := db.lookupHash(now, k)
+	if err != nil || e == nil {
+		return nil, false, err
+	}
+	e.lastAccess = now
+	return maps.Clone(e.h.fields), true, nil
+}
+
+// HExpireAt sets the absolute expiration time of fields of the hash at k honouring NX/XX/GT/LT.
+// As with keys, an expiration time that is not after "now" deletes the field.
+// Returns one code per field: -2 if it does not exist, 0 if a condition prevented
+// the update, 1 if the expiration was set and 2 if the field was deleted.
+func (db *DB) HExpireAt(now time.Time, k string, at time.Time, opts ExpireOptions, fields ...string) ([]int64, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	codes := make([]int64, len(fields))
+	e, err := db.lookupHash(now, k)
+	if err != nil {
+		return nil, err
+	}
+	if e == nil {
+		for i := range codes {
+			codes[i] = -2
+		}
+		return codes, nil
+	}
+	e.lastAccess = now
+
+	for i, f := range fields {
+		if _, ok := e.h.fields[f]; !ok {
+			codes[i] = -2
+			continue
+		}
+		cur := e.h.expires[f]
+		switch {
+		case opts.NX && !cur.IsZero(),
+			opts.XX && cur.IsZero(),
+			opts.GT && (cur.IsZero() || !at.After(cur)),
+			opts.LT && !cur.IsZero() && !at.Before(cur):
+			codes[i] = 0
+		case !at.After(now):
+			e.h.del(f)
+			codes[i] = 2
+		default:
+			e.h.setExpire(f, at)
+			codes[i] = 1
+		}
+	}
+	if len(e.h.fields) == 0 {
+		delete(db.entries, k)
+	}
+	return codes, nil
+}
+
+// HExpireTime returns the expiration of fields of the hash at k as unix milliseconds,
+// or -1 for fields without one and -2 for fields that do not exist.
+func (db *DB) HExpireTime(now time.Time, k string, fields ...string) ([]int64, error) {
+	return db.hFieldTimes(now, k, fields, func(at time.Time) int64 { return at.UnixMilli() })
+}
+
+// HPTTL returns the remaining time-to-live of fields of the hash at k in milliseconds,
+// with the same -2/-1 semantics as HExpireTime.
+func (db *DB) HPTTL(now time.Time, k string, fields ...string) ([]int64, error) {
+	return db.hFieldTimes(now, k, fields, func(at time.Time) int64 { return at.Sub(now).Milliseconds() })
+}
+
+// hFieldTimes reports conv of each field's expiration, or -1/-2 as HExpireTime does.
+func (db *DB) hFieldTimes(now time.Time, k string, fields []string, conv func(time.Time) int64) ([]int64, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	e, err := db.lookupHash(now, k)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]int64, len(fields))
+	for i, f := range fields {
+		switch {
+		case e == nil || !hasField(e.h, f):
+			out[i] = -2
+		case e.h.expires[f].IsZero():
+			out[i] = -1
+		default:
+			out[i] = conv(e.h.expires[f])
+		}
+	}
+	return out, nil
+}
+
+// HPersist removes the expiration of fields of the hash at k.
+// Returns one code per field: -2 if it does not exist, -1 if it has no
+// expiration and 1 if the expiration was removed.
+func (db *DB) HPersist(now time.Time, k string, fields ...string) ([]int64, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	e, err := db.lookupHash(now, k)
+	if err != nil {
+		return nil, err
+	}
+	codes := make([]int64, len(fields))
+	for i, f := range fields {
+		switch {
+		case e == nil || !hasField(e.h, f):
+			codes[i] = -2
+		case e.h.expires[f].IsZero():
+			codes[i] = -1
+		default:
+			e.h.setExpire(f, time.Time{})
+			e.lastAccess = now
+			codes[i] = 1
+		}
+	}
+	return codes, nil
+}
+
+// HGetEx returns the values of fields of the hash at k and updates their
+// expiration as described by opts, like GetEx does for strings.
+// An expiration that is not after "now" deletes the fields after reading them.
+// found[i] reports whether fields[i] exists.
+func (db *DB) HGetEx(now time.Time, k string, opts GetExOptions, fields ...string) (vals []string, found []bool, err error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	vals = make([]string, len(fields))
+	found = make([]bool, len(fields))
+	e, err := db.lookupHash(now, k)
+	if err != nil || e == nil {
+		return vals, found, err
+	}
+	e.lastAccess = now
+
+	for i, f := range fields {
+		v, ok := e.h.fields[f]
+		if !ok {
+			continue
+		}
+		vals[i], found[i] = v, true
+		switch {
+		case opts.HasExpire && !opts.ExpireAt.After(now):
+			e.h.del(f)
+		case opts.HasExpire:
+			e.h.setExpire(f, opts.ExpireAt)
+		case opts.Persist:
+			e.h.setExpire(f, time.Time{})
+		}
+	}
+	if len(e.h.fields) == 0 {
+		delete(db.entries, k)
+	}
+	return vals, found, nil
+}
+
+// HSetExOptions tweaks HSetEx behavior.
+type HSetExOptions struct {
+	FNX       bool // Only set if none of the fields exist
+	FXX       bool // Only set if all of the fields exist
+	ExpireAt  time.Time
+	HasExpire bool // Apply ExpireAt to the fields (deleting them if it lies in the past)
+	KeepTTL   bool // Keep the expiration of existing fields
+}
+
+// HSetEx sets fields of the hash at k from alternating field/value pairs and
+// updates their expiration as described by opts.
+// Returns false if a FNX/FXX condition prevented the update.
+func (db *DB) HSetEx(now time.Time, k string, opts HSetExOptions, fieldValues ...string) (bool, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	e, err := db.lookupHash(now, k)
+	if err != nil {
+		return false, err
+	}
+	for i := 0; i+1 < len(fieldValues); i += 2 {
+		exists := e != nil && hasField(e.h, fieldValues[i])
+		if (opts.FNX && exists) || (opts.FXX && !exists) {
+			return false, nil
+		}
+	}
+	if e == nil {
+		e = &entry{typ: THash, h: newHash()}
+		db.entries[k] = e
+	}
+	e.lastAccess = now
+
+	for i := 0; i+1 < len(fieldValues); i += 2 {
+		f := fieldValues[i]
+		e.h.fields[f] = fieldValues[i+1]
+		switch {
+		case opts.HasExpire && !opts.ExpireAt.After(now):
+			e.h.del(f)
+		case opts.HasExpire:
+			e.h.setExpire(f, opts.ExpireAt)
+		case !opts.KeepTTL:
+			e.h.setExpire(f, time.Time{})
+		}
+	}
+	if len(e.h.fields) == 0 {
+		delete(db.entries, k)
+	}
+	return true, nil
+}
+
+func hasField(h *hash, field string) bool {
+	_, ok := h.fields[field]
+	return ok
+}
+
+// lookupHash returns the live hash entry for k, or ErrWrongType if k holds another type.
+// Callers must hold the write lock.
+func (db *DB) lookupHash(now time.Time, k string) (*entry, error) {
+	e := db.lookup(now, k)
+	if e == nil {
+		return nil, nil
+	}
+	if e.typ != THash {
+		return nil, ErrWrongType
+	}
+	return e, nil
+}
@@ -0,0 +1,153 @@
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStore_HSet(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(0, 0)
+
+	st := New()
+	if n, err := st.HSet(now, "h", "a", "1", "b", "2"); err != nil || n != 2 {
+		t.Fatalf("expected 2, got %d err=%v", n, err)
+	}
+	if n, _ := st.HSet(now, "h", "a", "3", "c", "4"); n != 1 {
+		t.Fatalf("expected 1, got %d", n)
+	}
+	if v, ok, _ := st.HGet(now, "h", "a"); !ok || v != "3" {
+		t.Fatalf("expected 3, got %q ok=%v", v, ok)
+	}
+	if _, ok, _ := st.HGet(now, "h", "missing"); ok {
+		t.Fatalf("expected missing field")
+	}
+	if info, _ := st.Object(now, "h"); info.Encoding != "listpack" {
+		t.Fatalf("expected listpack encoding, got %q", info.Encoding)
+	}
+
+	st.SetString("s", "value", time.Time{})
+	if _, _, err := st.HGet(now, "s", "a"); err != ErrWrongType {
+		t.Fatalf("expected ErrWrongType, got %v", err)
+	}
+}
+
+func TestStore_HExpireAt(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(0, 0)
+
+	t.Run("expires fields lazily", func(t *testing.T) {
+		t.Parallel()
+
+		st := New()
+		st.HSet(now, "h", "a", "1", "b", "2")
+		if codes, _ := st.HExpireAt(now, "h", now.Add(time.Second), ExpireOptions{}, "a"); codes[0] != 1 {
+			t.Fatalf("expected 1, got %d", codes[0])
+		}
+		if info, _ := st.Object(now, "h"); info.Encoding != "hashtable" {
+			t.Fatalf("expected hashtable encoding, got %q", info.Encoding)
+		}
+
+		later := now.Add(2 * time.Second)
+		if _, ok, _ := st.HGet(later, "h", "a"); ok {
+			t.Fatalf("expected a to be expired")
+		}
+		if v, ok, _ := st.HGet(later, "h", "b"); !ok || v != "2" {
+			t.Fatalf("expected b=2, got %q ok=%v", v, ok)
+		}
+	})
+
+	t.Run("removes the hash once every field expired", func(t *testing.T) {
+		t.Parallel()
+
+		st := New()
+		st.HSet(now, "h", "a", "1", "b", "2")
+		st.HExpireAt(now, "h", now.Add(time.Second), ExpireOptions{}, "a", "b")
+
+		later := now.Add(2 * time.Second)
+		if n := st.Exists(later, "h"); n != 0 {
+			t.Fatalf("expected h to be gone, got %d", n)
+		}
+		st.HSet(now, "h2", "a", "1")
+		st.HExpireAt(now, "h2", now.Add(time.Second), ExpireOptions{}, "a")
+		st.CleanUpExpired(later)
+		if keys, _, _ := st.Stats(now); keys != 0 {
+			t.Fatalf("expected no keys after clean up, got %d", keys)
+		}
+	})
+
+	t.Run("overwriting a field clears its expiration", func(t *testing.T) {
+		t.Parallel()
+
+		st := New()
+		st.HSet(now, "h", "a", "1")
+		st.HExpireAt(now, "h", now.Add(time.Second), ExpireOptions{}, "a")
+		st.HSet(now, "h", "a", "2")
+		if ttls, _ := st.HPTTL(now, "h", "a"); ttls[0] != -1 {
+			t.Fatalf("expected -1, got %d", ttls[0])
+		}
+	})
+}
+
+func TestStore_HSetEx(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(0, 0)
+
+	st := New()
+	ok, err := st.HSetEx(now, "h", HSetExOptions{FXX: true}, "a", "1")
+	if err != nil || ok {
+		t.Fatalf("expected FXX to fail on a missing key, got %v err=%v", ok, err)
+	}
+	if _, exists := st.Type(now, "h"); exists {
+		t.Fatalf("expected h to stay missing")
+	}
+
+	at := now.Add(time.Minute)
+	if ok, _ := st.HSetEx(now, "h", HSetExOptions{ExpireAt: at, HasExpire: true}, "a", "1", "b", "2"); !ok {
+		t.Fatalf("expected fields to be set")
+	}
+	if times, _ := st.HExpireTime(now, "h", "a", "b"); times[0] != at.UnixMilli() || times[1] != at.UnixMilli() {
+		t.Fatalf("unexpected expiration times %v", times)
+	}
+
+	vals, found, _ := st.HGetEx(now, "h", GetExOptions{ExpireAt: now, HasExpire: true}, "a", "c")
+	if vals[0] != "1" || !found[0] || found[1] {
+		t.Fatalf("unexpected values %v found=%v", vals, found)
+	}
+	if _, ok, _ := st.HGet(now, "h", "a"); ok {
+		t.Fatalf("expected a to be deleted")
+	}
+}
+
+func TestStore_HDel(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(0, 0)
+
+	st := New()
+	st.HSet(now, "h", "a", "1", "b", "2")
+	if all, ok, _ := st.HGetAll(now, "h"); !ok || len(all) != 2 || all["a"] != "1" {
+		t.Fatalf("unexpected fields %v ok=%v", all, ok)
+	}
+	if n, err := st.HDel(now, "h", "a", "x"); err != nil || n != 1 {
+		t.Fatalf("expected 1, got %d err=%v", n, err)
+	}
+	if _, ok, _ := st.HGet(now, "h", "a"); ok {
+		t.Fatalf("expected a to be removed")
+	}
+	st.HDel(now, "h", "b")
+	if _, ok := st.Type(now, "h"); ok {
+		t.Fatalf("expected h to be deleted once empty")
+	}
+
+	st.SetString("s", "value", time.Time{})
+	if _, err := st.HDel(now, "s", "a"); err != ErrWrongType {
+		t.Fatalf("expected ErrWrongType, got %v", err)
+	}
+	if _, _, err := st.HGetAll(now, "s"); err != ErrWrongType {
+		t.Fatalf("expected ErrWrongType, got %v", err)
+	}
+}
@@ -0,0 +1,615 @@
+package db
+
+import (
+	"encoding/binary"
+	"math"
+	"math/bits"
+	"time"
+)
+
+// HyperLogLogs are stored as plain strings using Valkey's HYLL layout, so they
+// can be read with GET and are byte-compatible with a real server:
+//
+//	+------+---+-----+----------+
+//	| HYLL | E | N/U | Cardin.  |
+//	+------+---+-----+----------+
+//
+// a 4 byte magic, a 1 byte encoding (dense or sparse), 3 unused bytes and an
+// 8 byte little endian cached cardinality whose most significant bit marks the
+// cache as stale, followed by the registers.
+//
+// Dense registers are 6 bits each, packed least significant bit first. The
+// sparse encoding is a run-length sequence of opcodes:
+//
+//	ZERO:  00xxxxxx          run of 1-64 zero registers
+//	XZERO: 01xxxxxx xxxxxxxx run of 1-16384 zero registers
+//	VAL:   1vvvvvxx          run of 1-4 registers set to 1-32
+const (
+	hllP              = 14
+	hllQ              = 64 - hllP
+	hllRegisters      = 1 << hllP
+	hllPMask          = hllRegisters - 1
+	hllBits           = 6
+	hllRegisterMax    = 1<<hllBits - 1
+	hllHdrSize        = 16
+	hllDenseSize      = hllHdrSize + (hllRegisters*hllBits+7)/8
+	hllDense          = 0
+	hllSparse         = 1
+	hllMaxEncoding    = hllSparse
+	hllSparseMaxBytes = 3000 // Valkey's default hll-sparse-max-bytes
+	hllAlphaInf       = 0.721347520444481703680
+
+	hllSparseXZeroBit     = 0x40
+	hllSparseValBit       = 0x80
+	hllSparseValMaxValue  = 32
+	hllSparseValMaxLen    = 4
+	hllSparseZeroMaxLen   = 64
+	hllSparseXZeroMaxLen  = 16384
+	hllCardStaleByte      = 15 // card[7]
+	hllCardStaleBit       = 1 << 7
+	hllMurmurSeed         = 0xadc83b19
+	hllMurmurMultiplier   = 0xc6a4a7935bd1e995
+	hllMurmurShift        = 47
+	hllSparseEmptyOpcodes = (hllRegisters + hllSparseXZeroMaxLen - 1) / hllSparseXZeroMaxLen
+)
+
+// PFAdd adds elems to the HyperLogLog at k, creating an empty one if needed.
+// Returns true if the key was created or an internal register was altered.
+func (db *DB) PFAdd(now time.Time, k string, elems ...string) (bool, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	e, err := db.lookupString(now, k)
+	if err != nil {
+		return false, err
+	}
+	updated := false
+	var b []byte
+	if e == nil {
+		b = newHLL()
+		updated = true
+	} else {
+		if !isHLL(e.s) {
+			return false, ErrInvalidHLL
+		}
+		b = []byte(e.s)
+	}
+
+	for _, elem := range elems {
+		var res int
+		if b, res = hllAdd(b, elem); res < 0 {
+			return false, ErrCorruptHLL
+		}
+		if res == 1 {
+			updated = true
+		}
+	}
+	if updated {
+		b[hllCardStaleByte] |= hllCardStaleBit
+	}
+	if e == nil {
+		e = &entry{typ: TString}
+		db.entries[k] = e
+	}
+	e.s = string(b)
+	e.lastAccess = now
+	return updated, nil
+}
+
+// PFCount estimates the cardinality of the union of the HyperLogLogs at keys.
+// Missing keys count as empty. With a single key the estimate is cached in the
+// value's header, as Valkey does.
+func (db *DB) PFCount(now time.Time, keys ...string) (int64, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if len(keys) == 1 {
+		e, err := db.lookupString(now, keys[0])
+		if err != nil || e == nil {
+			return 0, err
+		}
+		if !isHLL(e.s) {
+			return 0, ErrInvalidHLL
+		}
+		e.lastAccess = now
+		if e.s[hllCardStaleByte]&hllCardStaleBit == 0 {
+			return int64(binary.LittleEndian.Uint64([]byte(e.s[8:hllHdrSize]))), nil
+		}
+		histo, ok := hllRegHisto([]byte(e.s))
+		if !ok {
+			return 0, ErrCorruptHLL
+		}
+		card := hllCount(histo)
+		b := []byte(e.s)
+		binary.LittleEndian.PutUint64(b[8:hllHdrSize], card)
+		e.s = string(b)
+		return int64(card), nil
+	}
+
+	regs, _, err := db.hllMergeKeys(now, keys)
+	if err != nil {
+		return 0, err
+	}
+	var histo [64]int
+	for _, r := range regs {
+		histo[r]++
+	}
+	return int64(hllCount(histo)), nil
+}
+
+// PFMerge merges the HyperLogLogs at srcs into the one at dst, creating it if needed.
+// The existing registers of dst are part of the union.
+func (db *DB) PFMerge(now time.Time, dst string, srcs ...string) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	regs, useDense, err := db.hllMergeKeys(now, append([]string{dst}, srcs...))
+	if err != nil {
+		return err
+	}
+
+	// dst was validated by hllMergeKeys.
+	e := db.lookup(now, dst)
+	var b []byte
+	if e == nil {
+		b = newHLL()
+	} else {
+		b = []byte(e.s)
+	}
+	if useDense {
+		if b, err = hllSparseToDense(b); err != nil {
+			return err
+		}
+	}
+	for j, r := range regs {
+		if r == 0 {
+			continue
+		}
+		if b[4] == hllDense {
+			hllDenseSet(b[hllHdrSize:], j, r)
+		} else if b, _ = hllSparseSet(b, j, r); b == nil {
+			return ErrCorruptHLL
+		}
+	}
+	b[hllCardStaleByte] |= hllCardStaleBit
+
+	if e == nil {
+		e = &entry{typ: TString}
+		db.entries[dst] = e
+	}
+	e.s = string(b)
+	e.lastAccess = now
+	return nil
+}
+
+// hllMergeKeys returns the register-wise maximum of the HyperLogLogs at keys,
+// and whether any of them uses the dense encoding.
+// Callers must hold the write lock.
+func (db *DB) hllMergeKeys(now time.Time, keys []string) ([]uint8, bool, error) {
+	regs := make([]uint8, hllRegisters)
+	useDense := false
+	for _, k := range keys {
+		e, err := db.lookupString(now, k)
+		if err != nil {
+			return nil, false, err
+		}
+		if e == nil {
+			continue
+		}
+		if !isHLL(e.s) {
+			return nil, false, ErrInvalidHLL
+		}
+		e.lastAccess = now
+		if e.s[4] == hllDense {
+			useDense = true
+		}
+		if !hllMerge(regs, []byte(e.s)) {
+			return nil, false, ErrCorruptHLL
+		}
+	}
+	return regs, useDense, nil
+}
+
+// newHLL returns an empty sparse HyperLogLog.
+func newHLL() []byte {
+	b := make([]byte, hllHdrSize, hllHdrSize+hllSparseEmptyOpcodes*2)
+	copy(b, "HYLL")
+	b[4] = hllSparse
+	for n := hllRegisters; n > 0; {
+		run := min(n, hllSparseXZeroMaxLen)
+		b = append(b, hllSparseXZero(run)...)
+		n -= run
+	}
+	return b
+}
+
+// isHLL reports whether s looks like a HyperLogLog value.
+func isHLL(s string) bool {
+	if len(s) < hllHdrSize || s[:4] != "HYLL" || s[4] > hllMaxEncoding {
+		return false
+	}
+	return s[4] != hllDense || len(s) == hllDenseSize
+}
+
+// hllAdd adds elem to the HyperLogLog b.
+// Returns the possibly reallocated value and 1 if a register changed, 0 if not, -1 if b is corrupted.
+func hllAdd(b []byte, elem string) ([]byte, int) {
+	index, count := hllPatLen(elem)
+	if b[4] == hllDense {
+		if hllDenseSet(b[hllHdrSize:], index, count) {
+			return b, 1
+		}
+		return b, 0
+	}
+	nb, res := hllSparseSet(b, index, count)
+	if nb == nil {
+		return b, -1
+	}
+	return nb, res
+}
+
+// hllPatLen hashes elem and returns the register it maps to along with the
+// length of the "000..1" pattern that follows the register index bits.
+func hllPatLen(elem string) (int, uint8) {
+	hash := murmurHash64A(elem, hllMurmurSeed)
+	index := int(hash & hllPMask)
+	hash >>= hllP
+	hash |= 1 << hllQ // Make sure the count is at most Q+1.
+	return index, uint8(bits.TrailingZeros64(hash) + 1)
+}
+
+// murmurHash64A is the 64-bit MurmurHash2 variant Valkey uses for HyperLogLog,
+// reading the input as little endian words.
+func murmurHash64A(key string, seed uint64) uint64 {
+	const m = hllMurmurMultiplier
+	const r = hllMurmurShift
+	n := len(key)
+	h := seed ^ (uint64(n) * m)
+
+	i := 0
+	for ; i+8 <= n; i += 8 {
+		k := binary.LittleEndian.Uint64([]byte(key[i : i+8]))
+		k *= m
+		k ^= k >> r
+		k *= m
+		h ^= k
+		h *= m
+	}
+	if rest := key[i:]; len(rest) > 0 {
+		for j := len(rest) - 1; j >= 0; j-- {
+			h ^= uint64(rest[j]) << (8 * j)
+		}
+		h *= m
+	}
+
+	h ^= h >> r
+	h *= m
+	h ^= h >> r
+	return h
+}
+
+// hllDenseGet returns register index of dense registers regs.
+func hllDenseGet(regs []byte, index int) uint8 {
+	byteIdx := index * hllBits / 8
+	fb := uint(index * hllBits & 7)
+	b0 := uint(regs[byteIdx])
+	var b1 uint
+	if byteIdx+1 < len(regs) {
+		b1 = uint(regs[byteIdx+1])
+	}
+	return uint8((b0>>fb | b1<<(8-fb)) & hllRegisterMax)
+}
+
+// hllDenseSet raises register index of dense registers regs to count.
+// Returns true if the register changed.
+func hllDenseSet(regs []byte, index int, count uint8) bool {
+	if hllDenseGet(regs, index) >= count {
+		return false
+	}
+	hllDenseWrite(regs, index, count)
+	return true
+}
+
+// hllDenseWrite stores v in register index of dense registers regs.
+func hllDenseWrite(regs []byte, index int, v uint8) {
+	byteIdx := index * hllBits / 8
+	fb := uint(index * hllBits & 7)
+	regs[byteIdx] &^= byte(hllRegisterMax << fb)
+	regs[byteIdx] |= byte(uint(v) << fb)
+	if byteIdx+1 < len(regs) {
+		regs[byteIdx+1] &^= byte(hllRegisterMax >> (8 - fb))
+		regs[byteIdx+1] |= byte(uint(v) >> (8 - fb))
+	}
+}
+
+func hllSparseIsZero(op byte) bool  { return op&0xc0 == 0 }
+func hllSparseIsXZero(op byte) bool { return op&0xc0 == hllSparseXZeroBit }
+func hllSparseIsVal(op byte) bool   { return op&hllSparseValBit != 0 }
+func hllSparseZeroLen(op byte) int  { return int(op&0x3f) + 1 }
+func hllSparseXZeroLen(op, next byte) int {
+	return (int(op&0x3f)<<8 | int(next)) + 1
+}
+func hllSparseValValue(op byte) uint8 { return (op>>2)&0x1f + 1 }
+func hllSparseValLen(op byte) int     { return int(op&0x3) + 1 }
+
+func hllSparseVal(v uint8, n int) byte { return (v-1)<<2 | byte(n-1) | hllSparseValBit }
+func hllSparseZero(n int) byte         { return byte(n - 1) }
+func hllSparseXZero(n int) []byte {
+	n--
+	return []byte{byte(n>>8) | hllSparseXZeroBit, byte(n)}
+}
+
+// hllSparseRun appends the shortest zero-run opcode covering n registers.
+func hllSparseRun(seq []byte, n int) []byte {
+	if n > hllSparseZeroMaxLen {
+		return append(seq, hllSparseXZero(n)...)
+	}
+	return append(seq, hllSparseZero(n))
+}
+
+// hllSparseSet raises register index of the sparse HyperLogLog b to count,
+// splitting and merging opcodes exactly like Valkey's hllSparseSet, and
+// promoting b to the dense encoding when needed.
+// Returns the possibly reallocated value (nil if b is corrupted) and 1 if
+// the register changed.
+func hllSparseSet(b []byte, index int, count uint8) ([]byte, int) {
+	promote := func() ([]byte, int) {
+		dense, err := hllSparseToDense(b)
+		if err != nil {
+			return nil, -1
+		}
+		hllDenseSet(dense[hllHdrSize:], index, count)
+		return dense, 1
+	}
+	if count > hllSparseValMaxValue {
+		return promote()
+	}
+
+	// Step 1: locate the opcode covering the register.
+	p, prev := hllHdrSize, -1
+	first, span := 0, 0
+	for p < len(b) {
+		oplen := 1
+		switch {
+		case hllSparseIsZero(b[p]):
+			span = hllSparseZeroLen(b[p])
+		case hllSparseIsVal(b[p]):
+			span = hllSparseValLen(b[p])
+		default:
+			if p+1 >= len(b) {
+				return nil, -1
+			}
+			span = hllSparseXZeroLen(b[p], b[p+1])
+			oplen = 2
+		}
+		if index <= first+span-1 {
+			break
+		}
+		prev = p
+		p += oplen
+		first += span
+	}
+	if span == 0 || p >= len(b) {
+		return nil, -1
+	}
+
+	op := b[p]
+	isZero, isXZero, isVal := hllSparseIsZero(op), hllSparseIsXZero(op), hllSparseIsVal(op)
+	var runlen int
+	switch {
+	case isZero:
+		runlen = hllSparseZeroLen(op)
+	case isXZero:
+		runlen = hllSparseXZeroLen(op, b[p+1])
+	default:
+		runlen = hllSparseValLen(op)
+	}
+
+	// Step 2: update the opcode in place when possible, or split it.
+	switch {
+	case isVal && hllSparseValValue(op) >= count:
+		return b, 0
+	case (isVal || isZero) && runlen == 1:
+		b[p] = hllSparseVal(count, 1)
+	default:
+		last := first + runlen - 1
+		seq := make([]byte, 0, 5)
+		if isVal {
+			cur := hllSparseValValue(op)
+			if index != first {
+				seq = append(seq, hllSparseVal(cur, index-first))
+			}
+			seq = append(seq, hllSparseVal(count, 1))
+			if index != last {
+				seq = append(seq, hllSparseVal(cur, last-index))
+			}
+		} else {
+			if index != first {
+				seq = hllSparseRun(seq, index-first)
+			}
+			seq = append(seq, hllSparseVal(count, 1))
+			if index != last {
+				seq = hllSparseRun(seq, last-index)
+			}
+		}
+
+		oldlen := 1
+		if isXZero {
+			oldlen = 2
+		}
+		delta := len(seq) - oldlen
+		if delta > 0 && len(b)+delta > hllSparseMaxBytes {
+			return promote()
+		}
+		nb := make([]byte, 0, len(b)+delta)
+		nb = append(nb, b[:p]...)
+		nb = append(nb, seq...)
+		b = append(nb, b[p+oldlen:]...)
+	}
+
+	// Step 3: merge adjacent VAL opcodes holding the same value, scanning up
+	// to 5 opcodes from the one preceding the update.
+	p = hllHdrSize
+	if prev >= 0 {
+		p = prev
+	}
+	for scan := 5; p < len(b) && scan > 0; scan-- {
+		switch {
+		case hllSparseIsXZero(b[p]):
+			p += 2
+			continue
+		case hllSparseIsZero(b[p]):
+			p++
+			continue
+		}
+		if p+1 < len(b) && hllSparseIsVal(b[p+1]) {
+			v := hllSparseValValue(b[p])
+			if n := hllSparseValLen(b[p]) + hllSparseValLen(b[p+1]); v == hllSparseValValue(b[p+1]) && n <= hllSparseValMaxLen {
+				b[p+1] = hllSparseVal(v, n)
+				b = append(b[:p], b[p+1:]...)
+				// Retry from the same position to merge with the next opcode too.
+				continue
+			}
+		}
+		p++
+	}
+
+	b[hllCardStaleByte] |= hllCardStaleBit
+	return b, 1
+}
+
+// hllSparseWalk calls fn for every opcode of the sparse registers regs with the
+// index of the first register it covers, its run length and value.
+// Returns false if the opcodes do not cover exactly hllRegisters registers.
+func hllSparseWalk(regs []byte, fn func(first, runlen int, v uint8)) bool {
+	idx := 0
+	for p := 0; p < len(regs); {
+		op := regs[p]
+		switch {
+		case hllSparseIsZero(op):
+			idx += hllSparseZeroLen(op)
+			p++
+		case hllSparseIsXZero(op):
+			if p+1 >= len(regs) {
+				return false
+			}
+			idx += hllSparseXZeroLen(op, regs[p+1])
+			p += 2
+		default:
+			runlen := hllSparseValLen(op)
+			if idx+runlen > hllRegisters {
+				return false
+			}
+			fn(idx, runlen, hllSparseValValue(op))
+			idx += runlen
+			p++
+		}
+	}
+	return idx == hllRegisters
+}
+
+// hllSparseToDense converts a sparse HyperLogLog to the dense encoding,
+// keeping its header. Dense values are returned unchanged.
+func hllSparseToDense(b []byte) ([]byte, error) {
+	if b[4] == hllDense {
+		return b, nil
+	}
+	dense := make([]byte, hllDenseSize)
+	copy(dense, b[:hllHdrSize])
+	dense[4] = hllDense
+	regs := dense[hllHdrSize:]
+	ok := hllSparseWalk(b[hllHdrSize:], func(first, runlen int, v uint8) {
+		for i := first; i < first+runlen; i++ {
+			hllDenseWrite(regs, i, v)
+		}
+	})
+	if !ok {
+		return nil, ErrCorruptHLL
+	}
+	return dense, nil
+}
+
+// hllMerge raises every register of dst to the matching register of b.
+// Returns false if b is corrupted.
+func hllMerge(dst []uint8, b []byte) bool {
+	regs := b[hllHdrSize:]
+	if b[4] == hllDense {
+		for i := range dst {
+			dst[i] = max(dst[i], hllDenseGet(regs, i))
+		}
+		return true
+	}
+	return hllSparseWalk(regs, func(first, runlen int, v uint8) {
+		for i := first; i < first+runlen; i++ {
+			dst[i] = max(dst[i], v)
+		}
+	})
+}
+
+// hllRegHisto returns how many registers of b hold each value.
+// Returns false if b is corrupted.
+func hllRegHisto(b []byte) ([64]int, bool) {
+	var histo [64]int
+	regs := b[hllHdrSize:]
+	if b[4] == hllDense {
+		for i := 0; i < hllRegisters; i++ {
+			histo[hllDenseGet(regs, i)]++
+		}
+		return histo, true
+	}
+	zeros := hllRegisters
+	ok := hllSparseWalk(regs, func(_, runlen int, v uint8) {
+		histo[v] += runlen
+		zeros -= runlen
+	})
+	histo[0] = zeros
+	return histo, ok
+}
+
+// hllCount estimates the cardinality from a register histogram using the
+// estimator from Otmar Ertl's "New cardinality estimation algorithms for
+// HyperLogLog sketches" (arXiv:1702.01284), as Valkey does.
+func hllCount(histo [64]int) uint64 {
+	m := float64(hllRegisters)
+	z := m * hllTau((m-float64(histo[hllQ+1]))/m)
+	for j := hllQ; j >= 1; j-- {
+		z += float64(histo[j])
+		z *= 0.5
+	}
+	z += m * hllSigma(float64(histo[0])/m)
+	return uint64(math.Round(hllAlphaInf * m * m / z))
+}
+
+func hllSigma(x float64) float64 {
+	if x == 1 {
+		return math.Inf(1)
+	}
+	y, z := 1.0, x
+	for {
+		x *= x
+		prev := z
+		// Explicit conversions keep the compiler from fusing the multiply-add,
+		// so results match Valkey bit for bit.
+		z += float64(x * y)
+		y += y
+		if prev == z {
+			return z
+		}
+	}
+}
+
+func hllTau(x float64) float64 {
+	if x == 0 || x == 1 {
+		return 0
+	}
+	y, z := 1.0, 1-x
+	for {
+		x = math.Sqrt(x)
+		prev := z
+		y *= 0.5
+		z -= float64((1-x)*(1-x)) * y
+		if prev == z {
+			return z / 3
+		}
+	}
+}
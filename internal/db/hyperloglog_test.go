@@ -0,0 +1,155 @@
+package db
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestStore_PFAdd(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(0, 0)
+
+	t.Run("starts sparse and promotes to dense", func(t *testing.T) {
+		t.Parallel()
+
+		st := New()
+		if _, err := st.PFAdd(now, "hll", "a"); err != nil {
+			t.Fatalf("PFAdd returned error: %v", err)
+		}
+		if v, _ := st.GetString(now, "hll"); v[4] != hllSparse {
+			t.Fatalf("expected sparse encoding, got %d", v[4])
+		}
+		for i := 0; i < 5000; i++ {
+			if _, err := st.PFAdd(now, "hll", strconv.Itoa(i)); err != nil {
+				t.Fatalf("PFAdd returned error: %v", err)
+			}
+		}
+		v, _ := st.GetString(now, "hll")
+		if v[4] != hllDense || len(v) != hllDenseSize {
+			t.Fatalf("expected dense encoding of %d bytes, got encoding %d with %d bytes", hllDenseSize, v[4], len(v))
+		}
+	})
+
+	t.Run("sparse and dense registers agree", func(t *testing.T) {
+		t.Parallel()
+
+		st := New()
+		elems := make([]string, 200)
+		for i := range elems {
+			elems[i] = "elem:" + strconv.Itoa(i)
+		}
+		if _, err := st.PFAdd(now, "hll", elems...); err != nil {
+			t.Fatalf("PFAdd returned error: %v", err)
+		}
+		v, _ := st.GetString(now, "hll")
+		dense, err := hllSparseToDense([]byte(v))
+		if err != nil {
+			t.Fatalf("hllSparseToDense returned error: %v", err)
+		}
+		want := newHLL()
+		if want, err = hllSparseToDense(want); err != nil {
+			t.Fatalf("hllSparseToDense returned error: %v", err)
+		}
+		for _, e := range elems {
+			want, _ = hllAdd(want, e)
+		}
+		if string(dense[hllHdrSize:]) != string(want[hllHdrSize:]) {
+			t.Fatalf("sparse registers differ from dense registers")
+		}
+	})
+
+	t.Run("rejects plain strings", func(t *testing.T) {
+		t.Parallel()
+
+		st := New()
+		st.SetString("hll", "value", time.Time{})
+		if _, err := st.PFAdd(now, "hll", "a"); err != ErrInvalidHLL {
+			t.Fatalf("expected ErrInvalidHLL, got %v", err)
+		}
+	})
+}
+
+func TestStore_PFCount(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(0, 0)
+
+	tcs := []struct {
+		name      string
+		n         int
+		tolerance float64
+	}{
+		{name: "small sets are exact", n: 10},
+		{name: "sparse estimate", n: 1000, tolerance: 0.02},
+		{name: "dense estimate", n: 100000, tolerance: 0.02},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			st := New()
+			for i := 0; i < tc.n; i++ {
+				if _, err := st.PFAdd(now, "hll", strconv.Itoa(i)); err != nil {
+					t.Fatalf("PFAdd returned error: %v", err)
+				}
+			}
+			got, err := st.PFCount(now, "hll")
+			if err != nil {
+				t.Fatalf("PFCount returned error: %v", err)
+			}
+			if diff := float64(got - int64(tc.n)); diff > float64(tc.n)*tc.tolerance || -diff > float64(tc.n)*tc.tolerance {
+				t.Fatalf("estimate %d too far from %d", got, tc.n)
+			}
+			v, _ := st.GetString(now, "hll")
+			if v[hllCardStaleByte]&hllCardStaleBit != 0 {
+				t.Fatalf("expected cached cardinality to be valid")
+			}
+			if cached, _ := st.PFCount(now, "hll"); cached != got {
+				t.Fatalf("expected cached %d, got %d", got, cached)
+			}
+		})
+	}
+}
+
+func TestStore_PFMerge(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(0, 0)
+
+	st := New()
+	for i := 0; i < 3000; i++ {
+		st.PFAdd(now, "dense", "d"+strconv.Itoa(i))
+	}
+	st.PFAdd(now, "sparse", "s1", "s2", "s3")
+	st.PFAdd(now, "dst", "x")
+	st.SetString("plain", "value", time.Time{})
+
+	if err := st.PFMerge(now, "dst", "sparse", "missing"); err != nil {
+		t.Fatalf("PFMerge returned error: %v", err)
+	}
+	if got, _ := st.PFCount(now, "dst"); got != 4 {
+		t.Fatalf("expected 4, got %d", got)
+	}
+	if v, _ := st.GetString(now, "dst"); v[4] != hllSparse {
+		t.Fatalf("expected sparse destination")
+	}
+
+	if err := st.PFMerge(now, "dst", "dense"); err != nil {
+		t.Fatalf("PFMerge returned error: %v", err)
+	}
+	want, _ := st.PFCount(now, "dense", "sparse", "dst")
+	if got, _ := st.PFCount(now, "dst"); got != want {
+		t.Fatalf("expected %d, got %d", want, got)
+	}
+	if v, _ := st.GetString(now, "dst"); v[4] != hllDense {
+		t.Fatalf("expected dense destination after merging a dense source")
+	}
+
+	if err := st.PFMerge(now, "dst", "plain"); err != ErrInvalidHLL {
+		t.Fatalf("expected ErrInvalidHLL, got %v", err)
+	}
+}
@@ -0,0 +1,196 @@
+package db
+
+import (
+	"math/rand/v2"
+	"slices"
+	"time"
+)
+
+// sharedIntegers mirrors Valkey's OBJ_SHARED_INTEGERS: small integer strings
+// are backed by shared objects and report a saturated refcount.
+const sharedIntegers = 10000
+
+// sharedRefCount is the refcount Valkey reports for shared objects.
+const sharedRefCount = 2147483647
+
+// ObjectInfo describes the internal representation of a value as reported by OBJECT.
+type ObjectInfo struct {
+	Encoding string
+	Idle     time.Duration
+	RefCount int64
+}
+
+// Type returns the value type stored at k.
+// Returns (type, false) if the key does not exist or has expired.
+func (db *DB) Type(now time.Time, k string) (ValueType, bool) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	e := db.lookup(now, k)
+	if e == nil {
+		return 0, false
+	}
+	return e.typ, true
+}
+
+// Touch updates the last access time of the given keys and returns how many exist.
+func (db *DB) Touch(now time.Time, keys ...string) int {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	n := 0
+	for _, k := range keys {
+		if e := db.lookup(now, k); e != nil {
+			e.lastAccess = now
+			n++
+		}
+	}
+	return n
+}
+
+// Rename moves src to dst, overwriting dst and carrying over src's TTL.
+// When nx is true the move is skipped if dst already exists.
+// Returns (renamed, srcExists).
+func (db *DB) Rename(now time.Time, src, dst string, nx bool) (bool, bool) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	e := db.lookup(now, src)
+	if e == nil {
+		return false, false
+	}
+	e.lastAccess = now
+	if src == dst {
+		// Renaming a key onto itself is a no-op; RENAMENX reports it as existing.
+		return !nx, true
+	}
+	if nx && db.lookup(now, dst) != nil {
+		return false, true
+	}
+	delete(db.entries, src)
+	db.entries[dst] = e
+	return true, true
+}
+
+// Copy duplicates src into dst, keeping src's TTL.
+// When replace is false the copy is skipped if dst already exists.
+// Returns true if the value was copied.
+func (db *DB) Copy(now time.Time, src, dst string, replace bool) bool {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	return db.copyTo(now, src, db, dst, replace)
+}
+
+// CopyTo is Copy into target, the database at index targetIdx, while db is
+// at idx. Both databases are locked together, in index order like CloneAll,
+// so a snapshot holds either the whole copy or none of it.
+func (db *DB) CopyTo(now time.Time, src string, idx int, target *DB, targetIdx int, dst string, replace bool) bool {
+	first, second := db, target
+	if targetIdx < idx {
+		first, second = target, db
+	}
+	first.mu.Lock()
+	defer first.mu.Unlock()
+	second.mu.Lock()
+	defer second.mu.Unlock()
+
+	return db.copyTo(now, src, target, dst, replace)
+}
+
+// copyTo implements Copy and CopyTo. Callers must hold the write locks of db and target.
+func (db *DB) copyTo(now time.Time, src string, target *DB, dst string, replace bool) bool {
+	e := db.lookup(now, src)
+	if e == nil {
+		return false
+	}
+	if !replace && target.lookup(now, dst) != nil {
+		return false
+	}
+	cp := e.clone()
+	cp.lastAccess = now
+	target.entries[dst] = cp
+	return true
+}
+
+// Keys returns every live key, sorted.
+func (db *DB) Keys(now time.Time) []string {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	keys := make([]string, 0, len(db.entries))
+	for k, e := range db.entries {
+		if e.expired(now) {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// RandomKey returns a random live key.
+// Returns ("", false) if the db holds no live keys.
+func (db *DB) RandomKey(now time.Time) (string, bool) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	keys := make([]string, 0, len(db.entries))
+	for k, e := range db.entries {
+		if e.expired(now) {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	if len(keys) == 0 {
+		return "", false
+	}
+	return keys[rand.IntN(len(keys))], true
+}
+
+// Object reports encoding, idle time and refcount of the value stored at k.
+// Looking a key up through Object does not count as an access.
+func (db *DB) Object(now time.Time, k string) (ObjectInfo, bool) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	e := db.lookup(now, k)
+	if e == nil {
+		return ObjectInfo{}, false
+	}
+
+	info := ObjectInfo{RefCount: 1}
+	if !e.lastAccess.IsZero() && now.After(e.lastAccess) {
+		info.Idle = now.Sub(e.lastAccess)
+	}
+	switch e.typ {
+	case TString:
+		info.Encoding = stringEncoding(e.s)
+		if info.Encoding == "int" {
+			if n, _ := ParseStrictInt(e.s); n >= 0 && n < sharedIntegers {
+				info.RefCount = sharedRefCount
+			}
+		}
+	case TZSet:
+		info.Encoding = e.z.encoding()
+	case TList:
+		info.Encoding = e.l.encoding()
+	case TSet:
+		info.Encoding = e.m.encoding()
+	case THash:
+		info.Encoding = e.h.encoding()
+	}
+	return info, true
+}
+
+// stringEncoding mimics how Valkey picks a string encoding:
+// canonical 64-bit integers are "int", short strings "embstr", others "raw".
+func stringEncoding(s string) string {
+	if _, ok := ParseStrictInt(s); ok {
+		return "int"
+	}
+	if len(s) <= 44 {
+		return "embstr"
+	}
+	return "raw"
+}
@@ -0,0 +1,327 @@
+package db
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestStore_Type(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(0, 0)
+
+	st := New()
+	st.SetString("foo", "bar", time.Time{})
+	st.SetString("stale", "x", now.Add(-time.Second))
+
+	if typ, ok := st.Type(now, "foo"); !ok || typ != TString {
+		t.Fatalf("Type(foo) = (%v,%v); want (string,true)", typ, ok)
+	}
+	if _, ok := st.Type(now, "stale"); ok {
+		t.Fatalf("expected stale key to be reported missing")
+	}
+	if _, ok := st.Type(now, "missing"); ok {
+		t.Fatalf("expected missing key to be reported missing")
+	}
+}
+
+func TestStore_Rename(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(0, 0)
+
+	tcs := []struct {
+		name        string
+		arrange     func(*DB)
+		src, dst    string
+		nx          bool
+		wantRenamed bool
+		wantExists  bool
+		check       func(*testing.T, *DB)
+	}{
+		{
+			name: "moves value and ttl",
+			arrange: func(st *DB) {
+				st.SetString("src", "v", now.Add(10*time.Second))
+			},
+			src:         "src",
+			dst:         "dst",
+			wantRenamed: true,
+			wantExists:  true,
+			check: func(t *testing.T, st *DB) {
+				if _, ok := st.GetString(now, "src"); ok {
+					t.Fatalf("expected src to be gone")
+				}
+				if got, _ := st.GetString(now, "dst"); got != "v" {
+					t.Fatalf("expected dst=v, got %q", got)
+				}
+				if ttl := st.TTL(now, "dst"); ttl != 10 {
+					t.Fatalf("expected ttl 10, got %d", ttl)
+				}
+			},
+		},
+		{
+			name: "overwrites destination",
+			arrange: func(st *DB) {
+				st.SetString("src", "new", time.Time{})
+				st.SetString("dst", "old", now.Add(time.Minute))
+			},
+			src:         "src",
+			dst:         "dst",
+			wantRenamed: true,
+			wantExists:  true,
+			check: func(t *testing.T, st *DB) {
+				if got, _ := st.GetString(now, "dst"); got != "new" {
+					t.Fatalf("expected dst=new, got %q", got)
+				}
+				if ttl := st.TTL(now, "dst"); ttl != -1 {
+					t.Fatalf("expected ttl -1, got %d", ttl)
+				}
+			},
+		},
+		{
+			name: "nx keeps existing destination",
+			arrange: func(st *DB) {
+				st.SetString("src", "new", time.Time{})
+				st.SetString("dst", "old", time.Time{})
+			},
+			src:        "src",
+			dst:        "dst",
+			nx:         true,
+			wantExists: true,
+			check: func(t *testing.T, st *DB) {
+				if got, _ := st.GetString(now, "dst"); got != "old" {
+					t.Fatalf("expected dst=old, got %q", got)
+				}
+			},
+		},
+		{
+			name: "same key is a no-op",
+			arrange: func(st *DB) {
+				st.SetString("k", "v", time.Time{})
+			},
+			src:         "k",
+			dst:         "k",
+			wantRenamed: true,
+			wantExists:  true,
+			check: func(t *testing.T, st *DB) {
+				if got, _ := st.GetString(now, "k"); got != "v" {
+					t.Fatalf("expected k=v, got %q", got)
+				}
+			},
+		},
+		{
+			name: "reports missing source",
+			src:  "missing",
+			dst:  "dst",
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			st := New()
+			if tc.arrange != nil {
+				tc.arrange(st)
+			}
+			renamed, exists := st.Rename(now, tc.src, tc.dst, tc.nx)
+			if renamed != tc.wantRenamed || exists != tc.wantExists {
+				t.Fatalf("Rename(%q,%q) = (%v,%v); want (%v,%v)", tc.src, tc.dst, renamed, exists, tc.wantRenamed, tc.wantExists)
+			}
+			if tc.check != nil {
+				tc.check(t, st)
+			}
+		})
+	}
+}
+
+func TestStore_Copy(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(0, 0)
+
+	t.Run("copies value and ttl within db", func(t *testing.T) {
+		t.Parallel()
+
+		st := New()
+		st.SetString("src", "v", now.Add(10*time.Second))
+		if !st.Copy(now, "src", "dst", false) {
+			t.Fatalf("expected copy to succeed")
+		}
+		if got, _ := st.GetString(now, "dst"); got != "v" {
+			t.Fatalf("expected dst=v, got %q", got)
+		}
+		if ttl := st.TTL(now, "dst"); ttl != 10 {
+			t.Fatalf("expected ttl 10, got %d", ttl)
+		}
+		if _, ok := st.GetString(now, "src"); !ok {
+			t.Fatalf("expected src to remain")
+		}
+	})
+
+	t.Run("respects replace flag", func(t *testing.T) {
+		t.Parallel()
+
+		st := New()
+		st.SetString("src", "new", time.Time{})
+		st.SetString("dst", "old", time.Time{})
+		if st.Copy(now, "src", "dst", false) {
+			t.Fatalf("expected copy without replace to fail")
+		}
+		if !st.Copy(now, "src", "dst", true) {
+			t.Fatalf("expected copy with replace to succeed")
+		}
+		if got, _ := st.GetString(now, "dst"); got != "new" {
+			t.Fatalf("expected dst=new, got %q", got)
+		}
+	})
+
+	t.Run("copies into another db", func(t *testing.T) {
+		t.Parallel()
+
+		src, dst := New(), New()
+		src.SetString("k", "v", time.Time{})
+		for _, idxs := range [][2]int{{0, 1}, {1, 0}} {
+			if !src.CopyTo(now, "k", idxs[0], dst, idxs[1], "k", true) {
+				t.Fatalf("expected copy from db %d to db %d to succeed", idxs[0], idxs[1])
+			}
+		}
+		if got, _ := dst.GetString(now, "k"); got != "v" {
+			t.Fatalf("expected k=v in target, got %q", got)
+		}
+		if src.CopyTo(now, "k", 0, dst, 1, "k", false) {
+			t.Fatalf("expected copy without replace to fail")
+		}
+	})
+
+	t.Run("returns false for missing source", func(t *testing.T) {
+		t.Parallel()
+
+		st := New()
+		if st.Copy(now, "missing", "dst", true) {
+			t.Fatalf("expected copy of missing key to fail")
+		}
+	})
+}
+
+func TestStore_Touch(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(0, 0)
+
+	st := New()
+	st.SetString("foo", "bar", time.Time{})
+	st.SetString("stale", "x", now.Add(-time.Second))
+
+	if got := st.Touch(now, "foo", "stale", "missing"); got != 1 {
+		t.Fatalf("Touch = %d; want 1", got)
+	}
+	later := now.Add(3 * time.Second)
+	if info, _ := st.Object(later, "foo"); info.Idle != 3*time.Second {
+		t.Fatalf("expected idle 3s after touch, got %v", info.Idle)
+	}
+}
+
+func TestStore_RandomKey(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(0, 0)
+
+	st := New()
+	if _, ok := st.RandomKey(now); ok {
+		t.Fatalf("expected no key in empty db")
+	}
+
+	st.SetString("stale", "x", now.Add(-time.Second))
+	st.SetString("foo", "bar", time.Time{})
+	for i := 0; i < 10; i++ {
+		if k, ok := st.RandomKey(now); !ok || k != "foo" {
+			t.Fatalf("RandomKey = (%q,%v); want (foo,true)", k, ok)
+		}
+	}
+}
+
+func TestStore_Object(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(0, 0)
+
+	tcs := []struct {
+		name         string
+		value        string
+		wantEncoding string
+		wantRefCount int64
+	}{
+		{
+			name:         "shared integer",
+			value:        "42",
+			wantEncoding: "int",
+			wantRefCount: sharedRefCount,
+		},
+		{
+			name:         "large integer",
+			value:        "123456789",
+			wantEncoding: "int",
+			wantRefCount: 1,
+		},
+		{
+			name:         "non canonical integer",
+			value:        "007",
+			wantEncoding: "embstr",
+			wantRefCount: 1,
+		},
+		{
+			name:         "short string",
+			value:        "hello",
+			wantEncoding: "embstr",
+			wantRefCount: 1,
+		},
+		{
+			name:         "long string",
+			value:        "this string is definitely longer than forty-four bytes",
+			wantEncoding: "raw",
+			wantRefCount: 1,
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			st := New()
+			st.SetStringWithOptions(now, "k", tc.value, SetOptions{})
+			info, ok := st.Object(now.Add(5*time.Second), "k")
+			if !ok {
+				t.Fatalf("expected key to exist")
+			}
+			if info.Encoding != tc.wantEncoding {
+				t.Fatalf("Encoding = %q; want %q", info.Encoding, tc.wantEncoding)
+			}
+			if info.RefCount != tc.wantRefCount {
+				t.Fatalf("RefCount = %d; want %d", info.RefCount, tc.wantRefCount)
+			}
+			if info.Idle != 5*time.Second {
+				t.Fatalf("Idle = %v; want 5s", info.Idle)
+			}
+		})
+	}
+}
+
+func TestStore_Keys(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(0, 0)
+
+	st := New()
+	st.SetString("b", "1", time.Time{})
+	st.SetString("a", "1", time.Time{})
+	st.SetString("gone", "1", now.Add(-time.Second))
+
+	if got := st.Keys(now); !slices.Equal(got, []string{"a", "b"}) {
+		t.Fatalf("unexpected keys %v", got)
+	}
+}
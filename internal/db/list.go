@@ -0,0 +1,147 @@
+package db
+
+import (
+	"time"
+)
+
+// listListpackBytes mirrors Valkey's default list-max-listpack-size of -2 (8kb).
+const listListpackBytes = 8 * 1024
+
+// list holds the elements of a list from head to tail.
+type list struct {
+	items []string
+}
+
+// clone returns a deep copy of l.
+func (l *list) clone() *list {
+	return &list{items: append([]string(nil), l.items...)}
+}
+
+// encoding mimics how Valkey picks a list encoding: small lists fit a single listpack.
+func (l *list) encoding() string {
+	size := 7 // listpack header and terminator
+	for _, v := range l.items {
+		size += len(v) + 2
+		if size > listListpackBytes {
+			return "quicklist"
+		}
+	}
+	return "listpack"
+}
+
+// RPush appends vals to the tail of the list at k, creating it if needed.
+// Returns the length of the list after the push.
+func (db *DB) RPush(now time.Time, k string, vals ...string) (int, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	e, err := db.lookupList(now, k)
+	if err != nil {
+		return 0, err
+	}
+	if e == nil {
+		e = &entry{typ: TList, l: &list{}}
+		db.entries[k] = e
+	}
+	e.lastAccess = now
+	e.l.items = append(e.l.items, vals...)
+	return len(e.l.items), nil
+}
+
+// LPush prepends vals to the head of the list at k one by one, creating it if needed,
+// so the last value ends up first.
+// Returns the length of the list after the push.
+func (db *DB) LPush(now time.Time, k string, vals ...string) (int, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	e, err := db.lookupList(now, k)
+	if err != nil {
+		return 0, err
+	}
+	if e == nil {
+		e = &entry{typ: TList, l: &list{}}
+		db.entries[k] = e
+	}
+	e.lastAccess = now
+	items := make([]string, 0, len(vals)+len(e.l.items))
+	for i := len(vals) - 1; i >= 0; i-- {
+		items = append(items, vals[i])
+	}
+	e.l.items = append(items, e.l.items...)
+	return len(e.l.items), nil
+}
+
+// LPop removes and returns the head of the list at k, deleting k once it is empty.
+// Returns ("", false, nil) if the key does not exist.
+func (db *DB) LPop(now time.Time, k string) (string, bool, error) {
+	return db.pop(now, k, true)
+}
+
+// RPop removes and returns the tail of the list at k, deleting k once it is empty.
+// Returns ("", false, nil) if the key does not exist.
+func (db *DB) RPop(now time.Time, k string) (string, bool, error) {
+	return db.pop(now, k, false)
+}
+
+func (db *DB) pop(now time.Time, k string, head bool) (string, bool, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	e, err := db.lookupList(now, k)
+	if err != nil || e == nil {
+		return "", false, err
+	}
+	e.lastAccess = now
+	var v string
+	if head {
+		v, e.l.items = e.l.items[0], e.l.items[1:]
+	} else {
+		last := len(e.l.items) - 1
+		v, e.l.items = e.l.items[last], e.l.items[:last]
+	}
+	if len(e.l.items) == 0 {
+		delete(db.entries, k)
+	}
+	return v, true, nil
+}
+
+// ListItems returns the elements of the list at k from head to tail.
+// Returns (nil, false, nil) if the key does not exist.
+func (db *DB) ListItems(now time.Time, k string) ([]string, bool, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	e, err := db.lookupList(now, k)
+	if err != nil || e == nil {
+		return nil, false, err
+	}
+	e.lastAccess = now
+	return append([]string(nil), e.l.items...), true, nil
+}
+
+// ListStore replaces whatever is stored at k with a list holding items.
+// An empty items list deletes k.
+func (db *DB) ListStore(now time.Time, k string, items []string) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if len(items) == 0 {
+		delete(db.entries, k)
+		return
+	}
+	db.entries[k] = &entry{typ: TList, l: &list{items: append([]string(nil), items...)}, lastAccess: now}
+}
+
+// lookupList returns the live list entry for k, or ErrWrongType if k holds another type.
+// Callers must hold the write lock.
+func (db *DB) lookupList(now time.Time, k string) (*entry, error) {
+	e := db.lookup(now, k)
+	if e == nil {
+		return nil, nil
+	}
+	if e.typ != TList {
+		return nil, ErrWrongType
+	}
+	return e, nil
+}
@@ -0,0 +1,94 @@
+package db
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStore_RPush(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(0, 0)
+
+	st := New()
+	if n, err := st.RPush(now, "l", "a", "b"); err != nil || n != 2 {
+		t.Fatalf("expected 2, got %d err=%v", n, err)
+	}
+	if n, _ := st.RPush(now, "l", "c"); n != 3 {
+		t.Fatalf("expected 3, got %d", n)
+	}
+	if items, ok, _ := st.ListItems(now, "l"); !ok || !slices.Equal(items, []string{"a", "b", "c"}) {
+		t.Fatalf("unexpected items %v ok=%v", items, ok)
+	}
+	if info, _ := st.Object(now, "l"); info.Encoding != "listpack" {
+		t.Fatalf("expected listpack encoding, got %q", info.Encoding)
+	}
+
+	st.RPush(now, "l", strings.Repeat("x", listListpackBytes))
+	if info, _ := st.Object(now, "l"); info.Encoding != "quicklist" {
+		t.Fatalf("expected quicklist encoding, got %q", info.Encoding)
+	}
+
+	st.SetString("s", "value", time.Time{})
+	if _, err := st.RPush(now, "s", "a"); err != ErrWrongType {
+		t.Fatalf("expected ErrWrongType, got %v", err)
+	}
+}
+
+func TestStore_ListStore(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(0, 0)
+
+	st := New()
+	st.SetString("l", "value", time.Time{})
+	st.ListStore(now, "l", []string{"a", "b"})
+	if items, _, _ := st.ListItems(now, "l"); !slices.Equal(items, []string{"a", "b"}) {
+		t.Fatalf("unexpected items %v", items)
+	}
+
+	st.ListStore(now, "l", nil)
+	if _, ok := st.Type(now, "l"); ok {
+		t.Fatalf("expected l to be deleted")
+	}
+}
+
+func TestStore_LPushPop(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(0, 0)
+
+	st := New()
+	if n, err := st.LPush(now, "l", "a", "b"); err != nil || n != 2 {
+		t.Fatalf("expected 2, got %d err=%v", n, err)
+	}
+	st.RPush(now, "l", "c")
+	if items, _, _ := st.ListItems(now, "l"); !slices.Equal(items, []string{"b", "a", "c"}) {
+		t.Fatalf("unexpected items %v", items)
+	}
+	if v, ok, _ := st.LPop(now, "l"); !ok || v != "b" {
+		t.Fatalf("expected b, got %q ok=%v", v, ok)
+	}
+	if v, ok, _ := st.RPop(now, "l"); !ok || v != "c" {
+		t.Fatalf("expected c, got %q ok=%v", v, ok)
+	}
+	if v, ok, _ := st.RPop(now, "l"); !ok || v != "a" {
+		t.Fatalf("expected a, got %q ok=%v", v, ok)
+	}
+	if _, ok := st.Type(now, "l"); ok {
+		t.Fatalf("expected l to be deleted once empty")
+	}
+	if _, ok, err := st.LPop(now, "l"); ok || err != nil {
+		t.Fatalf("expected missing key, got ok=%v err=%v", ok, err)
+	}
+
+	st.SetString("s", "value", time.Time{})
+	if _, err := st.LPush(now, "s", "a"); err != ErrWrongType {
+		t.Fatalf("expected ErrWrongType, got %v", err)
+	}
+	if _, _, err := st.LPop(now, "s"); err != ErrWrongType {
+		t.Fatalf("expected ErrWrongType, got %v", err)
+	}
+}
@@ -0,0 +1,172 @@
+package db
+
+import (
+	"cmp"
+	"slices"
+	"time"
+)
+
+// Valkey's default set-max-intset-entries, set-max-listpack-entries and set-max-listpack-value.
+const (
+	setIntsetEntries   = 512
+	setListpackEntries = 128
+	setListpackValue   = 64
+)
+
+// set holds the members of a set.
+type set struct {
+	members map[string]struct{}
+}
+
+func newSet() *set {
+	return &set{members: make(map[string]struct{})}
+}
+
+// clone returns a deep copy of st.
+func (st *set) clone() *set {
+	cp := newSet()
+	for m := range st.members {
+		cp.members[m] = struct{}{}
+	}
+	return cp
+}
+
+// isIntset reports whether every member is an integer Valkey would keep in an intset.
+func (st *set) isIntset() bool {
+	if len(st.members) > setIntsetEntries {
+		return false
+	}
+	for m := range st.members {
+		if _, ok := ParseStrictInt(m); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// encoding mimics how Valkey picks a set encoding.
+func (st *set) encoding() string {
+	if st.isIntset() {
+		return "intset"
+	}
+	if len(st.members) > setListpackEntries {
+		return "hashtable"
+	}
+	for m := range st.members {
+		if len(m) > setListpackValue {
+			return "hashtable"
+		}
+	}
+	return "listpack"
+}
+
+// sorted returns the members in a stable order: numerically for intsets,
+// as Valkey iterates them, and lexicographically otherwise.
+func (st *set) sorted() []string {
+	out := make([]string, 0, len(st.members))
+	for m := range st.members {
+		out = append(out, m)
+	}
+	if st.isIntset() {
+		slices.SortFunc(out, func(a, b string) int {
+			x, _ := ParseStrictInt(a)
+			y, _ := ParseStrictInt(b)
+			return cmp.Compare(x, y)
+		})
+		return out
+	}
+	slices.Sort(out)
+	return out
+}
+
+// SAdd adds members to the set at k, creating it if needed.
+// Returns the number of members that were not already present.
+func (db *DB) SAdd(now time.Time, k string, members ...string) (int, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	e, err := db.lookupSet(now, k)
+	if err != nil {
+		return 0, err
+	}
+	if e == nil {
+		e = &entry{typ: TSet, m: newSet()}
+		db.entries[k] = e
+	}
+	e.lastAccess = now
+
+	n := 0
+	for _, m := range members {
+		if _, ok := e.m.members[m]; !ok {
+			e.m.members[m] = struct{}{}
+			n++
+		}
+	}
+	return n, nil
+}
+
+// SRem removes members from the set at k, deleting k once it is empty.
+// Returns the number of members that were removed.
+func (db *DB) SRem(now time.Time, k string, members ...string) (int, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	e, err := db.lookupSet(now, k)
+	if err != nil || e == nil {
+		return 0, err
+	}
+	e.lastAccess = now
+
+	n := 0
+	for _, m := range members {
+		if _, ok := e.m.members[m]; ok {
+			delete(e.m.members, m)
+			n++
+		}
+	}
+	if len(e.m.members) == 0 {
+		delete(db.entries, k)
+	}
+	return n, nil
+}
+
+// SIsMember reports whether member belongs to the set at k.
+func (db *DB) SIsMember(now time.Time, k, member string) (bool, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	e, err := db.lookupSet(now, k)
+	if err != nil || e == nil {
+		return false, err
+	}
+	e.lastAccess = now
+	_, ok := e.m.members[member]
+	return ok, nil
+}
+
+// SMembers returns the members of the set at k.
+// Returns (nil, false, nil) if the key does not exist.
+func (db *DB) SMembers(now time.Time, k string) ([]string, bool, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	e, err := db.lookupSet(now, k)
+	if err != nil || e == nil {
+		return nil, false, err
+	}
+	e.lastAccess = now
+	return e.m.sorted(), true, nil
+}
+
+// lookupSet returns the live set entry for k, or ErrWrongType if k holds another type.
+// Callers must hold the write lock.
+func (db *DB) lookupSet(now time.Time, k string) (*entry, error) {
+	e := db.lookup(now, k)
+	if e == nil {
+		return nil, nil
+	}
+	if e.typ != TSet {
+		return nil, ErrWrongType
+	}
+	return e, nil
+}
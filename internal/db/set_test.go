@@ -0,0 +1,78 @@
+package db
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStore_SAdd(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(0, 0)
+
+	tcs := []struct {
+		name     string
+		members  []string
+		added    int
+		want     []string
+		encoding string
+	}{
+		{name: "integers", members: []string{"10", "-1", "2", "2"}, added: 3, want: []string{"-1", "2", "10"}, encoding: "intset"},
+		{name: "strings", members: []string{"b", "a", "10"}, added: 3, want: []string{"10", "a", "b"}, encoding: "listpack"},
+		{name: "long members", members: []string{strings.Repeat("x", setListpackValue+1)}, added: 1, want: []string{strings.Repeat("x", setListpackValue+1)}, encoding: "hashtable"},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			st := New()
+			n, err := st.SAdd(now, "s", tc.members...)
+			if err != nil {
+				t.Fatalf("SAdd returned error: %v", err)
+			}
+			if n != tc.added {
+				t.Fatalf("expected %d, got %d", tc.added, n)
+			}
+			if got, _, _ := st.SMembers(now, "s"); !slices.Equal(got, tc.want) {
+				t.Fatalf("expected %v, got %v", tc.want, got)
+			}
+			if info, _ := st.Object(now, "s"); info.Encoding != tc.encoding {
+				t.Fatalf("expected %s encoding, got %q", tc.encoding, info.Encoding)
+			}
+		})
+	}
+}
+
+func TestStore_SRem(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(0, 0)
+
+	st := New()
+	st.SAdd(now, "s", "a", "b")
+	if ok, _ := st.SIsMember(now, "s", "a"); !ok {
+		t.Fatalf("expected a to be a member")
+	}
+	if n, err := st.SRem(now, "s", "a", "x"); err != nil || n != 1 {
+		t.Fatalf("expected 1, got %d err=%v", n, err)
+	}
+	if ok, _ := st.SIsMember(now, "s", "a"); ok {
+		t.Fatalf("expected a to be removed")
+	}
+	st.SRem(now, "s", "b")
+	if _, ok := st.Type(now, "s"); ok {
+		t.Fatalf("expected s to be deleted once empty")
+	}
+
+	st.SetString("str", "value", time.Time{})
+	if _, err := st.SRem(now, "str", "a"); err != ErrWrongType {
+		t.Fatalf("expected ErrWrongType, got %v", err)
+	}
+	if _, err := st.SIsMember(now, "str", "a"); err != ErrWrongType {
+		t.Fatalf("expected ErrWrongType, got %v", err)
+	}
+}
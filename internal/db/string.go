@@ -0,0 +1,343 @@
+package db
+
+import (
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxStringLen mirrors Valkey's default proto-max-bulk-len (512MB).
+const MaxStringLen = 512 * 1024 * 1024
+
+// GetExOptions tweaks GetEx behavior.
+type GetExOptions struct {
+	ExpireAt  time.Time
+	HasExpire bool // Apply ExpireAt (deleting the key if it lies in the past)
+	Persist   bool // Remove any existing expiry
+}
+
+// LookupString fetches the string value of k.
+// Returns ("", false, nil) if the key does not exist and ErrWrongType if it holds another type.
+func (db *DB) LookupString(now time.Time, k string) (string, bool, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	e, err := db.lookupString(now, k)
+	if e == nil || err != nil {
+		return "", false, err
+	}
+	e.lastAccess = now
+	return e.s, true, nil
+}
+
+// Append appends v to the string at k, creating it if needed.
+// Returns the length of the string after the append.
+func (db *DB) Append(now time.Time, k, v string) (int, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	e, err := db.lookupString(now, k)
+	if err != nil {
+		return 0, err
+	}
+	if e == nil {
+		db.entries[k] = &entry{typ: TString, s: v, lastAccess: now}
+		return len(v), nil
+	}
+	if len(e.s)+len(v) > MaxStringLen {
+		return 0, ErrStringTooLong
+	}
+	e.s += v
+	e.lastAccess = now
+	return len(e.s), nil
+}
+
+// StrLen returns the length of the string at k (0 if missing).
+func (db *DB) StrLen(now time.Time, k string) (int, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	e, err := db.lookupString(now, k)
+	if e == nil || err != nil {
+		return 0, err
+	}
+	e.lastAccess = now
+	return len(e.s), nil
+}
+
+// SetRange overwrites the string at k starting at offset, zero-padding as needed.
+// A missing key with an empty v is left untouched.
+// Returns the length of the string after the write.
+func (db *DB) SetRange(now time.Time, k string, offset int, v string) (int, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	e, err := db.lookupString(now, k)
+	if err != nil {
+		return 0, err
+	}
+	if e == nil {
+		if v == "" {
+			return 0, nil
+		}
+		if offset+len(v) > MaxStringLen {
+			return 0, ErrStringTooLong
+		}
+		e = &entry{typ: TString}
+		db.entries[k] = e
+	}
+	e.lastAccess = now
+	if v == "" {
+		return len(e.s), nil
+	}
+	if offset+len(v) > MaxStringLen {
+		return 0, ErrStringTooLong
+	}
+	e.s = overwrite(e.s, offset, v)
+	return len(e.s), nil
+}
+
+// GetDel fetches the string at k and deletes the key.
+func (db *DB) GetDel(now time.Time, k string) (string, bool, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	e, err := db.lookupString(now, k)
+	if e == nil || err != nil {
+		return "", false, err
+	}
+	delete(db.entries, k)
+	return e.s, true, nil
+}
+
+// GetEx fetches the string at k and optionally updates its expiry.
+func (db *DB) GetEx(now time.Time, k string, opts GetExOptions) (string, bool, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	e, err := db.lookupString(now, k)
+	if e == nil || err != nil {
+		return "", false, err
+	}
+	e.lastAccess = now
+	switch {
+	case opts.HasExpire && !opts.ExpireAt.After(now):
+		delete(db.entries, k)
+	case opts.HasExpire:
+		e.expireAt = opts.ExpireAt
+	case opts.Persist:
+		e.expireAt = time.Time{}
+	}
+	return e.s, true, nil
+}
+
+// GetSet replaces the string at k with v, clearing any TTL, and returns the old value.
+func (db *DB) GetSet(now time.Time, k, v string) (string, bool, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	e, err := db.lookupString(now, k)
+	if err != nil {
+		return "", false, err
+	}
+	db.entries[k] = &entry{typ: TString, s: v, lastAccess: now}
+	if e == nil {
+		return "", false, nil
+	}
+	return e.s, true, nil
+}
+
+// MSet sets keys and values given as alternating kvs, clearing any TTL.
+// When nx is true nothing is written unless none of the keys exist.
+// Returns true if the values were written.
+func (db *DB) MSet(now time.Time, nx bool, kvs ...string) bool {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if nx {
+		for i := 0; i+1 < len(kvs); i += 2 {
+			if db.lookup(now, kvs[i]) != nil {
+				return false
+			}
+		}
+	}
+	for i := 0; i+1 < len(kvs); i += 2 {
+		db.entries[kvs[i]] = &entry{typ: TString, s: kvs[i+1], lastAccess: now}
+	}
+	return true
+}
+
+// IncrBy adds delta to the integer stored at k (missing keys count as 0), keeping its TTL.
+func (db *DB) IncrBy(now time.Time, k string, delta int64) (int64, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	e, err := db.lookupString(now, k)
+	if err != nil {
+		return 0, err
+	}
+	var cur int64
+	if e != nil {
+		var ok bool
+		if cur, ok = ParseStrictInt(e.s); !ok {
+			return 0, ErrNotInteger
+		}
+	}
+	if (delta < 0 && cur < math.MinInt64-delta) || (delta > 0 && cur > math.MaxInt64-delta) {
+		return 0, ErrOverflow
+	}
+	cur += delta
+	if e == nil {
+		e = &entry{typ: TString}
+		db.entries[k] = e
+	}
+	e.s = strconv.FormatInt(cur, 10)
+	e.lastAccess = now
+	return cur, nil
+}
+
+// IncrByFloat adds delta to the float stored at k (missing keys count as 0), keeping its TTL.
+// Like Valkey, the sum is computed in long double precision, so 0.1 plus 0.2 is "0.3".
+// Returns the new value formatted the way it is stored.
+func (db *DB) IncrByFloat(now time.Time, k string, delta string) (string, error) {
+	d, ok := parseLongDouble(delta)
+	if !ok {
+		return "", ErrNotFloat
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	e, err := db.lookupString(now, k)
+	if err != nil {
+		return "", err
+	}
+	cur := new(big.Float).SetPrec(longDoublePrec)
+	if e != nil {
+		if cur, ok = parseLongDouble(e.s); !ok {
+			return "", ErrNotFloat
+		}
+	}
+	if cur.IsInf() || d.IsInf() {
+		return "", ErrNaNOrInf
+	}
+	cur.Add(cur, d)
+	if longDoubleOverflows(cur) {
+		return "", ErrNaNOrInf
+	}
+	if e == nil {
+		e = &entry{typ: TString}
+		db.entries[k] = e
+	}
+	e.s = formatLongDouble(cur)
+	e.lastAccess = now
+	return e.s, nil
+}
+
+// lookupString returns the live string entry for k.
+// Returns (nil, nil) if missing and ErrWrongType if k holds another type.
+// Callers must hold the write lock.
+func (db *DB) lookupString(now time.Time, k string) (*entry, error) {
+	e := db.lookup(now, k)
+	if e == nil {
+		return nil, nil
+	}
+	if e.typ != TString {
+		return nil, ErrWrongType
+	}
+	return e, nil
+}
+
+// overwrite writes v into s at offset, zero-padding s if it is too short.
+func overwrite(s string, offset int, v string) string {
+	end := offset + len(v)
+	if end > len(s) {
+		s += strings.Repeat("\x00", end-len(s))
+	}
+	return s[:offset] + v + s[end:]
+}
+
+// ParseStrictInt parses s the way Valkey's string2ll does: only the canonical
+// decimal form of an int64 is accepted (no sign prefix "+", spaces or leading zeros).
+func ParseStrictInt(s string) (int64, bool) {
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || strconv.FormatInt(n, 10) != s {
+		return 0, false
+	}
+	return n, true
+}
+
+// ParseFloat parses s the way Valkey's string2ld does, rejecting surrounding
+// spaces, NaN and values that overflow or underflow.
+func ParseFloat(s string) (float64, bool) {
+	if s == "" || strings.TrimSpace(s) != s {
+		return 0, false
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil && (!isRangeError(err) || math.IsInf(f, 0) || f == 0) {
+		return 0, false
+	}
+	if math.IsNaN(f) {
+		return 0, false
+	}
+	return f, true
+}
+
+// The x87 long double Valkey does float arithmetic in: the mantissa size,
+// and the binary exponents (as big.Float.MantExp reports them) of its largest
+// finite value and of its smallest denormal.
+const (
+	longDoublePrec   = 64
+	longDoubleMaxExp = 16384
+	longDoubleMinExp = -16444
+)
+
+// parseLongDouble parses s the way Valkey's string2ld does with strtold:
+// like ParseFloat, but over the long double range (about ±1.19e4932) and
+// keeping long double precision.
+func parseLongDouble(s string) (*big.Float, bool) {
+	if s == "" || strings.TrimSpace(s) != s {
+		return nil, false
+	}
+	// strconv checks the syntax; its float64 range does not apply here.
+	if f, err := strconv.ParseFloat(s, 64); (err != nil && !isRangeError(err)) || math.IsNaN(f) {
+		return nil, false
+	}
+	f, _, err := big.ParseFloat(s, 0, longDoublePrec, big.ToNearestEven)
+	if err != nil || longDoubleOverflows(f) || longDoubleUnderflows(f) {
+		return nil, false
+	}
+	return f, true
+}
+
+// longDoubleOverflows reports whether the finite f is too large for a long double.
+func longDoubleOverflows(f *big.Float) bool {
+	return !f.IsInf() && f.Sign() != 0 && f.MantExp(nil) > longDoubleMaxExp
+}
+
+// longDoubleUnderflows reports whether the non-zero f is too small for a long
+// double, so strtold would round it to zero.
+func longDoubleUnderflows(f *big.Float) bool {
+	return !f.IsInf() && f.Sign() != 0 && f.MantExp(nil) < longDoubleMinExp
+}
+
+// formatLongDouble renders f the way Valkey's ld2string does for INCRBYFLOAT:
+// 17 decimals with the trailing zeros trimmed.
+func formatLongDouble(f *big.Float) string {
+	s := f.Text('f', 17)
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(s, "0")
+		s = strings.TrimSuffix(s, ".")
+	}
+	if s == "-0" {
+		return "0"
+	}
+	return s
+}
+
+func isRangeError(err error) bool {
+	ne, ok := err.(*strconv.NumError)
+	return ok && ne.Err == strconv.ErrRange
+}
@@ -0,0 +1,280 @@
+package db
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestStore_LookupString(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(0, 0)
+
+	st := New()
+	st.SetString("foo", "bar", time.Time{})
+	st.entries["other"] = &entry{typ: ValueType(-1)}
+
+	if v, ok, err := st.LookupString(now, "foo"); err != nil || !ok || v != "bar" {
+		t.Fatalf("LookupString(foo) = (%q,%v,%v); want (bar,true,nil)", v, ok, err)
+	}
+	if _, ok, err := st.LookupString(now, "missing"); err != nil || ok {
+		t.Fatalf("LookupString(missing) = (_,%v,%v); want (_,false,nil)", ok, err)
+	}
+	if _, _, err := st.LookupString(now, "other"); !errors.Is(err, ErrWrongType) {
+		t.Fatalf("LookupString(other) err = %v; want ErrWrongType", err)
+	}
+}
+
+func TestStore_Append(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(0, 0)
+
+	st := New()
+	st.SetString("foo", "bar", now.Add(10*time.Second))
+
+	if n, err := st.Append(now, "foo", "baz"); err != nil || n != 6 {
+		t.Fatalf("Append = (%d,%v); want (6,nil)", n, err)
+	}
+	if got, _ := st.GetString(now, "foo"); got != "barbaz" {
+		t.Fatalf("expected barbaz, got %q", got)
+	}
+	if ttl := st.TTL(now, "foo"); ttl != 10 {
+		t.Fatalf("expected append to keep ttl 10, got %d", ttl)
+	}
+	if n, err := st.Append(now, "new", "x"); err != nil || n != 1 {
+		t.Fatalf("Append(new) = (%d,%v); want (1,nil)", n, err)
+	}
+}
+
+func TestStore_SetRange(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(0, 0)
+
+	tcs := []struct {
+		name    string
+		arrange func(*DB)
+		offset  int
+		value   string
+		wantLen int
+		want    string
+		wantOK  bool
+	}{
+		{
+			name: "overwrites inside value",
+			arrange: func(st *DB) {
+				st.SetString("k", "Hello World", time.Time{})
+			},
+			offset:  6,
+			value:   "Redis",
+			wantLen: 11,
+			want:    "Hello Redis",
+			wantOK:  true,
+		},
+		{
+			name: "extends value",
+			arrange: func(st *DB) {
+				st.SetString("k", "ab", time.Time{})
+			},
+			offset:  1,
+			value:   "xyz",
+			wantLen: 4,
+			want:    "axyz",
+			wantOK:  true,
+		},
+		{
+			name:    "pads missing key with zero bytes",
+			offset:  3,
+			value:   "a",
+			wantLen: 4,
+			want:    "\x00\x00\x00a",
+			wantOK:  true,
+		},
+		{
+			name:    "leaves missing key alone for empty value",
+			offset:  3,
+			wantLen: 0,
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			st := New()
+			if tc.arrange != nil {
+				tc.arrange(st)
+			}
+			n, err := st.SetRange(now, "k", tc.offset, tc.value)
+			if err != nil || n != tc.wantLen {
+				t.Fatalf("SetRange = (%d,%v); want (%d,nil)", n, err, tc.wantLen)
+			}
+			got, ok := st.GetString(now, "k")
+			if ok != tc.wantOK || got != tc.want {
+				t.Fatalf("GetString = (%q,%v); want (%q,%v)", got, ok, tc.want, tc.wantOK)
+			}
+		})
+	}
+}
+
+func TestStore_IncrBy(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(0, 0)
+
+	tcs := []struct {
+		name    string
+		arrange func(*DB)
+		delta   int64
+		want    int64
+		wantErr error
+	}{
+		{
+			name:  "starts from zero",
+			delta: 5,
+			want:  5,
+		},
+		{
+			name: "adds to existing value",
+			arrange: func(st *DB) {
+				st.SetString("k", "-3", time.Time{})
+			},
+			delta: 10,
+			want:  7,
+		},
+		{
+			name: "rejects non canonical integers",
+			arrange: func(st *DB) {
+				st.SetString("k", " 1", time.Time{})
+			},
+			delta:   1,
+			wantErr: ErrNotInteger,
+		},
+		{
+			name: "detects overflow",
+			arrange: func(st *DB) {
+				st.SetString("k", "-9223372036854775808", time.Time{})
+			},
+			delta:   -1,
+			wantErr: ErrOverflow,
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			st := New()
+			if tc.arrange != nil {
+				tc.arrange(st)
+			}
+			got, err := st.IncrBy(now, "k", tc.delta)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("IncrBy err = %v; want %v", err, tc.wantErr)
+			}
+			if err == nil && got != tc.want {
+				t.Fatalf("IncrBy = %d; want %d", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestStore_IncrByFloat(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(0, 0)
+
+	st := New()
+	st.SetString("k", "10.50", now.Add(time.Minute))
+
+	if got, err := st.IncrByFloat(now, "k", "0.1"); err != nil || got != "10.6" {
+		t.Fatalf("IncrByFloat = (%q,%v); want (10.6,nil)", got, err)
+	}
+	if ttl := st.TTL(now, "k"); ttl != 60 {
+		t.Fatalf("expected ttl 60 to be kept, got %d", ttl)
+	}
+	if got, err := st.IncrByFloat(now, "k", "-5"); err != nil || got != "5.6" {
+		t.Fatalf("IncrByFloat = (%q,%v); want (5.6,nil)", got, err)
+	}
+
+	st.SetString("bad", "x", time.Time{})
+	if _, err := st.IncrByFloat(now, "bad", "1"); !errors.Is(err, ErrNotFloat) {
+		t.Fatalf("IncrByFloat(bad) err = %v; want ErrNotFloat", err)
+	}
+	if _, err := st.IncrByFloat(now, "k", "1e5000"); !errors.Is(err, ErrNotFloat) {
+		t.Fatalf("IncrByFloat(1e5000) err = %v; want ErrNotFloat", err)
+	}
+	if _, err := st.IncrByFloat(now, "k", "1e-5000"); !errors.Is(err, ErrNotFloat) {
+		t.Fatalf("IncrByFloat(1e-5000) err = %v; want ErrNotFloat", err)
+	}
+	if _, err := st.IncrByFloat(now, "k", "inf"); !errors.Is(err, ErrNaNOrInf) {
+		t.Fatalf("IncrByFloat(inf) err = %v; want ErrNaNOrInf", err)
+	}
+
+	// Valkey sums in long double, so the float64 rounding error never shows.
+	if _, err := st.IncrByFloat(now, "sum", "0.1"); err != nil {
+		t.Fatalf("IncrByFloat(0.1) err = %v", err)
+	}
+	if got, err := st.IncrByFloat(now, "sum", "0.2"); err != nil || got != "0.3" {
+		t.Fatalf("IncrByFloat = (%q,%v); want (0.3,nil)", got, err)
+	}
+	if got, err := st.IncrByFloat(now, "big", "5.0e3"); err != nil || got != "5000" {
+		t.Fatalf("IncrByFloat = (%q,%v); want (5000,nil)", got, err)
+	}
+
+	// Beyond float64, within the long double range strtold accepts.
+	st.SetString("huge", "1e400", time.Time{})
+	if got, err := st.IncrByFloat(now, "huge", "1e400"); err != nil || len(got) != 401 {
+		t.Fatalf("IncrByFloat(1e400) = (%q,%v); want the 401 digits of 2e400", got, err)
+	}
+	st.SetString("max", "1e4932", time.Time{})
+	if _, err := st.IncrByFloat(now, "max", "1e4932"); !errors.Is(err, ErrNaNOrInf) {
+		t.Fatalf("IncrByFloat past the long double range err = %v; want ErrNaNOrInf", err)
+	}
+}
+
+func TestStore_MSet(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(0, 0)
+
+	st := New()
+	if !st.MSet(now, false, "a", "1", "b", "2") {
+		t.Fatalf("expected MSet to succeed")
+	}
+	if st.MSet(now, true, "c", "3", "a", "x") {
+		t.Fatalf("expected MSet NX to fail when a key exists")
+	}
+	if _, ok := st.GetString(now, "c"); ok {
+		t.Fatalf("expected c to stay missing")
+	}
+	if got, _ := st.GetString(now, "a"); got != "1" {
+		t.Fatalf("expected a=1, got %q", got)
+	}
+}
+
+func TestStore_GetEx(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(0, 0)
+
+	st := New()
+	st.SetString("k", "v", now.Add(time.Minute))
+
+	if v, ok, err := st.GetEx(now, "k", GetExOptions{Persist: true}); err != nil || !ok || v != "v" {
+		t.Fatalf("GetEx = (%q,%v,%v); want (v,true,nil)", v, ok, err)
+	}
+	if ttl := st.TTL(now, "k"); ttl != -1 {
+		t.Fatalf("expected ttl -1, got %d", ttl)
+	}
+	if _, _, err := st.GetEx(now, "k", GetExOptions{HasExpire: true, ExpireAt: now}); err != nil {
+		t.Fatalf("GetEx returned error: %v", err)
+	}
+	if _, ok := st.GetString(now, "k"); ok {
+		t.Fatalf("expected key to be deleted by past expiry")
+	}
+}
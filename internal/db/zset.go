@@ -0,0 +1,197 @@
+package db
+
+import (
+	"cmp"
+	"slices"
+	"time"
+)
+
+// Valkey's default zset-max-listpack-entries and zset-max-listpack-value.
+const (
+	zsetListpackEntries = 128
+	zsetListpackValue   = 64
+)
+
+// ZMember is a sorted set member with its score.
+type ZMember struct {
+	Member string
+	Score  float64
+}
+
+// ZAddOptions tweaks ZAdd behavior.
+type ZAddOptions struct {
+	NX bool // Only add new members
+	XX bool // Only update existing members
+	CH bool // Count updated members along with added ones
+}
+
+// zset holds the members of a sorted set and their scores.
+type zset struct {
+	scores map[string]float64
+}
+
+func newZSet() *zset {
+	return &zset{scores: make(map[string]float64)}
+}
+
+// clone returns a deep copy of z.
+func (z *zset) clone() *zset {
+	cp := newZSet()
+	for m, s := range z.scores {
+		cp.scores[m] = s
+	}
+	return cp
+}
+
+// sorted returns the members ordered by score, then lexicographically.
+func (z *zset) sorted() []ZMember {
+	out := make([]ZMember, 0, len(z.scores))
+	for m, s := range z.scores {
+		out = append(out, ZMember{Member: m, Score: s})
+	}
+	slices.SortFunc(out, func(a, b ZMember) int {
+		if c := cmp.Compare(a.Score, b.Score); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Member, b.Member)
+	})
+	return out
+}
+
+// encoding mimics how Valkey picks a sorted set encoding.
+func (z *zset) encoding() string {
+	if len(z.scores) > zsetListpackEntries {
+		return "skiplist"
+	}
+	for m := range z.scores {
+		if len(m) > zsetListpackValue {
+			return "skiplist"
+		}
+	}
+	return "listpack"
+}
+
+// ZAdd adds members to the sorted set at k or updates their scores, creating it if needed.
+// Returns the number of added members, plus the updated ones when opts.CH is set.
+func (db *DB) ZAdd(now time.Time, k string, opts ZAddOptions, members ...ZMember) (int, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	e, err := db.lookupZSet(now, k)
+	if err != nil {
+		return 0, err
+	}
+	if e == nil {
+		if opts.XX {
+			return 0, nil
+		}
+		e = &entry{typ: TZSet, z: newZSet()}
+		db.entries[k] = e
+	}
+	e.lastAccess = now
+
+	n := 0
+	for _, m := range members {
+		cur, exists := e.z.scores[m.Member]
+		switch {
+		case exists && !opts.NX:
+			if cur != m.Score {
+				e.z.scores[m.Member] = m.Score
+				if opts.CH {
+					n++
+				}
+			}
+		case !exists && !opts.XX:
+			e.z.scores[m.Member] = m.Score
+			n++
+		}
+	}
+	if len(e.z.scores) == 0 {
+		delete(db.entries, k)
+	}
+	return n, nil
+}
+
+// ZRem removes members from the sorted set at k, deleting k once it is empty.
+// Returns the number of members that were removed.
+func (db *DB) ZRem(now time.Time, k string, members ...string) (int, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	e, err := db.lookupZSet(now, k)
+	if err != nil || e == nil {
+		return 0, err
+	}
+	e.lastAccess = now
+
+	n := 0
+	for _, m := range members {
+		if _, ok := e.z.scores[m]; ok {
+			delete(e.z.scores, m)
+			n++
+		}
+	}
+	if len(e.z.scores) == 0 {
+		delete(db.entries, k)
+	}
+	return n, nil
+}
+
+// ZScore returns the score of member in the sorted set at k.
+// Returns (0, false, nil) if the key or the member does not exist.
+func (db *DB) ZScore(now time.Time, k, member string) (float64, bool, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	e, err := db.lookupZSet(now, k)
+	if err != nil || e == nil {
+		return 0, false, err
+	}
+	e.lastAccess = now
+	s, ok := e.z.scores[member]
+	return s, ok, nil
+}
+
+// ZMembers returns the members of the sorted set at k ordered by score, then member.
+// Returns (nil, false, nil) if the key does not exist.
+func (db *DB) ZMembers(now time.Time, k string) ([]ZMember, bool, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	e, err := db.lookupZSet(now, k)
+	if err != nil || e == nil {
+		return nil, false, err
+	}
+	e.lastAccess = now
+	return e.z.sorted(), true, nil
+}
+
+// ZStore replaces whatever is stored at k with a sorted set holding members.
+// An empty members list deletes k.
+func (db *DB) ZStore(now time.Time, k string, members []ZMember) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if len(members) == 0 {
+		delete(db.entries, k)
+		return
+	}
+	z := newZSet()
+	for _, m := range members {
+		z.scores[m.Member] = m.Score
+	}
+	db.entries[k] = &entry{typ: TZSet, z: z, lastAccess: now}
+}
+
+// lookupZSet returns the live sorted set entry for k, or ErrWrongType if k holds another type.
+// Callers must hold the write lock.
+func (db *DB) lookupZSet(now time.Time, k string) (*entry, error) {
+	e := db.lookup(now, k)
+	if e == nil {
+		return nil, nil
+	}
+	if e.typ != TZSet {
+		return nil, ErrWrongType
+	}
+	return e, nil
+}
@@ -0,0 +1,142 @@
+package db
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStore_ZAdd(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(0, 0)
+
+	tcs := []struct {
+		name    string
+		opts    ZAddOptions
+		members []ZMember
+		want    int
+		final   []ZMember
+	}{
+		{
+			name:    "adds new members and updates existing ones",
+			members: []ZMember{{Member: "a", Score: 5}, {Member: "c", Score: 3}},
+			want:    1,
+			final:   []ZMember{{Member: "b", Score: 2}, {Member: "c", Score: 3}, {Member: "a", Score: 5}},
+		},
+		{
+			name:    "counts changes with CH",
+			opts:    ZAddOptions{CH: true},
+			members: []ZMember{{Member: "a", Score: 5}, {Member: "b", Score: 2}, {Member: "c", Score: 3}},
+			want:    2,
+			final:   []ZMember{{Member: "b", Score: 2}, {Member: "c", Score: 3}, {Member: "a", Score: 5}},
+		},
+		{
+			name:    "only adds with NX",
+			opts:    ZAddOptions{NX: true},
+			members: []ZMember{{Member: "a", Score: 5}, {Member: "c", Score: 3}},
+			want:    1,
+			final:   []ZMember{{Member: "a", Score: 1}, {Member: "b", Score: 2}, {Member: "c", Score: 3}},
+		},
+		{
+			name:    "only updates with XX",
+			opts:    ZAddOptions{XX: true, CH: true},
+			members: []ZMember{{Member: "a", Score: 5}, {Member: "c", Score: 3}},
+			want:    1,
+			final:   []ZMember{{Member: "b", Score: 2}, {Member: "a", Score: 5}},
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			st := New()
+			st.ZAdd(now, "z", ZAddOptions{}, ZMember{Member: "a", Score: 1}, ZMember{Member: "b", Score: 2})
+			got, err := st.ZAdd(now, "z", tc.opts, tc.members...)
+			if err != nil {
+				t.Fatalf("ZAdd returned error: %v", err)
+			}
+			if got != tc.want {
+				t.Fatalf("expected %d, got %d", tc.want, got)
+			}
+			if ms, _, _ := st.ZMembers(now, "z"); !slices.Equal(ms, tc.final) {
+				t.Fatalf("expected %v, got %v", tc.final, ms)
+			}
+		})
+	}
+
+	t.Run("does not create key with XX", func(t *testing.T) {
+		t.Parallel()
+
+		st := New()
+		if n, _ := st.ZAdd(now, "z", ZAddOptions{XX: true}, ZMember{Member: "a", Score: 1}); n != 0 {
+			t.Fatalf("expected 0, got %d", n)
+		}
+		if _, ok := st.Type(now, "z"); ok {
+			t.Fatalf("expected z to be missing")
+		}
+	})
+
+	t.Run("rejects wrong type", func(t *testing.T) {
+		t.Parallel()
+
+		st := New()
+		st.SetString("z", "value", time.Time{})
+		if _, err := st.ZAdd(now, "z", ZAddOptions{}, ZMember{Member: "a", Score: 1}); err != ErrWrongType {
+			t.Fatalf("expected ErrWrongType, got %v", err)
+		}
+	})
+}
+
+func TestStore_ZStore(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(0, 0)
+
+	st := New()
+	st.SetString("z", "value", time.Time{})
+	st.ZStore(now, "z", []ZMember{{Member: "a", Score: 1}})
+	if s, ok, err := st.ZScore(now, "z", "a"); err != nil || !ok || s != 1 {
+		t.Fatalf("expected score 1, got %v ok=%v err=%v", s, ok, err)
+	}
+	if info, _ := st.Object(now, "z"); info.Encoding != "listpack" {
+		t.Fatalf("expected listpack encoding, got %q", info.Encoding)
+	}
+
+	st.ZStore(now, "z", []ZMember{{Member: strings.Repeat("x", zsetListpackValue+1), Score: 1}})
+	if info, _ := st.Object(now, "z"); info.Encoding != "skiplist" {
+		t.Fatalf("expected skiplist encoding, got %q", info.Encoding)
+	}
+
+	st.ZStore(now, "z", nil)
+	if _, ok := st.Type(now, "z"); ok {
+		t.Fatalf("expected z to be deleted")
+	}
+}
+
+func TestStore_ZRem(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(0, 0)
+
+	st := New()
+	st.ZAdd(now, "z", ZAddOptions{}, ZMember{Member: "a", Score: 1}, ZMember{Member: "b", Score: 2})
+	if n, err := st.ZRem(now, "z", "a", "x"); err != nil || n != 1 {
+		t.Fatalf("expected 1, got %d err=%v", n, err)
+	}
+	if _, ok, _ := st.ZScore(now, "z", "a"); ok {
+		t.Fatalf("expected a to be removed")
+	}
+	st.ZRem(now, "z", "b")
+	if _, ok := st.Type(now, "z"); ok {
+		t.Fatalf("expected z to be deleted once empty")
+	}
+
+	st.SetString("s", "value", time.Time{})
+	if _, err := st.ZRem(now, "s", "a"); err != ErrWrongType {
+		t.Fatalf("expected ErrWrongType, got %v", err)
+	}
+}
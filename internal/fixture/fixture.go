@@ -0,0 +1,365 @@
+// Package fixture reads and writes datasets as JSON fixtures meant to be
+// written by hand and reviewed in diffs.
+//
+// A fixture maps database indexes to keys, and each key to exactly one value:
+//
+//	{
+//	  "0": {
+//	    "greeting": {"string": "hello", "ttl": "1m30s"},
+//	    "queue": {"list": ["a", "b"]},
+//	    "tags": {"set": ["x", "y"]},
+//	    "scores": {"zset": {"alice": 1.5, "bob": "-inf"}},
+//	    "user:1": {"hash": {"name": "Ann", "token": "t"}, "field_ttl": {"token": "10s"}}
+//	  }
+//	}
+//
+// TTLs are Go durations relative to the time the fixture is loaded.
+// Sorted set scores are numbers, or the strings "inf" and "-inf".
+// Values, members and fields that are not valid UTF-8, such as HyperLogLogs
+// and bitmaps, cannot be JSON strings. A key holding any of them is written
+// with "base64": true, and every value, member and field of that key is then
+// base64 encoded. Key names must be valid UTF-8.
+package fixture
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mickamy/minivalkey/internal/rdb"
+)
+
+// key is the JSON form of one key. Exactly one of the value fields is set.
+type key struct {
+	String   *string             `json:"string,omitempty"`
+	List     []string            `json:"list,omitempty"`
+	Set      []string            `json:"set,omitempty"`
+	ZSet     map[string]score    `json:"zset,omitempty"`
+	Hash     map[string]string   `json:"hash,omitempty"`
+	FieldTTL map[string]duration `json:"field_ttl,omitempty"`
+	TTL      *duration           `json:"ttl,omitempty"`
+	Base64   bool                `json:"base64,omitempty"` // values, members and fields are base64 encoded
+}
+
+// duration is a positive time.Duration written as a Go duration string.
+type duration time.Duration
+
+func (d duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("ttl must be a duration string such as \"1m30s\": %w", err)
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	if v <= 0 {
+		return fmt.Errorf("ttl %q must be positive", s)
+	}
+	*d = duration(v)
+	return nil
+}
+
+// score is a sorted set score; infinities are written as strings since JSON has no numbers for them.
+type score float64
+
+func (s score) MarshalJSON() ([]byte, error) {
+	switch f := float64(s); {
+	case math.IsInf(f, 1):
+		return []byte(`"inf"`), nil
+	case math.IsInf(f, -1):
+		return []byte(`"-inf"`), nil
+	default:
+		return strconv.AppendFloat(nil, f, 'g', -1, 64), nil
+	}
+}
+
+func (s *score) UnmarshalJSON(b []byte) error {
+	var f float64
+	if err := json.Unmarshal(b, &f); err != nil {
+		var str string
+		if json.Unmarshal(b, &str) != nil {
+			return fmt.Errorf("score must be a number, \"inf\" or \"-inf\", got %s", b)
+		}
+		switch strings.ToLower(str) {
+		case "inf", "+inf":
+			f = math.Inf(1)
+		case "-inf":
+			f = math.Inf(-1)
+		default:
+			return fmt.Errorf("score must be a number, \"inf\" or \"-inf\", got %s", b)
+		}
+	}
+	*s = score(f)
+	return nil
+}
+
+// Read decodes a fixture into databases ordered by index, with keys ordered by name.
+// TTLs are counted from now.
+func Read(r io.Reader, now time.Time) ([]rdb.Database, error) {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	var raw map[string]map[string]key
+	if err := dec.Decode(&raw); err != nil {
+		return nil, fmt.Errorf("fixture: %w", err)
+	}
+
+	dbs := make([]rdb.Database, 0, len(raw))
+	for idx, keys := range raw {
+		n, err := strconv.Atoi(idx)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("fixture: database index %q is not a non-negative integer", idx)
+		}
+		d := rdb.Database{Index: n, Entries: make([]rdb.Entry, 0, len(keys))}
+		for k, v := range keys {
+			e, err := v.entry(k, now)
+			if err != nil {
+				return nil, fmt.Errorf("fixture: database %d, key %q: %w", n, k, err)
+			}
+			d.Entries = append(d.Entries, e)
+		}
+		slices.SortFunc(d.Entries, func(a, b rdb.Entry) int { return cmp.Compare(a.Key, b.Key) })
+		dbs = append(dbs, d)
+	}
+	slices.SortFunc(dbs, func(a, b rdb.Database) int { return a.Index - b.Index })
+	return dbs, nil
+}
+
+// entry converts the JSON form of k to an entry.
+func (v key) entry(k string, now time.Time) (rdb.Entry, error) {
+	e := rdb.Entry{Key: k}
+	if v.Base64 {
+		var err error
+		if v, err = v.convert(decodeBase64); err != nil {
+			return e, err
+		}
+	}
+	if v.TTL != nil {
+		e.ExpireAt = now.Add(time.Duration(*v.TTL))
+	}
+
+	kinds := 0
+	for _, set := range []bool{v.String != nil, v.List != nil, v.Set != nil, v.ZSet != nil, v.Hash != nil} {
+		if set {
+			kinds++
+		}
+	}
+	if kinds != 1 {
+		return e, errors.New("exactly one of string, list, set, zset and hash must be given")
+	}
+	if v.FieldTTL != nil && v.Hash == nil {
+		return e, errors.New("field_ttl only applies to hashes")
+	}
+
+	switch {
+	case v.String != nil:
+		e.Value = rdb.Value{Kind: rdb.KindString, String: *v.String}
+	case v.List != nil:
+		if len(v.List) == 0 {
+			return e, errors.New("list is empty")
+		}
+		e.Value = rdb.Value{Kind: rdb.KindList, List: v.List}
+	case v.Set != nil:
+		if len(v.Set) == 0 {
+			return e, errors.New("set is empty")
+		}
+		members := slices.Clone(v.Set)
+		slices.Sort(members)
+		e.Value = rdb.Value{Kind: rdb.KindSet, Set: slices.Compact(members)}
+	case v.ZSet != nil:
+		if len(v.ZSet) == 0 {
+			return e, errors.New("zset is empty")
+		}
+		e.Value = rdb.Value{Kind: rdb.KindZSet}
+		for m, s := range v.ZSet {
+			e.Value.ZSet = append(e.Value.ZSet, rdb.ZMember{Member: m, Score: float64(s)})
+		}
+		slices.SortFunc(e.Value.ZSet, func(a, b rdb.ZMember) int {
+			return cmp.Or(cmp.Compare(a.Score, b.Score), cmp.Compare(a.Member, b.Member))
+		})
+	case v.Hash != nil:
+		if len(v.Hash) == 0 {
+			return e, errors.New("hash is empty")
+		}
+		for f := range v.FieldTTL {
+			if _, ok := v.Hash[f]; !ok {
+				return e, fmt.Errorf("field_ttl names missing field %q", f)
+			}
+		}
+		e.Value = rdb.Value{Kind: rdb.KindHash}
+		for f, val := range v.Hash {
+			hf := rdb.HashField{Field: f, Value: val}
+			if ttl, ok := v.FieldTTL[f]; ok {
+				hf.ExpireAt = now.Add(time.Duration(ttl))
+			}
+			e.Value.Hash = append(e.Value.Hash, hf)
+		}
+		slices.SortFunc(e.Value.Hash, func(a, b rdb.HashField) int { return cmp.Compare(a.Field, b.Field) })
+	}
+	return e, nil
+}
+
+// Write encodes dbs as an indented fixture. Databases and keys come out
+// sorted, so the same dataset always produces the same bytes.
+// TTLs are counted from now, and databases without keys are left out.
+func Write(w io.Writer, dbs []rdb.Database, now time.Time) error {
+	raw := make(map[string]map[string]key, len(dbs))
+	for _, d := range dbs {
+		if len(d.Entries) == 0 {
+			continue
+		}
+		keys := make(map[string]key, len(d.Entries))
+		for _, e := range d.Entries {
+			if !utf8.ValidString(e.Key) {
+				return fmt.Errorf("fixture: database %d: key %q is not valid UTF-8", d.Index, e.Key)
+			}
+			keys[e.Key] = fixtureKey(e, now)
+		}
+		raw[strconv.Itoa(d.Index)] = keys
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(raw); err != nil {
+		return fmt.Errorf("fixture: %w", err)
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// fixtureKey converts an entry to its JSON form, base64 encoding it when
+// any of its strings is not valid UTF-8.
+func fixtureKey(e rdb.Entry, now time.Time) key {
+	v := plainKey(e, now)
+	if v.valid() {
+		return v
+	}
+	v, _ = v.convert(func(s string) (string, error) {
+		return base64.StdEncoding.EncodeToString([]byte(s)), nil
+	})
+	v.Base64 = true
+	return v
+}
+
+// plainKey converts an entry to its JSON form without encoding it.
+func plainKey(e rdb.Entry, now time.Time) key {
+	var v key
+	if !e.ExpireAt.IsZero() {
+		v.TTL = ttlFrom(now, e.ExpireAt)
+	}
+	switch e.Value.Kind {
+	case rdb.KindList:
+		v.List = e.Value.List
+	case rdb.KindSet:
+		v.Set = slices.Sorted(slices.Values(e.Value.Set))
+	case rdb.KindZSet:
+		v.ZSet = make(map[string]score, len(e.Value.ZSet))
+		for _, m := range e.Value.ZSet {
+			v.ZSet[m.Member] = score(m.Score)
+		}
+	case rdb.KindHash:
+		v.Hash = make(map[string]string, len(e.Value.Hash))
+		for _, f := range e.Value.Hash {
+			v.Hash[f.Field] = f.Value
+			if !f.ExpireAt.IsZero() {
+				if v.FieldTTL == nil {
+					v.FieldTTL = make(map[string]duration)
+				}
+				v.FieldTTL[f.Field] = *ttlFrom(now, f.ExpireAt)
+			}
+		}
+	default:
+		s := e.Value.String
+		v.String = &s
+	}
+	return v
+}
+
+// valid reports whether every value, member and field of v is valid UTF-8.
+func (v key) valid() bool {
+	ok := true
+	_, _ = v.convert(func(s string) (string, error) {
+		ok = ok && utf8.ValidString(s)
+		return s, nil
+	})
+	return ok
+}
+
+// convert returns a copy of v with f applied to every value, member and field.
+func (v key) convert(f func(string) (string, error)) (key, error) {
+	var firstErr error
+	conv := func(s string) string {
+		out, err := f(s)
+		if firstErr == nil {
+			firstErr = err
+		}
+		return out
+	}
+	out := key{TTL: v.TTL, Base64: v.Base64}
+	if v.String != nil {
+		s := conv(*v.String)
+		out.String = &s
+	}
+	if v.List != nil {
+		out.List = make([]string, len(v.List))
+		for i, it := range v.List {
+			out.List[i] = conv(it)
+		}
+	}
+	if v.Set != nil {
+		out.Set = make([]string, len(v.Set))
+		for i, m := range v.Set {
+			out.Set[i] = conv(m)
+		}
+	}
+	if v.ZSet != nil {
+		out.ZSet = make(map[string]score, len(v.ZSet))
+		for m, sc := range v.ZSet {
+			out.ZSet[conv(m)] = sc
+		}
+	}
+	if v.Hash != nil {
+		out.Hash = make(map[string]string, len(v.Hash))
+		for fl, val := range v.Hash {
+			out.Hash[conv(fl)] = conv(val)
+		}
+	}
+	if v.FieldTTL != nil {
+		out.FieldTTL = make(map[string]duration, len(v.FieldTTL))
+		for fl, ttl := range v.FieldTTL {
+			out.FieldTTL[conv(fl)] = ttl
+		}
+	}
+	return out, firstErr
+}
+
+func decodeBase64(s string) (string, error) {
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return "", fmt.Errorf("%q is not valid base64", s)
+	}
+	return string(b), nil
+}
+
+// ttlFrom returns the time left until at, rounded to milliseconds and at least one.
+func ttlFrom(now, at time.Time) *duration {
+	d := max(at.Sub(now).Round(time.Millisecond), time.Millisecond)
+	ttl := duration(d)
+	return &ttl
+}
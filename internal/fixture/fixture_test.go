@@ -0,0 +1,208 @@
+package fixture
+
+import (
+	"bytes"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mickamy/minivalkey/internal/rdb"
+)
+
+func TestRead(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+
+	in := `{
+  "3": {"s": {"string": "", "ttl": "1m30s"}},
+  "0": {
+    "z": {"zset": {"b": 2, "a": 2, "lo": "-inf"}},
+    "h": {"hash": {"f": "1", "g": "2"}, "field_ttl": {"g": "10s"}},
+    "l": {"list": ["b", "a", "b"]},
+    "m": {"set": ["y", "x", "y"]}
+  }
+}`
+	got, err := Read(strings.NewReader(in), now)
+	if err != nil {
+		t.Fatalf("Read returned error: %v", err)
+	}
+
+	want := []rdb.Database{
+		{Index: 0, Entries: []rdb.Entry{
+			{Key: "h", Value: rdb.Value{Kind: rdb.KindHash, Hash: []rdb.HashField{
+				{Field: "f", Value: "1"},
+				{Field: "g", Value: "2", ExpireAt: now.Add(10 * time.Second)},
+			}}},
+			{Key: "l", Value: rdb.Value{Kind: rdb.KindList, List: []string{"b", "a", "b"}}},
+			{Key: "m", Value: rdb.Value{Kind: rdb.KindSet, Set: []string{"x", "y"}}},
+			{Key: "z", Value: rdb.Value{Kind: rdb.KindZSet, ZSet: []rdb.ZMember{
+				{Member: "lo", Score: math.Inf(-1)},
+				{Member: "a", Score: 2},
+				{Member: "b", Score: 2},
+			}}},
+		}},
+		{Index: 3, Entries: []rdb.Entry{
+			{Key: "s", Value: rdb.Value{Kind: rdb.KindString}, ExpireAt: now.Add(90 * time.Second)},
+		}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected databases:\nwant %+v\ngot  %+v", want, got)
+	}
+}
+
+func TestRead_Errors(t *testing.T) {
+	t.Parallel()
+
+	tcs := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "not json", in: `[`, want: "fixture:"},
+		{name: "bad database index", in: `{"x": {}}`, want: `database index "x"`},
+		{name: "negative database index", in: `{"-1": {}}`, want: `database index "-1"`},
+		{name: "no value", in: `{"0": {"k": {"ttl": "1s"}}}`, want: "exactly one of"},
+		{name: "two values", in: `{"0": {"k": {"string": "a", "list": ["a"]}}}`, want: "exactly one of"},
+		{name: "empty list", in: `{"0": {"k": {"list": []}}}`, want: "list is empty"},
+		{name: "empty hash", in: `{"0": {"k": {"hash": {}}}}`, want: "hash is empty"},
+		{name: "unknown field", in: `{"0": {"k": {"strnig": "a"}}}`, want: "unknown field"},
+		{name: "bad ttl", in: `{"0": {"k": {"string": "a", "ttl": 10}}}`, want: "duration string"},
+		{name: "non-positive ttl", in: `{"0": {"k": {"string": "a", "ttl": "0s"}}}`, want: "must be positive"},
+		{name: "bad score", in: `{"0": {"k": {"zset": {"a": "high"}}}}`, want: "score must be"},
+		{name: "field ttl without hash", in: `{"0": {"k": {"string": "a", "field_ttl": {"f": "1s"}}}}`, want: "only applies to hashes"},
+		{name: "field ttl for missing field", in: `{"0": {"k": {"hash": {"f": "v"}, "field_ttl": {"g": "1s"}}}}`, want: `missing field "g"`},
+		{name: "bad base64", in: `{"0": {"k": {"string": "not base64!", "base64": true}}}`, want: "not valid base64"},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := Read(strings.NewReader(tc.in), time.Unix(1_000, 0))
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("expected error containing %q, got %v", tc.want, err)
+			}
+		})
+	}
+}
+
+func TestWrite_Base64(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+
+	dbs := []rdb.Database{{Index: 0, Entries: []rdb.Entry{
+		{Key: "h", Value: rdb.Value{Kind: rdb.KindHash, Hash: []rdb.HashField{
+			{Field: "a", Value: "\xff"}, {Field: "b\x00", Value: "v", ExpireAt: now.Add(time.Second)},
+		}}},
+		{Key: "s", Value: rdb.Value{Kind: rdb.KindString, String: "\x80\x01"}},
+	}}}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, dbs, now); err != nil {
+		t.Fatalf("Write returned error: %v", err)
+	}
+	want := `{
+  "0": {
+    "h": {
+      "hash": {
+        "YQ==": "/w==",
+        "YgA=": "dg=="
+      },
+      "field_ttl": {
+        "YgA=": "1s"
+      },
+      "base64": true
+    },
+    "s": {
+      "string": "gAE=",
+      "base64": true
+    }
+  }
+}
+`
+	if got := buf.String(); got != want {
+		t.Fatalf("unexpected fixture:\nwant %s\ngot  %s", want, got)
+	}
+
+	got, err := Read(&buf, now)
+	if err != nil {
+		t.Fatalf("Read returned error: %v", err)
+	}
+	if !reflect.DeepEqual(got, dbs) {
+		t.Fatalf("expected %+v, got %+v", dbs, got)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+
+	dbs := []rdb.Database{
+		{Index: 0, Entries: []rdb.Entry{
+			{Key: "s", Value: rdb.Value{Kind: rdb.KindString, String: "<v>"}, ExpireAt: now.Add(1500 * time.Millisecond)},
+			{Key: "m", Value: rdb.Value{Kind: rdb.KindSet, Set: []string{"y", "x"}}},
+			{Key: "z", Value: rdb.Value{Kind: rdb.KindZSet, ZSet: []rdb.ZMember{{Member: "b", Score: 0.5}, {Member: "a", Score: math.Inf(1)}}}},
+			{Key: "h", Value: rdb.Value{Kind: rdb.KindHash, Hash: []rdb.HashField{{Field: "f", Value: "1", ExpireAt: now.Add(time.Minute)}}}},
+			{Key: "l", Value: rdb.Value{Kind: rdb.KindList, List: []string{"b", "a"}}},
+		}},
+		{Index: 1},
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, dbs, now); err != nil {
+		t.Fatalf("Write returned error: %v", err)
+	}
+
+	want := `{
+  "0": {
+    "h": {
+      "hash": {
+        "f": "1"
+      },
+      "field_ttl": {
+        "f": "1m0s"
+      }
+    },
+    "l": {
+      "list": [
+        "b",
+        "a"
+      ]
+    },
+    "m": {
+      "set": [
+        "x",
+        "y"
+      ]
+    },
+    "s": {
+      "string": "<v>",
+      "ttl": "1.5s"
+    },
+    "z": {
+      "zset": {
+        "a": "inf",
+        "b": 0.5
+      }
+    }
+  }
+}
+`
+	if got := buf.String(); got != want {
+		t.Fatalf("unexpected fixture:\nwant %s\ngot  %s", want, got)
+	}
+
+	back, err := Read(&buf, now)
+	if err != nil {
+		t.Fatalf("Read returned error: %v", err)
+	}
+	if len(back) != 1 || len(back[0].Entries) != 5 {
+		t.Fatalf("expected the fixture to read back, got %+v", back)
+	}
+}
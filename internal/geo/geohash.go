@@ -0,0 +1,243 @@
+// Package geo ports the geohash encoding and distance math Valkey uses for
+// its GEO commands, so positions, hashes and distances match a real server.
+package geo
+
+import (
+	"math"
+)
+
+const (
+	// StepMax is the number of bits per coordinate in a stored geohash (52 bits in total).
+	StepMax = 26
+
+	LatMin  = -85.05112878
+	LatMax  = 85.05112878
+	LongMin = -180.0
+	LongMax = 180.0
+
+	earthRadius = 6372797.560856 // meters
+	mercatorMax = 20037726.37
+	degToRad    = math.Pi / 180.0
+	alphabet    = "0123456789bcdefghjkmnpqrstuvwxyz"
+)
+
+// Range is a closed coordinate interval.
+type Range struct {
+	Min, Max float64
+}
+
+// Hash is an interleaved geohash of Step bits per coordinate. Latitude bits
+// occupy the even positions and longitude bits the odd ones.
+type Hash struct {
+	Bits uint64
+	Step uint8
+}
+
+// isZero mirrors Valkey's HASHISZERO: a hash that was cleared from the search.
+func (h Hash) isZero() bool {
+	return h.Bits == 0 && h.Step == 0
+}
+
+// align52 shifts the hash so it compares with 52-bit scores.
+func (h Hash) align52() uint64 {
+	return h.Bits << (52 - 2*uint(h.Step))
+}
+
+// Area is the cell a hash covers.
+type Area struct {
+	Hash Hash
+	Long Range
+	Lat  Range
+}
+
+var (
+	wgs84Long = Range{Min: LongMin, Max: LongMax}
+	wgs84Lat  = Range{Min: LatMin, Max: LatMax}
+)
+
+// ValidCoords reports whether the pair lies in the area Valkey can index.
+func ValidCoords(long, lat float64) bool {
+	return long >= LongMin && long <= LongMax && lat >= LatMin && lat <= LatMax
+}
+
+// Encode computes the geohash of a position within the given ranges.
+func Encode(longRange, latRange Range, long, lat float64, step uint8) (Hash, bool) {
+	if step == 0 || step > 32 || !ValidCoords(long, lat) {
+		return Hash{}, false
+	}
+	if lat < latRange.Min || lat > latRange.Max || long < longRange.Min || long > longRange.Max {
+		return Hash{}, false
+	}
+	latOffset := (lat - latRange.Min) / (latRange.Max - latRange.Min)
+	longOffset := (long - longRange.Min) / (longRange.Max - longRange.Min)
+	latOffset *= float64(uint64(1) << step)
+	longOffset *= float64(uint64(1) << step)
+	return Hash{Bits: interleave(uint32(latOffset), uint32(longOffset)), Step: step}, true
+}
+
+// Score returns the 52-bit geohash of a position as stored in a sorted set.
+func Score(long, lat float64) (float64, bool) {
+	h, ok := Encode(wgs84Long, wgs84Lat, long, lat, StepMax)
+	if !ok {
+		return 0, false
+	}
+	return float64(h.align52()), true
+}
+
+// Decode returns the cell covered by h within the given ranges.
+func Decode(longRange, latRange Range, h Hash) (Area, bool) {
+	if h.isZero() {
+		return Area{}, false
+	}
+	lat, long := deinterleave(h.Bits)
+	cells := float64(uint64(1) << h.Step)
+	latScale := latRange.Max - latRange.Min
+	longScale := longRange.Max - longRange.Min
+	// Explicit conversions keep the compiler from fusing multiply-adds, so
+	// the results match Valkey bit for bit.
+	return Area{
+		Hash: h,
+		Lat: Range{
+			Min: latRange.Min + float64(float64(lat)/cells*latScale),
+			Max: latRange.Min + float64(float64(lat+1)/cells*latScale),
+		},
+		Long: Range{
+			Min: longRange.Min + float64(float64(long)/cells*longScale),
+			Max: longRange.Min + float64(float64(long+1)/cells*longScale),
+		},
+	}, true
+}
+
+// DecodeScore returns the center of the cell encoded by a 52-bit score.
+func DecodeScore(score float64) (float64, float64, bool) {
+	area, ok := Decode(wgs84Long, wgs84Lat, Hash{Bits: uint64(score), Step: StepMax})
+	if !ok {
+		return 0, 0, false
+	}
+	long := min(max((area.Long.Min+area.Long.Max)/2, LongMin), LongMax)
+	lat := min(max((area.Lat.Min+area.Lat.Max)/2, LatMin), LatMax)
+	return long, lat, true
+}
+
+// HashString returns the standard 11 character geohash of a score, as GEOHASH
+// reports it. Valkey indexes latitudes within ±85.05 degrees, so the position
+// is decoded and encoded again using the standard ±90 degree range.
+func HashString(score float64) (string, bool) {
+	long, lat, ok := DecodeScore(score)
+	if !ok {
+		return "", false
+	}
+	h, ok := Encode(Range{Min: -180, Max: 180}, Range{Min: -90, Max: 90}, long, lat, StepMax)
+	if !ok {
+		return "", false
+	}
+	buf := make([]byte, 11)
+	for i := range buf {
+		idx := 0
+		// Only 52 bits are available; the 11th character is always zero.
+		if i < 10 {
+			idx = int(h.Bits>>(52-(i+1)*5)) & 0x1f
+		}
+		buf[i] = alphabet[idx]
+	}
+	return string(buf), true
+}
+
+// Distance returns the great-circle distance in meters between two points
+// using the haversine formula with Valkey's earth radius.
+func Distance(long1, lat1, long2, lat2 float64) float64 {
+	long1r := long1 * degToRad
+	long2r := long2 * degToRad
+	v := math.Sin((long2r - long1r) / 2)
+	// Skip the expensive math when the longitudes are practically the same.
+	if v == 0 {
+		return latDistance(lat1, lat2)
+	}
+	lat1r := lat1 * degToRad
+	lat2r := lat2 * degToRad
+	u := math.Sin((lat2r - lat1r) / 2)
+	a := float64(u*u) + float64(math.Cos(lat1r)*math.Cos(lat2r)*v*v)
+	return 2.0 * earthRadius * math.Asin(math.Sqrt(a))
+}
+
+func latDistance(lat1, lat2 float64) float64 {
+	return earthRadius * math.Abs(lat2*degToRad-lat1*degToRad)
+}
+
+// neighbors holds the eight cells surrounding a hash.
+type neighbors struct {
+	north, south, east, west                   Hash
+	northEast, northWest, southEast, southWest Hash
+}
+
+// neighborsOf returns the cells surrounding h.
+func neighborsOf(h Hash) neighbors {
+	return neighbors{
+		east:      moveY(moveX(h, 1), 0),
+		west:      moveY(moveX(h, -1), 0),
+		south:     moveY(moveX(h, 0), -1),
+		north:     moveY(moveX(h, 0), 1),
+		northWest: moveY(moveX(h, -1), 1),
+		southWest: moveY(moveX(h, -1), -1),
+		northEast: moveY(moveX(h, 1), 1),
+		southEast: moveY(moveX(h, 1), -1),
+	}
+}
+
+// moveX moves h one cell east (d > 0) or west (d < 0) by stepping the longitude bits.
+func moveX(h Hash, d int) Hash {
+	if d == 0 {
+		return h
+	}
+	x := h.Bits & 0xaaaaaaaaaaaaaaaa
+	y := h.Bits & 0x5555555555555555
+	zz := uint64(0x5555555555555555) >> (64 - 2*uint(h.Step))
+	if d > 0 {
+		x += zz + 1
+	} else {
+		x |= zz
+		x -= zz + 1
+	}
+	x &= uint64(0xaaaaaaaaaaaaaaaa) >> (64 - 2*uint(h.Step))
+	h.Bits = x | y
+	return h
+}
+
+// moveY moves h one cell north (d > 0) or south (d < 0) by stepping the latitude bits.
+func moveY(h Hash, d int) Hash {
+	if d == 0 {
+		return h
+	}
+	x := h.Bits & 0xaaaaaaaaaaaaaaaa
+	y := h.Bits & 0x5555555555555555
+	zz := uint64(0xaaaaaaaaaaaaaaaa) >> (64 - 2*uint(h.Step))
+	if d > 0 {
+		y += zz + 1
+	} else {
+		y |= zz
+		y -= zz + 1
+	}
+	y &= uint64(0x5555555555555555) >> (64 - 2*uint(h.Step))
+	h.Bits = x | y
+	return h
+}
+
+// interleave spreads x over the even bits and y over the odd bits.
+func interleave(x, y uint32) uint64 {
+	var out uint64
+	for i := 0; i < 32; i++ {
+		out |= uint64(x>>i&1) << (2 * i)
+		out |= uint64(y>>i&1) << (2*i + 1)
+	}
+	return out
+}
+
+// deinterleave splits bits into its even (x) and odd (y) positions.
+func deinterleave(bits uint64) (uint32, uint32) {
+	var x, y uint32
+	for i := 0; i < 32; i++ {
+		x |= uint32(bits>>(2*i)&1) << i
+		y |= uint32(bits>>(2*i+1)&1) << i
+	}
+	return x, y
+}
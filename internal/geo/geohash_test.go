@@ -0,0 +1,69 @@
+package geo
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestScore(t *testing.T) {
+	t.Parallel()
+
+	tcs := []struct {
+		name      string
+		long, lat float64
+		want      float64
+		ok        bool
+	}{
+		{name: "palermo", long: 13.361389, lat: 38.115556, want: 3479099956230698, ok: true},
+		{name: "catania", long: 15.087269, lat: 37.502669, want: 3479447370796909, ok: true},
+		{name: "latitude out of range", long: 0, lat: 86},
+		{name: "longitude out of range", long: 181, lat: 0},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, ok := Score(tc.long, tc.lat)
+			if ok != tc.ok || got != tc.want {
+				t.Fatalf("expected (%v, %v), got (%v, %v)", tc.want, tc.ok, got, ok)
+			}
+		})
+	}
+}
+
+func TestDecodeScore(t *testing.T) {
+	t.Parallel()
+
+	long, lat, ok := DecodeScore(3479099956230698)
+	if !ok {
+		t.Fatalf("expected score to decode")
+	}
+	if got := fmt.Sprintf("%.17f %.17f", long, lat); got != "13.36138933897018433 38.11555639549629859" {
+		t.Fatalf("unexpected position %s", got)
+	}
+}
+
+func TestHashString(t *testing.T) {
+	t.Parallel()
+
+	for score, want := range map[float64]string{
+		3479099956230698: "sqc8b49rny0",
+		3479447370796909: "sqdtr74hyu0",
+	} {
+		if got, _ := HashString(score); got != want {
+			t.Fatalf("expected %s, got %s", want, got)
+		}
+	}
+}
+
+func TestDistance(t *testing.T) {
+	t.Parallel()
+
+	palermoLong, palermoLat, _ := DecodeScore(3479099956230698)
+	cataniaLong, cataniaLat, _ := DecodeScore(3479447370796909)
+	if got := fmt.Sprintf("%.4f", Distance(palermoLong, palermoLat, cataniaLong, cataniaLat)); got != "166274.1516" {
+		t.Fatalf("expected 166274.1516, got %s", got)
+	}
+}
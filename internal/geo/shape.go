@@ -0,0 +1,148 @@
+package geo
+
+import (
+	"math"
+)
+
+// Shape is a GEOSEARCH area: a circle of Radius or a Width x Height box
+// centered on Long/Lat. Dimensions are expressed in a unit that converts to
+// meters through Conversion.
+type Shape struct {
+	Long, Lat     float64
+	Box           bool
+	Radius        float64
+	Width, Height float64
+	Conversion    float64
+}
+
+// ScoreRange is a [Min, Max) interval of 52-bit scores.
+type ScoreRange struct {
+	Min, Max float64
+}
+
+// Contains reports whether the point lies in the shape and returns its
+// distance in meters from the center.
+func (s Shape) Contains(long, lat float64) (float64, bool) {
+	if !s.Box {
+		d := Distance(s.Long, s.Lat, long, lat)
+		return d, d <= s.Radius*s.Conversion
+	}
+	// The latitude distance is cheaper to compute, so it is checked first.
+	if latDistance(lat, s.Lat) > s.Height*s.Conversion/2 {
+		return 0, false
+	}
+	if Distance(long, lat, s.Long, lat) > s.Width*s.Conversion/2 {
+		return 0, false
+	}
+	return Distance(s.Long, s.Lat, long, lat), true
+}
+
+// Ranges returns the score ranges Valkey scans to find the members of the
+// shape: the cell holding the center and its useful neighbors, in scan order
+// (center, N, S, E, W, NE, NW, SE, SW), skipping repeated cells.
+func (s Shape) Ranges() []ScoreRange {
+	minLong, minLat, maxLong, maxLat := s.boundingBox()
+
+	radius := s.Radius
+	if s.Box {
+		radius = math.Sqrt(float64(s.Width/2*(s.Width/2)) + float64(s.Height/2*(s.Height/2)))
+	}
+	radius *= s.Conversion
+
+	step := estimateSteps(radius, s.Lat)
+	h, _ := Encode(wgs84Long, wgs84Lat, s.Long, s.Lat, step)
+	n := neighborsOf(h)
+	area, _ := Decode(wgs84Long, wgs84Lat, h)
+
+	// When the search area is near the edge of the center cell, a neighbor
+	// may be too close to cover everything: use larger cells.
+	north, _ := Decode(wgs84Long, wgs84Lat, n.north)
+	south, _ := Decode(wgs84Long, wgs84Lat, n.south)
+	east, _ := Decode(wgs84Long, wgs84Lat, n.east)
+	west, _ := Decode(wgs84Long, wgs84Lat, n.west)
+	decrease := north.Lat.Max < maxLat || south.Lat.Min > minLat ||
+		east.Long.Max < maxLong || west.Long.Min > minLong
+	if step > 1 && decrease {
+		step--
+		h, _ = Encode(wgs84Long, wgs84Lat, s.Long, s.Lat, step)
+		n = neighborsOf(h)
+		area, _ = Decode(wgs84Long, wgs84Lat, h)
+	}
+
+	// Exclude neighbors that cannot hold results.
+	if step >= 2 {
+		if area.Lat.Min < minLat {
+			n.south, n.southWest, n.southEast = Hash{}, Hash{}, Hash{}
+		}
+		if area.Lat.Max > maxLat {
+			n.north, n.northEast, n.northWest = Hash{}, Hash{}, Hash{}
+		}
+		if area.Long.Min < minLong {
+			n.west, n.southWest, n.northWest = Hash{}, Hash{}, Hash{}
+		}
+		if area.Long.Max > maxLong {
+			n.east, n.southEast, n.northEast = Hash{}, Hash{}, Hash{}
+		}
+	}
+
+	cells := []Hash{h, n.north, n.south, n.east, n.west, n.northEast, n.northWest, n.southEast, n.southWest}
+	var ranges []ScoreRange
+	last := 0
+	for i, c := range cells {
+		if c.isZero() {
+			continue
+		}
+		// With huge radiuses adjacent neighbors can be the same cell. As in
+		// Valkey, the comparison is skipped while the last cell is the center.
+		if last != 0 && c == cells[last] {
+			continue
+		}
+		next := c
+		next.Bits++
+		ranges = append(ranges, ScoreRange{Min: float64(c.align52()), Max: float64(next.align52())})
+		last = i
+	}
+	return ranges
+}
+
+// boundingBox returns the min/max longitude and latitude enclosing the shape.
+func (s Shape) boundingBox() (float64, float64, float64, float64) {
+	height, width := s.Radius, s.Radius
+	if s.Box {
+		height, width = s.Height/2, s.Width/2
+	}
+	height *= s.Conversion
+	width *= s.Conversion
+
+	latDelta := height / earthRadius / degToRad
+	longDeltaTop := width / earthRadius / math.Cos((s.Lat+latDelta)*degToRad) / degToRad
+	longDeltaBottom := width / earthRadius / math.Cos((s.Lat-latDelta)*degToRad) / degToRad
+	// The hemispheres are mirrored, so the widest edge differs.
+	longDelta := longDeltaTop
+	if s.Lat < 0 {
+		longDelta = longDeltaBottom
+	}
+	return s.Long - longDelta, s.Lat - latDelta, s.Long + longDelta, s.Lat + latDelta
+}
+
+// estimateSteps returns the geohash precision whose cells cover the radius.
+func estimateSteps(radius, lat float64) uint8 {
+	if radius == 0 {
+		return StepMax
+	}
+	step := 1
+	for radius < mercatorMax {
+		radius *= 2
+		step++
+	}
+	step -= 2 // Make sure the range is included in most of the base cases.
+
+	// Cells get narrower towards the poles.
+	if lat > 66 || lat < -66 {
+		step--
+		if lat > 80 || lat < -80 {
+			step--
+		}
+	}
+	return uint8(min(max(step, 1), StepMax))
+}
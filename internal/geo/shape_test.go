@@ -0,0 +1,54 @@
+package geo
+
+import (
+	"testing"
+)
+
+func TestShape_Contains(t *testing.T) {
+	t.Parallel()
+
+	tcs := []struct {
+		name  string
+		shape Shape
+		ok    bool
+	}{
+		{name: "inside radius", shape: Shape{Long: 15, Lat: 37, Radius: 200, Conversion: 1000}, ok: true},
+		{name: "outside radius", shape: Shape{Long: 15, Lat: 37, Radius: 100, Conversion: 1000}},
+		{name: "inside box", shape: Shape{Long: 15, Lat: 37, Box: true, Width: 400, Height: 400, Conversion: 1000}, ok: true},
+		{name: "outside box", shape: Shape{Long: 15, Lat: 37, Box: true, Width: 400, Height: 200, Conversion: 1000}},
+	}
+
+	// Palermo is about 190km from the center, mostly to the west.
+	long, lat, _ := DecodeScore(3479099956230698)
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if _, ok := tc.shape.Contains(long, lat); ok != tc.ok {
+				t.Fatalf("expected %v, got %v", tc.ok, ok)
+			}
+		})
+	}
+}
+
+func TestShape_Ranges(t *testing.T) {
+	t.Parallel()
+
+	shape := Shape{Long: 15, Lat: 37, Radius: 200, Conversion: 1000}
+	ranges := shape.Ranges()
+	if len(ranges) == 0 || len(ranges) > 9 {
+		t.Fatalf("expected between 1 and 9 ranges, got %d", len(ranges))
+	}
+	for _, score := range []float64{3479099956230698, 3479447370796909} {
+		found := false
+		for _, r := range ranges {
+			if score >= r.Min && score < r.Max {
+				found = true
+			}
+		}
+		if !found {
+			t.Fatalf("expected score %v to be covered by %v", score, ranges)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+// Package glob matches strings against Valkey's glob-style patterns, as used by KEYS and SCAN.
+package glob
+
+// Match reports whether s matches pattern. Patterns support * (any run of
+// bytes), ? (one byte), [abc] / [^abc] / [a-z] classes and \ escapes.
+// Matching works on bytes, like Valkey's stringmatchlen.
+func Match(pattern, s string) bool {
+	p, i := 0, 0
+	// Backtracking point for the last *: where it sits in the pattern and
+	// how much of s it has consumed so far.
+	star, starI := -1, 0
+	for i < len(s) {
+		if p < len(pattern) {
+			switch pattern[p] {
+			case '*':
+				star, starI = p, i
+				p++
+				continue
+			case '?':
+				p++
+				i++
+				continue
+			case '[':
+				if ok, next := matchClass(pattern, p, s[i]); ok {
+					p = next
+					i++
+					continue
+				}
+			case '\\':
+				if p+1 < len(pattern) {
+					if pattern[p+1] == s[i] {
+						p += 2
+						i++
+						continue
+					}
+					break
+				}
+				fallthrough
+			default:
+				if pattern[p] == s[i] {
+					p++
+					i++
+					continue
+				}
+			}
+		}
+		if star < 0 {
+			return false
+		}
+		// Let the last * swallow one more byte and retry.
+		starI++
+		p, i = star+1, starI
+	}
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+	return p == len(pattern)
+}
+
+// matchClass matches c against the class starting at pattern[p] == '['.
+// Returns whether it matched and the pattern index past the class.
+// An unterminated class runs to the end of the pattern, as in Valkey.
+func matchClass(pattern string, p int, c byte) (bool, int) {
+	p++
+	not := p < len(pattern) && pattern[p] == '^'
+	if not {
+		p++
+	}
+	match := false
+	for p < len(pattern) && pattern[p] != ']' {
+		switch {
+		case pattern[p] == '\\' && p+1 < len(pattern):
+			p++
+			if pattern[p] == c {
+				match = true
+			}
+		case p+2 < len(pattern) && pattern[p+1] == '-' && pattern[p+2] != ']':
+			lo, hi := pattern[p], pattern[p+2]
+			if lo > hi {
+				lo, hi = hi, lo
+			}
+			if c >= lo && c <= hi {
+				match = true
+			}
+			p += 2
+		default:
+			if pattern[p] == c {
+				match = true
+			}
+		}
+		p++
+	}
+	if p < len(pattern) {
+		p++ // skip ']'
+	}
+	return match != not, p
+}
@@ -0,0 +1,51 @@
+package glob_test
+
+import (
+	"testing"
+
+	"github.com/mickamy/minivalkey/internal/glob"
+)
+
+func TestMatch(t *testing.T) {
+	t.Parallel()
+
+	tcs := []struct {
+		pattern string
+		s       string
+		want    bool
+	}{
+		{pattern: "*", s: "", want: true},
+		{pattern: "*", s: "anything/at:all", want: true},
+		{pattern: "user:*", s: "user:1", want: true},
+		{pattern: "user:*", s: "users", want: false},
+		{pattern: "*:*:name", s: "a:b:c:name", want: true},
+		{pattern: "h?llo", s: "hello", want: true},
+		{pattern: "h?llo", s: "hllo", want: false},
+		{pattern: "h[ae]llo", s: "hallo", want: true},
+		{pattern: "h[ae]llo", s: "hillo", want: false},
+		{pattern: "h[^e]llo", s: "hallo", want: true},
+		{pattern: "h[^e]llo", s: "hello", want: false},
+		{pattern: "h[a-b]llo", s: "hbllo", want: true},
+		{pattern: "h[b-a]llo", s: "hallo", want: true},
+		{pattern: "h[a-b]llo", s: "hcllo", want: false},
+		{pattern: `h\*llo`, s: "h*llo", want: true},
+		{pattern: `h\*llo`, s: "hello", want: false},
+		{pattern: `h[\]]llo`, s: "h]llo", want: true},
+		{pattern: "a*b*c", s: "aXbYbZc", want: true},
+		{pattern: "a*b*c", s: "aXbYbZ", want: false},
+		{pattern: "abc", s: "abcd", want: false},
+		{pattern: "abc*", s: "ab", want: false},
+		{pattern: "[abc", s: "b", want: true},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.pattern+"/"+tc.s, func(t *testing.T) {
+			t.Parallel()
+
+			if got := glob.Match(tc.pattern, tc.s); got != tc.want {
+				t.Fatalf("Match(%q, %q) = %v, want %v", tc.pattern, tc.s, got, tc.want)
+			}
+		})
+	}
+}
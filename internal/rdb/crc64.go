@@ -0,0 +1,21 @@
+package rdb
+
+import (
+	"hash/crc64"
+)
+
+// crcTable is the reflected form of the Jones polynomial (0xad93d23594c935a9)
+// Valkey checksums payloads with.
+var crcTable = crc64.MakeTable(0x95ac9329ac4bc9b5)
+
+// checksum computes Valkey's CRC-64/Jones of p. Unlike the standard library
+// variants it starts from zero and does not invert the result, so the
+// inversions hash/crc64 applies are undone.
+func checksum(p []byte) uint64 {
+	return updateChecksum(0, p)
+}
+
+// updateChecksum extends a checksum computed by checksum with p.
+func updateChecksum(crc uint64, p []byte) uint64 {
+	return ^crc64.Update(^crc, crcTable, p)
+}
@@ -0,0 +1,29 @@
+package rdb
+
+import (
+	"testing"
+)
+
+func TestChecksum(t *testing.T) {
+	t.Parallel()
+
+	tcs := []struct {
+		name string
+		in   string
+		want uint64
+	}{
+		{name: "empty input", in: "", want: 0},
+		{name: "check value", in: "123456789", want: 0xe9c6d914c4b8d9ca},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := checksum([]byte(tc.in)); got != tc.want {
+				t.Fatalf("expected %#x, got %#x", tc.want, got)
+			}
+		})
+	}
+}
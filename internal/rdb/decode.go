@@ -0,0 +1,478 @@
+package rdb
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// maxPrealloc caps how many elements are allocated up front from a length
+// read off the wire, so a corrupt length cannot exhaust memory.
+const maxPrealloc = 1024
+
+// decoder reads RDB encoded values from r.
+type decoder struct {
+	r io.Reader
+}
+
+// badFormat wraps ErrBadFormat with details on what could not be decoded.
+func badFormat(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrBadFormat, fmt.Sprintf(format, args...))
+}
+
+func (d *decoder) readByte() (byte, error) {
+	b, err := d.readBytes(1)
+	if err != nil {
+		return 0, err
+	}
+	return b[0], nil
+}
+
+// readBytes reads exactly n bytes. Large reads grow as data arrives instead
+// of trusting n.
+func (d *decoder) readBytes(n uint64) ([]byte, error) {
+	if n <= maxPrealloc {
+		b := make([]byte, n)
+		if _, err := io.ReadFull(d.r, b); err != nil {
+			return nil, badFormat("unexpected end of data")
+		}
+		return b, nil
+	}
+	var buf bytes.Buffer
+	if m, err := io.Copy(&buf, io.LimitReader(d.r, int64(min(n, math.MaxInt64)))); err != nil || uint64(m) != n {
+		return nil, badFormat("unexpected end of data")
+	}
+	return buf.Bytes(), nil
+}
+
+// readLen reads a length. When encoded is true the length is instead one of
+// the special string encodings.
+func (d *decoder) readLen() (n uint64, encoded bool, err error) {
+	c, err := d.readByte()
+	if err != nil {
+		return 0, false, err
+	}
+	switch c >> 6 {
+	case 0:
+		return uint64(c & 0x3f), false, nil
+	case 1:
+		next, err := d.readByte()
+		if err != nil {
+			return 0, false, err
+		}
+		return uint64(c&0x3f)<<8 | uint64(next), false, nil
+	case 3:
+		return uint64(c & 0x3f), true, nil
+	}
+	switch c {
+	case 0x80:
+		b, err := d.readBytes(4)
+		if err != nil {
+			return 0, false, err
+		}
+		return uint64(binary.BigEndian.Uint32(b)), false, nil
+	case 0x81:
+		b, err := d.readBytes(8)
+		if err != nil {
+			return 0, false, err
+		}
+		return binary.BigEndian.Uint64(b), false, nil
+	default:
+		return 0, false, badFormat("unknown length encoding %#x", c)
+	}
+}
+
+// readCount reads a plain length, such as the number of elements in a collection.
+func (d *decoder) readCount() (uint64, error) {
+	n, encoded, err := d.readLen()
+	if err != nil {
+		return 0, err
+	}
+	if encoded {
+		return 0, badFormat("unexpected string encoding")
+	}
+	return n, nil
+}
+
+// readString reads a string in any of its plain, integer or LZF encodings.
+func (d *decoder) readString() (string, error) {
+	n, encoded, err := d.readLen()
+	if err != nil {
+		return "", err
+	}
+	if !encoded {
+		b, err := d.readBytes(n)
+		return string(b), err
+	}
+	switch n {
+	case 0:
+		b, err := d.readBytes(1)
+		if err != nil {
+			return "", err
+		}
+		return strconv.Itoa(int(int8(b[0]))), nil
+	case 1:
+		b, err := d.readBytes(2)
+		if err != nil {
+			return "", err
+		}
+		return strconv.Itoa(int(int16(binary.LittleEndian.Uint16(b)))), nil
+	case 2:
+		b, err := d.readBytes(4)
+		if err != nil {
+			return "", err
+		}
+		return strconv.Itoa(int(int32(binary.LittleEndian.Uint32(b)))), nil
+	case 3:
+		clen, err := d.readCount()
+		if err != nil {
+			return "", err
+		}
+		ulen, err := d.readCount()
+		if err != nil {
+			return "", err
+		}
+		if ulen > math.MaxInt32 {
+			return "", badFormat("compressed string too long")
+		}
+		in, err := d.readBytes(clen)
+		if err != nil {
+			return "", err
+		}
+		out, ok := lzfDecompress(in, int(ulen))
+		if !ok {
+			return "", badFormat("invalid LZF data")
+		}
+		return string(out), nil
+	default:
+		return "", badFormat("unknown string encoding %d", n)
+	}
+}
+
+// readDouble reads a score in the string form of RDB_TYPE_ZSET.
+func (d *decoder) readDouble() (float64, error) {
+	n, err := d.readByte()
+	if err != nil {
+		return 0, err
+	}
+	switch n {
+	case 253:
+		return math.NaN(), nil
+	case 254:
+		return math.Inf(1), nil
+	case 255:
+		return math.Inf(-1), nil
+	}
+	b, err := d.readBytes(uint64(n))
+	if err != nil {
+		return 0, err
+	}
+	f, err := strconv.ParseFloat(string(b), 64)
+	if err != nil {
+		return 0, badFormat("invalid double %q", b)
+	}
+	return f, nil
+}
+
+// readBinaryDouble reads a score in the binary form of RDB_TYPE_ZSET_2.
+func (d *decoder) readBinaryDouble() (float64, error) {
+	b, err := d.readBytes(8)
+	if err != nil {
+		return 0, err
+	}
+	return math.Float64frombits(binary.LittleEndian.Uint64(b)), nil
+}
+
+// readStrings reads a count followed by that many strings.
+func (d *decoder) readStrings() ([]string, error) {
+	n, err := d.readCount()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]string, 0, min(n, maxPrealloc))
+	for range n {
+		s, err := d.readString()
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	return out, nil
+}
+
+// readBlob reads a string holding a compact encoding and decodes it with parse.
+func (d *decoder) readBlob(parse func([]byte) ([]string, bool)) ([]string, error) {
+	s, err := d.readString()
+	if err != nil {
+		return nil, err
+	}
+	out, ok := parse([]byte(s))
+	if !ok {
+		return nil, badFormat("corrupt compact encoding")
+	}
+	return out, nil
+}
+
+// readValue reads the value of an object of the given type.
+func (d *decoder) readValue(typ byte) (Value, error) {
+	switch typ {
+	case typeString:
+		s, err := d.readString()
+		return Value{Kind: KindString, String: s}, err
+	case typeList:
+		items, err := d.readStrings()
+		if err != nil {
+			return Value{}, err
+		}
+		return listValue(items)
+	case typeListZiplist:
+		items, err := d.readBlob(parseZiplist)
+		if err != nil {
+			return Value{}, err
+		}
+		return listValue(items)
+	case typeListQuicklist, typeListQuicklist2:
+		return d.readQuicklist(typ)
+	case typeSet:
+		members, err := d.readStrings()
+		if err != nil {
+			return Value{}, err
+		}
+		return setValue(members)
+	case typeSetIntset, typeSetListpack:
+		parse := parseListpack
+		if typ == typeSetIntset {
+			parse = parseIntset
+		}
+		members, err := d.readBlob(parse)
+		if err != nil {
+			return Value{}, err
+		}
+		return setValue(members)
+	case typeZSet, typeZSet2:
+		return d.readZSet(typ)
+	case typeZSetZiplist, typeZSetListpack:
+		return d.readPairs(typ, zsetValue)
+	case typeHash:
+		pairs, err := d.readPairList()
+		if err != nil {
+			return Value{}, err
+		}
+		return hashValue(pairs)
+	case typeHashZiplist, typeHashListpack:
+		return d.readPairs(typ, hashValue)
+	case typeHash2:
+		return d.readHash2()
+	default:
+		return Value{}, badFormat("unsupported object type %d", typ)
+	}
+}
+
+func (d *decoder) readQuicklist(typ byte) (Value, error) {
+	n, err := d.readCount()
+	if err != nil {
+		return Value{}, err
+	}
+	var items []string
+	for range n {
+		container := uint64(quicklistNodePacked)
+		if typ == typeListQuicklist2 {
+			if container, err = d.readCount(); err != nil {
+				return Value{}, err
+			}
+		}
+		switch container {
+		case quicklistNodePlain:
+			s, err := d.readString()
+			if err != nil {
+				return Value{}, err
+			}
+			items = append(items, s)
+		case quicklistNodePacked:
+			parse := parseListpack
+			if typ == typeListQuicklist {
+				parse = parseZiplist
+			}
+			node, err := d.readBlob(parse)
+			if err != nil {
+				return Value{}, err
+			}
+			if len(node) == 0 {
+				return Value{}, badFormat("empty quicklist node")
+			}
+			items = append(items, node...)
+		default:
+			return Value{}, badFormat("unknown quicklist container %d", container)
+		}
+	}
+	return listValue(items)
+}
+
+func (d *decoder) readZSet(typ byte) (Value, error) {
+	n, err := d.readCount()
+	if err != nil {
+		return Value{}, err
+	}
+	pairs := make([]string, 0, min(2*n, maxPrealloc))
+	for range n {
+		m, err := d.readString()
+		if err != nil {
+			return Value{}, err
+		}
+		var score float64
+		if typ == typeZSet2 {
+			score, err = d.readBinaryDouble()
+		} else {
+			score, err = d.readDouble()
+		}
+		if err != nil {
+			return Value{}, err
+		}
+		if math.IsNaN(score) {
+			return Value{}, badFormat("NaN score")
+		}
+		pairs = append(pairs, m, strconv.FormatFloat(score, 'g', -1, 64))
+	}
+	return zsetValue(pairs)
+}
+
+// readHash2 reads a hash whose fields each carry an expiration.
+func (d *decoder) readHash2() (Value, error) {
+	n, err := d.readCount()
+	if err != nil {
+		return Value{}, err
+	}
+	pairs := make([]string, 0, min(2*n, maxPrealloc))
+	expires := make([]time.Time, 0, min(n, maxPrealloc))
+	for range n {
+		b, err := d.readBytes(8)
+		if err != nil {
+			return Value{}, err
+		}
+		var at time.Time
+		if ms := int64(binary.LittleEndian.Uint64(b)); ms != noFieldExpiry {
+			if ms < 0 {
+				return Value{}, badFormat("invalid field expiration %d", ms)
+			}
+			at = time.UnixMilli(ms)
+		}
+		expires = append(expires, at)
+		for range 2 {
+			s, err := d.readString()
+			if err != nil {
+				return Value{}, err
+			}
+			pairs = append(pairs, s)
+		}
+	}
+	v, err := hashValue(pairs)
+	if err != nil {
+		return Value{}, err
+	}
+	for i := range v.Hash {
+		v.Hash[i].ExpireAt = expires[i]
+	}
+	return v, nil
+}
+
+// readPairList reads a count of pairs followed by alternating strings.
+func (d *decoder) readPairList() ([]string, error) {
+	n, err := d.readCount()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]string, 0, min(2*n, maxPrealloc))
+	for range 2 * n {
+		s, err := d.readString()
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	return out, nil
+}
+
+// readPairs reads a ziplist or listpack of alternating entries and builds the value with build.
+func (d *decoder) readPairs(typ byte, build func([]string) (Value, error)) (Value, error) {
+	parse := parseListpack
+	if typ == typeZSetZiplist || typ == typeHashZiplist {
+		parse = parseZiplist
+	}
+	pairs, err := d.readBlob(parse)
+	if err != nil {
+		return Value{}, err
+	}
+	return build(pairs)
+}
+
+func listValue(items []string) (Value, error) {
+	if len(items) == 0 {
+		return Value{}, badFormat("empty list")
+	}
+	return Value{Kind: KindList, List: items}, nil
+}
+
+func setValue(members []string) (Value, error) {
+	if len(members) == 0 {
+		return Value{}, badFormat("empty set")
+	}
+	seen := make(map[string]struct{}, len(members))
+	for _, m := range members {
+		if _, dup := seen[m]; dup {
+			return Value{}, badFormat("duplicate set member")
+		}
+		seen[m] = struct{}{}
+	}
+	return Value{Kind: KindSet, Set: members}, nil
+}
+
+// zsetValue builds a sorted set from alternating members and scores.
+func zsetValue(pairs []string) (Value, error) {
+	if len(pairs) == 0 || len(pairs)%2 != 0 {
+		return Value{}, badFormat("invalid sorted set")
+	}
+	seen := make(map[string]struct{}, len(pairs)/2)
+	members := make([]ZMember, 0, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		if _, dup := seen[pairs[i]]; dup {
+			return Value{}, badFormat("duplicate sorted set member")
+		}
+		seen[pairs[i]] = struct{}{}
+		score, err := strconv.ParseFloat(pairs[i+1], 64)
+		if err != nil && !errors.Is(err, strconv.ErrRange) || math.IsNaN(score) {
+			return Value{}, badFormat("invalid score %q", pairs[i+1])
+		}
+		members = append(members, ZMember{Member: pairs[i], Score: score})
+	}
+	slices.SortFunc(members, func(a, b ZMember) int {
+		if c := cmp.Compare(a.Score, b.Score); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Member, b.Member)
+	})
+	return Value{Kind: KindZSet, ZSet: members}, nil
+}
+
+// hashValue builds a hash from alternating fields and values.
+func hashValue(pairs []string) (Value, error) {
+	if len(pairs) == 0 || len(pairs)%2 != 0 {
+		return Value{}, badFormat("invalid hash")
+	}
+	seen := make(map[string]struct{}, len(pairs)/2)
+	fields := make([]HashField, 0, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		if _, dup := seen[pairs[i]]; dup {
+			return Value{}, badFormat("duplicate hash field")
+		}
+		seen[pairs[i]] = struct{}{}
+		fields = append(fields, HashField{Field: pairs[i], Value: pairs[i+1]})
+	}
+	return Value{Kind: KindHash, Hash: fields}, nil
+}
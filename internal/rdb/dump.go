@@ -0,0 +1,50 @@
+package rdb
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// footerSize is the RDB version (2 bytes) plus the CRC64 checksum (8 bytes)
+// closing a DUMP payload.
+const footerSize = 10
+
+// Dump serializes v the way DUMP does: the object type and value, followed
+// by the RDB version and a checksum of everything before it. Hashes with
+// field expirations are written as Valkey 9 does, which older releases reject.
+func Dump(v Value) []byte {
+	b := AppendValue(nil, v)
+	b = binary.LittleEndian.AppendUint16(b, v.version())
+	return binary.LittleEndian.AppendUint64(b, checksum(b))
+}
+
+// Undump decodes a payload produced by Dump or by Valkey's DUMP.
+// Returns ErrChecksum if the footer does not verify and ErrBadFormat if the
+// value cannot be decoded.
+func Undump(p []byte) (Value, error) {
+	if err := verifyPayload(p); err != nil {
+		return Value{}, err
+	}
+	d := &decoder{r: bytes.NewReader(p[:len(p)-footerSize])}
+	typ, err := d.readByte()
+	if err != nil {
+		return Value{}, err
+	}
+	return d.readValue(typ)
+}
+
+// verifyPayload checks the version and checksum in the footer of p.
+func verifyPayload(p []byte) error {
+	if len(p) < footerSize {
+		return ErrChecksum
+	}
+	footer := p[len(p)-footerSize:]
+	ver := binary.LittleEndian.Uint16(footer)
+	if ver > versionMax || (ver >= foreignVersionMin && ver <= foreignVersionMax) {
+		return ErrChecksum
+	}
+	if binary.LittleEndian.Uint64(footer[2:]) != checksum(p[:len(p)-8]) {
+		return ErrChecksum
+	}
+	return nil
+}
@@ -0,0 +1,226 @@
+package rdb
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// withFooter closes body with a version footer and a valid checksum.
+func withFooter(body []byte, version uint16) []byte {
+	b := binary.LittleEndian.AppendUint16(append([]byte(nil), body...), version)
+	return binary.LittleEndian.AppendUint64(b, checksum(b))
+}
+
+func TestDump(t *testing.T) {
+	t.Parallel()
+
+	// Payload of "SET mykey 10" followed by "DUMP mykey" on a server writing RDB version 9.
+	const valkeyPayload = "\x00\xc0\n\t\x00\xbem\x06\x89Z(\x00\n"
+
+	got, err := Undump([]byte(valkeyPayload))
+	if err != nil {
+		t.Fatalf("Undump returned error: %v", err)
+	}
+	if want := (Value{Kind: KindString, String: "10"}); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %+v, got %+v", want, got)
+	}
+
+	// The same value written with this package's version must only differ in the footer.
+	b := Dump(got)
+	if want := withFooter([]byte("\x00\xc0\n"), Version); string(b) != string(want) {
+		t.Fatalf("unexpected payload:\nwant %q\ngot  %q", want, b)
+	}
+}
+
+func TestDump_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	long := strings.Repeat("v", 100)
+	many := make([]string, 2000)
+	for i := range many {
+		many[i] = strings.Repeat("e", 10) + string(rune('a'+i%26))
+	}
+
+	tcs := []struct {
+		name     string
+		v        Value
+		wantType byte
+	}{
+		{name: "short string", v: Value{Kind: KindString, String: "bar"}, wantType: typeString},
+		{name: "integer string", v: Value{Kind: KindString, String: "-2147483648"}, wantType: typeString},
+		{name: "large integer string", v: Value{Kind: KindString, String: "9223372036854775807"}, wantType: typeString},
+		{name: "empty string", v: Value{Kind: KindString}, wantType: typeString},
+		{name: "listpack list", v: Value{Kind: KindList, Encoding: "listpack", List: []string{"a", "1", long}}, wantType: typeListQuicklist2},
+		{name: "quicklist list", v: Value{Kind: KindList, Encoding: "quicklist", List: many}, wantType: typeListQuicklist2},
+		{name: "intset", v: Value{Kind: KindSet, Encoding: "intset", Set: []string{"-70000", "1", "2"}}, wantType: typeSetIntset},
+		{name: "listpack set", v: Value{Kind: KindSet, Encoding: "listpack", Set: []string{"a", "b"}}, wantType: typeSetListpack},
+		{name: "hashtable set", v: Value{Kind: KindSet, Encoding: "hashtable", Set: []string{"a", long}}, wantType: typeSet},
+		{
+			name: "listpack zset",
+			v: Value{Kind: KindZSet, Encoding: "listpack", ZSet: []ZMember{
+				{Member: "a", Score: math.Inf(-1)}, {Member: "b", Score: -1.5}, {Member: "c", Score: 2}, {Member: "d", Score: math.Inf(1)},
+			}},
+			wantType: typeZSetListpack,
+		},
+		{
+			name:     "skiplist zset",
+			v:        Value{Kind: KindZSet, Encoding: "skiplist", ZSet: []ZMember{{Member: "a", Score: 0.1}, {Member: long, Score: 3}}},
+			wantType: typeZSet2,
+		},
+		{name: "listpack hash", v: Value{Kind: KindHash, Encoding: "listpack", Hash: []HashField{{Field: "f", Value: "1"}}}, wantType: typeHashListpack},
+		{name: "hashtable hash", v: Value{Kind: KindHash, Encoding: "hashtable", Hash: []HashField{{Field: "f", Value: long}}}, wantType: typeHash},
+		{
+			name: "hash with field expirations",
+			v: Value{Kind: KindHash, Encoding: "hashtable", Hash: []HashField{
+				{Field: "a", Value: "1", ExpireAt: time.UnixMilli(1_700_000_000_123)}, {Field: "b", Value: "2"},
+			}},
+			wantType: typeHash2,
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			b := Dump(tc.v)
+			if b[0] != tc.wantType {
+				t.Fatalf("expected object type %d, got %d", tc.wantType, b[0])
+			}
+			wantVersion := uint16(Version)
+			if tc.wantType == typeHash2 {
+				wantVersion = versionFieldTTL
+			}
+			if ver := binary.LittleEndian.Uint16(b[len(b)-footerSize:]); ver != wantVersion {
+				t.Fatalf("expected version %d, got %d", wantVersion, ver)
+			}
+			got, err := Undump(b)
+			if err != nil {
+				t.Fatalf("Undump returned error: %v", err)
+			}
+			want := tc.v
+			want.Encoding = ""
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("expected %+v, got %+v", want, got)
+			}
+		})
+	}
+}
+
+func TestUndump_Encodings(t *testing.T) {
+	t.Parallel()
+
+	ziplist := func(entries ...[]byte) string {
+		var body []byte
+		prev := 0
+		for _, e := range entries {
+			body = append(body, byte(prev))
+			body = append(body, e...)
+			prev = len(e) + 1
+		}
+		b := binary.LittleEndian.AppendUint32(nil, uint32(10+len(body)+1))
+		b = binary.LittleEndian.AppendUint32(b, 0)
+		b = binary.LittleEndian.AppendUint16(b, uint16(len(entries)))
+		return string(append(append(b, body...), 0xff))
+	}
+	blob := func(s string) []byte { return appendString(nil, s) }
+
+	tcs := []struct {
+		name string
+		body []byte
+		want Value
+	}{
+		{
+			name: "LZF compressed string",
+			body: []byte{typeString, 0xc3, 0x05, 0x18, 0x00, 'a', 0xe0, 0x0e, 0x00},
+			want: Value{Kind: KindString, String: strings.Repeat("a", 24)},
+		},
+		{
+			name: "plain list",
+			body: append([]byte{typeList, 0x02}, append(blob("a"), blob("b")...)...),
+			want: Value{Kind: KindList, List: []string{"a", "b"}},
+		},
+		{
+			name: "ziplist quicklist",
+			body: append([]byte{typeListQuicklist, 0x01}, blob(ziplist([]byte{0x01, 'a'}, []byte{0xf2}))...),
+			want: Value{Kind: KindList, List: []string{"a", "1"}},
+		},
+		{
+			name: "plain quicklist node",
+			body: append([]byte{typeListQuicklist2, 0x01, quicklistNodePlain}, blob("big")...),
+			want: Value{Kind: KindList, List: []string{"big"}},
+		},
+		{
+			name: "string scores",
+			body: append(append([]byte{typeZSet, 0x02}, blob("b")...), append([]byte{0x01, '2'}, append(blob("a"), 0xff)...)...),
+			want: Value{Kind: KindZSet, ZSet: []ZMember{{Member: "a", Score: math.Inf(-1)}, {Member: "b", Score: 2}}},
+		},
+		{
+			name: "ziplist zset",
+			body: append([]byte{typeZSetZiplist}, blob(ziplist([]byte{0x01, 'a'}, []byte{0x03, '1', '.', '5'}))...),
+			want: Value{Kind: KindZSet, ZSet: []ZMember{{Member: "a", Score: 1.5}}},
+		},
+		{
+			name: "ziplist hash",
+			body: append([]byte{typeHashZiplist}, blob(ziplist([]byte{0x01, 'f'}, []byte{0x01, 'v'}))...),
+			want: Value{Kind: KindHash, Hash: []HashField{{Field: "f", Value: "v"}}},
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := Undump(withFooter(tc.body, 9))
+			if err != nil {
+				t.Fatalf("Undump returned error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("expected %+v, got %+v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestUndump_Errors(t *testing.T) {
+	t.Parallel()
+
+	valid := Dump(Value{Kind: KindString, String: "bar"})
+	corrupt := append([]byte(nil), valid...)
+	corrupt[len(corrupt)-1] ^= 0xff
+
+	tcs := []struct {
+		name string
+		in   []byte
+		want error
+	}{
+		{name: "too short", in: []byte("\x00\x01"), want: ErrChecksum},
+		{name: "wrong checksum", in: corrupt, want: ErrChecksum},
+		{name: "newer version", in: withFooter([]byte("\x00\x03bar"), versionMax+1), want: ErrChecksum},
+		{name: "foreign version", in: withFooter([]byte("\x00\x03bar"), foreignVersionMin), want: ErrChecksum},
+		{name: "accepts valkey 9 version", in: withFooter([]byte("\x00\x03bar"), versionMax)},
+		{name: "unsupported type", in: withFooter([]byte("\x0f\x00"), Version), want: ErrBadFormat},
+		{name: "truncated value", in: withFooter([]byte("\x00\x05bar"), Version), want: ErrBadFormat},
+		{name: "empty list", in: withFooter([]byte{typeList, 0x00}, Version), want: ErrBadFormat},
+		{name: "duplicate set member", in: withFooter([]byte{typeSet, 0x02, 0x01, 'a', 0x01, 'a'}, Version), want: ErrBadFormat},
+		{name: "NaN score", in: withFooter([]byte{typeZSet, 0x01, 0x01, 'a', 253}, Version), want: ErrBadFormat},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := Undump(tc.in)
+			if !errors.Is(err, tc.want) && err != tc.want {
+				t.Fatalf("expected %v, got %v", tc.want, err)
+			}
+		})
+	}
+}
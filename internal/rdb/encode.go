@@ -0,0 +1,197 @@
+package rdb
+
+import (
+	"encoding/binary"
+	"math"
+	"strconv"
+)
+
+// listNodeBytes mirrors Valkey's default list-max-listpack-size of -2 (8kb)
+// when splitting a list into quicklist nodes.
+const listNodeBytes = 8 * 1024
+
+// AppendValue appends the object type of v followed by its serialized value.
+func AppendValue(b []byte, v Value) []byte {
+	switch v.Kind {
+	case KindList:
+		b = append(b, typeListQuicklist2)
+		return appendQuicklist(b, v.List)
+	case KindSet:
+		return appendSet(b, v)
+	case KindZSet:
+		return appendZSet(b, v)
+	case KindHash:
+		return appendHash(b, v)
+	default:
+		b = append(b, typeString)
+		return appendString(b, v.String)
+	}
+}
+
+// appendQuicklist appends a list as listpack nodes of at most listNodeBytes.
+func appendQuicklist(b []byte, items []string) []byte {
+	var nodes [][]string
+	size := listpackHeaderSize + 1
+	for _, it := range items {
+		n := listpackEntrySize(it)
+		if len(nodes) == 0 || size+n > listNodeBytes {
+			nodes = append(nodes, nil)
+			size = listpackHeaderSize + 1
+		}
+		nodes[len(nodes)-1] = append(nodes[len(nodes)-1], it)
+		size += n
+	}
+	b = appendLen(b, uint64(len(nodes)))
+	for _, node := range nodes {
+		b = appendLen(b, quicklistNodePacked)
+		b = appendString(b, string(appendListpack(nil, node)))
+	}
+	return b
+}
+
+func appendSet(b []byte, v Value) []byte {
+	switch v.Encoding {
+	case "intset":
+		vals := make([]int64, 0, len(v.Set))
+		for _, m := range v.Set {
+			n, ok := parseInt(m)
+			if !ok {
+				// Not an intset after all; fall back to the generic encoding.
+				return appendGenericSet(b, v.Set)
+			}
+			vals = append(vals, n)
+		}
+		b = append(b, typeSetIntset)
+		return appendString(b, string(appendIntset(nil, vals)))
+	case "listpack":
+		b = append(b, typeSetListpack)
+		return appendString(b, string(appendListpack(nil, v.Set)))
+	default:
+		return appendGenericSet(b, v.Set)
+	}
+}
+
+func appendGenericSet(b []byte, members []string) []byte {
+	b = append(b, typeSet)
+	b = appendLen(b, uint64(len(members)))
+	for _, m := range members {
+		b = appendString(b, m)
+	}
+	return b
+}
+
+func appendZSet(b []byte, v Value) []byte {
+	if v.Encoding == "listpack" {
+		entries := make([]string, 0, 2*len(v.ZSet))
+		for _, m := range v.ZSet {
+			entries = append(entries, m.Member, formatScore(m.Score))
+		}
+		b = append(b, typeZSetListpack)
+		return appendString(b, string(appendListpack(nil, entries)))
+	}
+	// Members are written from the highest score down, as Valkey walks its skiplist.
+	b = append(b, typeZSet2)
+	b = appendLen(b, uint64(len(v.ZSet)))
+	for i := len(v.ZSet) - 1; i >= 0; i-- {
+		b = appendString(b, v.ZSet[i].Member)
+		b = binary.LittleEndian.AppendUint64(b, math.Float64bits(v.ZSet[i].Score))
+	}
+	return b
+}
+
+func appendHash(b []byte, v Value) []byte {
+	if v.hasFieldTTL() {
+		// Each field is preceded by its expiration in milliseconds, -1 for none.
+		b = append(b, typeHash2)
+		b = appendLen(b, uint64(len(v.Hash)))
+		for _, f := range v.Hash {
+			at := int64(noFieldExpiry)
+			if !f.ExpireAt.IsZero() {
+				at = f.ExpireAt.UnixMilli()
+			}
+			b = binary.LittleEndian.AppendUint64(b, uint64(at))
+			b = appendString(b, f.Field)
+			b = appendString(b, f.Value)
+		}
+		return b
+	}
+	if v.Encoding == "listpack" {
+		entries := make([]string, 0, 2*len(v.Hash))
+		for _, f := range v.Hash {
+			entries = append(entries, f.Field, f.Value)
+		}
+		b = append(b, typeHashListpack)
+		return appendString(b, string(appendListpack(nil, entries)))
+	}
+	b = append(b, typeHash)
+	b = appendLen(b, uint64(len(v.Hash)))
+	for _, f := range v.Hash {
+		b = appendString(b, f.Field)
+		b = appendString(b, f.Value)
+	}
+	return b
+}
+
+// noFieldExpiry marks a field without expiration in RDB_TYPE_HASH_2.
+const noFieldExpiry = -1
+
+// appendLen appends n in RDB's variable length encoding.
+func appendLen(b []byte, n uint64) []byte {
+	switch {
+	case n < 1<<6:
+		return append(b, byte(n))
+	case n < 1<<14:
+		return append(b, byte(n>>8)|0x40, byte(n))
+	case n <= math.MaxUint32:
+		b = append(b, 0x80)
+		return binary.BigEndian.AppendUint32(b, uint32(n))
+	default:
+		b = append(b, 0x81)
+		return binary.BigEndian.AppendUint64(b, n)
+	}
+}
+
+// appendString appends s, using the integer encodings when s is a canonical
+// integer that fits in 32 bits. Strings are never compressed.
+func appendString(b []byte, s string) []byte {
+	if len(s) <= 11 {
+		if v, ok := parseInt(s); ok {
+			switch {
+			case v >= math.MinInt8 && v <= math.MaxInt8:
+				return append(b, 0xc0, byte(v))
+			case v >= math.MinInt16 && v <= math.MaxInt16:
+				b = append(b, 0xc1)
+				return binary.LittleEndian.AppendUint16(b, uint16(v))
+			case v >= math.MinInt32 && v <= math.MaxInt32:
+				b = append(b, 0xc2)
+				return binary.LittleEndian.AppendUint32(b, uint32(v))
+			}
+		}
+	}
+	b = appendLen(b, uint64(len(s)))
+	return append(b, s...)
+}
+
+// formatScore formats a score the way Valkey stores it in a listpack.
+func formatScore(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "inf"
+	case math.IsInf(f, -1):
+		return "-inf"
+	case f == math.Trunc(f) && math.Abs(f) < 1<<53:
+		return strconv.FormatInt(int64(f), 10)
+	default:
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	}
+}
+
+// parseInt parses s the way Valkey's string2ll does: only the canonical
+// decimal form of an int64 is accepted.
+func parseInt(s string) (int64, bool) {
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || strconv.FormatInt(n, 10) != s {
+		return 0, false
+	}
+	return n, true
+}
@@ -0,0 +1,392 @@
+package rdb
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"time"
+)
+
+// Opcodes that frame the keys of an RDB file.
+const (
+	opSlotInfo     = 0xf4
+	opFunction2    = 0xf5
+	opIdle         = 0xf8
+	opFreq         = 0xf9
+	opAux          = 0xfa
+	opResizeDB     = 0xfb
+	opExpireTimeMS = 0xfc
+	opExpireTime   = 0xfd
+	opSelectDB     = 0xfe
+	opEOF          = 0xff
+)
+
+// Stream types, which are skipped while reading since streams are not supported.
+const (
+	typeStreamListpacks  = 15
+	typeStreamListpacks2 = 19
+	typeStreamListpacks3 = 21
+)
+
+// Entry is a key with its value and expiry.
+type Entry struct {
+	Key      string
+	Value    Value
+	ExpireAt time.Time     // zero => no expiry
+	Idle     time.Duration // time since last access, when the file records it
+}
+
+// Database holds the keys of one numbered database.
+type Database struct {
+	Index   int
+	Entries []Entry
+	// Streams lists the keys holding streams, which Read skips since
+	// streams are not supported. Write ignores it.
+	Streams []string
+}
+
+// Write writes dbs as a complete RDB file, closed by a checksum.
+// Databases without entries are left out. A file holding hashes with field
+// expirations is written as Valkey 9 does, which older releases reject.
+func Write(w io.Writer, dbs []Database, now time.Time) error {
+	var b []byte
+	if hasFieldTTL(dbs) {
+		b = fmt.Appendf(nil, "VALKEY%03d", versionFieldTTL)
+		b = appendAux(b, "valkey-ver", "9.0.0")
+	} else {
+		b = fmt.Appendf(nil, "REDIS%04d", Version)
+		b = appendAux(b, "redis-ver", "7.2.4")
+	}
+	b = appendAux(b, "redis-bits", "64")
+	b = appendAux(b, "ctime", strconv.FormatInt(now.Unix(), 10))
+	b = appendAux(b, "used-mem", "0")
+	b = appendAux(b, "aof-base", "0")
+
+	crc := checksum(b)
+	if _, err := w.Write(b); err != nil {
+		return err
+	}
+	for _, d := range dbs {
+		if len(d.Entries) == 0 {
+			continue
+		}
+		expires := 0
+		for _, e := range d.Entries {
+			if !e.ExpireAt.IsZero() {
+				expires++
+			}
+		}
+		b = append(b[:0], opSelectDB)
+		b = appendLen(b, uint64(d.Index))
+		b = append(b, opResizeDB)
+		b = appendLen(b, uint64(len(d.Entries)))
+		b = appendLen(b, uint64(expires))
+		for _, e := range d.Entries {
+			if !e.ExpireAt.IsZero() {
+				b = append(b, opExpireTimeMS)
+				b = binary.LittleEndian.AppendUint64(b, uint64(e.ExpireAt.UnixMilli()))
+			}
+			// The object type goes before the key, so it is moved from the value.
+			v := AppendValue(nil, e.Value)
+			b = append(b, v[0])
+			b = appendString(b, e.Key)
+			b = append(b, v[1:]...)
+
+			// Flush as we go so a large keyspace is not held in memory twice.
+			crc = updateChecksum(crc, b)
+			if _, err := w.Write(b); err != nil {
+				return err
+			}
+			b = b[:0]
+		}
+	}
+	b = append(b[:0], opEOF)
+	crc = updateChecksum(crc, b)
+	b = binary.LittleEndian.AppendUint64(b, crc)
+	_, err := w.Write(b)
+	return err
+}
+
+// hasFieldTTL reports whether any hash in dbs has an expiring field.
+func hasFieldTTL(dbs []Database) bool {
+	for _, d := range dbs {
+		for _, e := range d.Entries {
+			if e.Value.hasFieldTTL() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func appendAux(b []byte, key, val string) []byte {
+	b = append(b, opAux)
+	b = appendString(b, key)
+	return appendString(b, val)
+}
+
+// crcReader checksums every byte read through it.
+type crcReader struct {
+	r   io.Reader
+	crc uint64
+}
+
+func (c *crcReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.crc = updateChecksum(c.crc, p[:n])
+	return n, err
+}
+
+// Read parses an RDB file written by Write or by Valkey. Keys that already
+// expired at now are skipped, and so are keys holding streams, which are
+// listed in Database.Streams instead.
+// A *bufio.Reader is read no further than the end of the file, so whatever
+// follows it can still be read.
+// Returns ErrChecksum if the header or checksum do not verify and
+// ErrBadFormat if the content cannot be decoded.
+func Read(r io.Reader, now time.Time) ([]Database, error) {
+	br, ok := r.(*bufio.Reader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+	cr := &crcReader{r: br}
+	d := &decoder{r: cr}
+
+	header, err := d.readBytes(9)
+	if err != nil {
+		return nil, err
+	}
+	ver, err := checkHeader(header)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		dbs      []Database
+		cur      = -1
+		expireAt time.Time
+		idle     time.Duration
+	)
+	for {
+		op, err := d.readByte()
+		if err != nil {
+			return nil, err
+		}
+		switch op {
+		case opEOF:
+			// Files older than version 5 carry no checksum.
+			if ver < 5 {
+				return dbs, nil
+			}
+			want := cr.crc
+			sum, err := d.readBytes(8)
+			if err != nil {
+				return nil, err
+			}
+			// A zero checksum means the file was written with checksums disabled.
+			if got := binary.LittleEndian.Uint64(sum); got != 0 && got != want {
+				return nil, ErrChecksum
+			}
+			return dbs, nil
+		case opSelectDB:
+			idx, err := d.readCount()
+			if err != nil {
+				return nil, err
+			}
+			if idx > math.MaxInt32 {
+				return nil, badFormat("invalid database index %d", idx)
+			}
+			dbs = append(dbs, Database{Index: int(idx)})
+			cur = len(dbs) - 1
+		case opResizeDB:
+			if err := d.skipLens(2); err != nil {
+				return nil, err
+			}
+		case opSlotInfo:
+			if err := d.skipLens(3); err != nil {
+				return nil, err
+			}
+		case opAux:
+			if err := d.skipStrings(2); err != nil {
+				return nil, err
+			}
+		case opFunction2:
+			if err := d.skipStrings(1); err != nil {
+				return nil, err
+			}
+		case opExpireTimeMS:
+			b, err := d.readBytes(8)
+			if err != nil {
+				return nil, err
+			}
+			expireAt = time.UnixMilli(int64(binary.LittleEndian.Uint64(b)))
+		case opExpireTime:
+			b, err := d.readBytes(4)
+			if err != nil {
+				return nil, err
+			}
+			expireAt = time.Unix(int64(int32(binary.LittleEndian.Uint32(b))), 0)
+		case opIdle:
+			secs, err := d.readCount()
+			if err != nil {
+				return nil, err
+			}
+			idle = time.Duration(min(secs, math.MaxInt64/uint64(time.Second))) * time.Second
+		case opFreq:
+			if _, err := d.readByte(); err != nil {
+				return nil, err
+			}
+		default:
+			// Anything else is the object type of a key.
+			key, err := d.readString()
+			if err != nil {
+				return nil, err
+			}
+			stream := op == typeStreamListpacks || op == typeStreamListpacks2 || op == typeStreamListpacks3
+			var v Value
+			if stream {
+				err = d.skipStream(op)
+			} else {
+				v, err = d.readValue(op)
+			}
+			if err != nil {
+				return nil, err
+			}
+			if expireAt.IsZero() || expireAt.After(now) {
+				if cur < 0 {
+					dbs = append(dbs, Database{})
+					cur = 0
+				}
+				if stream {
+					dbs[cur].Streams = append(dbs[cur].Streams, key)
+				} else {
+					dbs[cur].Entries = append(dbs[cur].Entries, Entry{Key: key, Value: v, ExpireAt: expireAt, Idle: idle})
+				}
+			}
+			expireAt, idle = time.Time{}, 0
+		}
+	}
+}
+
+// checkHeader verifies the magic string and version an RDB file opens with.
+// Valkey 9 and later open with "VALKEY" instead of "REDIS".
+func checkHeader(header []byte) (int, error) {
+	var digits string
+	switch {
+	case string(header[:5]) == "REDIS":
+		digits = string(header[5:])
+	case string(header[:6]) == "VALKEY":
+		digits = string(header[6:])
+	default:
+		return 0, badFormat("not an RDB file")
+	}
+	ver, err := strconv.Atoi(digits)
+	if err != nil || ver < 1 || ver > versionMax || (ver >= foreignVersionMin && ver <= foreignVersionMax) {
+		return 0, ErrChecksum
+	}
+	return ver, nil
+}
+
+// skipLens reads and discards n lengths.
+func (d *decoder) skipLens(n int) error {
+	for range n {
+		if _, err := d.readCount(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// skipStrings reads and discards n strings.
+func (d *decoder) skipStrings(n int) error {
+	for range n {
+		if _, err := d.readString(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// skipStream reads and discards a stream, including its consumer groups.
+func (d *decoder) skipStream(typ byte) error {
+	nodes, err := d.readCount()
+	if err != nil {
+		return err
+	}
+	// Each node is a master ID and a listpack of entries.
+	for range nodes {
+		if err := d.skipStrings(2); err != nil {
+			return err
+		}
+	}
+	// Length and last ID, then first ID, max deleted ID and entries added since v2.
+	meta := 3
+	if typ >= typeStreamListpacks2 {
+		meta += 5
+	}
+	if err := d.skipLens(meta); err != nil {
+		return err
+	}
+
+	groups, err := d.readCount()
+	if err != nil {
+		return err
+	}
+	for range groups {
+		if err := d.skipStrings(1); err != nil {
+			return err
+		}
+		// Last delivered ID, plus entries read since v2.
+		fields := 2
+		if typ >= typeStreamListpacks2 {
+			fields++
+		}
+		if err := d.skipLens(fields); err != nil {
+			return err
+		}
+		// Pending entries: raw ID, delivery time and delivery count.
+		pending, err := d.readCount()
+		if err != nil {
+			return err
+		}
+		for range pending {
+			if _, err := d.readBytes(16 + 8); err != nil {
+				return err
+			}
+			if err := d.skipLens(1); err != nil {
+				return err
+			}
+		}
+		consumers, err := d.readCount()
+		if err != nil {
+			return err
+		}
+		for range consumers {
+			if err := d.skipStrings(1); err != nil {
+				return err
+			}
+			// Seen time, plus active time since v3.
+			times := 8
+			if typ >= typeStreamListpacks3 {
+				times += 8
+			}
+			if _, err := d.readBytes(uint64(times)); err != nil {
+				return err
+			}
+			owned, err := d.readCount()
+			if err != nil {
+				return err
+			}
+			if owned > math.MaxUint32 {
+				return badFormat("invalid consumer")
+			}
+			if _, err := d.readBytes(owned * 16); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
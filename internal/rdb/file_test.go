@@ -0,0 +1,212 @@
+package rdb
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWrite_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	now := time.UnixMilli(1_000_000)
+	dbs := []Database{
+		{Index: 0, Entries: []Entry{
+			{Key: "s", Value: Value{Kind: KindString, String: "bar"}},
+			{Key: "l", Value: Value{Kind: KindList, List: []string{"a", "b"}}, ExpireAt: now.Add(time.Minute)},
+		}},
+		{Index: 3, Entries: []Entry{
+			{Key: "h", Value: Value{Kind: KindHash, Encoding: "listpack", Hash: []HashField{{Field: "f", Value: "v"}}}},
+			{Key: "z", Value: Value{Kind: KindZSet, ZSet: []ZMember{{Member: "m", Score: 1.5}}}},
+		}},
+		{Index: 5},
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, dbs, now); err != nil {
+		t.Fatalf("Write returned error: %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), "REDIS0011") {
+		t.Fatalf("unexpected header %q", buf.String()[:9])
+	}
+
+	// Whatever follows the file is left for the caller to read.
+	buf.WriteString("tail")
+	br := bufio.NewReader(&buf)
+	got, err := Read(br, now)
+	if err != nil {
+		t.Fatalf("Read returned error: %v", err)
+	}
+	if rest, _ := io.ReadAll(br); string(rest) != "tail" {
+		t.Fatalf("expected tail to be left unread, got %q", rest)
+	}
+	want := dbs[:2]
+	want[1].Entries[0].Value.Encoding = ""
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %+v, got %+v", want, got)
+	}
+}
+
+func TestWrite_FieldTTL(t *testing.T) {
+	t.Parallel()
+
+	now := time.UnixMilli(1_000_000)
+	dbs := []Database{{Entries: []Entry{
+		{Key: "h", Value: Value{Kind: KindHash, Hash: []HashField{
+			{Field: "a", Value: "1", ExpireAt: now.Add(time.Minute)}, {Field: "b", Value: "2"},
+		}}},
+	}}}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, dbs, now); err != nil {
+		t.Fatalf("Write returned error: %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), "VALKEY080") {
+		t.Fatalf("unexpected header %q", buf.String()[:9])
+	}
+	got, err := Read(&buf, now)
+	if err != nil {
+		t.Fatalf("Read returned error: %v", err)
+	}
+	if !reflect.DeepEqual(got, dbs) {
+		t.Fatalf("expected %+v, got %+v", dbs, got)
+	}
+}
+
+func TestRead(t *testing.T) {
+	t.Parallel()
+
+	now := time.UnixMilli(1_000_000)
+
+	// file assembles an RDB file from its body, closing it with sum
+	// (or a valid checksum when sum is nil).
+	file := func(header string, body []byte, sum []byte) []byte {
+		b := append([]byte(header), body...)
+		b = append(b, opEOF)
+		if sum == nil {
+			sum = binary.LittleEndian.AppendUint64(nil, checksum(b))
+		}
+		return append(b, sum...)
+	}
+	key := func(b []byte, typ byte, k string, v []byte) []byte {
+		b = append(b, typ)
+		b = appendString(b, k)
+		return append(b, v...)
+	}
+	expireMS := func(b []byte, at time.Time) []byte {
+		b = append(b, opExpireTimeMS)
+		return binary.LittleEndian.AppendUint64(b, uint64(at.UnixMilli()))
+	}
+
+	// stream is a stream with one node and a consumer group holding a
+	// pending entry owned by its one consumer.
+	id := make([]byte, 16)
+	var stream []byte
+	stream = appendLen(stream, 1)
+	stream = appendString(stream, string(id))
+	stream = appendString(stream, "listpack")
+	stream = append(stream, 0x01, 0x05, 0x00)             // length and last ID
+	stream = append(stream, 0x05, 0x00, 0x00, 0x00, 0x01) // first ID, max deleted ID, entries added
+	stream = appendLen(stream, 1)
+	stream = appendString(stream, "group")
+	stream = append(stream, 0x05, 0x00, 0x01) // last delivered ID, entries read
+	stream = appendLen(stream, 1)
+	stream = append(stream, id...)
+	stream = binary.LittleEndian.AppendUint64(stream, uint64(now.UnixMilli()))
+	stream = appendLen(stream, 1)
+	stream = appendLen(stream, 1)
+	stream = appendString(stream, "consumer")
+	stream = binary.LittleEndian.AppendUint64(stream, uint64(now.UnixMilli())) // seen time
+	stream = binary.LittleEndian.AppendUint64(stream, uint64(now.UnixMilli())) // active time
+	stream = appendLen(stream, 1)
+	stream = append(stream, id...)
+
+	var body []byte
+	body = appendAux(body, "valkey-ver", "8.0.1")
+	body = append(body, opFunction2)
+	body = appendString(body, "#!lua name=lib")
+	body = append(body, opSelectDB, 0x02, opResizeDB, 0x04, 0x02)
+	body = key(body, typeString, "kept", appendString(nil, "v"))
+	body = append(body, opIdle, 0x0a, opFreq, 0x05)
+	body = key(body, typeString, "idle", appendString(nil, "v"))
+	body = expireMS(body, now.Add(-time.Second))
+	body = key(body, typeString, "expired", appendString(nil, "v"))
+	body = append(body, opExpireTime)
+	body = binary.LittleEndian.AppendUint32(body, uint32(now.Unix()+60))
+	body = key(body, typeString, "seconds", appendString(nil, "v"))
+
+	tcs := []struct {
+		name    string
+		in      []byte
+		want    []Database
+		wantErr error
+	}{
+		{
+			name: "reads keys and skips expired ones",
+			in:   file("REDIS0011", body, nil),
+			want: []Database{{Index: 2, Entries: []Entry{
+				{Key: "kept", Value: Value{Kind: KindString, String: "v"}},
+				{Key: "idle", Value: Value{Kind: KindString, String: "v"}, Idle: 10 * time.Second},
+				{Key: "seconds", Value: Value{Kind: KindString, String: "v"}, ExpireAt: time.Unix(now.Unix()+60, 0)},
+			}}},
+		},
+		{
+			name: "accepts a disabled checksum",
+			in:   file("REDIS0011", key(nil, typeString, "k", appendString(nil, "v")), make([]byte, 8)),
+			want: []Database{{Entries: []Entry{{Key: "k", Value: Value{Kind: KindString, String: "v"}}}}},
+		},
+		{
+			name: "accepts valkey 9 header",
+			in:   file("VALKEY080", nil, nil),
+		},
+		{
+			name: "skips streams and loads the other keys",
+			in:   file("REDIS0011", key(key(nil, typeStreamListpacks3, "stream", stream), typeString, "k", appendString(nil, "v")), nil),
+			want: []Database{{
+				Entries: []Entry{{Key: "k", Value: Value{Kind: KindString, String: "v"}}},
+				Streams: []string{"stream"},
+			}},
+		},
+		{
+			name:    "rejects a wrong checksum",
+			in:      file("REDIS0011", nil, []byte("12345678")),
+			wantErr: ErrChecksum,
+		},
+		{
+			name:    "rejects a foreign version",
+			in:      file("REDIS0012", nil, nil),
+			wantErr: ErrChecksum,
+		},
+		{
+			name:    "rejects a file without magic",
+			in:      file("NOTREDIS1", nil, nil),
+			wantErr: ErrBadFormat,
+		},
+		{
+			name:    "rejects a truncated file",
+			in:      []byte("REDIS0011\xfe"),
+			wantErr: ErrBadFormat,
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := Read(bytes.NewReader(tc.in), now)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("expected error %v, got %v", tc.wantErr, err)
+			}
+			if err == nil && !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("expected %+v, got %+v", tc.want, got)
+			}
+		})
+	}
+}
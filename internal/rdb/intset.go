@@ -0,0 +1,66 @@
+package rdb
+
+import (
+	"encoding/binary"
+	"math"
+	"slices"
+	"strconv"
+)
+
+// appendIntset appends an intset holding vals, using the narrowest integer width that fits them.
+func appendIntset(b []byte, vals []int64) []byte {
+	vals = slices.Clone(vals)
+	slices.Sort(vals)
+	width := 2
+	for _, v := range vals {
+		switch {
+		case v < math.MinInt32 || v > math.MaxInt32:
+			width = 8
+		case (v < math.MinInt16 || v > math.MaxInt16) && width < 4:
+			width = 4
+		}
+	}
+	b = binary.LittleEndian.AppendUint32(b, uint32(width))
+	b = binary.LittleEndian.AppendUint32(b, uint32(len(vals)))
+	for _, v := range vals {
+		switch width {
+		case 2:
+			b = binary.LittleEndian.AppendUint16(b, uint16(v))
+		case 4:
+			b = binary.LittleEndian.AppendUint32(b, uint32(v))
+		default:
+			b = binary.LittleEndian.AppendUint64(b, uint64(v))
+		}
+	}
+	return b
+}
+
+// parseIntset returns the members of an intset blob as decimal strings.
+func parseIntset(p []byte) ([]string, bool) {
+	if len(p) < 8 {
+		return nil, false
+	}
+	width := int(binary.LittleEndian.Uint32(p))
+	n := int(binary.LittleEndian.Uint32(p[4:]))
+	if width != 2 && width != 4 && width != 8 {
+		return nil, false
+	}
+	p = p[8:]
+	if len(p) != n*width {
+		return nil, false
+	}
+	out := make([]string, n)
+	for i := range out {
+		var v int64
+		switch width {
+		case 2:
+			v = int64(int16(binary.LittleEndian.Uint16(p[i*2:])))
+		case 4:
+			v = int64(int32(binary.LittleEndian.Uint32(p[i*4:])))
+		default:
+			v = int64(binary.LittleEndian.Uint64(p[i*8:]))
+		}
+		out[i] = strconv.FormatInt(v, 10)
+	}
+	return out, true
+}
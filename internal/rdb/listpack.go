@@ -0,0 +1,189 @@
+package rdb
+
+import (
+	"encoding/binary"
+	"math"
+	"strconv"
+)
+
+const (
+	listpackHeaderSize = 6
+	listpackEnd        = 0xff
+)
+
+// appendListpack appends a listpack holding entries. Entries that are
+// canonical integers are stored with the integer encodings, as Valkey does.
+func appendListpack(b []byte, entries []string) []byte {
+	start := len(b)
+	b = append(b, make([]byte, listpackHeaderSize)...)
+	for _, e := range entries {
+		b = appendListpackEntry(b, e)
+	}
+	b = append(b, listpackEnd)
+	binary.LittleEndian.PutUint32(b[start:], uint32(len(b)-start))
+	binary.LittleEndian.PutUint16(b[start+4:], uint16(min(len(entries), math.MaxUint16)))
+	return b
+}
+
+// listpackEntrySize returns the number of bytes e takes in a listpack.
+func listpackEntrySize(e string) int {
+	return len(appendListpackEntry(nil, e))
+}
+
+// appendListpackEntry appends one element with its encoding and back length.
+func appendListpackEntry(b []byte, e string) []byte {
+	start := len(b)
+	if v, ok := parseInt(e); ok {
+		switch {
+		case v >= 0 && v <= 127:
+			b = append(b, byte(v))
+		case v >= -4096 && v <= 4095:
+			u := uint64(v) & 0x1fff
+			b = append(b, byte(u>>8)|0xc0, byte(u))
+		case v >= math.MinInt16 && v <= math.MaxInt16:
+			b = append(b, 0xf1)
+			b = binary.LittleEndian.AppendUint16(b, uint16(v))
+		case v >= -1<<23 && v < 1<<23:
+			b = append(b, 0xf2, byte(v), byte(v>>8), byte(v>>16))
+		case v >= math.MinInt32 && v <= math.MaxInt32:
+			b = append(b, 0xf3)
+			b = binary.LittleEndian.AppendUint32(b, uint32(v))
+		default:
+			b = append(b, 0xf4)
+			b = binary.LittleEndian.AppendUint64(b, uint64(v))
+		}
+	} else {
+		switch n := len(e); {
+		case n < 64:
+			b = append(b, 0x80|byte(n))
+		case n < 4096:
+			b = append(b, 0xe0|byte(n>>8), byte(n))
+		default:
+			b = append(b, 0xf0)
+			b = binary.LittleEndian.AppendUint32(b, uint32(n))
+		}
+		b = append(b, e...)
+	}
+	return appendBacklen(b, len(b)-start)
+}
+
+// appendBacklen appends the back length of an entry of l bytes, which lets
+// Valkey walk a listpack from its tail.
+func appendBacklen(b []byte, l int) []byte {
+	n := backlenSize(l)
+	b = append(b, byte(l>>(7*(n-1))))
+	for i := n - 2; i >= 0; i-- {
+		b = append(b, byte(l>>(7*i))&127|128)
+	}
+	return b
+}
+
+func backlenSize(l int) int {
+	switch {
+	case l <= 127:
+		return 1
+	case l < 16383:
+		return 2
+	case l < 2097151:
+		return 3
+	case l < 268435455:
+		return 4
+	default:
+		return 5
+	}
+}
+
+// parseListpack returns the elements of a listpack blob, with integers as decimal strings.
+func parseListpack(p []byte) ([]string, bool) {
+	if len(p) < listpackHeaderSize+1 || int(binary.LittleEndian.Uint32(p)) != len(p) {
+		return nil, false
+	}
+	count := int(binary.LittleEndian.Uint16(p[4:]))
+	var out []string
+	i := listpackHeaderSize
+	for {
+		if i >= len(p) {
+			return nil, false
+		}
+		if p[i] == listpackEnd {
+			break
+		}
+		e, size, ok := parseListpackEntry(p[i:])
+		if !ok {
+			return nil, false
+		}
+		i += size + backlenSize(size)
+		out = append(out, e)
+	}
+	if i != len(p)-1 || (count != math.MaxUint16 && count != len(out)) {
+		return nil, false
+	}
+	return out, true
+}
+
+// parseListpackEntry decodes the element at the start of p.
+// Returns the element and the size of its encoding and data, without the back length.
+func parseListpackEntry(p []byte) (string, int, bool) {
+	c := p[0]
+	var (
+		v    int64
+		size int
+	)
+	switch {
+	case c&0x80 == 0:
+		return strconv.Itoa(int(c)), 1, true
+	case c&0xc0 == 0x80:
+		return listpackString(p, 1, int(c&0x3f))
+	case c&0xe0 == 0xc0:
+		if len(p) < 2 {
+			return "", 0, false
+		}
+		u := int64(c&0x1f)<<8 | int64(p[1])
+		if u >= 1<<12 {
+			u -= 1 << 13
+		}
+		return strconv.FormatInt(u, 10), 2, true
+	case c&0xf0 == 0xe0:
+		if len(p) < 2 {
+			return "", 0, false
+		}
+		return listpackString(p, 2, int(c&0x0f)<<8|int(p[1]))
+	case c == 0xf0:
+		if len(p) < 5 {
+			return "", 0, false
+		}
+		return listpackString(p, 5, int(binary.LittleEndian.Uint32(p[1:])))
+	case c == 0xf1:
+		size = 3
+	case c == 0xf2:
+		size = 4
+	case c == 0xf3:
+		size = 5
+	case c == 0xf4:
+		size = 9
+	default:
+		return "", 0, false
+	}
+	if len(p) < size {
+		return "", 0, false
+	}
+	switch size {
+	case 3:
+		v = int64(int16(binary.LittleEndian.Uint16(p[1:])))
+	case 4:
+		v = int64(int32(uint32(p[1])|uint32(p[2])<<8|uint32(p[3])<<16) << 8 >> 8)
+	case 5:
+		v = int64(int32(binary.LittleEndian.Uint32(p[1:])))
+	default:
+		v = int64(binary.LittleEndian.Uint64(p[1:]))
+	}
+	return strconv.FormatInt(v, 10), size, true
+}
+
+// listpackString reads a string of n bytes following a header of hdr bytes.
+func listpackString(p []byte, hdr, n int) (string, int, bool) {
+	if len(p) < hdr+n {
+		return "", 0, false
+	}
+	return string(p[hdr : hdr+n]), hdr + n, true
+}
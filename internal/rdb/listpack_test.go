@@ -0,0 +1,96 @@
+package rdb
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestListpack(t *testing.T) {
+	t.Parallel()
+
+	tcs := []struct {
+		name    string
+		entries []string
+		want    []byte // nil skips the byte comparison
+	}{
+		{
+			name:    "small integers and strings",
+			entries: []string{"a", "1", "-1"},
+			want: []byte{
+				0x0f, 0x00, 0x00, 0x00, 0x03, 0x00,
+				0x81, 'a', 0x02,
+				0x01, 0x01,
+				0xdf, 0xff, 0x02,
+				0xff,
+			},
+		},
+		{
+			name:    "every integer width",
+			entries: []string{"127", "4095", "-4096", "32767", "-8388608", "2147483647", "-9223372036854775808"},
+		},
+		{
+			name:    "non canonical integers stay strings",
+			entries: []string{"01", "+1", " 1", "1.0"},
+		},
+		{
+			name:    "long strings",
+			entries: []string{strings.Repeat("x", 63), strings.Repeat("y", 64), strings.Repeat("z", 4096)},
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			b := appendListpack(nil, tc.entries)
+			if tc.want != nil && !slices.Equal(b, tc.want) {
+				t.Fatalf("unexpected encoding:\nwant % x\ngot  % x", tc.want, b)
+			}
+			got, ok := parseListpack(b)
+			if !ok {
+				t.Fatalf("parseListpack failed on % x", b)
+			}
+			if !slices.Equal(got, tc.entries) {
+				t.Fatalf("expected %q, got %q", tc.entries, got)
+			}
+		})
+	}
+}
+
+func TestParseListpack_Corrupt(t *testing.T) {
+	t.Parallel()
+
+	valid := appendListpack(nil, []string{"a", "b"})
+
+	tcs := []struct {
+		name string
+		in   []byte
+	}{
+		{name: "too short", in: []byte{0x07, 0x00, 0x00}},
+		{name: "wrong total size", in: append(slices.Clone(valid[:len(valid)-1]), 0x00, 0xff)},
+		{name: "missing terminator", in: func() []byte {
+			b := slices.Clone(valid)
+			b[len(b)-1] = 0x00
+			return b
+		}()},
+		{name: "wrong element count", in: func() []byte {
+			b := slices.Clone(valid)
+			b[4] = 3
+			return b
+		}()},
+		{name: "truncated string", in: []byte{0x09, 0x00, 0x00, 0x00, 0x01, 0x00, 0x85, 'a', 0xff}},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got, ok := parseListpack(tc.in); ok {
+				t.Fatalf("expected failure, got %q", got)
+			}
+		})
+	}
+}
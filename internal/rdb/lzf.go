@@ -0,0 +1,47 @@
+package rdb
+
+// lzfDecompress expands LZF data into exactly n bytes, as Valkey stores
+// compressed strings. Returns false if the data is malformed.
+func lzfDecompress(in []byte, n int) ([]byte, bool) {
+	out := make([]byte, 0, n)
+	for i := 0; i < len(in); {
+		ctrl := int(in[i])
+		i++
+		if ctrl < 1<<5 {
+			// Literal run of ctrl+1 bytes.
+			run := ctrl + 1
+			if i+run > len(in) || len(out)+run > n {
+				return nil, false
+			}
+			out = append(out, in[i:i+run]...)
+			i += run
+			continue
+		}
+		// Back reference: copy length+2 bytes from earlier output.
+		length := ctrl >> 5
+		if length == 7 {
+			if i >= len(in) {
+				return nil, false
+			}
+			length += int(in[i])
+			i++
+		}
+		if i >= len(in) {
+			return nil, false
+		}
+		ref := len(out) - (ctrl&0x1f)<<8 - 1 - int(in[i])
+		i++
+		length += 2
+		if ref < 0 || len(out)+length > n {
+			return nil, false
+		}
+		// The source may overlap what is being written, so copy byte by byte.
+		for j := 0; j < length; j++ {
+			out = append(out, out[ref+j])
+		}
+	}
+	if len(out) != n {
+		return nil, false
+	}
+	return out, true
+}
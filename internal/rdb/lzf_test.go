@@ -0,0 +1,40 @@
+package rdb
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLZFDecompress(t *testing.T) {
+	t.Parallel()
+
+	tcs := []struct {
+		name   string
+		in     []byte
+		n      int
+		want   string
+		wantOK bool
+	}{
+		{name: "literal run", in: []byte{0x02, 'a', 'b', 'c'}, n: 3, want: "abc", wantOK: true},
+		{name: "overlapping back reference", in: []byte{0x00, 'a', 0xe0, 0x0e, 0x00}, n: 24, want: strings.Repeat("a", 24), wantOK: true},
+		{name: "short back reference", in: []byte{0x01, 'a', 'b', 0x20, 0x01}, n: 5, want: "ababa", wantOK: true},
+		{name: "rejects reference before start", in: []byte{0x00, 'a', 0x20, 0x05}, n: 4},
+		{name: "rejects truncated literal", in: []byte{0x05, 'a'}, n: 6},
+		{name: "rejects length mismatch", in: []byte{0x02, 'a', 'b', 'c'}, n: 4},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, ok := lzfDecompress(tc.in, tc.n)
+			if ok != tc.wantOK {
+				t.Fatalf("expected ok=%v, got %v", tc.wantOK, ok)
+			}
+			if ok && string(got) != tc.want {
+				t.Fatalf("expected %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
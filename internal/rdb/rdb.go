@@ -0,0 +1,114 @@
+// Package rdb reads and writes values in Valkey's RDB serialization, as used
+// by DUMP and RESTORE, so payloads are interchangeable with a real server.
+package rdb
+
+import (
+	"errors"
+	"time"
+)
+
+// Version is the RDB version written in payload footers.
+// Valkey 7.2 and 8.x write 11, and every later release can read it.
+const Version = 11
+
+// versionFieldTTL is the version written instead of Version when a hash
+// carries field expirations, which only Valkey 9 and later can read.
+const versionFieldTTL = 80
+
+// Valkey 9 moved to version 80 and leaves 12 to 79 to Redis releases whose
+// formats it cannot read.
+const (
+	foreignVersionMin = 12
+	foreignVersionMax = 79
+	versionMax        = 80
+)
+
+// Object types as written before a serialized value.
+const (
+	typeString         = 0
+	typeList           = 1
+	typeSet            = 2
+	typeZSet           = 3
+	typeHash           = 4
+	typeZSet2          = 5
+	typeListZiplist    = 10
+	typeSetIntset      = 11
+	typeZSetZiplist    = 12
+	typeHashZiplist    = 13
+	typeListQuicklist  = 14
+	typeHashListpack   = 16
+	typeZSetListpack   = 17
+	typeListQuicklist2 = 18
+	typeSetListpack    = 20
+	typeHash2          = 22 // hash with field expirations, since Valkey 9
+)
+
+// Quicklist node containers in RDB_TYPE_LIST_QUICKLIST_2.
+const (
+	quicklistNodePlain  = 1
+	quicklistNodePacked = 2
+)
+
+var (
+	// ErrChecksum indicates a payload whose version footer or checksum is invalid.
+	ErrChecksum = errors.New("rdb: payload version or checksum are wrong")
+	// ErrBadFormat indicates a payload that cannot be decoded into a value.
+	ErrBadFormat = errors.New("rdb: bad data format")
+)
+
+// Kind identifies the type of a Value.
+type Kind int
+
+const (
+	KindString Kind = iota
+	KindList
+	KindSet
+	KindZSet
+	KindHash
+)
+
+// ZMember is a sorted set member with its score.
+type ZMember struct {
+	Member string
+	Score  float64
+}
+
+// HashField is a hash field with its value.
+type HashField struct {
+	Field string
+	Value string
+	// ExpireAt is the field expiration (zero => none).
+	ExpireAt time.Time
+}
+
+// Value is a decoded key value. Only the field matching Kind is used.
+type Value struct {
+	Kind Kind
+	// Encoding is the in-memory encoding as OBJECT ENCODING reports it. It
+	// picks the matching compact RDB type when writing and is ignored otherwise.
+	Encoding string
+
+	String string
+	List   []string
+	Set    []string
+	ZSet   []ZMember // ordered by score, then member
+	Hash   []HashField
+}
+
+// version returns the RDB version a payload holding v needs.
+func (v Value) version() uint16 {
+	if v.hasFieldTTL() {
+		return versionFieldTTL
+	}
+	return Version
+}
+
+// hasFieldTTL reports whether v is a hash with at least one expiring field.
+func (v Value) hasFieldTTL() bool {
+	for _, f := range v.Hash {
+		if !f.ExpireAt.IsZero() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,107 @@
+package rdb
+
+import (
+	"encoding/binary"
+	"strconv"
+)
+
+const (
+	ziplistHeaderSize = 10
+	ziplistEnd        = 0xff
+)
+
+// parseZiplist returns the elements of a ziplist blob, the compact encoding
+// RDB files written before Valkey 7.0 use.
+func parseZiplist(p []byte) ([]string, bool) {
+	if len(p) < ziplistHeaderSize+1 || int(binary.LittleEndian.Uint32(p)) != len(p) {
+		return nil, false
+	}
+	var out []string
+	i := ziplistHeaderSize
+	for {
+		if i >= len(p) {
+			return nil, false
+		}
+		if p[i] == ziplistEnd {
+			break
+		}
+		// Skip the length of the previous entry.
+		if p[i] == 0xfe {
+			i += 5
+		} else {
+			i++
+		}
+		if i >= len(p) {
+			return nil, false
+		}
+		e, size, ok := parseZiplistEntry(p[i:])
+		if !ok {
+			return nil, false
+		}
+		i += size
+		out = append(out, e)
+	}
+	if i != len(p)-1 {
+		return nil, false
+	}
+	return out, true
+}
+
+// parseZiplistEntry decodes the element whose encoding starts p.
+// Returns the element and the size of its encoding and data.
+func parseZiplistEntry(p []byte) (string, int, bool) {
+	c := p[0]
+	switch c >> 6 {
+	case 0:
+		return listpackString(p, 1, int(c&0x3f))
+	case 1:
+		if len(p) < 2 {
+			return "", 0, false
+		}
+		return listpackString(p, 2, int(c&0x3f)<<8|int(p[1]))
+	case 2:
+		if len(p) < 5 {
+			return "", 0, false
+		}
+		return listpackString(p, 5, int(binary.BigEndian.Uint32(p[1:])))
+	}
+
+	var (
+		v    int64
+		size int
+	)
+	switch c {
+	case 0xc0:
+		size = 3
+	case 0xd0:
+		size = 5
+	case 0xe0:
+		size = 9
+	case 0xf0:
+		size = 4
+	case 0xfe:
+		size = 2
+	default:
+		// 1111xxxx holds an immediate value from 0 to 12 as xxxx-1.
+		if c < 0xf1 || c > 0xfd {
+			return "", 0, false
+		}
+		return strconv.Itoa(int(c&0x0f) - 1), 1, true
+	}
+	if len(p) < size {
+		return "", 0, false
+	}
+	switch c {
+	case 0xc0:
+		v = int64(int16(binary.LittleEndian.Uint16(p[1:])))
+	case 0xd0:
+		v = int64(int32(binary.LittleEndian.Uint32(p[1:])))
+	case 0xe0:
+		v = int64(binary.LittleEndian.Uint64(p[1:]))
+	case 0xf0:
+		v = int64(int32(uint32(p[1])|uint32(p[2])<<8|uint32(p[3])<<16) << 8 >> 8)
+	case 0xfe:
+		v = int64(int8(p[1]))
+	}
+	return strconv.FormatInt(v, 10), size, true
+}
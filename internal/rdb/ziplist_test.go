@@ -0,0 +1,69 @@
+package rdb
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseZiplist(t *testing.T) {
+	t.Parallel()
+
+	tcs := []struct {
+		name   string
+		in     []byte
+		want   []string
+		wantOK bool
+	}{
+		{
+			name: "strings and integers",
+			in: []byte{
+				0x14, 0x00, 0x00, 0x00, 0x0f, 0x00, 0x00, 0x00, 0x03, 0x00,
+				0x00, 0x01, 'a',
+				0x03, 0xf6,
+				0x02, 0xc0, 0x2c, 0x01,
+				0xff,
+			},
+			want:   []string{"a", "5", "300"},
+			wantOK: true,
+		},
+		{
+			name: "int8 and int24",
+			in: []byte{
+				0x13, 0x00, 0x00, 0x00, 0x0d, 0x00, 0x00, 0x00, 0x02, 0x00,
+				0x00, 0xfe, 0x80,
+				0x03, 0xf0, 0x00, 0x00, 0x80,
+				0xff,
+			},
+			want:   []string{"-128", "-8388608"},
+			wantOK: true,
+		},
+		{
+			name:   "empty",
+			in:     []byte{0x0b, 0x00, 0x00, 0x00, 0x0a, 0x00, 0x00, 0x00, 0x00, 0x00, 0xff},
+			wantOK: true,
+		},
+		{
+			name: "rejects truncated entry",
+			in:   []byte{0x0e, 0x00, 0x00, 0x00, 0x0a, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x05, 'a', 0xff},
+		},
+		{
+			name: "rejects unknown encoding",
+			in:   []byte{0x0d, 0x00, 0x00, 0x00, 0x0a, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0xc1, 0xff},
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, ok := parseZiplist(tc.in)
+			if ok != tc.wantOK {
+				t.Fatalf("expected ok=%v, got %v", tc.wantOK, ok)
+			}
+			if ok && !slices.Equal(got, tc.want) {
+				t.Fatalf("expected %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
@@ -1,7 +1,13 @@
 package resp
 
+import (
+	"math"
+)
+
+// ParseInt parses a base-10 signed integer.
+// Returns false if b is empty, contains non-digits, or overflows int64.
 func ParseInt(b []byte) (int64, bool) {
-	var n int64
+	var n uint64
 	var neg bool
 	if len(b) == 0 {
 		return 0, false
@@ -14,10 +20,19 @@ func ParseInt(b []byte) (int64, bool) {
 		if c < '0' || c > '9' {
 			return 0, false
 		}
-		n = n*10 + int64(c-'0')
+		if n > (math.MaxUint64-9)/10 {
+			return 0, false
+		}
+		n = n*10 + uint64(c-'0')
 	}
 	if neg {
-		n = -n
+		if len(b) == 1 || n > math.MaxInt64+1 {
+			return 0, false
+		}
+		return -int64(n), true
+	}
+	if n > math.MaxInt64 {
+		return 0, false
 	}
-	return n, true
+	return int64(n), true
 }
@@ -54,6 +54,22 @@ func TestParseInt(t *testing.T) {
 			input:  []byte(""),
 			wantOK: false,
 		},
+		{
+			name:   "min int64",
+			input:  []byte("-9223372036854775808"),
+			want:   -9223372036854775808,
+			wantOK: true,
+		},
+		{
+			name:   "overflow",
+			input:  []byte("9223372036854775808"),
+			wantOK: false,
+		},
+		{
+			name:   "lone minus",
+			input:  []byte("-"),
+			wantOK: false,
+		},
 		{
 			name:   "plus sign unsupported",
 			input:  []byte("+1"),
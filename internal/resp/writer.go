@@ -2,12 +2,15 @@ package resp
 
 import (
 	"bufio"
+	"bytes"
 	"strconv"
 )
 
 // Writer provides RESP2 write helpers over a buffered writer.
 type Writer struct {
-	w *bufio.Writer
+	w       *bufio.Writer
+	errors  int
+	lastErr string
 }
 
 // NewWriter wraps the provided bufio.Writer.
@@ -28,6 +31,8 @@ func (w *Writer) WriteString(s string) error {
 
 // WriteErrorString writes a RESP2 error ("-...").
 func (w *Writer) WriteErrorString(msg string) error {
+	w.errors++
+	w.lastErr = msg
 	_, err := w.w.WriteString("-" + msg + "\r\n")
 	return err
 }
@@ -44,6 +49,16 @@ func (w *Writer) WriteErrorAndFlush(err error) error {
 	return w.Flush()
 }
 
+// Errors returns how many error replies have been written so far.
+func (w *Writer) Errors() int {
+	return w.errors
+}
+
+// LastError returns the message of the last error reply written, if any.
+func (w *Writer) LastError() string {
+	return w.lastErr
+}
+
 // WriteInt writes a RESP2 integer (":...").
 func (w *Writer) WriteInt(n int64) error {
 	_, err := w.w.WriteString(":" + strconv.FormatInt(n, 10) + "\r\n")
@@ -105,3 +120,53 @@ func (w *Writer) WriteNull() error {
 	_, err := w.w.WriteString("$-1\r\n")
 	return err
 }
+
+// WriteNullArray writes a RESP2 null array ("*-1").
+func (w *Writer) WriteNullArray() error {
+	_, err := w.w.WriteString("*-1\r\n")
+	return err
+}
+
+// Buffer is a Writer that keeps its output in memory, even when flushed,
+// until it is copied to another Writer.
+type Buffer struct {
+	*Writer
+	buf bytes.Buffer
+}
+
+// NewBuffer returns an empty Buffer.
+func NewBuffer() *Buffer {
+	b := &Buffer{}
+	b.Writer = NewWriter(bufio.NewWriter(&b.buf))
+	return b
+}
+
+// CopyTo writes what b holds to w without flushing it, and counts the error
+// replies among it as written by w.
+func (b *Buffer) CopyTo(w *Writer) error {
+	if err := b.Flush(); err != nil {
+		return err
+	}
+	if b.errors > 0 {
+		w.errors += b.errors
+		w.lastErr = b.lastErr
+	}
+	_, err := w.w.Write(b.buf.Bytes())
+	return err
+}
+
+// AppendArrayBulk appends args as a RESP2 array of bulk strings, the form
+// commands are sent in.
+func AppendArrayBulk(b []byte, args Args) []byte {
+	b = append(b, '*')
+	b = strconv.AppendInt(b, int64(len(args)), 10)
+	b = append(b, "\r\n"...)
+	for _, a := range args {
+		b = append(b, '$')
+		b = strconv.AppendInt(b, int64(len(a)), 10)
+		b = append(b, "\r\n"...)
+		b = append(b, a...)
+		b = append(b, "\r\n"...)
+	}
+	return b
+}
@@ -84,6 +84,13 @@ func TestWriter_WriteCommands(t *testing.T) {
 			},
 			want: "*0\r\n$-1\r\n",
 		},
+		{
+			name: "null array",
+			write: func(w *resp.Writer) error {
+				return w.WriteNullArray()
+			},
+			want: "*-1\r\n",
+		},
 	}
 
 	for _, tc := range tcs {
@@ -107,3 +114,75 @@ func TestWriter_WriteCommands(t *testing.T) {
 		})
 	}
 }
+
+func TestWriter_Errors(t *testing.T) {
+	t.Parallel()
+
+	w := resp.NewWriter(bufio.NewWriter(new(bytes.Buffer)))
+	_ = w.WriteString("OK")
+	if got := w.Errors(); got != 0 {
+		t.Fatalf("expected 0 errors, got %d", got)
+	}
+	_ = w.WriteErrorString("ERR one")
+	_ = w.WriteErrorAndFlush(errors.New("ERR two"))
+	if got := w.Errors(); got != 2 {
+		t.Fatalf("expected 2 errors, got %d", got)
+	}
+	if got := w.LastError(); got != "ERR two" {
+		t.Fatalf("expected last error %q, got %q", "ERR two", got)
+	}
+}
+
+func TestBuffer_CopyTo(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	w := resp.NewWriter(bufio.NewWriter(&out))
+	_ = w.WriteErrorString("ERR before")
+
+	b := resp.NewBuffer()
+	_ = b.WriteString("OK")
+	_ = b.WriteErrorAndFlush(errors.New("ERR buffered"))
+	if out.Len() != 0 {
+		t.Fatalf("expected nothing written before CopyTo, got %q", out.String())
+	}
+	if err := b.CopyTo(w); err != nil {
+		t.Fatalf("CopyTo returned error: %v", err)
+	}
+	if got := w.Errors(); got != 2 {
+		t.Fatalf("expected 2 errors, got %d", got)
+	}
+	if got := w.LastError(); got != "ERR buffered" {
+		t.Fatalf("expected last error %q, got %q", "ERR buffered", got)
+	}
+	if out.Len() != 0 {
+		t.Fatalf("expected CopyTo not to flush, got %q", out.String())
+	}
+	_ = w.Flush()
+	if want := "-ERR before\r\n+OK\r\n-ERR buffered\r\n"; out.String() != want {
+		t.Fatalf("unexpected output:\nwant %q\ngot  %q", want, out.String())
+	}
+
+	// A buffer without errors keeps the last error of w.
+	ok := resp.NewBuffer()
+	_ = ok.WriteString("OK")
+	_ = ok.CopyTo(w)
+	if got := w.LastError(); got != "ERR buffered" {
+		t.Fatalf("expected last error %q, got %q", "ERR buffered", got)
+	}
+}
+
+func TestAppendArrayBulk(t *testing.T) {
+	t.Parallel()
+
+	got := resp.AppendArrayBulk([]byte("x"), resp.Args{[]byte("SET"), []byte("k"), []byte("")})
+	want := "x*3\r\n$3\r\nSET\r\n$1\r\nk\r\n$0\r\n\r\n"
+	if string(got) != want {
+		t.Fatalf("unexpected payload:\nwant %q\ngot  %q", want, got)
+	}
+
+	args, err := resp.NewReader(bufio.NewReader(bytes.NewReader(got[1:]))).ReadArrayBulk()
+	if err != nil || len(args) != 3 || string(args[0]) != "SET" {
+		t.Fatalf("expected the command to read back, got %q err=%v", args, err)
+	}
+}
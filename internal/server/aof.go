@@ -0,0 +1,514 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mickamy/minivalkey/internal/rdb"
+	"github.com/mickamy/minivalkey/internal/resp"
+	"github.com/mickamy/minivalkey/internal/session"
+)
+
+// AppendFsync policies, named after Valkey's appendfsync values.
+const (
+	AppendFsyncAlways   = "always"
+	AppendFsyncEverySec = "everysec"
+	AppendFsyncNo       = "no"
+)
+
+// aof appends write commands to the append-only file.
+type aof struct {
+	// gate is held shared while a write command runs and is logged, and
+	// exclusively while a rewrite captures the dataset, so every command
+	// lands either in the snapshot or after it.
+	gate sync.RWMutex
+
+	mu    sync.Mutex
+	fsync string
+	f     *os.File
+	db    int // database the file last selected; -1 before any SELECT
+	done  chan struct{}
+
+	rewriting  bool
+	rewriteBuf []byte
+	rewriteDB  int
+}
+
+// aofRewrite turns an executed write command into the commands logged for it,
+// or nil when this invocation did not write.
+type aofRewrite func(now time.Time, args resp.Args) []resp.Args
+
+// aofRewrites lists the commands that can change the dataset.
+var aofRewrites = map[string]aofRewrite{
+	"APPEND":            aofAsIs,
+	"BITFIELD":          aofBitField,
+	"BITOP":             aofAsIs,
+	"COPY":              aofAsIs,
+	"DECR":              aofAsIs,
+	"DECRBY":            aofAsIs,
+	"DEL":               aofAsIs,
+	"EXPIRE":            aofExpire(time.Second, false),
+	"EXPIREAT":          aofExpire(time.Second, true),
+	"GEOADD":            aofAsIs,
+	"GEORADIUS":         aofIfStore,
+	"GEORADIUSBYMEMBER": aofIfStore,
+	"GEOSEARCHSTORE":    aofAsIs,
+	"GETDEL":            aofAsIs,
+	"GETEX":             aofGetEx,
+	"GETSET":            aofAsIs,
+	"HEXPIRE":           aofHExpire(time.Second, false),
+	"HEXPIREAT":         aofHExpire(time.Second, true),
+	"HGETEX":            aofHGetEx,
+	"HPERSIST":          aofAsIs,
+	"HPEXPIRE":          aofHExpire(time.Millisecond, false),
+	"HPEXPIREAT":        aofHExpire(time.Millisecond, true),
+	"HSETEX":            aofHSetEx,
+	"INCR":              aofAsIs,
+	"INCRBY":            aofAsIs,
+	"INCRBYFLOAT":       aofAsIs,
+	"MSET":              aofAsIs,
+	"MSETNX":            aofAsIs,
+	"PERSIST":           aofAsIs,
+	"PEXPIRE":           aofExpire(time.Millisecond, false),
+	"PEXPIREAT":         aofExpire(time.Millisecond, true),
+	"PFADD":             aofAsIs,
+	"PFMERGE":           aofAsIs,
+	"PSETEX":            aofSetEx(time.Millisecond),
+	"RENAME":            aofAsIs,
+	"RENAMENX":          aofAsIs,
+	"RESTORE":           aofRestore,
+	"SET":               aofSet,
+	"SETBIT":            aofAsIs,
+	"SETEX":             aofSetEx(time.Second),
+	"SETNX":             aofAsIs,
+	"SETRANGE":          aofAsIs,
+	"SORT":              aofIfStore,
+	"UNLINK":            aofAsIs,
+}
+
+func aofAsIs(_ time.Time, args resp.Args) []resp.Args {
+	return []resp.Args{args}
+}
+
+// aofIfStore logs commands that only write when given a STORE option.
+func aofIfStore(_ time.Time, args resp.Args) []resp.Args {
+	for _, a := range args[1:] {
+		if opt := strings.ToUpper(string(a)); opt == "STORE" || opt == "STOREDIST" {
+			return []resp.Args{args}
+		}
+	}
+	return nil
+}
+
+// aofBitField logs BITFIELD only when it has a SET or INCRBY operation.
+func aofBitField(_ time.Time, args resp.Args) []resp.Args {
+	for _, a := range args[2:] {
+		if op := strings.ToUpper(string(a)); op == "SET" || op == "INCRBY" {
+			return []resp.Args{args}
+		}
+	}
+	return nil
+}
+
+// aofExpireAt converts an expiration argument to absolute unix milliseconds.
+func aofExpireAt(now time.Time, arg resp.Arg, unit time.Duration, absolute bool) []byte {
+	n, _ := resp.ParseInt(arg)
+	at, _ := expireAtFrom(now, n, unit, absolute)
+	return []byte(strconv.FormatInt(at.UnixMilli(), 10))
+}
+
+// aofExpire rewrites EXPIRE and friends as PEXPIREAT.
+func aofExpire(unit time.Duration, absolute bool) aofRewrite {
+	return func(now time.Time, args resp.Args) []resp.Args {
+		out := resp.Args{[]byte("PEXPIREAT"), args[1], aofExpireAt(now, args[2], unit, absolute)}
+		return []resp.Args{append(out, args[3:]...)}
+	}
+}
+
+// aofHExpire rewrites HEXPIRE and friends as HPEXPIREAT.
+func aofHExpire(unit time.Duration, absolute bool) aofRewrite {
+	return func(now time.Time, args resp.Args) []resp.Args {
+		out := resp.Args{[]byte("HPEXPIREAT"), args[1], aofExpireAt(now, args[2], unit, absolute)}
+		return []resp.Args{append(out, args[3:]...)}
+	}
+}
+
+// aofSetEx rewrites SETEX and PSETEX as SET with an absolute PXAT.
+func aofSetEx(unit time.Duration) aofRewrite {
+	return func(now time.Time, args resp.Args) []resp.Args {
+		return []resp.Args{{[]byte("SET"), args[1], args[3], []byte("PXAT"), aofExpireAt(now, args[2], unit, false)}}
+	}
+}
+
+// aofRewriteExpireOptions replaces EX, PX and EXAT options from args[from:]
+// on with an absolute PXAT, leaving the other arguments untouched.
+// Returns the rewritten arguments and the expiration option that was found.
+func aofRewriteExpireOptions(now time.Time, args resp.Args, from int) (resp.Args, string) {
+	out := slices.Clone(args[:from])
+	found := ""
+	for i := from; i < len(args); i++ {
+		opt := strings.ToUpper(string(args[i]))
+		switch opt {
+		case "EX", "PX", "EXAT", "PXAT":
+			if i+1 >= len(args) {
+				out = append(out, args[i])
+				continue
+			}
+			unit := time.Second
+			if opt == "PX" || opt == "PXAT" {
+				unit = time.Millisecond
+			}
+			out = append(out, []byte("PXAT"), aofExpireAt(now, args[i+1], unit, opt == "EXAT" || opt == "PXAT"))
+			found = opt
+			i++
+		case "PERSIST":
+			out = append(out, args[i])
+			found = opt
+		case "FIELDS":
+			// Field names could look like options, so copy the rest verbatim.
+			return append(out, args[i:]...), found
+		default:
+			out = append(out, args[i])
+		}
+	}
+	return out, found
+}
+
+func aofSet(now time.Time, args resp.Args) []resp.Args {
+	out, _ := aofRewriteExpireOptions(now, args, 3)
+	return []resp.Args{out}
+}
+
+// aofGetEx logs GETEX as the PEXPIREAT or PERSIST it applied, if any.
+func aofGetEx(now time.Time, args resp.Args) []resp.Args {
+	out, found := aofRewriteExpireOptions(now, args, 2)
+	switch found {
+	case "":
+		return nil
+	case "PERSIST":
+		return []resp.Args{{[]byte("PERSIST"), args[1]}}
+	default:
+		return []resp.Args{{[]byte("PEXPIREAT"), args[1], out[3]}}
+	}
+}
+
+func aofHSetEx(now time.Time, args resp.Args) []resp.Args {
+	out, _ := aofRewriteExpireOptions(now, args, 2)
+	return []resp.Args{out}
+}
+
+// aofHGetEx logs HGETEX only when it changed field expirations.
+func aofHGetEx(now time.Time, args resp.Args) []resp.Args {
+	out, found := aofRewriteExpireOptions(now, args, 2)
+	if found == "" {
+		return nil
+	}
+	return []resp.Args{out}
+}
+
+// aofRestore makes a relative RESTORE ttl absolute.
+func aofRestore(now time.Time, args resp.Args) []resp.Args {
+	ttl, _ := resp.ParseInt(args[2])
+	if ttl == 0 {
+		return []resp.Args{args}
+	}
+	for _, a := range args[4:] {
+		if strings.ToUpper(string(a)) == "ABSTTL" {
+			return []resp.Args{args}
+		}
+	}
+	out := slices.Clone(args)
+	out[2] = aofExpireAt(now, args[2], time.Millisecond, false)
+	return []resp.Args{append(out, []byte("ABSTTL"))}
+}
+
+// appendCommands appends the commands to b, preceded by a SELECT when the
+// database differs from *cur.
+func appendCommands(b []byte, cur *int, dbIdx int, cmds []resp.Args) []byte {
+	if *cur != dbIdx {
+		b = resp.AppendArrayBulk(b, resp.Args{[]byte("SELECT"), []byte(strconv.Itoa(dbIdx))})
+		*cur = dbIdx
+	}
+	for _, c := range cmds {
+		b = resp.AppendArrayBulk(b, c)
+	}
+	return b
+}
+
+// execute runs handle and, when it changed the dataset, appends the command to the AOF.
+func (s *Server) execute(handle handleFunc, w *resp.Writer, r *request) error {
+	rewrite, isWrite := aofRewrites[r.cmd.String()]
+	if s.aof == nil || !isWrite {
+		return handle(w, r)
+	}
+
+	s.aof.gate.RLock()
+	defer s.aof.gate.RUnlock()
+
+	now := s.Now()
+	dbIdx := r.session.SelectedDB
+	errs := w.Errors()
+	if err := handle(w, r); err != nil {
+		return err
+	}
+	if w.Errors() > errs {
+		return nil
+	}
+	if cmds := rewrite(now, r.args); len(cmds) > 0 {
+		if err := s.aof.append(dbIdx, cmds); err != nil {
+			s.logger.Error("failed to append to AOF", "err", err)
+		}
+	}
+	return nil
+}
+
+// append writes cmds to the file, and to the rewrite buffer while a rewrite runs.
+func (a *aof) append(dbIdx int, cmds []resp.Args) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.rewriting {
+		a.rewriteBuf = appendCommands(a.rewriteBuf, &a.rewriteDB, dbIdx, cmds)
+	}
+	if a.f == nil {
+		return nil
+	}
+	if _, err := a.f.Write(appendCommands(nil, &a.db, dbIdx, cmds)); err != nil {
+		return err
+	}
+	if a.fsync == AppendFsyncAlways {
+		return a.f.Sync()
+	}
+	return nil
+}
+
+// OpenAOF replays the append-only file and starts appending write commands
+// to it. A missing file is created from the current dataset, so whatever was
+// loaded before survives the next restart.
+func (s *Server) OpenAOF() error {
+	_, err := os.Stat(s.appendFilename)
+	exists := err == nil
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if exists {
+		if err := s.loadAOF(s.appendFilename); err != nil {
+			return err
+		}
+	} else if err := s.rewriteAOF(); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(s.appendFilename, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+	a := &aof{fsync: s.appendFsync, f: f, db: -1, done: make(chan struct{})}
+	s.aof = a
+
+	if a.fsync == AppendFsyncEverySec {
+		go func() {
+			ticker := time.NewTicker(time.Second)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-a.done:
+					return
+				case <-ticker.C:
+					a.mu.Lock()
+					if a.f != nil {
+						_ = a.f.Sync()
+					}
+					a.mu.Unlock()
+				}
+			}
+		}()
+	}
+	return nil
+}
+
+// closeAOF flushes the append-only file to disk and closes it.
+func (s *Server) closeAOF() error {
+	if s.aof == nil {
+		return nil
+	}
+	a := s.aof
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.f == nil {
+		return nil
+	}
+	close(a.done)
+	err := errors.Join(a.f.Sync(), a.f.Close())
+	a.f = nil
+	return err
+}
+
+// countingReader counts the bytes read through it.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// loadAOF replays filename: an optional RDB preamble, then commands.
+// A command cut short at the end of the file, as left by a crash, is
+// dropped and the file truncated to the last complete command.
+func (s *Server) loadAOF(filename string) error {
+	f, err := os.Open(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	cr := &countingReader{r: f}
+	br := bufio.NewReader(cr)
+	if magic, _ := br.Peek(6); bytes.HasPrefix(magic, []byte("REDIS")) || bytes.HasPrefix(magic, []byte("VALKEY")) {
+		dbs, err := rdb.Read(br, s.Now())
+		if err != nil {
+			return fmt.Errorf("aof: failed to load RDB preamble: %w", err)
+		}
+		if err := s.loadDatabases(s.Now(), dbs); err != nil {
+			return err
+		}
+	}
+
+	sess := session.New()
+	w := resp.NewWriter(bufio.NewWriter(io.Discard))
+	r := resp.NewReader(br)
+	for {
+		good := cr.n - int64(br.Buffered())
+		args, err := r.ReadArrayBulk()
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			if size := cr.n; good < size {
+				s.logger.Warn("truncating AOF with an incomplete command at its end", "offset", good)
+				return os.Truncate(filename, good)
+			}
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("aof: bad command at offset %d: %w", good, err)
+		}
+		if len(args) == 0 {
+			continue
+		}
+		cmd := args.Cmd()
+		handle, ok := s.handlers[cmd.String()]
+		if !ok {
+			return fmt.Errorf("aof: unknown command %q at offset %d", cmd.String(), good)
+		}
+		if err := handle(w, newRequest(sess, cmd, args)); err != nil {
+			return err
+		}
+	}
+}
+
+// rewriteAOF compacts the append-only file: the current dataset is written
+// as an RDB preamble, followed by the commands that ran meanwhile.
+func (s *Server) rewriteAOF() error {
+	filename := s.appendFilename
+	a := s.aof
+	var (
+		dbs []rdb.Database
+		now time.Time
+	)
+	if a != nil {
+		a.gate.Lock()
+		now = s.Now()
+		dbs = s.rdbDatabases(now)
+		a.mu.Lock()
+		a.rewriting, a.rewriteBuf, a.rewriteDB = true, nil, -1
+		a.mu.Unlock()
+		a.gate.Unlock()
+	} else {
+		now = s.Now()
+		dbs = s.rdbDatabases(now)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(filename), "temp-rewriteaof-*.aof")
+	if err != nil {
+		s.abortRewrite()
+		return err
+	}
+	defer func() {
+		_ = os.Remove(tmp.Name())
+	}()
+
+	bw := bufio.NewWriter(tmp)
+	err = rdb.Write(bw, dbs, now)
+	if err == nil {
+		err = bw.Flush()
+	}
+	if err != nil {
+		_ = tmp.Close()
+		s.abortRewrite()
+		return err
+	}
+
+	if a == nil {
+		if err := tmp.Sync(); err != nil {
+			_ = tmp.Close()
+			return err
+		}
+		if err := tmp.Close(); err != nil {
+			return err
+		}
+		return os.Rename(tmp.Name(), filename)
+	}
+
+	// Swap files while holding the lock so no command is lost in between.
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.rewriting = false
+	if _, err := tmp.Write(a.rewriteBuf); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := os.Rename(tmp.Name(), filename); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if a.f != nil {
+		_ = a.f.Close()
+		a.f, a.db = tmp, a.rewriteDB
+	} else {
+		_ = tmp.Close()
+	}
+	a.rewriteBuf = nil
+	return nil
+}
+
+// abortRewrite stops buffering commands for a rewrite that failed.
+func (s *Server) abortRewrite() {
+	if s.aof == nil {
+		return
+	}
+	s.aof.mu.Lock()
+	s.aof.rewriting, s.aof.rewriteBuf = false, nil
+	s.aof.mu.Unlock()
+}
@@ -0,0 +1,337 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mickamy/minivalkey/internal/clock"
+	"github.com/mickamy/minivalkey/internal/resp"
+	"github.com/mickamy/minivalkey/internal/session"
+)
+
+func TestAOFRewrites(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+
+	tcs := []struct {
+		name string
+		args []string
+		want [][]string
+	}{
+		{
+			name: "logs plain writes as-is",
+			args: []string{"DEL", "a", "b"},
+			want: [][]string{{"DEL", "a", "b"}},
+		},
+		{
+			name: "makes EXPIRE absolute",
+			args: []string{"expire", "k", "10", "NX"},
+			want: [][]string{{"PEXPIREAT", "k", "1010000", "NX"}},
+		},
+		{
+			name: "makes EXPIREAT milliseconds",
+			args: []string{"EXPIREAT", "k", "2000"},
+			want: [][]string{{"PEXPIREAT", "k", "2000000"}},
+		},
+		{
+			name: "rewrites SETEX as SET PXAT",
+			args: []string{"SETEX", "k", "5", "v"},
+			want: [][]string{{"SET", "k", "v", "PXAT", "1005000"}},
+		},
+		{
+			name: "rewrites SET EX as PXAT",
+			args: []string{"SET", "k", "v", "NX", "ex", "5"},
+			want: [][]string{{"SET", "k", "v", "NX", "PXAT", "1005000"}},
+		},
+		{
+			name: "keeps SET KEEPTTL",
+			args: []string{"SET", "k", "v", "KEEPTTL"},
+			want: [][]string{{"SET", "k", "v", "KEEPTTL"}},
+		},
+		{
+			name: "skips GETEX without options",
+			args: []string{"GETEX", "k"},
+		},
+		{
+			name: "rewrites GETEX PX as PEXPIREAT",
+			args: []string{"GETEX", "k", "PX", "1500"},
+			want: [][]string{{"PEXPIREAT", "k", "1001500"}},
+		},
+		{
+			name: "rewrites GETEX PERSIST as PERSIST",
+			args: []string{"GETEX", "k", "PERSIST"},
+			want: [][]string{{"PERSIST", "k"}},
+		},
+		{
+			name: "makes HEXPIRE absolute",
+			args: []string{"HEXPIRE", "h", "10", "FIELDS", "1", "f"},
+			want: [][]string{{"HPEXPIREAT", "h", "1010000", "FIELDS", "1", "f"}},
+		},
+		{
+			name: "leaves HSETEX field names alone",
+			args: []string{"HSETEX", "h", "EX", "10", "FIELDS", "1", "EX", "v"},
+			want: [][]string{{"HSETEX", "h", "PXAT", "1010000", "FIELDS", "1", "EX", "v"}},
+		},
+		{
+			name: "skips HGETEX without options",
+			args: []string{"HGETEX", "h", "FIELDS", "1", "EX"},
+		},
+		{
+			name: "makes RESTORE ttl absolute",
+			args: []string{"RESTORE", "k", "500", "payload", "REPLACE"},
+			want: [][]string{{"RESTORE", "k", "1000500", "payload", "REPLACE", "ABSTTL"}},
+		},
+		{
+			name: "keeps RESTORE without ttl",
+			args: []string{"RESTORE", "k", "0", "payload"},
+			want: [][]string{{"RESTORE", "k", "0", "payload"}},
+		},
+		{
+			name: "skips SORT without STORE",
+			args: []string{"SORT", "k", "ALPHA"},
+		},
+		{
+			name: "logs SORT with STORE",
+			args: []string{"SORT", "k", "STORE", "dst"},
+			want: [][]string{{"SORT", "k", "STORE", "dst"}},
+		},
+		{
+			name: "skips read-only BITFIELD",
+			args: []string{"BITFIELD", "k", "GET", "u8", "0"},
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			args := make(resp.Args, len(tc.args))
+			for i, a := range tc.args {
+				args[i] = []byte(a)
+			}
+			rewrite := aofRewrites[strings.ToUpper(tc.args[0])]
+			var got [][]string
+			for _, c := range rewrite(now, args) {
+				var cmd []string
+				for _, a := range c {
+					cmd = append(cmd, string(a))
+				}
+				got = append(got, cmd)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("expected %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+// newAOFServer returns a server appending to filename, with its file replayed.
+func newAOFServer(t *testing.T, filename string, now time.Time) *Server {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen failed: %v", err)
+	}
+	srv, err := New(ln, Options{AppendFilename: filename, AppendFsync: AppendFsyncAlways})
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+	srv.clock = clock.New(now)
+	if err := srv.OpenAOF(); err != nil {
+		t.Fatalf("OpenAOF returned error: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = srv.Close()
+	})
+	return srv
+}
+
+// runCommand executes a command the way a client connection does and returns the reply.
+func runCommand(t *testing.T, srv *Server, sess *session.Session, args ...string) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+	w := resp.NewWriter(bufio.NewWriter(&buf))
+	in := make(resp.Args, len(args))
+	for i, a := range args {
+		in[i] = []byte(a)
+	}
+	cmd := in.Cmd()
+	if err := srv.execute(srv.handlers[cmd.String()], w, newRequest(sess, cmd, in)); err != nil {
+		t.Fatalf("%s returned error: %v", args[0], err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+	return buf.String()
+}
+
+func TestServer_OpenAOF(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+	filename := filepath.Join(t.TempDir(), "appendonly.aof")
+
+	srv := newAOFServer(t, filename, now)
+	sess := session.New()
+	runCommand(t, srv, sess, "SET", "a", "1")
+	runCommand(t, srv, sess, "INCR", "a")
+	runCommand(t, srv, sess, "INCR", "a", "extra") // rejected, so not logged
+	runCommand(t, srv, sess, "SETEX", "ttl", "100", "v")
+	runCommand(t, srv, sess, "SELECT", "2")
+	runCommand(t, srv, sess, "SET", "b", "2")
+	runCommand(t, srv, sess, "GET", "b")
+	if err := srv.Close(); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+
+	got, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("failed to read aof: %v", err)
+	}
+	if !bytes.HasPrefix(got, []byte("REDIS0011")) {
+		t.Fatalf("expected an RDB preamble, got %q", got)
+	}
+	wantTail := "*2\r\n$6\r\nSELECT\r\n$1\r\n0\r\n" +
+		"*3\r\n$3\r\nSET\r\n$1\r\na\r\n$1\r\n1\r\n" +
+		"*2\r\n$4\r\nINCR\r\n$1\r\na\r\n" +
+		"*5\r\n$3\r\nSET\r\n$3\r\nttl\r\n$1\r\nv\r\n$4\r\nPXAT\r\n$7\r\n1100000\r\n" +
+		"*2\r\n$6\r\nSELECT\r\n$1\r\n2\r\n" +
+		"*3\r\n$3\r\nSET\r\n$1\r\nb\r\n$1\r\n2\r\n"
+	if !bytes.HasSuffix(got, []byte(wantTail)) {
+		t.Fatalf("unexpected commands:\nwant suffix %q\ngot         %q", wantTail, got)
+	}
+
+	// Replay later: the absolute expiration keeps counting down.
+	replayed := newAOFServer(t, filename, now.Add(40*time.Second))
+	if v, _ := replayed.dbAt(0).GetString(replayed.Now(), "a"); v != "2" {
+		t.Fatalf("expected a=2, got %q", v)
+	}
+	if ttl := replayed.dbAt(0).TTL(replayed.Now(), "ttl"); ttl != 60 {
+		t.Fatalf("expected ttl 60, got %d", ttl)
+	}
+	if v, _ := replayed.dbAt(2).GetString(replayed.Now(), "b"); v != "2" {
+		t.Fatalf("expected b=2 in db 2, got %q", v)
+	}
+}
+
+func TestServer_OpenAOF_TruncatedTail(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+	filename := filepath.Join(t.TempDir(), "appendonly.aof")
+	complete := "*3\r\n$3\r\nSET\r\n$1\r\na\r\n$1\r\n1\r\n"
+	if err := os.WriteFile(filename, []byte(complete+"*3\r\n$3\r\nSET\r\n$1\r\nb"), 0o644); err != nil {
+		t.Fatalf("failed to write aof: %v", err)
+	}
+
+	srv := newAOFServer(t, filename, now)
+	if v, _ := srv.dbAt(0).GetString(now, "a"); v != "1" {
+		t.Fatalf("expected a=1, got %q", v)
+	}
+	if srv.dbAt(0).Exists(now, "b") != 0 {
+		t.Fatalf("expected the incomplete command to be dropped")
+	}
+	got, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("failed to read aof: %v", err)
+	}
+	if string(got) != complete {
+		t.Fatalf("expected file truncated to %q, got %q", complete, got)
+	}
+}
+
+func TestServer_OpenAOF_UnknownCommand(t *testing.T) {
+	t.Parallel()
+
+	filename := filepath.Join(t.TempDir(), "appendonly.aof")
+	if err := os.WriteFile(filename, []byte("*1\r\n$4\r\nNOPE\r\n"), 0o644); err != nil {
+		t.Fatalf("failed to write aof: %v", err)
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen failed: %v", err)
+	}
+	srv, err := New(ln, Options{AppendFilename: filename})
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+	defer func() {
+		_ = srv.Close()
+	}()
+	if err := srv.OpenAOF(); err == nil {
+		t.Fatalf("expected an error for an unknown command")
+	}
+}
+
+func TestServer_rewriteAOF(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+	filename := filepath.Join(t.TempDir(), "appendonly.aof")
+
+	srv := newAOFServer(t, filename, now)
+	sess := session.New()
+	for i := 0; i < 100; i++ {
+		runCommand(t, srv, sess, "INCR", "n")
+	}
+	runCommand(t, srv, sess, "HSETEX", "h", "EX", "30", "FIELDS", "2", "f", "1", "g", "2")
+	runCommand(t, srv, sess, "HPERSIST", "h", "FIELDS", "1", "g")
+	before, err := os.Stat(filename)
+	if err != nil {
+		t.Fatalf("stat failed: %v", err)
+	}
+
+	if err := srv.rewriteAOF(); err != nil {
+		t.Fatalf("rewriteAOF returned error: %v", err)
+	}
+	runCommand(t, srv, sess, "SELECT", "1")
+	runCommand(t, srv, sess, "SET", "after", "rewrite")
+	if err := srv.Close(); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+
+	after, err := os.Stat(filename)
+	if err != nil {
+		t.Fatalf("stat failed: %v", err)
+	}
+	if after.Size() >= before.Size() {
+		t.Fatalf("expected rewrite to shrink the file from %d bytes, got %d", before.Size(), after.Size())
+	}
+
+	replayed := newAOFServer(t, filename, now.Add(10*time.Second))
+	rnow := replayed.Now()
+	if v, _ := replayed.dbAt(0).GetString(rnow, "n"); v != "100" {
+		t.Fatalf("expected n=100, got %q", v)
+	}
+	ttls, err := replayed.dbAt(0).HPTTL(rnow, "h", "f", "g")
+	if err != nil {
+		t.Fatalf("HPTTL returned error: %v", err)
+	}
+	if want := []int64{20_000, -1}; !reflect.DeepEqual(ttls, want) {
+		t.Fatalf("expected field ttls %v, got %v", want, ttls)
+	}
+	if v, _ := replayed.dbAt(1).GetString(rnow, "after"); v != "rewrite" {
+		t.Fatalf("expected after=rewrite in db 1, got %q", v)
+	}
+}
+
+func TestServer_loadAOF_Missing(t *testing.T) {
+	t.Parallel()
+
+	srv := &Server{clock: clock.New(time.Unix(1_000, 0))}
+	if err := srv.loadAOF(filepath.Join(t.TempDir(), "missing.aof")); err != nil {
+		t.Fatalf("expected a missing file to be ignored, got %v", err)
+	}
+}
@@ -0,0 +1,115 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+
+	"github.com/mickamy/minivalkey/internal/resp"
+)
+
+// TruncateRule cuts the reply to matching commands after Bytes bytes and then
+// closes the connection. The command itself still runs.
+type TruncateRule struct {
+	CommandRule
+	Bytes int
+}
+
+// AddTruncation registers rule and returns an ID to remove it with.
+func (s *Server) AddTruncation(rule TruncateRule) int {
+	return s.truncations.add(rule.CommandRule, max(rule.Bytes, 0))
+}
+
+// RemoveTruncation unregisters the truncation rule with the given ID, if it is still registered.
+func (s *Server) RemoveTruncation(id int) {
+	s.truncations.remove(id)
+}
+
+// ClearTruncations unregisters every truncation rule.
+func (s *Server) ClearTruncations() {
+	s.truncations.clear()
+}
+
+// truncateAt returns how many bytes of the reply to r to send before closing
+// the connection, per the first truncation rule it matches.
+func (s *Server) truncateAt(r *request) (int, bool) {
+	if s.truncations.empty() {
+		return 0, false
+	}
+	return s.truncations.match(r, commandKeys(r.args), &s.random)
+}
+
+// truncatedWriter buffers a reply so only part of it reaches the client.
+type truncatedWriter struct {
+	*resp.Writer
+	buf bytes.Buffer
+	n   int
+}
+
+func newTruncatedWriter(n int) *truncatedWriter {
+	tw := &truncatedWriter{n: n}
+	tw.Writer = resp.NewWriter(bufio.NewWriter(&tw.buf))
+	return tw
+}
+
+// send flushes what w holds, then the first n bytes of the buffered reply, to c.
+func (tw *truncatedWriter) send(c net.Conn, w *resp.Writer) error {
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	reply := tw.buf.Bytes()
+	_, err := c.Write(reply[:min(tw.n, len(reply))])
+	return err
+}
+
+// DropConnections makes the server close the next n connections as soon as
+// it accepts them, on top of any drops still pending.
+func (s *Server) DropConnections(n int) {
+	s.dropConns.Add(int64(n))
+}
+
+// dropConn reports whether a newly accepted connection should be closed, counting it if so.
+func (s *Server) dropConn() bool {
+	for {
+		n := s.dropConns.Load()
+		if n <= 0 {
+			return false
+		}
+		if s.dropConns.CompareAndSwap(n, n-1) {
+			return true
+		}
+	}
+}
+
+// CloseClients closes the open connections match selects, or all of them
+// when match is nil, and returns how many it closed.
+func (s *Server) CloseClients(match func(ClientInfo) bool) int {
+	return s.closeClients(match, false)
+}
+
+// closeClients closes the connections match selects. With reset, TCP
+// connections are aborted with a RST instead of being shut down cleanly.
+func (s *Server) closeClients(match func(ClientInfo) bool, reset bool) int {
+	closed := 0
+	for _, info := range s.Clients() {
+		if match != nil && !match(info) {
+			continue
+		}
+		s.clients.mu.Lock()
+		c, ok := s.clients.byID[info.ID]
+		s.clients.mu.Unlock()
+		if !ok {
+			continue
+		}
+		if tc, isTCP := c.conn.(*net.TCPConn); reset && isTCP {
+			_ = tc.SetLinger(0)
+		}
+		if c.conn.Close() == nil {
+			closed++
+		}
+	}
+	return closed
+}
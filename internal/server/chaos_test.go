@@ -0,0 +1,102 @@
+package server
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestServer_Truncation(t *testing.T) {
+	t.Parallel()
+
+	srv := startTestServer(t)
+	srv.AddTruncation(TruncateRule{CommandRule: CommandRule{Commands: []string{"GET"}, DB: -1, Times: 1}, Bytes: 4})
+
+	conn := dialTestServer(t, srv)
+	pipeline := "*3\r\n$3\r\nSET\r\n$1\r\nk\r\n$5\r\nhello\r\n" +
+		"*2\r\n$3\r\nGET\r\n$1\r\nk\r\n" +
+		"*1\r\n$4\r\nPING\r\n"
+	if _, err := conn.Write([]byte(pipeline)); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	got, err := io.ReadAll(conn)
+	if err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+	if want := "+OK\r\n$5\r\n"; string(got) != want {
+		t.Fatalf("unexpected bytes:\nwant %q\ngot  %q", want, got)
+	}
+
+	// The command still ran, and the rule is used up.
+	conn = dialTestServer(t, srv)
+	if _, err := conn.Write([]byte("*2\r\n$3\r\nGET\r\n$1\r\nk\r\n")); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	r := bufio.NewReader(conn)
+	for _, want := range []string{"$5\r\n", "hello\r\n"} {
+		if line, err := r.ReadString('\n'); err != nil || line != want {
+			t.Fatalf("expected %q, got %q err=%v", want, line, err)
+		}
+	}
+}
+
+func TestServer_DropConnections(t *testing.T) {
+	t.Parallel()
+
+	srv := startTestServer(t)
+	srv.DropConnections(1)
+
+	ping := func() error {
+		conn := dialTestServer(t, srv)
+		if _, err := conn.Write([]byte("*1\r\n$4\r\nPING\r\n")); err != nil {
+			return err
+		}
+		_, err := bufio.NewReader(conn).ReadString('\n')
+		return err
+	}
+	if err := ping(); err == nil {
+		t.Fatalf("expected the first connection to be dropped")
+	}
+	if err := ping(); err != nil {
+		t.Fatalf("expected the second connection to be served, got %v", err)
+	}
+}
+
+func TestServer_CloseClients(t *testing.T) {
+	t.Parallel()
+
+	srv := startTestServer(t)
+
+	named := dialTestServer(t, srv)
+	if _, err := named.Write([]byte("*3\r\n$6\r\nCLIENT\r\n$7\r\nSETNAME\r\n$6\r\nworker\r\n")); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	other := dialTestServer(t, srv)
+	if _, err := other.Write([]byte("*1\r\n$4\r\nPING\r\n")); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	namedR, otherR := bufio.NewReader(named), bufio.NewReader(other)
+	for _, r := range []*bufio.Reader{namedR, otherR} {
+		if _, err := r.ReadString('\n'); err != nil {
+			t.Fatalf("read failed: %v", err)
+		}
+	}
+
+	if n := srv.CloseClients(func(c ClientInfo) bool { return c.Name == "worker" }); n != 1 {
+		t.Fatalf("expected 1 closed client, got %d", n)
+	}
+	if _, err := namedR.ReadString('\n'); !errors.Is(err, io.EOF) {
+		t.Fatalf("expected the named client to be closed, got %v", err)
+	}
+	if _, err := other.Write([]byte("*1\r\n$4\r\nPING\r\n")); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	if line, err := otherR.ReadString('\n'); err != nil || line != "+PONG\r\n" {
+		t.Fatalf("expected the other client to be served, got %q err=%v", line, err)
+	}
+
+	if n := srv.CloseClients(nil); n != 1 {
+		t.Fatalf("expected 1 closed client, got %d", n)
+	}
+}
@@ -0,0 +1,69 @@
+package server
+
+import (
+	"cmp"
+	"net"
+	"slices"
+	"sync"
+)
+
+// ClientInfo describes an open client connection.
+type ClientInfo struct {
+	ID          int64
+	Addr        string
+	Name        string // as set by CLIENT SETNAME
+	LastCommand string
+}
+
+// client tracks one connection while it is open.
+type client struct {
+	conn net.Conn
+
+	mu      sync.Mutex
+	name    string
+	lastCmd string
+}
+
+// clients registers the open connections.
+type clients struct {
+	mu   sync.Mutex
+	byID map[int64]*client
+}
+
+func (cs *clients) add(id int64, c *client) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	if cs.byID == nil {
+		cs.byID = make(map[int64]*client)
+	}
+	cs.byID[id] = c
+}
+
+func (cs *clients) remove(id int64) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	delete(cs.byID, id)
+}
+
+// record notes the command a client just ran and its current name.
+func (c *client) record(cmd, name string) {
+	c.mu.Lock()
+	c.lastCmd, c.name = cmd, name
+	c.mu.Unlock()
+}
+
+// Clients returns the open client connections, ordered by ID.
+func (s *Server) Clients() []ClientInfo {
+	s.clients.mu.Lock()
+	out := make([]ClientInfo, 0, len(s.clients.byID))
+	for id, c := range s.clients.byID {
+		c.mu.Lock()
+		out = append(out, ClientInfo{ID: id, Addr: c.conn.RemoteAddr().String(), Name: c.name, LastCommand: c.lastCmd})
+		c.mu.Unlock()
+	}
+	s.clients.mu.Unlock()
+
+	slices.SortFunc(out, func(a, b ClientInfo) int { return cmp.Compare(a.ID, b.ID) })
+	return out
+}
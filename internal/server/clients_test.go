@@ -0,0 +1,60 @@
+package server
+
+import (
+	"bufio"
+	"log/slog"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestServer_Clients(t *testing.T) {
+	t.Parallel()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen failed: %v", err)
+	}
+	srv, err := New(ln, Options{Logger: slog.New(slog.DiscardHandler)})
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+	go srv.Serve()
+	t.Cleanup(func() {
+		_ = srv.Close()
+	})
+
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	r := bufio.NewReader(conn)
+	for _, cmd := range []string{
+		"*3\r\n$6\r\nCLIENT\r\n$7\r\nSETNAME\r\n$3\r\napp\r\n",
+		"*1\r\n$4\r\nPING\r\n",
+	} {
+		if _, err := conn.Write([]byte(cmd)); err != nil {
+			t.Fatalf("write failed: %v", err)
+		}
+		if _, err := r.ReadString('\n'); err != nil {
+			t.Fatalf("read failed: %v", err)
+		}
+	}
+
+	clients := srv.Clients()
+	if len(clients) != 1 {
+		t.Fatalf("expected 1 client, got %+v", clients)
+	}
+	if c := clients[0]; c.Name != "app" || c.LastCommand != "PING" || c.Addr != conn.LocalAddr().String() {
+		t.Fatalf("unexpected client %+v", c)
+	}
+
+	_ = conn.Close()
+	deadline := time.Now().Add(5 * time.Second)
+	for len(srv.Clients()) > 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the closed connection to be removed")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,35 @@
+package server
+
+import (
+	"fmt"
+
+	"github.com/mickamy/minivalkey/internal/cluster"
+	"github.com/mickamy/minivalkey/internal/resp"
+)
+
+// routeCluster returns a handler replying with MOVED or CROSSSLOT when r
+// cannot run on this cluster node, or handle itself when it can.
+func (s *Server) routeCluster(handle handleFunc, r *request) handleFunc {
+	if s.cluster == nil {
+		return handle
+	}
+	keys := commandKeys(r.args)
+	if len(keys) == 0 {
+		return handle
+	}
+
+	slot := cluster.KeySlot(keys[0])
+	for _, k := range keys[1:] {
+		if cluster.KeySlot(k) != slot {
+			return func(w *resp.Writer, r *request) error {
+				return w.WriteErrorAndFlush(ErrCrossSlot)
+			}
+		}
+	}
+	if owner := s.cluster.Owner(slot); owner != s.myself {
+		return func(w *resp.Writer, r *request) error {
+			return w.WriteErrorAndFlush(fmt.Errorf("MOVED %d %s", slot, owner.Addr()))
+		}
+	}
+	return handle
+}
@@ -0,0 +1,123 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/mickamy/minivalkey/internal/clock"
+	"github.com/mickamy/minivalkey/internal/cluster"
+	"github.com/mickamy/minivalkey/internal/db"
+	"github.com/mickamy/minivalkey/internal/resp"
+	"github.com/mickamy/minivalkey/internal/session"
+)
+
+// newClusterNode returns the first node of a three-node cluster, which owns slots 0-5460.
+func newClusterNode(t *testing.T) *Server {
+	t.Helper()
+
+	topo, err := cluster.New([]string{"127.0.0.1:7000", "127.0.0.1:7001", "127.0.0.1:7002"})
+	if err != nil {
+		t.Fatalf("cluster.New returned error: %v", err)
+	}
+	return &Server{
+		dbMap:   map[int]*db.DB{0: db.New()},
+		clock:   clock.New(time.Unix(1_000, 0)),
+		cluster: topo,
+		myself:  topo.Nodes[0],
+	}
+}
+
+func TestServer_routeCluster(t *testing.T) {
+	t.Parallel()
+
+	// Slots: "bar" 5061 (node 0), "foo" 12182 (node 2), "{bar}x" 5061 (node 0).
+	tcs := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{name: "runs keys this node owns", args: []string{"GET", "bar"}, want: "$-1\r\n"},
+		{name: "runs keyless commands", args: []string{"PING"}, want: "+PONG\r\n"},
+		{name: "moves keys another node owns", args: []string{"GET", "foo"}, want: "-MOVED 12182 127.0.0.1:7002\r\n"},
+		{name: "runs keys sharing a hash tag", args: []string{"MGET", "bar", "{bar}x"}, want: "*2\r\n$-1\r\n$-1\r\n"},
+		{name: "rejects keys across slots", args: []string{"MGET", "bar", "foo"}, want: "-CROSSSLOT Keys in request don't hash to the same slot\r\n"},
+		{name: "rejects a sort destination in another slot", args: []string{"SORT", "bar", "STORE", "foo"}, want: "-CROSSSLOT Keys in request don't hash to the same slot\r\n"},
+		{name: "rejects a geo destination in another slot", args: []string{"GEORADIUS", "bar", "0", "0", "1", "km", "STORE", "foo"}, want: "-CROSSSLOT Keys in request don't hash to the same slot\r\n"},
+		{name: "runs a sort destination sharing a hash tag", args: []string{"SORT", "bar", "STORE", "{bar}x"}, want: ":0\r\n"},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := newClusterNode(t)
+			args := testArgs(tc.args...)
+			handlers := map[string]handleFunc{"GET": srv.cmdGet, "MGET": srv.cmdMGet, "PING": srv.cmdPing, "SORT": srv.cmdSort}
+			req := newRequest(session.New(), args.Cmd(), args)
+
+			var buf bytes.Buffer
+			w := resp.NewWriter(bufio.NewWriter(&buf))
+			if err := srv.routeCluster(handlers[args.Cmd().String()], req)(w, req); err != nil {
+				t.Fatalf("handler returned error: %v", err)
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("flush failed: %v", err)
+			}
+			if got := buf.String(); got != tc.want {
+				t.Fatalf("unexpected payload:\nwant %q\ngot  %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestServer_cmdSelectInCluster(t *testing.T) {
+	t.Parallel()
+
+	srv := newClusterNode(t)
+	var buf bytes.Buffer
+	w := resp.NewWriter(bufio.NewWriter(&buf))
+	args := testArgs("SELECT", "1")
+	if err := srv.cmdSelect(w, newRequest(session.New(), args.Cmd(), args)); err != nil {
+		t.Fatalf("cmdSelect returned error: %v", err)
+	}
+	if got, want := buf.String(), "-"+ErrSelectInCluster.Error()+"\r\n"; got != want {
+		t.Fatalf("unexpected payload:\nwant %q\ngot  %q", want, got)
+	}
+}
+
+func TestServer_cmdCopyInCluster(t *testing.T) {
+	t.Parallel()
+
+	tcs := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{name: "copies within database 0", args: []string{"COPY", "bar", "{bar}x", "DB", "0"}, want: ":0\r\n"},
+		{name: "rejects another database", args: []string{"COPY", "bar", "{bar}x", "DB", "1"}, want: "-" + ErrCopyInCluster.Error() + "\r\n"},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := newClusterNode(t)
+			var buf bytes.Buffer
+			w := resp.NewWriter(bufio.NewWriter(&buf))
+			args := testArgs(tc.args...)
+			if err := srv.cmdCopy(w, newRequest(session.New(), args.Cmd(), args)); err != nil {
+				t.Fatalf("cmdCopy returned error: %v", err)
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("flush failed: %v", err)
+			}
+			if got := buf.String(); got != tc.want {
+				t.Fatalf("unexpected payload:\nwant %q\ngot  %q", tc.want, got)
+			}
+		})
+	}
+}
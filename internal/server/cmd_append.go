@@ -0,0 +1,21 @@
+package server
+
+import (
+	"github.com/mickamy/minivalkey/internal/resp"
+)
+
+func (s *Server) cmdAppend(w *resp.Writer, r *request) error {
+	if err := validateCommand(r.cmd, r.args, validateArgCountExact(3)); err != nil {
+		return w.WriteErrorAndFlush(err)
+	}
+	key := string(r.args[1])
+	n, err := s.db(r.session).Append(s.Now(), key, string(r.args[2]))
+	if err != nil {
+		return w.WriteErrorAndFlush(dbError(err))
+	}
+	if err := w.WriteInt(int64(n)); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,86 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/mickamy/minivalkey/internal/clock"
+	"github.com/mickamy/minivalkey/internal/db"
+	"github.com/mickamy/minivalkey/internal/resp"
+	"github.com/mickamy/minivalkey/internal/session"
+)
+
+func TestServer_cmdAppend(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+
+	tcs := []struct {
+		name    string
+		args    resp.Args
+		arrange func(*db.DB)
+		want    string
+	}{
+		{
+			name: "appends to existing value",
+			args: resp.Args{
+				[]byte("append"),
+				[]byte("foo"),
+				[]byte("bar"),
+			},
+			arrange: func(db *db.DB) {
+				db.SetString("foo", "hello ", time.Time{})
+			},
+			want: ":9\r\n",
+		},
+		{
+			name: "creates missing key",
+			args: resp.Args{
+				[]byte("append"),
+				[]byte("foo"),
+				[]byte("bar"),
+			},
+			want: ":3\r\n",
+		},
+		{
+			name: "complains when value is missing",
+			args: resp.Args{
+				[]byte("append"),
+				[]byte("foo"),
+			},
+			want: "-ERR wrong number of arguments for 'append' command\r\n",
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			d := db.New()
+			if tc.arrange != nil {
+				tc.arrange(d)
+			}
+			srv := &Server{
+				dbMap: map[int]*db.DB{0: d},
+				clock: clock.New(now),
+			}
+
+			buf := new(bytes.Buffer)
+			w := resp.NewWriter(bufio.NewWriter(buf))
+			req := newRequest(session.New(), "APPEND", tc.args)
+
+			if err := srv.cmdAppend(w, req); err != nil {
+				t.Fatalf("cmdAppend returned error: %v", err)
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("flush failed: %v", err)
+			}
+			if got := buf.String(); got != tc.want {
+				t.Fatalf("unexpected payload:\nwant %q\ngot  %q", tc.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package server
+
+import (
+	"github.com/mickamy/minivalkey/internal/resp"
+)
+
+func (s *Server) cmdBgRewriteAOF(w *resp.Writer, r *request) error {
+	if err := validateCommand(r.cmd, r.args, validateArgCountExact(1)); err != nil {
+		return w.WriteErrorAndFlush(err)
+	}
+	if !s.startBgRewriteAOF() {
+		return w.WriteErrorAndFlush(ErrAOFRewriteInProgress)
+	}
+
+	if err := w.WriteString("Background append only file rewriting started"); err != nil {
+		return err
+	}
+
+	return nil
+}
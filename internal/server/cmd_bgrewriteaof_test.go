@@ -0,0 +1,92 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mickamy/minivalkey/internal/clock"
+	"github.com/mickamy/minivalkey/internal/db"
+	"github.com/mickamy/minivalkey/internal/resp"
+	"github.com/mickamy/minivalkey/internal/session"
+)
+
+func TestServer_cmdBgRewriteAOF(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+
+	tcs := []struct {
+		name      string
+		args      resp.Args
+		rewriting bool
+		wantFile  bool
+		want      string
+	}{
+		{
+			name:     "starts a background rewrite",
+			args:     resp.Args{[]byte("bgrewriteaof")},
+			wantFile: true,
+			want:     "+Background append only file rewriting started\r\n",
+		},
+		{
+			name:      "refuses while a rewrite runs",
+			args:      resp.Args{[]byte("bgrewriteaof")},
+			rewriting: true,
+			want:      "-ERR Background append only file rewriting already in progress\r\n",
+		},
+		{
+			name: "complains about wrong number of arguments",
+			args: resp.Args{[]byte("bgrewriteaof"), []byte("now")},
+			want: "-ERR wrong number of arguments for 'bgrewriteaof' command\r\n",
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			filename := filepath.Join(t.TempDir(), "appendonly.aof")
+			d := db.New()
+			d.SetString("foo", "bar", time.Time{})
+			srv := &Server{
+				dbMap:          map[int]*db.DB{0: d},
+				clock:          clock.New(now),
+				appendFilename: filename,
+			}
+			srv.persistence.aofRewriting = tc.rewriting
+
+			buf := new(bytes.Buffer)
+			w := resp.NewWriter(bufio.NewWriter(buf))
+			req := newRequest(session.New(), "BGREWRITEAOF", tc.args)
+
+			if err := srv.cmdBgRewriteAOF(w, req); err != nil {
+				t.Fatalf("cmdBgRewriteAOF returned error: %v", err)
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("flush failed: %v", err)
+			}
+			if got := buf.String(); got != tc.want {
+				t.Fatalf("unexpected payload:\nwant %q\ngot  %q", tc.want, got)
+			}
+
+			if !tc.wantFile {
+				return
+			}
+			deadline := time.Now().Add(5 * time.Second)
+			for srv.aofRewriting() {
+				if time.Now().After(deadline) {
+					t.Fatalf("background rewrite did not finish")
+				}
+				time.Sleep(time.Millisecond)
+			}
+			if _, err := os.Stat(filename); err != nil {
+				t.Fatalf("expected aof file: %v", err)
+			}
+		})
+	}
+}
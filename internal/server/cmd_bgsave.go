@@ -0,0 +1,27 @@
+package server
+
+import (
+	"strings"
+
+	"github.com/mickamy/minivalkey/internal/resp"
+)
+
+func (s *Server) cmdBgSave(w *resp.Writer, r *request) error {
+	// BGSAVE [SCHEDULE]
+	if err := validateCommand(r.cmd, r.args, validateArgCountAtMost(2)); err != nil {
+		return w.WriteErrorAndFlush(err)
+	}
+	// SCHEDULE only matters while another kind of child process runs, which never happens here.
+	if len(r.args) == 2 && strings.ToUpper(string(r.args[1])) != "SCHEDULE" {
+		return w.WriteErrorAndFlush(ErrSyntax)
+	}
+	if !s.startBgSave() {
+		return w.WriteErrorAndFlush(ErrBgSaveInProgress)
+	}
+
+	if err := w.WriteString("Background saving started"); err != nil {
+		return err
+	}
+
+	return nil
+}
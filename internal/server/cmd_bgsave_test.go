@@ -0,0 +1,103 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mickamy/minivalkey/internal/clock"
+	"github.com/mickamy/minivalkey/internal/db"
+	"github.com/mickamy/minivalkey/internal/resp"
+	"github.com/mickamy/minivalkey/internal/session"
+)
+
+func TestServer_cmdBgSave(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+
+	tcs := []struct {
+		name     string
+		args     resp.Args
+		bgSaving bool
+		wantFile bool
+		want     string
+	}{
+		{
+			name:     "starts a background save",
+			args:     resp.Args{[]byte("bgsave")},
+			wantFile: true,
+			want:     "+Background saving started\r\n",
+		},
+		{
+			name:     "accepts SCHEDULE",
+			args:     resp.Args{[]byte("bgsave"), []byte("schedule")},
+			wantFile: true,
+			want:     "+Background saving started\r\n",
+		},
+		{
+			name:     "refuses while a background save runs",
+			args:     resp.Args{[]byte("bgsave")},
+			bgSaving: true,
+			want:     "-ERR Background save already in progress\r\n",
+		},
+		{
+			name: "rejects an unknown option",
+			args: resp.Args{[]byte("bgsave"), []byte("now")},
+			want: "-ERR syntax error\r\n",
+		},
+		{
+			name: "complains about wrong number of arguments",
+			args: resp.Args{[]byte("bgsave"), []byte("schedule"), []byte("now")},
+			want: "-ERR wrong number of arguments for 'bgsave' command\r\n",
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			filename := filepath.Join(t.TempDir(), "dump.rdb")
+			d := db.New()
+			d.SetString("foo", "bar", time.Time{})
+			srv := &Server{
+				dbMap:      map[int]*db.DB{0: d},
+				clock:      clock.New(now),
+				dbFilename: filename,
+			}
+			srv.persistence.bgSaving = tc.bgSaving
+
+			buf := new(bytes.Buffer)
+			w := resp.NewWriter(bufio.NewWriter(buf))
+			req := newRequest(session.New(), "BGSAVE", tc.args)
+
+			if err := srv.cmdBgSave(w, req); err != nil {
+				t.Fatalf("cmdBgSave returned error: %v", err)
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("flush failed: %v", err)
+			}
+			if got := buf.String(); got != tc.want {
+				t.Fatalf("unexpected payload:\nwant %q\ngot  %q", tc.want, got)
+			}
+
+			if !tc.wantFile {
+				return
+			}
+			deadline := time.Now().Add(5 * time.Second)
+			for srv.bgSaving() {
+				if time.Now().After(deadline) {
+					t.Fatalf("background save did not finish")
+				}
+				time.Sleep(time.Millisecond)
+			}
+			if _, err := os.Stat(filename); err != nil {
+				t.Fatalf("expected rdb file: %v", err)
+			}
+		})
+	}
+}
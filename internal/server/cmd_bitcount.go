@@ -0,0 +1,99 @@
+package server
+
+import (
+	"math/bits"
+	"strings"
+
+	"github.com/mickamy/minivalkey/internal/resp"
+)
+
+func (s *Server) cmdBitCount(w *resp.Writer, r *request) error {
+	// BITCOUNT key [start end [BYTE | BIT]]
+	if err := validateCommand(r.cmd, r.args, validateArgCountAtLeast(2)); err != nil {
+		return w.WriteErrorAndFlush(err)
+	}
+	if n := len(r.args); n != 2 && n != 4 && n != 5 {
+		return w.WriteErrorAndFlush(ErrSyntax)
+	}
+	key := string(r.args[1])
+	start, end := int64(0), int64(-1)
+	isBit := false
+	if len(r.args) > 2 {
+		var ok bool
+		if start, ok = resp.ParseInt(r.args[2]); !ok {
+			return w.WriteErrorAndFlush(ErrValueNotInteger)
+		}
+		if end, ok = resp.ParseInt(r.args[3]); !ok {
+			return w.WriteErrorAndFlush(ErrValueNotInteger)
+		}
+		if len(r.args) == 5 {
+			var err error
+			if isBit, err = parseBitUnit(r.args[4]); err != nil {
+				return w.WriteErrorAndFlush(err)
+			}
+		}
+	}
+
+	v, _, err := s.db(r.session).LookupString(s.Now(), key)
+	if err != nil {
+		return w.WriteErrorAndFlush(dbError(err))
+	}
+	count := 0
+	if first, last, ok := bitRange(int64(len(v)), start, end, isBit); ok {
+		for i := first >> 3; i <= last>>3; i++ {
+			b := v[i]
+			if i == first>>3 {
+				b &= 0xff >> (first & 7)
+			}
+			if i == last>>3 {
+				b &= 0xff << (7 - last&7)
+			}
+			count += bits.OnesCount8(b)
+		}
+	}
+	if err := w.WriteInt(int64(count)); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// parseBitUnit parses the BYTE | BIT range unit of BITCOUNT and BITPOS.
+func parseBitUnit(a resp.Arg) (bool, error) {
+	switch strings.ToUpper(string(a)) {
+	case "BYTE":
+		return false, nil
+	case "BIT":
+		return true, nil
+	default:
+		return false, ErrSyntax
+	}
+}
+
+// bitRange resolves a BITCOUNT/BITPOS range over a string of n bytes into an
+// inclusive range of bit offsets, resolving negative indexes from the end the
+// way Valkey does. Returns false when the range is empty.
+func bitRange(n, start, end int64, isBit bool) (int64, int64, bool) {
+	total := n
+	if isBit {
+		total *= 8
+	}
+	if start < 0 {
+		start += total
+	}
+	if end < 0 {
+		end += total
+	}
+	start = max(start, 0)
+	end = max(end, 0)
+	if end >= total {
+		end = total - 1
+	}
+	if start > end {
+		return 0, 0, false
+	}
+	if isBit {
+		return start, end, true
+	}
+	return start * 8, end*8 + 7, true
+}
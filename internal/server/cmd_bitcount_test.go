@@ -0,0 +1,184 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/mickamy/minivalkey/internal/clock"
+	"github.com/mickamy/minivalkey/internal/db"
+	"github.com/mickamy/minivalkey/internal/resp"
+	"github.com/mickamy/minivalkey/internal/session"
+)
+
+func TestServer_cmdBitCount(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+
+	tcs := []struct {
+		name    string
+		args    resp.Args
+		arrange func(*db.DB)
+		want    string
+	}{
+		{
+			name: "counts whole string",
+			args: resp.Args{
+				[]byte("bitcount"),
+				[]byte("foo"),
+			},
+			arrange: func(d *db.DB) {
+				d.SetString("foo", "foobar", time.Time{})
+			},
+			want: ":26\r\n",
+		},
+		{
+			name: "counts byte range",
+			args: resp.Args{
+				[]byte("bitcount"),
+				[]byte("foo"),
+				[]byte("1"),
+				[]byte("1"),
+			},
+			arrange: func(d *db.DB) {
+				d.SetString("foo", "foobar", time.Time{})
+			},
+			want: ":6\r\n",
+		},
+		{
+			name: "counts explicit byte range",
+			args: resp.Args{
+				[]byte("bitcount"),
+				[]byte("foo"),
+				[]byte("0"),
+				[]byte("0"),
+				[]byte("BYTE"),
+			},
+			arrange: func(d *db.DB) {
+				d.SetString("foo", "foobar", time.Time{})
+			},
+			want: ":4\r\n",
+		},
+		{
+			name: "counts bit range",
+			args: resp.Args{
+				[]byte("bitcount"),
+				[]byte("foo"),
+				[]byte("5"),
+				[]byte("30"),
+				[]byte("bit"),
+			},
+			arrange: func(d *db.DB) {
+				d.SetString("foo", "foobar", time.Time{})
+			},
+			want: ":17\r\n",
+		},
+		{
+			name: "resolves negative indexes",
+			args: resp.Args{
+				[]byte("bitcount"),
+				[]byte("foo"),
+				[]byte("-2"),
+				[]byte("-1"),
+			},
+			arrange: func(d *db.DB) {
+				d.SetString("foo", "foobar", time.Time{})
+			},
+			want: ":7\r\n",
+		},
+		{
+			name: "empty range",
+			args: resp.Args{
+				[]byte("bitcount"),
+				[]byte("foo"),
+				[]byte("-1"),
+				[]byte("-2"),
+			},
+			arrange: func(d *db.DB) {
+				d.SetString("foo", "foobar", time.Time{})
+			},
+			want: ":0\r\n",
+		},
+		{
+			name: "missing key",
+			args: resp.Args{
+				[]byte("bitcount"),
+				[]byte("foo"),
+				[]byte("0"),
+				[]byte("-1"),
+			},
+			want: ":0\r\n",
+		},
+		{
+			name: "start without end",
+			args: resp.Args{
+				[]byte("bitcount"),
+				[]byte("foo"),
+				[]byte("0"),
+			},
+			arrange: func(d *db.DB) {
+				d.SetString("foo", "foobar", time.Time{})
+			},
+			want: "-ERR syntax error\r\n",
+		},
+		{
+			name: "invalid unit",
+			args: resp.Args{
+				[]byte("bitcount"),
+				[]byte("foo"),
+				[]byte("0"),
+				[]byte("1"),
+				[]byte("WORD"),
+			},
+			arrange: func(d *db.DB) {
+				d.SetString("foo", "foobar", time.Time{})
+			},
+			want: "-ERR syntax error\r\n",
+		},
+		{
+			name: "invalid start",
+			args: resp.Args{
+				[]byte("bitcount"),
+				[]byte("foo"),
+				[]byte("x"),
+				[]byte("1"),
+			},
+			arrange: func(d *db.DB) {
+				d.SetString("foo", "foobar", time.Time{})
+			},
+			want: "-ERR value is not an integer or out of range\r\n",
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			d := db.New()
+			if tc.arrange != nil {
+				tc.arrange(d)
+			}
+			srv := &Server{
+				dbMap: map[int]*db.DB{0: d},
+				clock: clock.New(now),
+			}
+
+			buf := new(bytes.Buffer)
+			w := resp.NewWriter(bufio.NewWriter(buf))
+			req := newRequest(session.New(), "BITCOUNT", tc.args)
+
+			if err := srv.cmdBitCount(w, req); err != nil {
+				t.Fatalf("cmdBitCount returned error: %v", err)
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("flush failed: %v", err)
+			}
+			if got := buf.String(); got != tc.want {
+				t.Fatalf("unexpected payload:\nwant %q\ngot  %q", tc.want, got)
+			}
+		})
+	}
+}
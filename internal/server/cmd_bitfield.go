@@ -0,0 +1,119 @@
+package server
+
+import (
+	"strings"
+
+	"github.com/mickamy/minivalkey/internal/db"
+	"github.com/mickamy/minivalkey/internal/resp"
+)
+
+func (s *Server) cmdBitField(w *resp.Writer, r *request) error {
+	// BITFIELD key [GET encoding offset | [OVERFLOW <WRAP | SAT | FAIL>] <SET encoding offset value | INCRBY encoding offset increment> ...]
+	return s.bitFieldGeneric(w, r, false)
+}
+
+func (s *Server) cmdBitFieldRO(w *resp.Writer, r *request) error {
+	// BITFIELD_RO key [GET encoding offset ...]
+	return s.bitFieldGeneric(w, r, true)
+}
+
+func (s *Server) bitFieldGeneric(w *resp.Writer, r *request, readOnly bool) error {
+	if err := validateCommand(r.cmd, r.args, validateArgCountAtLeast(2)); err != nil {
+		return w.WriteErrorAndFlush(err)
+	}
+	key := string(r.args[1])
+
+	var ops []db.BitFieldOp
+	overflow := db.OverflowWrap
+	writes := false
+	for i := 2; i < len(r.args); i++ {
+		remaining := len(r.args) - i - 1
+		var kind db.BitFieldOpKind
+		switch sub := strings.ToUpper(string(r.args[i])); {
+		case sub == "GET" && remaining >= 2:
+			kind = db.BitFieldGet
+		case sub == "SET" && remaining >= 3:
+			kind = db.BitFieldSet
+		case sub == "INCRBY" && remaining >= 3:
+			kind = db.BitFieldIncrBy
+		case sub == "OVERFLOW" && remaining >= 1:
+			i++
+			switch strings.ToUpper(string(r.args[i])) {
+			case "WRAP":
+				overflow = db.OverflowWrap
+			case "SAT":
+				overflow = db.OverflowSat
+			case "FAIL":
+				overflow = db.OverflowFail
+			default:
+				return w.WriteErrorAndFlush(ErrBitFieldOverflow)
+			}
+			continue
+		default:
+			return w.WriteErrorAndFlush(ErrSyntax)
+		}
+
+		op := db.BitFieldOp{Kind: kind, Overflow: overflow}
+		var ok bool
+		if op.Signed, op.Bits, ok = parseBitFieldType(r.args[i+1]); !ok {
+			return w.WriteErrorString(msgBitFieldType)
+		}
+		if op.Offset, ok = parseBitOffset(r.args[i+2], true, op.Bits); !ok {
+			return w.WriteErrorAndFlush(ErrBitOffset)
+		}
+		if kind != db.BitFieldGet {
+			writes = true
+			if op.Value, ok = resp.ParseInt(r.args[i+3]); !ok {
+				return w.WriteErrorAndFlush(ErrValueNotInteger)
+			}
+			i++
+		}
+		i += 2
+		ops = append(ops, op)
+	}
+	if readOnly && writes {
+		return w.WriteErrorAndFlush(ErrBitFieldReadOnly)
+	}
+
+	results, err := s.db(r.session).BitField(s.Now(), key, ops)
+	if err != nil {
+		return w.WriteErrorAndFlush(dbError(err))
+	}
+	if err := w.WriteArrayHeader(len(results)); err != nil {
+		return err
+	}
+	for _, v := range results {
+		if v == nil {
+			if err := w.WriteBulkElem(nil); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := w.WriteIntElem(*v); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// parseBitFieldType parses a BITFIELD encoding such as i8 or u16.
+// Signed fields can be up to 64 bits wide, unsigned ones up to 63.
+func parseBitFieldType(a resp.Arg) (bool, uint, bool) {
+	if len(a) < 2 {
+		return false, 0, false
+	}
+	var signed bool
+	switch a[0] {
+	case 'i', 'I':
+		signed = true
+	case 'u', 'U':
+	default:
+		return false, 0, false
+	}
+	n, ok := db.ParseStrictInt(string(a[1:]))
+	if !ok || n < 1 || (signed && n > 64) || (!signed && n > 63) {
+		return false, 0, false
+	}
+	return signed, uint(n), true
+}
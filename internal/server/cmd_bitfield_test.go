@@ -0,0 +1,260 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/mickamy/minivalkey/internal/clock"
+	"github.com/mickamy/minivalkey/internal/db"
+	"github.com/mickamy/minivalkey/internal/resp"
+	"github.com/mickamy/minivalkey/internal/session"
+)
+
+func TestServer_cmdBitField(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+
+	tcs := []struct {
+		name    string
+		args    resp.Args
+		arrange func(*db.DB)
+		assert  func(*testing.T, *db.DB)
+		want    string
+	}{
+		{
+			name: "incrby and get",
+			args: resp.Args{
+				[]byte("bitfield"),
+				[]byte("foo"),
+				[]byte("INCRBY"),
+				[]byte("i5"),
+				[]byte("100"),
+				[]byte("1"),
+				[]byte("GET"),
+				[]byte("u4"),
+				[]byte("0"),
+			},
+			assert: func(t *testing.T, d *db.DB) {
+				if got, _ := d.GetString(now, "foo"); got != "\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x80" {
+					t.Fatalf("expected %q, got %q", "\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x80", got)
+				}
+			},
+			want: "*2\r\n:1\r\n:0\r\n",
+		},
+		{
+			name: "overflow sat applies to following operations",
+			args: resp.Args{
+				[]byte("bitfield"),
+				[]byte("foo"),
+				[]byte("incrby"),
+				[]byte("u2"),
+				[]byte("100"),
+				[]byte("1"),
+				[]byte("OVERFLOW"),
+				[]byte("SAT"),
+				[]byte("incrby"),
+				[]byte("u2"),
+				[]byte("102"),
+				[]byte("1"),
+			},
+			arrange: func(d *db.DB) {
+				d.SetString("foo", "\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x0f", time.Time{})
+			},
+			want: "*2\r\n:0\r\n:3\r\n",
+		},
+		{
+			name: "overflow fail returns null",
+			args: resp.Args{
+				[]byte("bitfield"),
+				[]byte("foo"),
+				[]byte("OVERFLOW"),
+				[]byte("FAIL"),
+				[]byte("SET"),
+				[]byte("u8"),
+				[]byte("0"),
+				[]byte("256"),
+			},
+			want: "*1\r\n$-1\r\n",
+		},
+		{
+			name: "hash offset multiplies by width",
+			args: resp.Args{
+				[]byte("bitfield"),
+				[]byte("foo"),
+				[]byte("SET"),
+				[]byte("i8"),
+				[]byte("#1"),
+				[]byte("-100"),
+				[]byte("GET"),
+				[]byte("u8"),
+				[]byte("8"),
+			},
+			assert: func(t *testing.T, d *db.DB) {
+				if got, _ := d.GetString(now, "foo"); got != "\x00\x9c" {
+					t.Fatalf("expected %q, got %q", "\x00\x9c", got)
+				}
+			},
+			want: "*2\r\n:0\r\n:156\r\n",
+		},
+		{
+			name: "no operations",
+			args: resp.Args{
+				[]byte("bitfield"),
+				[]byte("foo"),
+			},
+			want: "*0\r\n",
+		},
+		{
+			name: "rejects u64",
+			args: resp.Args{
+				[]byte("bitfield"),
+				[]byte("foo"),
+				[]byte("GET"),
+				[]byte("u64"),
+				[]byte("0"),
+			},
+			want: "-ERR Invalid bitfield type. Use something like i16 u8. Note that u64 is not supported but i64 is.\r\n",
+		},
+		{
+			name: "rejects invalid overflow",
+			args: resp.Args{
+				[]byte("bitfield"),
+				[]byte("foo"),
+				[]byte("OVERFLOW"),
+				[]byte("LOOP"),
+			},
+			want: "-ERR Invalid OVERFLOW type specified\r\n",
+		},
+		{
+			name: "rejects invalid offset",
+			args: resp.Args{
+				[]byte("bitfield"),
+				[]byte("foo"),
+				[]byte("GET"),
+				[]byte("i8"),
+				[]byte("-1"),
+			},
+			want: "-ERR bit offset is not an integer or out of range\r\n",
+		},
+		{
+			name: "rejects missing arguments",
+			args: resp.Args{
+				[]byte("bitfield"),
+				[]byte("foo"),
+				[]byte("SET"),
+				[]byte("i8"),
+				[]byte("0"),
+			},
+			want: "-ERR syntax error\r\n",
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			d := db.New()
+			if tc.arrange != nil {
+				tc.arrange(d)
+			}
+			srv := &Server{
+				dbMap: map[int]*db.DB{0: d},
+				clock: clock.New(now),
+			}
+
+			buf := new(bytes.Buffer)
+			w := resp.NewWriter(bufio.NewWriter(buf))
+			req := newRequest(session.New(), "BITFIELD", tc.args)
+
+			if err := srv.cmdBitField(w, req); err != nil {
+				t.Fatalf("cmdBitField returned error: %v", err)
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("flush failed: %v", err)
+			}
+			if got := buf.String(); got != tc.want {
+				t.Fatalf("unexpected payload:\nwant %q\ngot  %q", tc.want, got)
+			}
+			if tc.assert != nil {
+				tc.assert(t, d)
+			}
+		})
+	}
+}
+
+func TestServer_cmdBitFieldRO(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+
+	tcs := []struct {
+		name    string
+		args    resp.Args
+		arrange func(*db.DB)
+		want    string
+	}{
+		{
+			name: "gets fields",
+			args: resp.Args{
+				[]byte("bitfield_ro"),
+				[]byte("foo"),
+				[]byte("GET"),
+				[]byte("u8"),
+				[]byte("0"),
+				[]byte("GET"),
+				[]byte("i4"),
+				[]byte("4"),
+			},
+			arrange: func(d *db.DB) {
+				d.SetString("foo", "\x9c", time.Time{})
+			},
+			want: "*2\r\n:156\r\n:-4\r\n",
+		},
+		{
+			name: "rejects writes",
+			args: resp.Args{
+				[]byte("bitfield_ro"),
+				[]byte("foo"),
+				[]byte("SET"),
+				[]byte("u8"),
+				[]byte("0"),
+				[]byte("1"),
+			},
+			want: "-ERR BITFIELD_RO only supports the GET subcommand\r\n",
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			d := db.New()
+			if tc.arrange != nil {
+				tc.arrange(d)
+			}
+			srv := &Server{
+				dbMap: map[int]*db.DB{0: d},
+				clock: clock.New(now),
+			}
+
+			buf := new(bytes.Buffer)
+			w := resp.NewWriter(bufio.NewWriter(buf))
+			req := newRequest(session.New(), "BITFIELD_RO", tc.args)
+
+			if err := srv.cmdBitFieldRO(w, req); err != nil {
+				t.Fatalf("cmdBitFieldRO returned error: %v", err)
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("flush failed: %v", err)
+			}
+			if got := buf.String(); got != tc.want {
+				t.Fatalf("unexpected payload:\nwant %q\ngot  %q", tc.want, got)
+			}
+		})
+	}
+}
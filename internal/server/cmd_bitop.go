@@ -0,0 +1,49 @@
+package server
+
+import (
+	"strings"
+
+	"github.com/mickamy/minivalkey/internal/db"
+	"github.com/mickamy/minivalkey/internal/resp"
+)
+
+var bitOps = map[string]db.BitOp{
+	"AND":  db.BitOpAnd,
+	"OR":   db.BitOpOr,
+	"XOR":  db.BitOpXor,
+	"NOT":  db.BitOpNot,
+	"DIFF": db.BitOpDiff,
+	"ONE":  db.BitOpOne,
+}
+
+func (s *Server) cmdBitOp(w *resp.Writer, r *request) error {
+	// BITOP <AND | OR | XOR | NOT | DIFF | ONE> destkey key [key ...]
+	if err := validateCommand(r.cmd, r.args, validateArgCountAtLeast(4)); err != nil {
+		return w.WriteErrorAndFlush(err)
+	}
+	op, ok := bitOps[strings.ToUpper(string(r.args[1]))]
+	if !ok {
+		return w.WriteErrorAndFlush(ErrSyntax)
+	}
+	dst := string(r.args[2])
+	srcs := make([]string, 0, len(r.args)-3)
+	for _, a := range r.args[3:] {
+		srcs = append(srcs, string(a))
+	}
+	if op == db.BitOpNot && len(srcs) != 1 {
+		return w.WriteErrorString(msgBitOpNot)
+	}
+	if op == db.BitOpDiff && len(srcs) < 2 {
+		return w.WriteErrorString(msgBitOpDiff)
+	}
+
+	n, err := s.db(r.session).BitOpStore(s.Now(), op, dst, srcs...)
+	if err != nil {
+		return w.WriteErrorAndFlush(dbError(err))
+	}
+	if err := w.WriteInt(int64(n)); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,190 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/mickamy/minivalkey/internal/clock"
+	"github.com/mickamy/minivalkey/internal/db"
+	"github.com/mickamy/minivalkey/internal/resp"
+	"github.com/mickamy/minivalkey/internal/session"
+)
+
+func TestServer_cmdBitOp(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+
+	tcs := []struct {
+		name    string
+		args    resp.Args
+		arrange func(*db.DB)
+		assert  func(*testing.T, *db.DB)
+		want    string
+	}{
+		{
+			name: "and",
+			args: resp.Args{
+				[]byte("bitop"),
+				[]byte("and"),
+				[]byte("dest"),
+				[]byte("a"),
+				[]byte("b"),
+			},
+			arrange: func(d *db.DB) {
+				d.SetString("a", "foobar", time.Time{})
+				d.SetString("b", "abcdef", time.Time{})
+			},
+			assert: func(t *testing.T, d *db.DB) {
+				if got, _ := d.GetString(now, "dest"); got != "`bc`ab" {
+					t.Fatalf("expected %q, got %q", "`bc`ab", got)
+				}
+			},
+			want: ":6\r\n",
+		},
+		{
+			name: "not",
+			args: resp.Args{
+				[]byte("bitop"),
+				[]byte("NOT"),
+				[]byte("dest"),
+				[]byte("a"),
+			},
+			arrange: func(d *db.DB) {
+				d.SetString("a", "\xf0\x0f", time.Time{})
+			},
+			assert: func(t *testing.T, d *db.DB) {
+				if got, _ := d.GetString(now, "dest"); got != "\x0f\xf0" {
+					t.Fatalf("expected %q, got %q", "\x0f\xf0", got)
+				}
+			},
+			want: ":2\r\n",
+		},
+		{
+			name: "diff",
+			args: resp.Args{
+				[]byte("bitop"),
+				[]byte("DIFF"),
+				[]byte("dest"),
+				[]byte("a"),
+				[]byte("b"),
+			},
+			arrange: func(d *db.DB) {
+				d.SetString("a", "\xff", time.Time{})
+				d.SetString("b", "\x0f", time.Time{})
+			},
+			assert: func(t *testing.T, d *db.DB) {
+				if got, _ := d.GetString(now, "dest"); got != "\xf0" {
+					t.Fatalf("expected %q, got %q", "\xf0", got)
+				}
+			},
+			want: ":1\r\n",
+		},
+		{
+			name: "one",
+			args: resp.Args{
+				[]byte("bitop"),
+				[]byte("ONE"),
+				[]byte("dest"),
+				[]byte("a"),
+				[]byte("b"),
+				[]byte("c"),
+			},
+			arrange: func(d *db.DB) {
+				d.SetString("a", "\x03", time.Time{})
+				d.SetString("b", "\x06", time.Time{})
+				d.SetString("c", "\f", time.Time{})
+			},
+			assert: func(t *testing.T, d *db.DB) {
+				if got, _ := d.GetString(now, "dest"); got != "\t" {
+					t.Fatalf("expected %q, got %q", "\t", got)
+				}
+			},
+			want: ":1\r\n",
+		},
+		{
+			name: "missing sources delete destination",
+			args: resp.Args{
+				[]byte("bitop"),
+				[]byte("OR"),
+				[]byte("dest"),
+				[]byte("a"),
+			},
+			arrange: func(d *db.DB) {
+				d.SetString("dest", "x", time.Time{})
+			},
+			assert: func(t *testing.T, d *db.DB) {
+				if _, ok := d.GetString(now, "dest"); ok {
+					t.Fatalf("expected %q to be deleted", "dest")
+				}
+			},
+			want: ":0\r\n",
+		},
+		{
+			name: "not with several keys",
+			args: resp.Args{
+				[]byte("bitop"),
+				[]byte("NOT"),
+				[]byte("dest"),
+				[]byte("a"),
+				[]byte("b"),
+			},
+			want: "-ERR BITOP NOT must be called with a single source key.\r\n",
+		},
+		{
+			name: "diff with a single key",
+			args: resp.Args{
+				[]byte("bitop"),
+				[]byte("DIFF"),
+				[]byte("dest"),
+				[]byte("a"),
+			},
+			want: "-ERR BITOP DIFF must be called with at least two source keys.\r\n",
+		},
+		{
+			name: "unknown operation",
+			args: resp.Args{
+				[]byte("bitop"),
+				[]byte("NAND"),
+				[]byte("dest"),
+				[]byte("a"),
+			},
+			want: "-ERR syntax error\r\n",
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			d := db.New()
+			if tc.arrange != nil {
+				tc.arrange(d)
+			}
+			srv := &Server{
+				dbMap: map[int]*db.DB{0: d},
+				clock: clock.New(now),
+			}
+
+			buf := new(bytes.Buffer)
+			w := resp.NewWriter(bufio.NewWriter(buf))
+			req := newRequest(session.New(), "BITOP", tc.args)
+
+			if err := srv.cmdBitOp(w, req); err != nil {
+				t.Fatalf("cmdBitOp returned error: %v", err)
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("flush failed: %v", err)
+			}
+			if got := buf.String(); got != tc.want {
+				t.Fatalf("unexpected payload:\nwant %q\ngot  %q", tc.want, got)
+			}
+			if tc.assert != nil {
+				tc.assert(t, d)
+			}
+		})
+	}
+}
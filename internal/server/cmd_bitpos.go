@@ -0,0 +1,78 @@
+package server
+
+import (
+	"github.com/mickamy/minivalkey/internal/db"
+	"github.com/mickamy/minivalkey/internal/resp"
+)
+
+func (s *Server) cmdBitPos(w *resp.Writer, r *request) error {
+	// BITPOS key bit [start [end [BYTE | BIT]]]
+	if err := validateCommand(r.cmd, r.args, validateArgCountAtLeast(3)); err != nil {
+		return w.WriteErrorAndFlush(err)
+	}
+	if len(r.args) > 6 {
+		return w.WriteErrorAndFlush(ErrSyntax)
+	}
+	key := string(r.args[1])
+	bit, ok := resp.ParseInt(r.args[2])
+	if !ok {
+		return w.WriteErrorAndFlush(ErrValueNotInteger)
+	}
+	if bit != 0 && bit != 1 {
+		return w.WriteErrorString(msgBitPosBit)
+	}
+	start, end := int64(0), int64(-1)
+	endGiven, isBit := false, false
+	if len(r.args) > 3 {
+		if start, ok = resp.ParseInt(r.args[3]); !ok {
+			return w.WriteErrorAndFlush(ErrValueNotInteger)
+		}
+	}
+	if len(r.args) == 6 {
+		var err error
+		if isBit, err = parseBitUnit(r.args[5]); err != nil {
+			return w.WriteErrorAndFlush(err)
+		}
+	}
+	if len(r.args) > 4 {
+		if end, ok = resp.ParseInt(r.args[4]); !ok {
+			return w.WriteErrorAndFlush(ErrValueNotInteger)
+		}
+		endGiven = true
+	}
+
+	v, exists, err := s.db(r.session).LookupString(s.Now(), key)
+	if err != nil {
+		return w.WriteErrorAndFlush(dbError(err))
+	}
+	if err := w.WriteInt(bitPos(v, exists, int(bit), start, end, isBit, endGiven)); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// bitPos returns the first offset holding bit within the range, following Valkey:
+// a missing key is an endless run of zeros, and when looking for a clear bit
+// without an explicit end the string is treated as zero padded on the right.
+func bitPos(v string, exists bool, bit int, start, end int64, isBit, endGiven bool) int64 {
+	if !exists {
+		if bit == 1 {
+			return -1
+		}
+		return 0
+	}
+	first, last, ok := bitRange(int64(len(v)), start, end, isBit)
+	if !ok {
+		return -1
+	}
+	for i := first; i <= last; i++ {
+		if db.GetBit(v, uint64(i)) == bit {
+			return i
+		}
+	}
+	if bit == 1 || endGiven {
+		return -1
+	}
+	return last + 1
+}
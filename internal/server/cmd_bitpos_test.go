@@ -0,0 +1,177 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/mickamy/minivalkey/internal/clock"
+	"github.com/mickamy/minivalkey/internal/db"
+	"github.com/mickamy/minivalkey/internal/resp"
+	"github.com/mickamy/minivalkey/internal/session"
+)
+
+func TestServer_cmdBitPos(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+
+	tcs := []struct {
+		name    string
+		args    resp.Args
+		arrange func(*db.DB)
+		want    string
+	}{
+		{
+			name: "first clear bit",
+			args: resp.Args{
+				[]byte("bitpos"),
+				[]byte("foo"),
+				[]byte("0"),
+			},
+			arrange: func(d *db.DB) {
+				d.SetString("foo", "\xff\xf0\x00", time.Time{})
+			},
+			want: ":12\r\n",
+		},
+		{
+			name: "first set bit",
+			args: resp.Args{
+				[]byte("bitpos"),
+				[]byte("foo"),
+				[]byte("1"),
+				[]byte("0"),
+			},
+			arrange: func(d *db.DB) {
+				d.SetString("foo", "\x00\xff\xf0", time.Time{})
+			},
+			want: ":8\r\n",
+		},
+		{
+			name: "first set bit from byte",
+			args: resp.Args{
+				[]byte("bitpos"),
+				[]byte("foo"),
+				[]byte("1"),
+				[]byte("2"),
+				[]byte("-1"),
+				[]byte("BYTE"),
+			},
+			arrange: func(d *db.DB) {
+				d.SetString("foo", "\x00\xff\xf0", time.Time{})
+			},
+			want: ":16\r\n",
+		},
+		{
+			name: "bit range",
+			args: resp.Args{
+				[]byte("bitpos"),
+				[]byte("foo"),
+				[]byte("1"),
+				[]byte("7"),
+				[]byte("15"),
+				[]byte("BIT"),
+			},
+			arrange: func(d *db.DB) {
+				d.SetString("foo", "\x00\xff\xf0", time.Time{})
+			},
+			want: ":8\r\n",
+		},
+		{
+			name: "no set bit",
+			args: resp.Args{
+				[]byte("bitpos"),
+				[]byte("foo"),
+				[]byte("1"),
+			},
+			arrange: func(d *db.DB) {
+				d.SetString("foo", "\x00\x00\x00", time.Time{})
+			},
+			want: ":-1\r\n",
+		},
+		{
+			name: "clear bit past the end without explicit end",
+			args: resp.Args{
+				[]byte("bitpos"),
+				[]byte("foo"),
+				[]byte("0"),
+			},
+			arrange: func(d *db.DB) {
+				d.SetString("foo", "\xff\xff\xff", time.Time{})
+			},
+			want: ":24\r\n",
+		},
+		{
+			name: "no clear bit in explicit range",
+			args: resp.Args{
+				[]byte("bitpos"),
+				[]byte("foo"),
+				[]byte("0"),
+				[]byte("0"),
+				[]byte("-1"),
+			},
+			arrange: func(d *db.DB) {
+				d.SetString("foo", "\xff\xff\xff", time.Time{})
+			},
+			want: ":-1\r\n",
+		},
+		{
+			name: "missing key looking for clear bit",
+			args: resp.Args{
+				[]byte("bitpos"),
+				[]byte("foo"),
+				[]byte("0"),
+			},
+			want: ":0\r\n",
+		},
+		{
+			name: "missing key looking for set bit",
+			args: resp.Args{
+				[]byte("bitpos"),
+				[]byte("foo"),
+				[]byte("1"),
+			},
+			want: ":-1\r\n",
+		},
+		{
+			name: "rejects invalid bit",
+			args: resp.Args{
+				[]byte("bitpos"),
+				[]byte("foo"),
+				[]byte("2"),
+			},
+			want: "-ERR The bit argument must be 1 or 0.\r\n",
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			d := db.New()
+			if tc.arrange != nil {
+				tc.arrange(d)
+			}
+			srv := &Server{
+				dbMap: map[int]*db.DB{0: d},
+				clock: clock.New(now),
+			}
+
+			buf := new(bytes.Buffer)
+			w := resp.NewWriter(bufio.NewWriter(buf))
+			req := newRequest(session.New(), "BITPOS", tc.args)
+
+			if err := srv.cmdBitPos(w, req); err != nil {
+				t.Fatalf("cmdBitPos returned error: %v", err)
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("flush failed: %v", err)
+			}
+			if got := buf.String(); got != tc.want {
+				t.Fatalf("unexpected payload:\nwant %q\ngot  %q", tc.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/mickamy/minivalkey/internal/resp"
+)
+
+func (s *Server) cmdClient(w *resp.Writer, r *request) error {
+	// Minimal CLIENT handler covering the subcommands client libraries send
+	// while setting up a connection (ID, SETNAME, GETNAME and SETINFO),
+	// plus PAUSE and UNPAUSE.
+	if err := validateCommand(r.cmd, r.args, validateArgCountAtLeast(2)); err != nil {
+		return w.WriteErrorAndFlush(err)
+	}
+	sub := strings.ToUpper(string(r.args[1]))
+	// Minimum and maximum number of arguments, counting CLIENT and the subcommand.
+	arity := map[string][2]int{
+		"ID":      {2, 2},
+		"GETNAME": {2, 2},
+		"SETNAME": {3, 3},
+		"SETINFO": {4, 4},
+		"PAUSE":   {3, 4},
+		"UNPAUSE": {2, 2},
+	}
+	want, ok := arity[sub]
+	if !ok {
+		return w.WriteErrorString(unknownSubcommandMsg(r.cmd, r.args[1]))
+	}
+	if len(r.args) < want[0] || len(r.args) > want[1] {
+		return w.WriteErrorAndFlush(errors.New(resp.WrongNumberOfArgsError(resp.Command("client|" + strings.ToLower(sub)))))
+	}
+
+	switch sub {
+	case "ID":
+		if err := w.WriteInt(r.session.ID); err != nil {
+			return err
+		}
+	case "GETNAME":
+		if r.session.Name == "" {
+			if err := w.WriteNull(); err != nil {
+				return err
+			}
+			return nil
+		}
+		if err := w.WriteBulk([]byte(r.session.Name)); err != nil {
+			return err
+		}
+	case "SETNAME":
+		name := string(r.args[2])
+		if !validClientName(name) {
+			return w.WriteErrorString(msgInvalidName)
+		}
+		r.session.Name = name
+		if err := w.WriteString("OK"); err != nil {
+			return err
+		}
+	case "SETINFO":
+		// Library name/version are accepted but not recorded.
+		switch attr := strings.ToUpper(string(r.args[2])); attr {
+		case "LIB-NAME", "LIB-VER":
+		default:
+			return w.WriteErrorAndFlush(fmt.Errorf("ERR Unrecognized option '%s'", string(r.args[2])))
+		}
+		if err := w.WriteString("OK"); err != nil {
+			return err
+		}
+	case "PAUSE":
+		ms, ok := resp.ParseInt(r.args[2])
+		if !ok || ms < 0 {
+			return w.WriteErrorAndFlush(ErrPauseTimeout)
+		}
+		mode := PauseAll
+		if len(r.args) == 4 {
+			mode = strings.ToUpper(string(r.args[3]))
+			if mode != PauseWrite && mode != PauseAll {
+				return w.WriteErrorAndFlush(ErrSyntax)
+			}
+		}
+		// Clamp the timeout so it does not overflow a time.Duration.
+		s.Pause(time.Duration(min(ms, math.MaxInt64/int64(time.Millisecond)))*time.Millisecond, mode)
+		if err := w.WriteString("OK"); err != nil {
+			return err
+		}
+	case "UNPAUSE":
+		s.Unpause()
+		if err := w.WriteString("OK"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validClientName reports whether name only contains printable characters
+// other than space, as Valkey requires for CLIENT SETNAME.
+func validClientName(name string) bool {
+	for i := 0; i < len(name); i++ {
+		if name[i] < '!' || name[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,224 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/mickamy/minivalkey/internal/clock"
+	"github.com/mickamy/minivalkey/internal/db"
+	"github.com/mickamy/minivalkey/internal/resp"
+	"github.com/mickamy/minivalkey/internal/session"
+)
+
+func TestServer_cmdClient(t *testing.T) {
+	t.Parallel()
+
+	tcs := []struct {
+		name    string
+		args    resp.Args
+		arrange func(*session.Session)
+		assert  func(*testing.T, *session.Session)
+		want    string
+	}{
+		{
+			name: "returns client id",
+			args: resp.Args{
+				[]byte("client"),
+				[]byte("id"),
+			},
+			arrange: func(sess *session.Session) {
+				sess.ID = 42
+			},
+			want: ":42\r\n",
+		},
+		{
+			name: "sets client name",
+			args: resp.Args{
+				[]byte("client"),
+				[]byte("setname"),
+				[]byte("worker-1"),
+			},
+			assert: func(t *testing.T, sess *session.Session) {
+				if sess.Name != "worker-1" {
+					t.Fatalf("expected name worker-1, got %q", sess.Name)
+				}
+			},
+			want: "+OK\r\n",
+		},
+		{
+			name: "rejects names with spaces",
+			args: resp.Args{
+				[]byte("client"),
+				[]byte("setname"),
+				[]byte("bad name"),
+			},
+			want: "-" + msgInvalidName + "\r\n",
+		},
+		{
+			name: "returns client name",
+			args: resp.Args{
+				[]byte("client"),
+				[]byte("getname"),
+			},
+			arrange: func(sess *session.Session) {
+				sess.Name = "worker-1"
+			},
+			want: "$8\r\nworker-1\r\n",
+		},
+		{
+			name: "returns null when name is unset",
+			args: resp.Args{
+				[]byte("client"),
+				[]byte("getname"),
+			},
+			want: "$-1\r\n",
+		},
+		{
+			name: "accepts library info",
+			args: resp.Args{
+				[]byte("client"),
+				[]byte("setinfo"),
+				[]byte("lib-name"),
+				[]byte("valkey-go"),
+			},
+			want: "+OK\r\n",
+		},
+		{
+			name: "complains about unknown subcommand",
+			args: resp.Args{
+				[]byte("client"),
+				[]byte("nope"),
+			},
+			want: "-ERR unknown subcommand 'nope'. Try CLIENT HELP.\r\n",
+		},
+		{
+			name: "complains about wrong arity",
+			args: resp.Args{
+				[]byte("client"),
+				[]byte("setname"),
+			},
+			want: "-ERR wrong number of arguments for 'client|setname' command\r\n",
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := &Server{
+				dbMap: map[int]*db.DB{},
+				clock: clock.New(time.Time{}),
+			}
+			sess := session.New()
+			if tc.arrange != nil {
+				tc.arrange(sess)
+			}
+
+			buf := new(bytes.Buffer)
+			w := resp.NewWriter(bufio.NewWriter(buf))
+			req := newRequest(sess, "CLIENT", tc.args)
+
+			if err := srv.cmdClient(w, req); err != nil {
+				t.Fatalf("cmdClient returned error: %v", err)
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("flush failed: %v", err)
+			}
+			if got := buf.String(); got != tc.want {
+				t.Fatalf("unexpected payload:\nwant %q\ngot  %q", tc.want, got)
+			}
+			if tc.assert != nil {
+				tc.assert(t, sess)
+			}
+		})
+	}
+}
+
+func TestServer_cmdClientPause(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+
+	tcs := []struct {
+		name     string
+		args     []string
+		paused   bool
+		wantMode string
+		wantEnd  time.Time
+		want     string
+	}{
+		{
+			name:     "pauses all clients by default",
+			args:     []string{"client", "pause", "1500"},
+			wantMode: PauseAll,
+			wantEnd:  now.Add(1500 * time.Millisecond),
+			want:     "+OK\r\n",
+		},
+		{
+			name:     "pauses writes",
+			args:     []string{"client", "pause", "100", "write"},
+			wantMode: PauseWrite,
+			wantEnd:  now.Add(100 * time.Millisecond),
+			want:     "+OK\r\n",
+		},
+		{
+			name:     "clamps a huge timeout",
+			args:     []string{"client", "pause", "9223372036854775807"},
+			wantMode: PauseAll,
+			wantEnd:  now.Add(time.Duration(math.MaxInt64/int64(time.Millisecond)) * time.Millisecond),
+			want:     "+OK\r\n",
+		},
+		{
+			name:   "unpauses",
+			args:   []string{"client", "unpause"},
+			paused: true,
+			want:   "+OK\r\n",
+		},
+		{
+			name: "rejects negative timeout",
+			args: []string{"client", "pause", "-1"},
+			want: "-ERR timeout is not an integer or out of range\r\n",
+		},
+		{
+			name: "rejects unknown mode",
+			args: []string{"client", "pause", "100", "read"},
+			want: "-ERR syntax error\r\n",
+		},
+		{
+			name: "complains about wrong arity",
+			args: []string{"client", "pause"},
+			want: "-ERR wrong number of arguments for 'client|pause' command\r\n",
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := &Server{clock: clock.New(now)}
+			if tc.paused {
+				srv.Pause(time.Second, PauseAll)
+			}
+
+			buf := new(bytes.Buffer)
+			w := resp.NewWriter(bufio.NewWriter(buf))
+			if err := srv.cmdClient(w, newRequest(session.New(), "CLIENT", testArgs(tc.args...))); err != nil {
+				t.Fatalf("cmdClient returned error: %v", err)
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("flush failed: %v", err)
+			}
+			if got := buf.String(); got != tc.want {
+				t.Fatalf("unexpected payload:\nwant %q\ngot  %q", tc.want, got)
+			}
+			if srv.pause.mode != tc.wantMode || !srv.pause.end.Equal(tc.wantEnd) && tc.wantMode != "" {
+				t.Fatalf("expected pause %q until %v, got %q until %v", tc.wantMode, tc.wantEnd, srv.pause.mode, srv.pause.end)
+			}
+		})
+	}
+}
@@ -0,0 +1,260 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mickamy/minivalkey/internal/cluster"
+	"github.com/mickamy/minivalkey/internal/resp"
+)
+
+func (s *Server) cmdCluster(w *resp.Writer, r *request) error {
+	// Read-only CLUSTER subcommands that clients use to discover the slot map.
+	// The map is fixed when the cluster starts, so nothing can reshard it.
+	if err := validateCommand(r.cmd, r.args, validateArgCountAtLeast(2)); err != nil {
+		return w.WriteErrorAndFlush(err)
+	}
+	if s.cluster == nil {
+		return w.WriteErrorAndFlush(ErrClusterDisabled)
+	}
+	sub := strings.ToUpper(string(r.args[1]))
+	arity := map[string]int{
+		"INFO":            2,
+		"MYID":            2,
+		"NODES":           2,
+		"SHARDS":          2,
+		"SLOTS":           2,
+		"KEYSLOT":         3,
+		"COUNTKEYSINSLOT": 3,
+		"GETKEYSINSLOT":   4,
+	}
+	want, ok := arity[sub]
+	if !ok {
+		return w.WriteErrorString(unknownSubcommandMsg(r.cmd, r.args[1]))
+	}
+	if len(r.args) != want {
+		return w.WriteErrorAndFlush(errors.New(resp.WrongNumberOfArgsError(resp.Command("cluster|" + strings.ToLower(sub)))))
+	}
+
+	switch sub {
+	case "INFO":
+		if err := w.WriteBulk([]byte(s.clusterInfo())); err != nil {
+			return err
+		}
+	case "MYID":
+		if err := w.WriteBulk([]byte(s.myself.ID)); err != nil {
+			return err
+		}
+	case "NODES":
+		if err := w.WriteBulk([]byte(s.clusterNodes())); err != nil {
+			return err
+		}
+	case "SHARDS":
+		if err := s.writeClusterShards(w); err != nil {
+			return err
+		}
+	case "SLOTS":
+		if err := s.writeClusterSlots(w); err != nil {
+			return err
+		}
+	case "KEYSLOT":
+		if err := w.WriteInt(int64(cluster.KeySlot(string(r.args[2])))); err != nil {
+			return err
+		}
+	case "COUNTKEYSINSLOT":
+		slot, ok := parseSlot(r.args[2])
+		if !ok {
+			return w.WriteErrorAndFlush(ErrInvalidSlot)
+		}
+		if err := w.WriteInt(int64(len(s.keysInSlot(slot, -1)))); err != nil {
+			return err
+		}
+	case "GETKEYSINSLOT":
+		slot, ok := parseSlot(r.args[2])
+		if !ok {
+			return w.WriteErrorAndFlush(ErrInvalidSlot)
+		}
+		count, ok := resp.ParseInt(r.args[3])
+		if !ok || count < 0 {
+			return w.WriteErrorAndFlush(ErrInvalidNumKeys)
+		}
+		keys := s.keysInSlot(slot, int(count))
+		if err := w.WriteArrayHeader(len(keys)); err != nil {
+			return err
+		}
+		for _, k := range keys {
+			if err := w.WriteBulkElem([]byte(k)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// parseSlot parses a hash slot number.
+func parseSlot(b []byte) (int, bool) {
+	n, ok := resp.ParseInt(b)
+	if !ok || n < 0 || n >= cluster.NumSlots {
+		return 0, false
+	}
+	return int(n), true
+}
+
+// keysInSlot returns up to limit keys of database 0 that hash to slot, or all of them when limit is negative.
+func (s *Server) keysInSlot(slot, limit int) []string {
+	var out []string
+	for _, k := range s.dbAt(0).Keys(s.Now()) {
+		if limit >= 0 && len(out) == limit {
+			break
+		}
+		if cluster.KeySlot(k) == slot {
+			out = append(out, k)
+		}
+	}
+	return out
+}
+
+func (s *Server) clusterInfo() string {
+	n := len(s.cluster.Nodes)
+	var b strings.Builder
+	b.WriteString("cluster_state:ok\r\n")
+	fmt.Fprintf(&b, "cluster_slots_assigned:%d\r\n", cluster.NumSlots)
+	fmt.Fprintf(&b, "cluster_slots_ok:%d\r\n", cluster.NumSlots)
+	b.WriteString("cluster_slots_pfail:0\r\n")
+	b.WriteString("cluster_slots_fail:0\r\n")
+	fmt.Fprintf(&b, "cluster_known_nodes:%d\r\n", n)
+	fmt.Fprintf(&b, "cluster_size:%d\r\n", n)
+	fmt.Fprintf(&b, "cluster_current_epoch:%d\r\n", n)
+	fmt.Fprintf(&b, "cluster_my_epoch:%d\r\n", s.myself.Epoch)
+	b.WriteString("cluster_stats_messages_sent:0\r\n")
+	b.WriteString("cluster_stats_messages_received:0\r\n")
+	b.WriteString("total_cluster_links_buffer_limit_exceeded:0\r\n")
+	return b.String()
+}
+
+// clusterNodes renders the CLUSTER NODES table, one line per node:
+// <id> <ip:port@cport> <flags> <master> <ping-sent> <pong-recv> <config-epoch> <link-state> <slot> ...
+func (s *Server) clusterNodes() string {
+	var b strings.Builder
+	for _, n := range s.cluster.Nodes {
+		flags := "master"
+		if n == s.myself {
+			flags = "myself,master"
+		}
+		fmt.Fprintf(&b, "%s %s@%d %s - 0 0 %d connected", n.ID, n.Addr(), n.Port+10000, flags, n.Epoch)
+		for _, r := range n.Slots {
+			if r.Start == r.End {
+				fmt.Fprintf(&b, " %d", r.Start)
+			} else {
+				fmt.Fprintf(&b, " %d-%d", r.Start, r.End)
+			}
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// writeClusterSlots writes one entry per slot range: start, end and the node serving it.
+func (s *Server) writeClusterSlots(w *resp.Writer) error {
+	var count int
+	for _, n := range s.cluster.Nodes {
+		count += len(n.Slots)
+	}
+	if err := w.WriteArrayHeader(count); err != nil {
+		return err
+	}
+	for _, n := range s.cluster.Nodes {
+		for _, r := range n.Slots {
+			if err := w.WriteArrayHeader(3); err != nil {
+				return err
+			}
+			if err := w.WriteIntElem(int64(r.Start)); err != nil {
+				return err
+			}
+			if err := w.WriteIntElem(int64(r.End)); err != nil {
+				return err
+			}
+			if err := w.WriteArrayHeader(4); err != nil {
+				return err
+			}
+			if err := w.WriteBulkElem([]byte(n.Host)); err != nil {
+				return err
+			}
+			if err := w.WriteIntElem(int64(n.Port)); err != nil {
+				return err
+			}
+			if err := w.WriteBulkElem([]byte(n.ID)); err != nil {
+				return err
+			}
+			if err := w.WriteEmptyArray(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// writeClusterShards writes one shard per node, each a map of its slots and its single primary.
+func (s *Server) writeClusterShards(w *resp.Writer) error {
+	if err := w.WriteArrayHeader(len(s.cluster.Nodes)); err != nil {
+		return err
+	}
+	for _, n := range s.cluster.Nodes {
+		if err := w.WriteArrayHeader(4); err != nil {
+			return err
+		}
+		if err := w.WriteBulkElem([]byte("slots")); err != nil {
+			return err
+		}
+		if err := w.WriteArrayHeader(2 * len(n.Slots)); err != nil {
+			return err
+		}
+		for _, r := range n.Slots {
+			if err := w.WriteIntElem(int64(r.Start)); err != nil {
+				return err
+			}
+			if err := w.WriteIntElem(int64(r.End)); err != nil {
+				return err
+			}
+		}
+		if err := w.WriteBulkElem([]byte("nodes")); err != nil {
+			return err
+		}
+		if err := w.WriteArrayHeader(1); err != nil {
+			return err
+		}
+		fields := []struct {
+			key   string
+			value any
+		}{
+			{"id", n.ID},
+			{"port", int64(n.Port)},
+			{"ip", n.Host},
+			{"endpoint", n.Host},
+			{"role", "master"},
+			{"replication-offset", int64(0)},
+			{"health", "online"},
+		}
+		if err := w.WriteArrayHeader(2 * len(fields)); err != nil {
+			return err
+		}
+		for _, f := range fields {
+			if err := w.WriteBulkElem([]byte(f.key)); err != nil {
+				return err
+			}
+			switch v := f.value.(type) {
+			case string:
+				if err := w.WriteBulkElem([]byte(v)); err != nil {
+					return err
+				}
+			case int64:
+				if err := w.WriteIntElem(v); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
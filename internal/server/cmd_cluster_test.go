@@ -0,0 +1,146 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mickamy/minivalkey/internal/clock"
+	"github.com/mickamy/minivalkey/internal/db"
+	"github.com/mickamy/minivalkey/internal/resp"
+	"github.com/mickamy/minivalkey/internal/session"
+)
+
+func TestServer_cmdCluster(t *testing.T) {
+	t.Parallel()
+
+	bulk := func(s string) string { return fmt.Sprintf("$%d\r\n%s\r\n", len(s), s) }
+
+	tcs := []struct {
+		name    string
+		args    []string
+		arrange func(*Server)
+		want    func(*Server) string
+	}{
+		{
+			name: "returns own id",
+			args: []string{"cluster", "myid"},
+			want: func(srv *Server) string { return bulk(srv.myself.ID) },
+		},
+		{
+			name: "returns key slot",
+			args: []string{"cluster", "keyslot", "{user1000}.following"},
+			want: func(*Server) string { return ":3443\r\n" },
+		},
+		{
+			name: "counts keys in slot",
+			args: []string{"cluster", "countkeysinslot", "5061"},
+			arrange: func(srv *Server) {
+				srv.dbAt(0).SetString("bar", "1", time.Time{})
+				srv.dbAt(0).SetString("{bar}x", "1", time.Time{})
+				srv.dbAt(0).SetString("other", "1", time.Time{})
+			},
+			want: func(*Server) string { return ":2\r\n" },
+		},
+		{
+			name: "gets keys in slot up to count",
+			args: []string{"cluster", "getkeysinslot", "5061", "1"},
+			arrange: func(srv *Server) {
+				srv.dbAt(0).SetString("bar", "1", time.Time{})
+				srv.dbAt(0).SetString("{bar}x", "1", time.Time{})
+			},
+			want: func(*Server) string { return "*1\r\n$3\r\nbar\r\n" },
+		},
+		{
+			name: "rejects out of range slot",
+			args: []string{"cluster", "countkeysinslot", "16384"},
+			want: func(*Server) string { return "-ERR Invalid or out of range slot\r\n" },
+		},
+		{
+			name: "rejects negative count",
+			args: []string{"cluster", "getkeysinslot", "0", "-1"},
+			want: func(*Server) string { return "-ERR Invalid number of keys\r\n" },
+		},
+		{
+			name: "returns slot map",
+			args: []string{"cluster", "slots"},
+			want: func(srv *Server) string {
+				var b strings.Builder
+				b.WriteString("*3\r\n")
+				for i, r := range [][2]int{{0, 5460}, {5461, 10921}, {10922, 16383}} {
+					n := srv.cluster.Nodes[i]
+					fmt.Fprintf(&b, "*3\r\n:%d\r\n:%d\r\n*4\r\n$9\r\n127.0.0.1\r\n:%d\r\n%s*0\r\n", r[0], r[1], 7000+i, bulk(n.ID))
+				}
+				return b.String()
+			},
+		},
+		{
+			name: "returns nodes table",
+			args: []string{"cluster", "nodes"},
+			want: func(srv *Server) string {
+				ids := []string{srv.cluster.Nodes[0].ID, srv.cluster.Nodes[1].ID, srv.cluster.Nodes[2].ID}
+				return bulk(ids[0] + " 127.0.0.1:7000@17000 myself,master - 0 0 1 connected 0-5460\n" +
+					ids[1] + " 127.0.0.1:7001@17001 master - 0 0 2 connected 5461-10921\n" +
+					ids[2] + " 127.0.0.1:7002@17002 master - 0 0 3 connected 10922-16383\n")
+			},
+		},
+		{
+			name: "returns cluster info",
+			args: []string{"cluster", "info"},
+			want: func(srv *Server) string { return bulk(srv.clusterInfo()) },
+		},
+		{
+			name: "complains about unknown subcommand",
+			args: []string{"cluster", "meet"},
+			want: func(*Server) string { return "-ERR unknown subcommand 'meet'. Try CLUSTER HELP.\r\n" },
+		},
+		{
+			name: "complains about wrong arity",
+			args: []string{"cluster", "keyslot"},
+			want: func(*Server) string { return "-ERR wrong number of arguments for 'cluster|keyslot' command\r\n" },
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := newClusterNode(t)
+			if tc.arrange != nil {
+				tc.arrange(srv)
+			}
+
+			buf := new(bytes.Buffer)
+			w := resp.NewWriter(bufio.NewWriter(buf))
+			args := testArgs(tc.args...)
+			if err := srv.cmdCluster(w, newRequest(session.New(), args.Cmd(), args)); err != nil {
+				t.Fatalf("cmdCluster returned error: %v", err)
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("flush failed: %v", err)
+			}
+			if got, want := buf.String(), tc.want(srv); got != want {
+				t.Fatalf("unexpected payload:\nwant %q\ngot  %q", want, got)
+			}
+		})
+	}
+}
+
+func TestServer_cmdClusterDisabled(t *testing.T) {
+	t.Parallel()
+
+	srv := &Server{dbMap: map[int]*db.DB{}, clock: clock.New(time.Unix(1_000, 0))}
+	buf := new(bytes.Buffer)
+	w := resp.NewWriter(bufio.NewWriter(buf))
+	args := testArgs("cluster", "slots")
+	if err := srv.cmdCluster(w, newRequest(session.New(), args.Cmd(), args)); err != nil {
+		t.Fatalf("cmdCluster returned error: %v", err)
+	}
+	if got, want := buf.String(), "-ERR This instance has cluster support disabled\r\n"; got != want {
+		t.Fatalf("unexpected payload:\nwant %q\ngot  %q", want, got)
+	}
+}
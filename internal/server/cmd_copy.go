@@ -0,0 +1,66 @@
+package server
+
+import (
+	"strings"
+
+	"github.com/mickamy/minivalkey/internal/resp"
+)
+
+func (s *Server) cmdCopy(w *resp.Writer, r *request) error {
+	// COPY source destination [DB destination-db] [REPLACE]
+	if err := validateCommand(r.cmd, r.args, validateArgCountAtLeast(3)); err != nil {
+		return w.WriteErrorAndFlush(err)
+	}
+	src := string(r.args[1])
+	dst := string(r.args[2])
+
+	dstDB := r.session.SelectedDB
+	replace := false
+	for i := 3; i < len(r.args); i++ {
+		opt := strings.ToUpper(string(r.args[i]))
+		switch opt {
+		case "REPLACE":
+			replace = true
+		case "DB":
+			i++
+			if i >= len(r.args) {
+				return w.WriteErrorAndFlush(ErrSyntax)
+			}
+			idx, ok := resp.ParseInt(r.args[i])
+			if !ok {
+				return w.WriteErrorAndFlush(ErrValueNotInteger)
+			}
+			if idx < 0 || idx >= numDatabases {
+				return w.WriteErrorAndFlush(ErrDBIndexOutOfRange)
+			}
+			dstDB = int(idx)
+		default:
+			return w.WriteErrorAndFlush(ErrSyntax)
+		}
+	}
+
+	if s.cluster != nil && dstDB != 0 {
+		return w.WriteErrorAndFlush(ErrCopyInCluster)
+	}
+	if src == dst && dstDB == r.session.SelectedDB {
+		return w.WriteErrorAndFlush(ErrSameObject)
+	}
+
+	var copied bool
+	if from := s.db(r.session); dstDB == r.session.SelectedDB {
+		copied = from.Copy(s.Now(), src, dst, replace)
+	} else {
+		copied = from.CopyTo(s.Now(), src, r.session.SelectedDB, s.dbAt(dstDB), dstDB, dst, replace)
+	}
+	if copied {
+		if err := w.WriteInt(1); err != nil {
+			return err
+		}
+	} else {
+		if err := w.WriteInt(0); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
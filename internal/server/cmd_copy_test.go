@@ -0,0 +1,183 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/mickamy/minivalkey/internal/clock"
+	"github.com/mickamy/minivalkey/internal/db"
+	"github.com/mickamy/minivalkey/internal/resp"
+	"github.com/mickamy/minivalkey/internal/session"
+)
+
+func TestServer_cmdCopy(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+
+	tcs := []struct {
+		name    string
+		args    resp.Args
+		arrange func(*db.DB)
+		assert  func(*testing.T, *Server)
+		want    string
+	}{
+		{
+			name: "copies value and ttl",
+			args: resp.Args{
+				[]byte("copy"),
+				[]byte("foo"),
+				[]byte("bar"),
+			},
+			arrange: func(db *db.DB) {
+				db.SetString("foo", "v", now.Add(10*time.Second))
+			},
+			assert: func(t *testing.T, srv *Server) {
+				d := srv.dbAt(0)
+				if got, _ := d.GetString(now, "bar"); got != "v" {
+					t.Fatalf("expected bar=v, got %q", got)
+				}
+				if ttl := d.TTL(now, "bar"); ttl != 10 {
+					t.Fatalf("expected ttl 10, got %d", ttl)
+				}
+			},
+			want: ":1\r\n",
+		},
+		{
+			name: "returns zero when destination exists",
+			args: resp.Args{
+				[]byte("copy"),
+				[]byte("foo"),
+				[]byte("bar"),
+			},
+			arrange: func(db *db.DB) {
+				db.SetString("foo", "new", time.Time{})
+				db.SetString("bar", "old", time.Time{})
+			},
+			want: ":0\r\n",
+		},
+		{
+			name: "replaces destination with REPLACE",
+			args: resp.Args{
+				[]byte("copy"),
+				[]byte("foo"),
+				[]byte("bar"),
+				[]byte("replace"),
+			},
+			arrange: func(db *db.DB) {
+				db.SetString("foo", "new", time.Time{})
+				db.SetString("bar", "old", time.Time{})
+			},
+			assert: func(t *testing.T, srv *Server) {
+				if got, _ := srv.dbAt(0).GetString(now, "bar"); got != "new" {
+					t.Fatalf("expected bar=new, got %q", got)
+				}
+			},
+			want: ":1\r\n",
+		},
+		{
+			name: "copies into another db",
+			args: resp.Args{
+				[]byte("copy"),
+				[]byte("foo"),
+				[]byte("foo"),
+				[]byte("DB"),
+				[]byte("3"),
+			},
+			arrange: func(db *db.DB) {
+				db.SetString("foo", "v", time.Time{})
+			},
+			assert: func(t *testing.T, srv *Server) {
+				if got, _ := srv.dbAt(3).GetString(now, "foo"); got != "v" {
+					t.Fatalf("expected foo=v in db 3, got %q", got)
+				}
+			},
+			want: ":1\r\n",
+		},
+		{
+			name: "returns zero when source is missing",
+			args: resp.Args{
+				[]byte("copy"),
+				[]byte("missing"),
+				[]byte("bar"),
+			},
+			want: ":0\r\n",
+		},
+		{
+			name: "complains when source and destination are the same",
+			args: resp.Args{
+				[]byte("copy"),
+				[]byte("foo"),
+				[]byte("foo"),
+			},
+			want: "-ERR source and destination objects are the same\r\n",
+		},
+		{
+			name: "complains when db is out of range",
+			args: resp.Args{
+				[]byte("copy"),
+				[]byte("foo"),
+				[]byte("bar"),
+				[]byte("db"),
+				[]byte("16"),
+			},
+			want: "-ERR DB index is out of range\r\n",
+		},
+		{
+			name: "complains when db is not an integer",
+			args: resp.Args{
+				[]byte("copy"),
+				[]byte("foo"),
+				[]byte("bar"),
+				[]byte("db"),
+				[]byte("x"),
+			},
+			want: "-ERR value is not an integer or out of range\r\n",
+		},
+		{
+			name: "complains about unknown option",
+			args: resp.Args{
+				[]byte("copy"),
+				[]byte("foo"),
+				[]byte("bar"),
+				[]byte("nope"),
+			},
+			want: "-ERR syntax error\r\n",
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			d := db.New()
+			if tc.arrange != nil {
+				tc.arrange(d)
+			}
+			srv := &Server{
+				dbMap: map[int]*db.DB{0: d},
+				clock: clock.New(now),
+			}
+
+			buf := new(bytes.Buffer)
+			w := resp.NewWriter(bufio.NewWriter(buf))
+			req := newRequest(session.New(), "COPY", tc.args)
+
+			if err := srv.cmdCopy(w, req); err != nil {
+				t.Fatalf("cmdCopy returned error: %v", err)
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("flush failed: %v", err)
+			}
+			if got := buf.String(); got != tc.want {
+				t.Fatalf("unexpected payload:\nwant %q\ngot  %q", tc.want, got)
+			}
+			if tc.assert != nil {
+				tc.assert(t, srv)
+			}
+		})
+	}
+}
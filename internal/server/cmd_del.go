@@ -13,7 +13,7 @@ func (s *Server) cmdDel(w *resp.Writer, r *request) error {
 		keys[i] = string(a)
 	}
 
-	n := s.db(r.session).Del(keys...)
+	n := s.db(r.session).Del(s.Now(), keys...)
 	if err := w.WriteInt(int64(n)); err != nil {
 		return err
 	}
@@ -51,6 +51,23 @@ func TestServer_cmdDel(t *testing.T) {
 			},
 			want: ":0\r\n",
 		},
+		{
+			name: "unlink behaves like del",
+			args: resp.Args{
+				[]byte("unlink"),
+				[]byte("foo"),
+				[]byte("missing"),
+			},
+			arrange: func(db *db.DB) {
+				db.SetString("foo", "1", time.Time{})
+			},
+			assert: func(t *testing.T, db *db.DB) {
+				if _, ok := db.GetString(time.Time{}, "foo"); ok {
+					t.Fatalf("foo was not unlinked")
+				}
+			},
+			want: ":1\r\n",
+		},
 		{
 			name: "complains when no keys are provided",
 			args: resp.Args{
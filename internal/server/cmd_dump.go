@@ -0,0 +1,26 @@
+package server
+
+import (
+	"github.com/mickamy/minivalkey/internal/rdb"
+	"github.com/mickamy/minivalkey/internal/resp"
+)
+
+func (s *Server) cmdDump(w *resp.Writer, r *request) error {
+	// DUMP key
+	if err := validateCommand(r.cmd, r.args, validateArgCountExact(2)); err != nil {
+		return w.WriteErrorAndFlush(err)
+	}
+
+	v, ok := s.db(r.session).DumpValue(s.Now(), string(r.args[1]))
+	if !ok {
+		if err := w.WriteNull(); err != nil {
+			return err
+		}
+		return nil
+	}
+	if err := w.WriteBulk(rdb.Dump(v)); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,94 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/mickamy/minivalkey/internal/clock"
+	"github.com/mickamy/minivalkey/internal/db"
+	"github.com/mickamy/minivalkey/internal/resp"
+	"github.com/mickamy/minivalkey/internal/session"
+)
+
+func TestServer_cmdDump(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+
+	tcs := []struct {
+		name    string
+		args    resp.Args
+		arrange func(*db.DB)
+		want    string
+	}{
+		{
+			name: "serializes a string",
+			args: resp.Args{
+				[]byte("dump"),
+				[]byte("foo"),
+			},
+			arrange: func(d *db.DB) {
+				d.SetString("foo", "bar", time.Time{})
+			},
+			want: "$15\r\n\x00\x03bar\v\x00\x8fa\xf4\x13\x13\xf9\x14\x9e\r\n",
+		},
+		{
+			name: "serializes a list as a quicklist",
+			args: resp.Args{
+				[]byte("dump"),
+				[]byte("foo"),
+			},
+			arrange: func(d *db.DB) {
+				d.RPush(now, "foo", "a", "b")
+			},
+			want: "$27\r\n\x12\x01\x02\r\r\x00\x00\x00\x02\x00\x81a\x02\x81b\x02\xff\v\x00\x014\xb7\xfa\xee\xdeR8\r\n",
+		},
+		{
+			name: "returns null for missing key",
+			args: resp.Args{
+				[]byte("dump"),
+				[]byte("missing"),
+			},
+			want: "$-1\r\n",
+		},
+		{
+			name: "complains about wrong number of arguments",
+			args: resp.Args{
+				[]byte("dump"),
+			},
+			want: "-ERR wrong number of arguments for 'dump' command\r\n",
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			d := db.New()
+			if tc.arrange != nil {
+				tc.arrange(d)
+			}
+			srv := &Server{
+				dbMap: map[int]*db.DB{0: d},
+				clock: clock.New(now),
+			}
+
+			buf := new(bytes.Buffer)
+			w := resp.NewWriter(bufio.NewWriter(buf))
+			req := newRequest(session.New(), "DUMP", tc.args)
+
+			if err := srv.cmdDump(w, req); err != nil {
+				t.Fatalf("cmdDump returned error: %v", err)
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("flush failed: %v", err)
+			}
+			if got := buf.String(); got != tc.want {
+				t.Fatalf("unexpected payload:\nwant %q\ngot  %q", tc.want, got)
+			}
+		})
+	}
+}
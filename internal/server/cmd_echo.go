@@ -0,0 +1,16 @@
+package server
+
+import (
+	"github.com/mickamy/minivalkey/internal/resp"
+)
+
+func (s *Server) cmdEcho(w *resp.Writer, r *request) error {
+	if err := validateCommand(r.cmd, r.args, validateArgCountExact(2)); err != nil {
+		return w.WriteErrorAndFlush(err)
+	}
+	if err := w.WriteBulk(r.args[1]); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -1,19 +1,57 @@
 package server
 
 import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/mickamy/minivalkey/internal/db"
 	"github.com/mickamy/minivalkey/internal/resp"
 )
 
 func (s *Server) cmdExpire(w *resp.Writer, r *request) error {
-	if err := validateCommand(r.cmd, r.args, validateArgCountExact(3)); err != nil {
+	return s.expireGeneric(w, r, time.Second, false)
+}
+
+func (s *Server) cmdPExpire(w *resp.Writer, r *request) error {
+	return s.expireGeneric(w, r, time.Millisecond, false)
+}
+
+func (s *Server) cmdExpireAt(w *resp.Writer, r *request) error {
+	return s.expireGeneric(w, r, time.Second, true)
+}
+
+func (s *Server) cmdPExpireAt(w *resp.Writer, r *request) error {
+	return s.expireGeneric(w, r, time.Millisecond, true)
+}
+
+// expireGeneric implements EXPIRE, PEXPIRE, EXPIREAT and PEXPIREAT:
+// CMD key value [NX|XX|GT|LT]
+// unit is the unit of value; absolute means value is a unix timestamp
+// rather than a duration relative to now.
+func (s *Server) expireGeneric(w *resp.Writer, r *request, unit time.Duration, absolute bool) error {
+	if err := validateCommand(r.cmd, r.args, validateArgCountAtLeast(3)); err != nil {
 		return w.WriteErrorAndFlush(err)
 	}
 	key := string(r.args[1])
-	sec, ok := resp.ParseInt(r.args[2])
+
+	opts, err := parseExpireOptions(r.args[3:])
+	if err != nil {
+		return w.WriteErrorAndFlush(err)
+	}
+
+	when, ok := resp.ParseInt(r.args[2])
 	if !ok {
 		return w.WriteErrorAndFlush(ErrValueNotInteger)
 	}
-	if s.db(r.session).Expire(s.Now(), key, sec) {
+	now := s.Now()
+	at, ok := expireAtFrom(now, when, unit, absolute)
+	if !ok {
+		return w.WriteErrorAndFlush(invalidExpireTimeError(r.cmd))
+	}
+
+	if s.db(r.session).ExpireAt(now, key, at, opts) {
 		if err := w.WriteInt(1); err != nil {
 			return err
 		}
@@ -25,3 +63,54 @@ func (s *Server) cmdExpire(w *resp.Writer, r *request) error {
 
 	return nil
 }
+
+// expireAtFrom converts an expiration argument in the given unit into an
+// absolute time. absolute means n is a unix timestamp rather than a duration
+// relative to now. Returns false if the conversion would overflow.
+func expireAtFrom(now time.Time, n int64, unit time.Duration, absolute bool) (time.Time, bool) {
+	// Work in unix milliseconds and reject anything that would overflow.
+	if unit == time.Second {
+		if n > math.MaxInt64/1000 || n < math.MinInt64/1000 {
+			return time.Time{}, false
+		}
+		n *= 1000
+	}
+	if absolute {
+		return time.UnixMilli(n), true
+	}
+	base := now.UnixMilli()
+	if n > math.MaxInt64-base {
+		return time.Time{}, false
+	}
+	if n < math.MaxInt64/int64(time.Millisecond) && n > math.MinInt64/int64(time.Millisecond) {
+		// Keep the sub-millisecond part of "now" so relative TTLs read back exactly.
+		return now.Add(time.Duration(n) * time.Millisecond), true
+	}
+	return time.UnixMilli(base + n), true
+}
+
+// parseExpireOptions parses the trailing NX/XX/GT/LT flags of the EXPIRE family.
+func parseExpireOptions(args resp.Args) (db.ExpireOptions, error) {
+	opts := db.ExpireOptions{}
+	for _, a := range args {
+		switch strings.ToUpper(string(a)) {
+		case "NX":
+			opts.NX = true
+		case "XX":
+			opts.XX = true
+		case "GT":
+			opts.GT = true
+		case "LT":
+			opts.LT = true
+		default:
+			return opts, fmt.Errorf("ERR Unsupported option %s", string(a))
+		}
+	}
+	if opts.NX && (opts.XX || opts.GT || opts.LT) {
+		return opts, ErrExpireNXCombo
+	}
+	if opts.GT && opts.LT {
+		return opts, ErrExpireGTLTCombo
+	}
+	return opts, nil
+}
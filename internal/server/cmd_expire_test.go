@@ -3,6 +3,7 @@ package server
 import (
 	"bufio"
 	"bytes"
+	"strconv"
 	"testing"
 	"time"
 
@@ -42,7 +43,7 @@ func TestServer_cmdExpire(t *testing.T) {
 			want: ":1\r\n",
 		},
 		{
-			name: "removes ttl when negative seconds are given",
+			name: "deletes key when negative seconds are given",
 			args: resp.Args{
 				[]byte("expire"),
 				[]byte("foo"),
@@ -50,15 +51,108 @@ func TestServer_cmdExpire(t *testing.T) {
 			},
 			arrange: func(db *db.DB) {
 				db.SetString("foo", "bar", now.Add(10*time.Second))
-				db.Expire(now, "foo", 10)
 			},
 			assert: func(t *testing.T, db *db.DB, srv *Server) {
-				if ttl := db.TTL(srv.Now(), "foo"); ttl != -1 {
-					t.Fatalf("expected ttl -1, got %d", ttl)
+				if ttl := db.TTL(srv.Now(), "foo"); ttl != -2 {
+					t.Fatalf("expected ttl -2, got %d", ttl)
 				}
 			},
 			want: ":1\r\n",
 		},
+		{
+			name: "deletes key when zero seconds are given",
+			args: resp.Args{
+				[]byte("expire"),
+				[]byte("foo"),
+				[]byte("0"),
+			},
+			arrange: func(db *db.DB) {
+				db.SetString("foo", "bar", time.Time{})
+			},
+			assert: func(t *testing.T, db *db.DB, srv *Server) {
+				if ttl := db.TTL(srv.Now(), "foo"); ttl != -2 {
+					t.Fatalf("expected ttl -2, got %d", ttl)
+				}
+			},
+			want: ":1\r\n",
+		},
+		{
+			name: "NX refuses to override existing ttl",
+			args: resp.Args{
+				[]byte("expire"),
+				[]byte("foo"),
+				[]byte("100"),
+				[]byte("nx"),
+			},
+			arrange: func(db *db.DB) {
+				db.SetString("foo", "bar", now.Add(10*time.Second))
+			},
+			assert: func(t *testing.T, db *db.DB, srv *Server) {
+				if ttl := db.TTL(srv.Now(), "foo"); ttl != 10 {
+					t.Fatalf("expected ttl 10, got %d", ttl)
+				}
+			},
+			want: ":0\r\n",
+		},
+		{
+			name: "GT extends existing ttl",
+			args: resp.Args{
+				[]byte("expire"),
+				[]byte("foo"),
+				[]byte("100"),
+				[]byte("GT"),
+			},
+			arrange: func(db *db.DB) {
+				db.SetString("foo", "bar", now.Add(10*time.Second))
+			},
+			assert: func(t *testing.T, db *db.DB, srv *Server) {
+				if ttl := db.TTL(srv.Now(), "foo"); ttl != 100 {
+					t.Fatalf("expected ttl 100, got %d", ttl)
+				}
+			},
+			want: ":1\r\n",
+		},
+		{
+			name: "complains about NX combined with GT",
+			args: resp.Args{
+				[]byte("expire"),
+				[]byte("foo"),
+				[]byte("100"),
+				[]byte("NX"),
+				[]byte("GT"),
+			},
+			want: "-ERR NX and XX, GT or LT options at the same time are not compatible\r\n",
+		},
+		{
+			name: "complains about GT combined with LT",
+			args: resp.Args{
+				[]byte("expire"),
+				[]byte("foo"),
+				[]byte("100"),
+				[]byte("GT"),
+				[]byte("LT"),
+			},
+			want: "-ERR GT and LT options at the same time are not compatible\r\n",
+		},
+		{
+			name: "complains about unknown option",
+			args: resp.Args{
+				[]byte("expire"),
+				[]byte("foo"),
+				[]byte("100"),
+				[]byte("maybe"),
+			},
+			want: "-ERR Unsupported option maybe\r\n",
+		},
+		{
+			name: "complains when seconds overflow",
+			args: resp.Args{
+				[]byte("expire"),
+				[]byte("foo"),
+				[]byte("9223372036854775"),
+			},
+			want: "-ERR invalid expire time in 'expire' command\r\n",
+		},
 		{
 			name: "returns zero when key does not exist",
 			args: resp.Args{
@@ -120,3 +214,129 @@ func TestServer_cmdExpire(t *testing.T) {
 		})
 	}
 }
+
+func TestServer_cmdExpireVariants(t *testing.T) {
+	t.Parallel()
+
+	now := time.UnixMilli(1_000_000)
+
+	tcs := []struct {
+		name     string
+		cmd      resp.Command
+		args     resp.Args
+		arrange  func(*db.DB)
+		wantPTTL int64
+		want     string
+	}{
+		{
+			name: "PEXPIRE sets ttl in milliseconds",
+			cmd:  "PEXPIRE",
+			args: resp.Args{
+				[]byte("pexpire"),
+				[]byte("foo"),
+				[]byte("1500"),
+			},
+			wantPTTL: 1500,
+			want:     ":1\r\n",
+		},
+		{
+			name: "EXPIREAT sets absolute time in seconds",
+			cmd:  "EXPIREAT",
+			args: resp.Args{
+				[]byte("expireat"),
+				[]byte("foo"),
+				[]byte(strconv.FormatInt(now.Add(30*time.Second).Unix(), 10)),
+			},
+			wantPTTL: 30_000,
+			want:     ":1\r\n",
+		},
+		{
+			name: "PEXPIREAT sets absolute time in milliseconds",
+			cmd:  "PEXPIREAT",
+			args: resp.Args{
+				[]byte("pexpireat"),
+				[]byte("foo"),
+				[]byte(strconv.FormatInt(now.Add(2500*time.Millisecond).UnixMilli(), 10)),
+			},
+			wantPTTL: 2500,
+			want:     ":1\r\n",
+		},
+		{
+			name: "EXPIREAT in the past deletes key",
+			cmd:  "EXPIREAT",
+			args: resp.Args{
+				[]byte("expireat"),
+				[]byte("foo"),
+				[]byte(strconv.FormatInt(now.Add(-time.Minute).Unix(), 10)),
+			},
+			wantPTTL: -2,
+			want:     ":1\r\n",
+		},
+		{
+			name: "PEXPIRE with XX skips key without ttl",
+			cmd:  "PEXPIRE",
+			args: resp.Args{
+				[]byte("pexpire"),
+				[]byte("foo"),
+				[]byte("1500"),
+				[]byte("xx"),
+			},
+			wantPTTL: -1,
+			want:     ":0\r\n",
+		},
+		{
+			name: "PEXPIREAT with LT shortens ttl",
+			cmd:  "PEXPIREAT",
+			args: resp.Args{
+				[]byte("pexpireat"),
+				[]byte("bar"),
+				[]byte(strconv.FormatInt(now.Add(time.Second).UnixMilli(), 10)),
+				[]byte("lt"),
+			},
+			arrange: func(db *db.DB) {
+				db.SetString("bar", "v", now.Add(time.Minute))
+			},
+			wantPTTL: -1,
+			want:     ":1\r\n",
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			d := db.New()
+			d.SetString("foo", "bar", time.Time{})
+			if tc.arrange != nil {
+				tc.arrange(d)
+			}
+			srv := &Server{
+				dbMap: map[int]*db.DB{0: d},
+				clock: clock.New(now),
+			}
+			handlers := map[resp.Command]handleFunc{
+				"PEXPIRE":   srv.cmdPExpire,
+				"EXPIREAT":  srv.cmdExpireAt,
+				"PEXPIREAT": srv.cmdPExpireAt,
+			}
+
+			buf := new(bytes.Buffer)
+			w := resp.NewWriter(bufio.NewWriter(buf))
+			req := newRequest(session.New(), tc.cmd, tc.args)
+
+			if err := handlers[tc.cmd](w, req); err != nil {
+				t.Fatalf("%s returned error: %v", tc.cmd, err)
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("flush failed: %v", err)
+			}
+			if got := buf.String(); got != tc.want {
+				t.Fatalf("unexpected payload:\nwant %q\ngot  %q", tc.want, got)
+			}
+			if got := d.PTTL(now, "foo"); got != tc.wantPTTL {
+				t.Fatalf("expected pttl %d, got %d", tc.wantPTTL, got)
+			}
+		})
+	}
+}
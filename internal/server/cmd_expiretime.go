@@ -0,0 +1,41 @@
+package server
+
+import (
+	"github.com/mickamy/minivalkey/internal/resp"
+)
+
+func (s *Server) cmdExpireTime(w *resp.Writer, r *request) error {
+	return s.expireTimeGeneric(w, r, false)
+}
+
+func (s *Server) cmdPExpireTime(w *resp.Writer, r *request) error {
+	return s.expireTimeGeneric(w, r, true)
+}
+
+// expireTimeGeneric replies with the absolute expiration of a key as a unix
+// timestamp in seconds (rounded like Valkey) or milliseconds.
+// -2: key does not exist, -1: key has no associated expire.
+func (s *Server) expireTimeGeneric(w *resp.Writer, r *request, millis bool) error {
+	if err := validateCommand(r.cmd, r.args, validateArgCountExact(2)); err != nil {
+		return w.WriteErrorAndFlush(err)
+	}
+	key := string(r.args[1])
+	at, ok := s.db(r.session).ExpireTime(s.Now(), key)
+
+	var n int64
+	switch {
+	case !ok:
+		n = -2
+	case at.IsZero():
+		n = -1
+	case millis:
+		n = at.UnixMilli()
+	default:
+		n = (at.UnixMilli() + 500) / 1000
+	}
+	if err := w.WriteInt(n); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,150 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/mickamy/minivalkey/internal/clock"
+	"github.com/mickamy/minivalkey/internal/db"
+	"github.com/mickamy/minivalkey/internal/resp"
+	"github.com/mickamy/minivalkey/internal/session"
+)
+
+func TestServer_cmdExpireTime(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+
+	tcs := []struct {
+		name    string
+		args    resp.Args
+		arrange func(*db.DB)
+		want    string
+	}{
+		{
+			name: "returns unix seconds",
+			args: resp.Args{
+				[]byte("expiretime"),
+				[]byte("foo"),
+			},
+			arrange: func(db *db.DB) {
+				db.SetString("foo", "bar", time.UnixMilli(1_010_600))
+			},
+			want: ":1011\r\n",
+		},
+		{
+			name: "returns minus one when key has no expiry",
+			args: resp.Args{
+				[]byte("expiretime"),
+				[]byte("foo"),
+			},
+			arrange: func(db *db.DB) {
+				db.SetString("foo", "bar", time.Time{})
+			},
+			want: ":-1\r\n",
+		},
+		{
+			name: "returns minus two when key is missing",
+			args: resp.Args{
+				[]byte("expiretime"),
+				[]byte("missing"),
+			},
+			want: ":-2\r\n",
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			d := db.New()
+			if tc.arrange != nil {
+				tc.arrange(d)
+			}
+			srv := &Server{
+				dbMap: map[int]*db.DB{0: d},
+				clock: clock.New(now),
+			}
+
+			buf := new(bytes.Buffer)
+			w := resp.NewWriter(bufio.NewWriter(buf))
+			req := newRequest(session.New(), "EXPIRETIME", tc.args)
+
+			if err := srv.cmdExpireTime(w, req); err != nil {
+				t.Fatalf("cmdExpireTime returned error: %v", err)
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("flush failed: %v", err)
+			}
+			if got := buf.String(); got != tc.want {
+				t.Fatalf("unexpected payload:\nwant %q\ngot  %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestServer_cmdPExpireTime(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+
+	tcs := []struct {
+		name    string
+		args    resp.Args
+		arrange func(*db.DB)
+		want    string
+	}{
+		{
+			name: "returns unix milliseconds",
+			args: resp.Args{
+				[]byte("pexpiretime"),
+				[]byte("foo"),
+			},
+			arrange: func(db *db.DB) {
+				db.SetString("foo", "bar", time.UnixMilli(1_010_600))
+			},
+			want: ":1010600\r\n",
+		},
+		{
+			name: "returns minus two when key is missing",
+			args: resp.Args{
+				[]byte("pexpiretime"),
+				[]byte("missing"),
+			},
+			want: ":-2\r\n",
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			d := db.New()
+			if tc.arrange != nil {
+				tc.arrange(d)
+			}
+			srv := &Server{
+				dbMap: map[int]*db.DB{0: d},
+				clock: clock.New(now),
+			}
+
+			buf := new(bytes.Buffer)
+			w := resp.NewWriter(bufio.NewWriter(buf))
+			req := newRequest(session.New(), "PEXPIRETIME", tc.args)
+
+			if err := srv.cmdPExpireTime(w, req); err != nil {
+				t.Fatalf("cmdPExpireTime returned error: %v", err)
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("flush failed: %v", err)
+			}
+			if got := buf.String(); got != tc.want {
+				t.Fatalf("unexpected payload:\nwant %q\ngot  %q", tc.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package server
+
+import (
+	"strings"
+
+	"github.com/mickamy/minivalkey/internal/db"
+	"github.com/mickamy/minivalkey/internal/geo"
+	"github.com/mickamy/minivalkey/internal/resp"
+)
+
+func (s *Server) cmdGeoAdd(w *resp.Writer, r *request) error {
+	// GEOADD key [NX | XX] [CH] longitude latitude member [longitude latitude member ...]
+	if err := validateCommand(r.cmd, r.args, validateArgCountAtLeast(5)); err != nil {
+		return w.WriteErrorAndFlush(err)
+	}
+	key := string(r.args[1])
+	var opts db.ZAddOptions
+	i := 2
+options:
+	for ; i < len(r.args); i++ {
+		switch strings.ToUpper(string(r.args[i])) {
+		case "NX":
+			opts.NX = true
+		case "XX":
+			opts.XX = true
+		case "CH":
+			opts.CH = true
+		default:
+			break options
+		}
+	}
+	if (len(r.args)-i)%3 != 0 || i == len(r.args) || (opts.NX && opts.XX) {
+		return w.WriteErrorAndFlush(ErrSyntax)
+	}
+
+	members := make([]db.ZMember, 0, (len(r.args)-i)/3)
+	for ; i < len(r.args); i += 3 {
+		long, lat, err := parseLongLat(r.args[i], r.args[i+1])
+		if err != nil {
+			return w.WriteErrorAndFlush(err)
+		}
+		score, _ := geo.Score(long, lat)
+		members = append(members, db.ZMember{Member: string(r.args[i+2]), Score: score})
+	}
+
+	n, err := s.db(r.session).ZAdd(s.Now(), key, opts, members...)
+	if err != nil {
+		return w.WriteErrorAndFlush(dbError(err))
+	}
+	if err := w.WriteInt(int64(n)); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// parseLongLat parses a longitude/latitude pair and checks it can be indexed.
+func parseLongLat(longArg, latArg resp.Arg) (float64, float64, error) {
+	long, ok := db.ParseFloat(string(longArg))
+	if !ok {
+		return 0, 0, ErrNotFloat
+	}
+	lat, ok := db.ParseFloat(string(latArg))
+	if !ok {
+		return 0, 0, ErrNotFloat
+	}
+	if !geo.ValidCoords(long, lat) {
+		return 0, 0, invalidLongLatError(long, lat)
+	}
+	return long, lat, nil
+}
@@ -0,0 +1,208 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/mickamy/minivalkey/internal/clock"
+	"github.com/mickamy/minivalkey/internal/db"
+	"github.com/mickamy/minivalkey/internal/resp"
+	"github.com/mickamy/minivalkey/internal/session"
+)
+
+func TestServer_cmdGeoAdd(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+
+	tcs := []struct {
+		name    string
+		args    resp.Args
+		arrange func(*db.DB)
+		assert  func(*testing.T, *db.DB)
+		want    string
+	}{
+		{
+			name: "adds members",
+			args: resp.Args{
+				[]byte("geoadd"),
+				[]byte("Sicily"),
+				[]byte("13.361389"),
+				[]byte("38.115556"),
+				[]byte("Palermo"),
+				[]byte("15.087269"),
+				[]byte("37.502669"),
+				[]byte("Catania"),
+			},
+			assert: func(t *testing.T, d *db.DB) {
+				ms, _, _ := d.ZMembers(now, "Sicily")
+				if want := []db.ZMember{{Member: "Palermo", Score: 3479099956230698}, {Member: "Catania", Score: 3479447370796909}}; !slices.Equal(ms, want) {
+					t.Fatalf("expected %v, got %v", want, ms)
+				}
+			},
+			want: ":2\r\n",
+		},
+		{
+			name: "counts changed members with CH",
+			args: resp.Args{
+				[]byte("geoadd"),
+				[]byte("Sicily"),
+				[]byte("CH"),
+				[]byte("15.087269"),
+				[]byte("37.502669"),
+				[]byte("Catania"),
+				[]byte("13.361389"),
+				[]byte("38.115556"),
+				[]byte("Palermo"),
+			},
+			arrange: func(d *db.DB) {
+				d.ZAdd(now, "Sicily", db.ZAddOptions{}, db.ZMember{Member: "Catania", Score: 3479447370796909}, db.ZMember{Member: "Palermo", Score: 3479030013248308})
+			},
+			assert: func(t *testing.T, d *db.DB) {
+				ms, _, _ := d.ZMembers(now, "Sicily")
+				if want := []db.ZMember{{Member: "Palermo", Score: 3479099956230698}, {Member: "Catania", Score: 3479447370796909}}; !slices.Equal(ms, want) {
+					t.Fatalf("expected %v, got %v", want, ms)
+				}
+			},
+			want: ":1\r\n",
+		},
+		{
+			name: "skips existing members with NX",
+			args: resp.Args{
+				[]byte("geoadd"),
+				[]byte("Sicily"),
+				[]byte("NX"),
+				[]byte("15.087269"),
+				[]byte("37.502669"),
+				[]byte("Catania"),
+				[]byte("13.361389"),
+				[]byte("38.115556"),
+				[]byte("Palermo"),
+			},
+			arrange: func(d *db.DB) {
+				d.ZAdd(now, "Sicily", db.ZAddOptions{}, db.ZMember{Member: "Palermo", Score: 3479030013248308})
+			},
+			assert: func(t *testing.T, d *db.DB) {
+				ms, _, _ := d.ZMembers(now, "Sicily")
+				if want := []db.ZMember{{Member: "Palermo", Score: 3479030013248308}, {Member: "Catania", Score: 3479447370796909}}; !slices.Equal(ms, want) {
+					t.Fatalf("expected %v, got %v", want, ms)
+				}
+			},
+			want: ":1\r\n",
+		},
+		{
+			name: "only updates with XX",
+			args: resp.Args{
+				[]byte("geoadd"),
+				[]byte("Sicily"),
+				[]byte("XX"),
+				[]byte("15.087269"),
+				[]byte("37.502669"),
+				[]byte("Catania"),
+			},
+			assert: func(t *testing.T, d *db.DB) {
+				if _, ok, _ := d.ZMembers(now, "Sicily"); ok {
+					t.Fatalf("expected Sicily to stay missing")
+				}
+			},
+			want: ":0\r\n",
+		},
+		{
+			name: "rejects NX with XX",
+			args: resp.Args{
+				[]byte("geoadd"),
+				[]byte("Sicily"),
+				[]byte("NX"),
+				[]byte("XX"),
+				[]byte("15"),
+				[]byte("37"),
+				[]byte("Catania"),
+			},
+			want: "-ERR syntax error\r\n",
+		},
+		{
+			name: "rejects incomplete triplets",
+			args: resp.Args{
+				[]byte("geoadd"),
+				[]byte("Sicily"),
+				[]byte("15"),
+				[]byte("37"),
+				[]byte("Catania"),
+				[]byte("13"),
+			},
+			want: "-ERR syntax error\r\n",
+		},
+		{
+			name: "rejects non float coordinates",
+			args: resp.Args{
+				[]byte("geoadd"),
+				[]byte("Sicily"),
+				[]byte("abc"),
+				[]byte("37"),
+				[]byte("Catania"),
+			},
+			want: "-ERR value is not a valid float\r\n",
+		},
+		{
+			name: "rejects out of range coordinates",
+			args: resp.Args{
+				[]byte("geoadd"),
+				[]byte("Sicily"),
+				[]byte("15"),
+				[]byte("86"),
+				[]byte("Catania"),
+			},
+			want: "-ERR invalid longitude,latitude pair 15.000000,86.000000\r\n",
+		},
+		{
+			name: "rejects wrong type",
+			args: resp.Args{
+				[]byte("geoadd"),
+				[]byte("Sicily"),
+				[]byte("15"),
+				[]byte("37"),
+				[]byte("Catania"),
+			},
+			arrange: func(d *db.DB) {
+				d.SetString("Sicily", "value", time.Time{})
+			},
+			want: "-WRONGTYPE Operation against a key holding the wrong kind of value\r\n",
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			d := db.New()
+			if tc.arrange != nil {
+				tc.arrange(d)
+			}
+			srv := &Server{
+				dbMap: map[int]*db.DB{0: d},
+				clock: clock.New(now),
+			}
+
+			buf := new(bytes.Buffer)
+			w := resp.NewWriter(bufio.NewWriter(buf))
+			req := newRequest(session.New(), "GEOADD", tc.args)
+
+			if err := srv.cmdGeoAdd(w, req); err != nil {
+				t.Fatalf("cmdGeoAdd returned error: %v", err)
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("flush failed: %v", err)
+			}
+			if got := buf.String(); got != tc.want {
+				t.Fatalf("unexpected payload:\nwant %q\ngot  %q", tc.want, got)
+			}
+			if tc.assert != nil {
+				tc.assert(t, d)
+			}
+		})
+	}
+}
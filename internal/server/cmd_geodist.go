@@ -0,0 +1,69 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mickamy/minivalkey/internal/geo"
+	"github.com/mickamy/minivalkey/internal/resp"
+)
+
+func (s *Server) cmdGeoDist(w *resp.Writer, r *request) error {
+	// GEODIST key member1 member2 [M | KM | FT | MI]
+	if err := validateCommand(r.cmd, r.args, validateArgCountAtLeast(4)); err != nil {
+		return w.WriteErrorAndFlush(err)
+	}
+	if len(r.args) > 5 {
+		return w.WriteErrorAndFlush(ErrSyntax)
+	}
+	key := string(r.args[1])
+	toMeters := 1.0
+	if len(r.args) == 5 {
+		var ok bool
+		if toMeters, ok = parseGeoUnit(r.args[4]); !ok {
+			return w.WriteErrorAndFlush(ErrGeoUnit)
+		}
+	}
+
+	d := s.db(r.session)
+	now := s.Now()
+	score1, ok1, err := d.ZScore(now, key, string(r.args[2]))
+	if err != nil {
+		return w.WriteErrorAndFlush(dbError(err))
+	}
+	score2, ok2, _ := d.ZScore(now, key, string(r.args[3]))
+	if !ok1 || !ok2 {
+		return w.WriteNull()
+	}
+	long1, lat1, ok1 := geo.DecodeScore(score1)
+	long2, lat2, ok2 := geo.DecodeScore(score2)
+	if !ok1 || !ok2 {
+		return w.WriteNull()
+	}
+	if err := w.WriteBulk([]byte(formatGeoDist(geo.Distance(long1, lat1, long2, lat2) / toMeters))); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// parseGeoUnit returns how many meters one unit of a GEO distance argument stands for.
+func parseGeoUnit(a resp.Arg) (float64, bool) {
+	switch strings.ToLower(string(a)) {
+	case "m":
+		return 1, true
+	case "km":
+		return 1000, true
+	case "ft":
+		return 0.3048, true
+	case "mi":
+		return 1609.34, true
+	default:
+		return 0, false
+	}
+}
+
+// formatGeoDist renders a distance with the four decimals Valkey replies with.
+func formatGeoDist(d float64) string {
+	return fmt.Sprintf("%.4f", d)
+}
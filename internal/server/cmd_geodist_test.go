@@ -0,0 +1,147 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/mickamy/minivalkey/internal/clock"
+	"github.com/mickamy/minivalkey/internal/db"
+	"github.com/mickamy/minivalkey/internal/resp"
+	"github.com/mickamy/minivalkey/internal/session"
+)
+
+func TestServer_cmdGeoDist(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+
+	tcs := []struct {
+		name    string
+		args    resp.Args
+		arrange func(*db.DB)
+		want    string
+	}{
+		{
+			name: "returns distance in meters",
+			args: resp.Args{
+				[]byte("geodist"),
+				[]byte("Sicily"),
+				[]byte("Palermo"),
+				[]byte("Catania"),
+			},
+			arrange: func(d *db.DB) {
+				d.ZAdd(now, "Sicily", db.ZAddOptions{}, db.ZMember{Member: "Palermo", Score: 3479099956230698}, db.ZMember{Member: "Catania", Score: 3479447370796909})
+			},
+			want: "$11\r\n166274.1516\r\n",
+		},
+		{
+			name: "returns distance in kilometers",
+			args: resp.Args{
+				[]byte("geodist"),
+				[]byte("Sicily"),
+				[]byte("Palermo"),
+				[]byte("Catania"),
+				[]byte("km"),
+			},
+			arrange: func(d *db.DB) {
+				d.ZAdd(now, "Sicily", db.ZAddOptions{}, db.ZMember{Member: "Palermo", Score: 3479099956230698}, db.ZMember{Member: "Catania", Score: 3479447370796909})
+			},
+			want: "$8\r\n166.2742\r\n",
+		},
+		{
+			name: "returns distance in miles",
+			args: resp.Args{
+				[]byte("geodist"),
+				[]byte("Sicily"),
+				[]byte("Palermo"),
+				[]byte("Catania"),
+				[]byte("MI"),
+			},
+			arrange: func(d *db.DB) {
+				d.ZAdd(now, "Sicily", db.ZAddOptions{}, db.ZMember{Member: "Palermo", Score: 3479099956230698}, db.ZMember{Member: "Catania", Score: 3479447370796909})
+			},
+			want: "$8\r\n103.3182\r\n",
+		},
+		{
+			name: "returns nil for missing member",
+			args: resp.Args{
+				[]byte("geodist"),
+				[]byte("Sicily"),
+				[]byte("Foo"),
+				[]byte("Bar"),
+			},
+			arrange: func(d *db.DB) {
+				d.ZAdd(now, "Sicily", db.ZAddOptions{}, db.ZMember{Member: "Palermo", Score: 3479099956230698}, db.ZMember{Member: "Catania", Score: 3479447370796909})
+			},
+			want: "$-1\r\n",
+		},
+		{
+			name: "rejects unknown unit",
+			args: resp.Args{
+				[]byte("geodist"),
+				[]byte("Sicily"),
+				[]byte("Palermo"),
+				[]byte("Catania"),
+				[]byte("yd"),
+			},
+			want: "-ERR unsupported unit provided. please use M, KM, FT, MI\r\n",
+		},
+		{
+			name: "rejects extra arguments",
+			args: resp.Args{
+				[]byte("geodist"),
+				[]byte("Sicily"),
+				[]byte("Palermo"),
+				[]byte("Catania"),
+				[]byte("km"),
+				[]byte("x"),
+			},
+			want: "-ERR syntax error\r\n",
+		},
+		{
+			name: "rejects wrong type",
+			args: resp.Args{
+				[]byte("geodist"),
+				[]byte("Sicily"),
+				[]byte("Palermo"),
+				[]byte("Catania"),
+			},
+			arrange: func(d *db.DB) {
+				d.SetString("Sicily", "value", time.Time{})
+			},
+			want: "-WRONGTYPE Operation against a key holding the wrong kind of value\r\n",
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			d := db.New()
+			if tc.arrange != nil {
+				tc.arrange(d)
+			}
+			srv := &Server{
+				dbMap: map[int]*db.DB{0: d},
+				clock: clock.New(now),
+			}
+
+			buf := new(bytes.Buffer)
+			w := resp.NewWriter(bufio.NewWriter(buf))
+			req := newRequest(session.New(), "GEODIST", tc.args)
+
+			if err := srv.cmdGeoDist(w, req); err != nil {
+				t.Fatalf("cmdGeoDist returned error: %v", err)
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("flush failed: %v", err)
+			}
+			if got := buf.String(); got != tc.want {
+				t.Fatalf("unexpected payload:\nwant %q\ngot  %q", tc.want, got)
+			}
+		})
+	}
+}
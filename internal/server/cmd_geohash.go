@@ -0,0 +1,36 @@
+package server
+
+import (
+	"github.com/mickamy/minivalkey/internal/geo"
+	"github.com/mickamy/minivalkey/internal/resp"
+)
+
+func (s *Server) cmdGeoHash(w *resp.Writer, r *request) error {
+	// GEOHASH key [member [member ...]]
+	if err := validateCommand(r.cmd, r.args, validateArgCountAtLeast(2)); err != nil {
+		return w.WriteErrorAndFlush(err)
+	}
+	key := string(r.args[1])
+	d := s.db(r.session)
+	now := s.Now()
+
+	if _, _, err := d.ZScore(now, key, ""); err != nil {
+		return w.WriteErrorAndFlush(dbError(err))
+	}
+	if err := w.WriteArrayHeader(len(r.args) - 2); err != nil {
+		return err
+	}
+	for _, m := range r.args[2:] {
+		var hash []byte
+		if score, ok, _ := d.ZScore(now, key, string(m)); ok {
+			if h, ok := geo.HashString(score); ok {
+				hash = []byte(h)
+			}
+		}
+		if err := w.WriteBulkElem(hash); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,92 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/mickamy/minivalkey/internal/clock"
+	"github.com/mickamy/minivalkey/internal/db"
+	"github.com/mickamy/minivalkey/internal/resp"
+	"github.com/mickamy/minivalkey/internal/session"
+)
+
+func TestServer_cmdGeoHash(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+
+	tcs := []struct {
+		name    string
+		args    resp.Args
+		arrange func(*db.DB)
+		want    string
+	}{
+		{
+			name: "returns geohash strings",
+			args: resp.Args{
+				[]byte("geohash"),
+				[]byte("Sicily"),
+				[]byte("Palermo"),
+				[]byte("Catania"),
+				[]byte("NonExisting"),
+			},
+			arrange: func(d *db.DB) {
+				d.ZAdd(now, "Sicily", db.ZAddOptions{}, db.ZMember{Member: "Palermo", Score: 3479099956230698}, db.ZMember{Member: "Catania", Score: 3479447370796909})
+			},
+			want: "*3\r\n$11\r\nsqc8b49rny0\r\n$11\r\nsqdtr74hyu0\r\n$-1\r\n",
+		},
+		{
+			name: "returns nil for missing key",
+			args: resp.Args{
+				[]byte("geohash"),
+				[]byte("Sicily"),
+				[]byte("Palermo"),
+			},
+			want: "*1\r\n$-1\r\n",
+		},
+		{
+			name: "rejects wrong type",
+			args: resp.Args{
+				[]byte("geohash"),
+				[]byte("Sicily"),
+				[]byte("Palermo"),
+			},
+			arrange: func(d *db.DB) {
+				d.SetString("Sicily", "value", time.Time{})
+			},
+			want: "-WRONGTYPE Operation against a key holding the wrong kind of value\r\n",
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			d := db.New()
+			if tc.arrange != nil {
+				tc.arrange(d)
+			}
+			srv := &Server{
+				dbMap: map[int]*db.DB{0: d},
+				clock: clock.New(now),
+			}
+
+			buf := new(bytes.Buffer)
+			w := resp.NewWriter(bufio.NewWriter(buf))
+			req := newRequest(session.New(), "GEOHASH", tc.args)
+
+			if err := srv.cmdGeoHash(w, req); err != nil {
+				t.Fatalf("cmdGeoHash returned error: %v", err)
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("flush failed: %v", err)
+			}
+			if got := buf.String(); got != tc.want {
+				t.Fatalf("unexpected payload:\nwant %q\ngot  %q", tc.want, got)
+			}
+		})
+	}
+}
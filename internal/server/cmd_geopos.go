@@ -0,0 +1,64 @@
+package server
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/mickamy/minivalkey/internal/geo"
+	"github.com/mickamy/minivalkey/internal/resp"
+)
+
+func (s *Server) cmdGeoPos(w *resp.Writer, r *request) error {
+	// GEOPOS key [member [member ...]]
+	if err := validateCommand(r.cmd, r.args, validateArgCountAtLeast(2)); err != nil {
+		return w.WriteErrorAndFlush(err)
+	}
+	key := string(r.args[1])
+	d := s.db(r.session)
+	now := s.Now()
+
+	// Check the type first so a wrong type key fails before the reply starts.
+	if _, _, err := d.ZScore(now, key, ""); err != nil {
+		return w.WriteErrorAndFlush(dbError(err))
+	}
+	if err := w.WriteArrayHeader(len(r.args) - 2); err != nil {
+		return err
+	}
+	for _, m := range r.args[2:] {
+		score, ok, _ := d.ZScore(now, key, string(m))
+		var long, lat float64
+		if ok {
+			long, lat, ok = geo.DecodeScore(score)
+		}
+		if !ok {
+			if err := w.WriteNullArray(); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := writeGeoCoords(w, long, lat); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeGeoCoords writes a [longitude, latitude] pair the way GEOPOS reports it.
+func writeGeoCoords(w *resp.Writer, long, lat float64) error {
+	if err := w.WriteArrayHeader(2); err != nil {
+		return err
+	}
+	if err := w.WriteBulkElem([]byte(formatGeoCoord(long))); err != nil {
+		return err
+	}
+	return w.WriteBulkElem([]byte(formatGeoCoord(lat)))
+}
+
+// formatGeoCoord renders a coordinate with 17 decimals, dropping trailing zeros,
+// like Valkey's human-readable long double replies.
+func formatGeoCoord(f float64) string {
+	s := strconv.FormatFloat(f, 'f', 17, 64)
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
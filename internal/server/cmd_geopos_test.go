@@ -0,0 +1,92 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/mickamy/minivalkey/internal/clock"
+	"github.com/mickamy/minivalkey/internal/db"
+	"github.com/mickamy/minivalkey/internal/resp"
+	"github.com/mickamy/minivalkey/internal/session"
+)
+
+func TestServer_cmdGeoPos(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+
+	tcs := []struct {
+		name    string
+		args    resp.Args
+		arrange func(*db.DB)
+		want    string
+	}{
+		{
+			name: "returns positions",
+			args: resp.Args{
+				[]byte("geopos"),
+				[]byte("Sicily"),
+				[]byte("Palermo"),
+				[]byte("Catania"),
+				[]byte("NonExisting"),
+			},
+			arrange: func(d *db.DB) {
+				d.ZAdd(now, "Sicily", db.ZAddOptions{}, db.ZMember{Member: "Palermo", Score: 3479099956230698}, db.ZMember{Member: "Catania", Score: 3479447370796909})
+			},
+			want: "*3\r\n*2\r\n$20\r\n13.36138933897018433\r\n$20\r\n38.11555639549629859\r\n*2\r\n$20\r\n15.08726745843887329\r\n$20\r\n37.50266842333162032\r\n*-1\r\n",
+		},
+		{
+			name: "returns nil for missing key",
+			args: resp.Args{
+				[]byte("geopos"),
+				[]byte("Sicily"),
+				[]byte("Palermo"),
+			},
+			want: "*1\r\n*-1\r\n",
+		},
+		{
+			name: "rejects wrong type",
+			args: resp.Args{
+				[]byte("geopos"),
+				[]byte("Sicily"),
+				[]byte("Palermo"),
+			},
+			arrange: func(d *db.DB) {
+				d.SetString("Sicily", "value", time.Time{})
+			},
+			want: "-WRONGTYPE Operation against a key holding the wrong kind of value\r\n",
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			d := db.New()
+			if tc.arrange != nil {
+				tc.arrange(d)
+			}
+			srv := &Server{
+				dbMap: map[int]*db.DB{0: d},
+				clock: clock.New(now),
+			}
+
+			buf := new(bytes.Buffer)
+			w := resp.NewWriter(bufio.NewWriter(buf))
+			req := newRequest(session.New(), "GEOPOS", tc.args)
+
+			if err := srv.cmdGeoPos(w, req); err != nil {
+				t.Fatalf("cmdGeoPos returned error: %v", err)
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("flush failed: %v", err)
+			}
+			if got := buf.String(); got != tc.want {
+				t.Fatalf("unexpected payload:\nwant %q\ngot  %q", tc.want, got)
+			}
+		})
+	}
+}
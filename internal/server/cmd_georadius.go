@@ -0,0 +1,41 @@
+package server
+
+import (
+	"github.com/mickamy/minivalkey/internal/resp"
+)
+
+func (s *Server) cmdGeoRadius(w *resp.Writer, r *request) error {
+	// GEORADIUS key longitude latitude radius <M | KM | FT | MI> [WITHCOORD] [WITHDIST] [WITHHASH]
+	//   [COUNT count [ANY]] [ASC | DESC] [STORE key | STOREDIST key]
+	if err := validateCommand(r.cmd, r.args, validateArgCountAtLeast(6)); err != nil {
+		return w.WriteErrorAndFlush(err)
+	}
+	return s.geoSearchGeneric(w, r, 1, geoRadiusCoords)
+}
+
+func (s *Server) cmdGeoRadiusRO(w *resp.Writer, r *request) error {
+	// GEORADIUS_RO key longitude latitude radius <M | KM | FT | MI> [WITHCOORD] [WITHDIST] [WITHHASH]
+	//   [COUNT count [ANY]] [ASC | DESC]
+	if err := validateCommand(r.cmd, r.args, validateArgCountAtLeast(6)); err != nil {
+		return w.WriteErrorAndFlush(err)
+	}
+	return s.geoSearchGeneric(w, r, 1, geoRadiusCoords|geoNoStore)
+}
+
+func (s *Server) cmdGeoRadiusByMember(w *resp.Writer, r *request) error {
+	// GEORADIUSBYMEMBER key member radius <M | KM | FT | MI> [WITHCOORD] [WITHDIST] [WITHHASH]
+	//   [COUNT count [ANY]] [ASC | DESC] [STORE key | STOREDIST key]
+	if err := validateCommand(r.cmd, r.args, validateArgCountAtLeast(5)); err != nil {
+		return w.WriteErrorAndFlush(err)
+	}
+	return s.geoSearchGeneric(w, r, 1, geoRadiusMember)
+}
+
+func (s *Server) cmdGeoRadiusByMemberRO(w *resp.Writer, r *request) error {
+	// GEORADIUSBYMEMBER_RO key member radius <M | KM | FT | MI> [WITHCOORD] [WITHDIST] [WITHHASH]
+	//   [COUNT count [ANY]] [ASC | DESC]
+	if err := validateCommand(r.cmd, r.args, validateArgCountAtLeast(5)); err != nil {
+		return w.WriteErrorAndFlush(err)
+	}
+	return s.geoSearchGeneric(w, r, 1, geoRadiusMember|geoNoStore)
+}
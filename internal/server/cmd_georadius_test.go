@@ -0,0 +1,414 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/mickamy/minivalkey/internal/clock"
+	"github.com/mickamy/minivalkey/internal/db"
+	"github.com/mickamy/minivalkey/internal/resp"
+	"github.com/mickamy/minivalkey/internal/session"
+)
+
+func TestServer_cmdGeoRadius(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+
+	tcs := []struct {
+		name    string
+		args    resp.Args
+		arrange func(*db.DB)
+		assert  func(*testing.T, *db.DB)
+		want    string
+	}{
+		{
+			name: "returns members with distance",
+			args: resp.Args{
+				[]byte("georadius"),
+				[]byte("Sicily"),
+				[]byte("15"),
+				[]byte("37"),
+				[]byte("200"),
+				[]byte("km"),
+				[]byte("WITHDIST"),
+			},
+			arrange: func(d *db.DB) {
+				d.ZAdd(now, "Sicily", db.ZAddOptions{}, db.ZMember{Member: "Palermo", Score: 3479099956230698}, db.ZMember{Member: "Catania", Score: 3479447370796909})
+			},
+			want: "*2\r\n*2\r\n$7\r\nPalermo\r\n$8\r\n190.4424\r\n*2\r\n$7\r\nCatania\r\n$7\r\n56.4413\r\n",
+		},
+		{
+			name: "returns members with coordinates",
+			args: resp.Args{
+				[]byte("georadius"),
+				[]byte("Sicily"),
+				[]byte("15"),
+				[]byte("37"),
+				[]byte("200"),
+				[]byte("km"),
+				[]byte("WITHCOORD"),
+			},
+			arrange: func(d *db.DB) {
+				d.ZAdd(now, "Sicily", db.ZAddOptions{}, db.ZMember{Member: "Palermo", Score: 3479099956230698}, db.ZMember{Member: "Catania", Score: 3479447370796909})
+			},
+			want: "*2\r\n*2\r\n$7\r\nPalermo\r\n*2\r\n$20\r\n13.36138933897018433\r\n$20\r\n38.11555639549629859\r\n*2\r\n$7\r\nCatania\r\n*2\r\n$20\r\n15.08726745843887329\r\n$20\r\n37.50266842333162032\r\n",
+		},
+		{
+			name: "returns closest member with COUNT",
+			args: resp.Args{
+				[]byte("georadius"),
+				[]byte("Sicily"),
+				[]byte("15"),
+				[]byte("37"),
+				[]byte("200"),
+				[]byte("km"),
+				[]byte("COUNT"),
+				[]byte("1"),
+			},
+			arrange: func(d *db.DB) {
+				d.ZAdd(now, "Sicily", db.ZAddOptions{}, db.ZMember{Member: "Palermo", Score: 3479099956230698}, db.ZMember{Member: "Catania", Score: 3479447370796909})
+			},
+			want: "*1\r\n$7\r\nCatania\r\n",
+		},
+		{
+			name: "stores results",
+			args: resp.Args{
+				[]byte("georadius"),
+				[]byte("Sicily"),
+				[]byte("15"),
+				[]byte("37"),
+				[]byte("200"),
+				[]byte("km"),
+				[]byte("STORE"),
+				[]byte("dst"),
+			},
+			arrange: func(d *db.DB) {
+				d.ZAdd(now, "Sicily", db.ZAddOptions{}, db.ZMember{Member: "Palermo", Score: 3479099956230698}, db.ZMember{Member: "Catania", Score: 3479447370796909})
+			},
+			assert: func(t *testing.T, d *db.DB) {
+				ms, _, _ := d.ZMembers(now, "dst")
+				if want := []db.ZMember{{Member: "Palermo", Score: 3479099956230698}, {Member: "Catania", Score: 3479447370796909}}; !slices.Equal(ms, want) {
+					t.Fatalf("expected %v, got %v", want, ms)
+				}
+			},
+			want: ":2\r\n",
+		},
+		{
+			name: "rejects STORE with WITHDIST",
+			args: resp.Args{
+				[]byte("georadius"),
+				[]byte("Sicily"),
+				[]byte("15"),
+				[]byte("37"),
+				[]byte("200"),
+				[]byte("km"),
+				[]byte("WITHDIST"),
+				[]byte("STORE"),
+				[]byte("dst"),
+			},
+			arrange: func(d *db.DB) {
+				d.ZAdd(now, "Sicily", db.ZAddOptions{}, db.ZMember{Member: "Palermo", Score: 3479099956230698}, db.ZMember{Member: "Catania", Score: 3479447370796909})
+			},
+			want: "-ERR STORE option in GEORADIUS is not compatible with WITHDIST, WITHHASH and WITHCOORD options\r\n",
+		},
+		{
+			name: "rejects non numeric radius",
+			args: resp.Args{
+				[]byte("georadius"),
+				[]byte("Sicily"),
+				[]byte("15"),
+				[]byte("37"),
+				[]byte("far"),
+				[]byte("km"),
+			},
+			arrange: func(d *db.DB) {
+				d.ZAdd(now, "Sicily", db.ZAddOptions{}, db.ZMember{Member: "Palermo", Score: 3479099956230698}, db.ZMember{Member: "Catania", Score: 3479447370796909})
+			},
+			want: "-ERR need numeric radius\r\n",
+		},
+		{
+			name: "returns empty array for missing key",
+			args: resp.Args{
+				[]byte("georadius"),
+				[]byte("Sicily"),
+				[]byte("15"),
+				[]byte("37"),
+				[]byte("200"),
+				[]byte("km"),
+			},
+			want: "*0\r\n",
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			d := db.New()
+			if tc.arrange != nil {
+				tc.arrange(d)
+			}
+			srv := &Server{
+				dbMap: map[int]*db.DB{0: d},
+				clock: clock.New(now),
+			}
+
+			buf := new(bytes.Buffer)
+			w := resp.NewWriter(bufio.NewWriter(buf))
+			req := newRequest(session.New(), "GEORADIUS", tc.args)
+
+			if err := srv.cmdGeoRadius(w, req); err != nil {
+				t.Fatalf("cmdGeoRadius returned error: %v", err)
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("flush failed: %v", err)
+			}
+			if got := buf.String(); got != tc.want {
+				t.Fatalf("unexpected payload:\nwant %q\ngot  %q", tc.want, got)
+			}
+			if tc.assert != nil {
+				tc.assert(t, d)
+			}
+		})
+	}
+}
+
+func TestServer_cmdGeoRadiusRO(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+
+	tcs := []struct {
+		name    string
+		args    resp.Args
+		arrange func(*db.DB)
+		want    string
+	}{
+		{
+			name: "returns members",
+			args: resp.Args{
+				[]byte("georadius_ro"),
+				[]byte("Sicily"),
+				[]byte("15"),
+				[]byte("37"),
+				[]byte("100"),
+				[]byte("km"),
+			},
+			arrange: func(d *db.DB) {
+				d.ZAdd(now, "Sicily", db.ZAddOptions{}, db.ZMember{Member: "Palermo", Score: 3479099956230698}, db.ZMember{Member: "Catania", Score: 3479447370796909})
+			},
+			want: "*1\r\n$7\r\nCatania\r\n",
+		},
+		{
+			name: "rejects STORE",
+			args: resp.Args{
+				[]byte("georadius_ro"),
+				[]byte("Sicily"),
+				[]byte("15"),
+				[]byte("37"),
+				[]byte("200"),
+				[]byte("km"),
+				[]byte("STORE"),
+				[]byte("dst"),
+			},
+			arrange: func(d *db.DB) {
+				d.ZAdd(now, "Sicily", db.ZAddOptions{}, db.ZMember{Member: "Palermo", Score: 3479099956230698}, db.ZMember{Member: "Catania", Score: 3479447370796909})
+			},
+			want: "-ERR syntax error\r\n",
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			d := db.New()
+			if tc.arrange != nil {
+				tc.arrange(d)
+			}
+			srv := &Server{
+				dbMap: map[int]*db.DB{0: d},
+				clock: clock.New(now),
+			}
+
+			buf := new(bytes.Buffer)
+			w := resp.NewWriter(bufio.NewWriter(buf))
+			req := newRequest(session.New(), "GEORADIUS_RO", tc.args)
+
+			if err := srv.cmdGeoRadiusRO(w, req); err != nil {
+				t.Fatalf("cmdGeoRadiusRO returned error: %v", err)
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("flush failed: %v", err)
+			}
+			if got := buf.String(); got != tc.want {
+				t.Fatalf("unexpected payload:\nwant %q\ngot  %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestServer_cmdGeoRadiusByMember(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+
+	tcs := []struct {
+		name    string
+		args    resp.Args
+		arrange func(*db.DB)
+		want    string
+	}{
+		{
+			name: "returns members around member",
+			args: resp.Args{
+				[]byte("georadiusbymember"),
+				[]byte("Sicily"),
+				[]byte("Agrigento"),
+				[]byte("100"),
+				[]byte("km"),
+			},
+			arrange: func(d *db.DB) {
+				d.ZAdd(now, "Sicily", db.ZAddOptions{}, db.ZMember{Member: "Palermo", Score: 3479099956230698}, db.ZMember{Member: "Catania", Score: 3479447370796909}, db.ZMember{Member: "Agrigento", Score: 3479030013248308})
+			},
+			want: "*2\r\n$9\r\nAgrigento\r\n$7\r\nPalermo\r\n",
+		},
+		{
+			name: "rejects unknown member",
+			args: resp.Args{
+				[]byte("georadiusbymember"),
+				[]byte("Sicily"),
+				[]byte("Rome"),
+				[]byte("100"),
+				[]byte("km"),
+			},
+			arrange: func(d *db.DB) {
+				d.ZAdd(now, "Sicily", db.ZAddOptions{}, db.ZMember{Member: "Palermo", Score: 3479099956230698}, db.ZMember{Member: "Catania", Score: 3479447370796909})
+			},
+			want: "-ERR could not decode requested zset member\r\n",
+		},
+		{
+			name: "replies zero with STORE on missing key",
+			args: resp.Args{
+				[]byte("georadiusbymember"),
+				[]byte("Sicily"),
+				[]byte("Rome"),
+				[]byte("100"),
+				[]byte("km"),
+				[]byte("STORE"),
+				[]byte("dst"),
+			},
+			want: ":0\r\n",
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			d := db.New()
+			if tc.arrange != nil {
+				tc.arrange(d)
+			}
+			srv := &Server{
+				dbMap: map[int]*db.DB{0: d},
+				clock: clock.New(now),
+			}
+
+			buf := new(bytes.Buffer)
+			w := resp.NewWriter(bufio.NewWriter(buf))
+			req := newRequest(session.New(), "GEORADIUSBYMEMBER", tc.args)
+
+			if err := srv.cmdGeoRadiusByMember(w, req); err != nil {
+				t.Fatalf("cmdGeoRadiusByMember returned error: %v", err)
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("flush failed: %v", err)
+			}
+			if got := buf.String(); got != tc.want {
+				t.Fatalf("unexpected payload:\nwant %q\ngot  %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestServer_cmdGeoRadiusByMemberRO(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+
+	tcs := []struct {
+		name    string
+		args    resp.Args
+		arrange func(*db.DB)
+		want    string
+	}{
+		{
+			name: "returns members around member",
+			args: resp.Args{
+				[]byte("georadiusbymember_ro"),
+				[]byte("Sicily"),
+				[]byte("Agrigento"),
+				[]byte("100"),
+				[]byte("km"),
+			},
+			arrange: func(d *db.DB) {
+				d.ZAdd(now, "Sicily", db.ZAddOptions{}, db.ZMember{Member: "Palermo", Score: 3479099956230698}, db.ZMember{Member: "Catania", Score: 3479447370796909}, db.ZMember{Member: "Agrigento", Score: 3479030013248308})
+			},
+			want: "*2\r\n$9\r\nAgrigento\r\n$7\r\nPalermo\r\n",
+		},
+		{
+			name: "rejects STOREDIST",
+			args: resp.Args{
+				[]byte("georadiusbymember_ro"),
+				[]byte("Sicily"),
+				[]byte("Agrigento"),
+				[]byte("100"),
+				[]byte("km"),
+				[]byte("STOREDIST"),
+				[]byte("dst"),
+			},
+			arrange: func(d *db.DB) {
+				d.ZAdd(now, "Sicily", db.ZAddOptions{}, db.ZMember{Member: "Palermo", Score: 3479099956230698}, db.ZMember{Member: "Catania", Score: 3479447370796909}, db.ZMember{Member: "Agrigento", Score: 3479030013248308})
+			},
+			want: "-ERR syntax error\r\n",
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			d := db.New()
+			if tc.arrange != nil {
+				tc.arrange(d)
+			}
+			srv := &Server{
+				dbMap: map[int]*db.DB{0: d},
+				clock: clock.New(now),
+			}
+
+			buf := new(bytes.Buffer)
+			w := resp.NewWriter(bufio.NewWriter(buf))
+			req := newRequest(session.New(), "GEORADIUSBYMEMBER_RO", tc.args)
+
+			if err := srv.cmdGeoRadiusByMemberRO(w, req); err != nil {
+				t.Fatalf("cmdGeoRadiusByMemberRO returned error: %v", err)
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("flush failed: %v", err)
+			}
+			if got := buf.String(); got != tc.want {
+				t.Fatalf("unexpected payload:\nwant %q\ngot  %q", tc.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,349 @@
+package server
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/mickamy/minivalkey/internal/db"
+	"github.com/mickamy/minivalkey/internal/geo"
+	"github.com/mickamy/minivalkey/internal/resp"
+)
+
+// geoSearchFlags tells geoSearchGeneric which command it serves, like Valkey's georadiusGeneric flags.
+type geoSearchFlags int
+
+const (
+	geoRadiusCoords geoSearchFlags = 1 << iota // GEORADIUS: center given as longitude latitude
+	geoRadiusMember                            // GEORADIUSBYMEMBER: center given as a member
+	geoNoStore                                 // *_RO variants: STORE and STOREDIST are rejected
+	geoSearch                                  // GEOSEARCH: FROM* and BY* options
+	geoSearchStore                             // GEOSEARCHSTORE: destination key first
+)
+
+// geoPoint is a member found by a GEO search.
+type geoPoint struct {
+	member    string
+	score     float64
+	dist      float64
+	long, lat float64
+}
+
+func (s *Server) cmdGeoSearch(w *resp.Writer, r *request) error {
+	// GEOSEARCH key <FROMMEMBER member | FROMLONLAT longitude latitude>
+	//   <BYRADIUS radius <M | KM | FT | MI> | BYBOX width height <M | KM | FT | MI>>
+	//   [ASC | DESC] [COUNT count [ANY]] [WITHCOORD] [WITHDIST] [WITHHASH]
+	if err := validateCommand(r.cmd, r.args, validateArgCountAtLeast(7)); err != nil {
+		return w.WriteErrorAndFlush(err)
+	}
+	return s.geoSearchGeneric(w, r, 1, geoSearch)
+}
+
+func (s *Server) cmdGeoSearchStore(w *resp.Writer, r *request) error {
+	// GEOSEARCHSTORE destination source <FROMMEMBER member | FROMLONLAT longitude latitude>
+	//   <BYRADIUS radius <M | KM | FT | MI> | BYBOX width height <M | KM | FT | MI>>
+	//   [ASC | DESC] [COUNT count [ANY]] [STOREDIST]
+	if err := validateCommand(r.cmd, r.args, validateArgCountAtLeast(8)); err != nil {
+		return w.WriteErrorAndFlush(err)
+	}
+	return s.geoSearchGeneric(w, r, 2, geoSearch|geoSearchStore)
+}
+
+// geoSearchGeneric implements the GEOSEARCH and GEORADIUS command families, following
+// Valkey's georadiusGeneric so options are validated in the same order.
+func (s *Server) geoSearchGeneric(w *resp.Writer, r *request, srcIdx int, flags geoSearchFlags) error {
+	d := s.db(r.session)
+	now := s.Now()
+	members, exists, err := d.ZMembers(now, string(r.args[srcIdx]))
+	if err != nil {
+		return w.WriteErrorAndFlush(dbError(err))
+	}
+
+	var (
+		shape    = geo.Shape{}
+		baseArgs int
+		storeKey string
+		store    bool
+	)
+	switch {
+	case flags&geoRadiusCoords != 0:
+		baseArgs = 6
+		if shape.Long, shape.Lat, err = parseLongLat(r.args[2], r.args[3]); err != nil {
+			return w.WriteErrorAndFlush(err)
+		}
+		if shape.Radius, shape.Conversion, err = parseGeoRadius(r.args[4], r.args[5]); err != nil {
+			return w.WriteErrorAndFlush(err)
+		}
+	case flags&geoRadiusMember != 0:
+		baseArgs = 5
+		// Without a source key the remaining arguments are still parsed, so
+		// the reply matches the STORE option.
+		if exists {
+			var ok bool
+			if shape.Long, shape.Lat, ok = geoMemberCoords(members, string(r.args[2])); !ok {
+				return w.WriteErrorAndFlush(ErrGeoMember)
+			}
+			if shape.Radius, shape.Conversion, err = parseGeoRadius(r.args[3], r.args[4]); err != nil {
+				return w.WriteErrorAndFlush(err)
+			}
+		}
+	default:
+		baseArgs = 2
+		if flags&geoSearchStore != 0 {
+			baseArgs = 3
+			storeKey, store = string(r.args[1]), true
+		}
+	}
+
+	var (
+		withDist, withHash, withCoord bool
+		fromMember, fromLonLat        bool
+		byRadius, byBox               bool
+		storeDist, anyMatch           bool
+		sortDir                       int // 0: unsorted, 1: ASC, -1: DESC
+		count                         int64
+	)
+	args := r.args[baseArgs:]
+	for i := 0; i < len(args); i++ {
+		remaining := len(args) - i - 1
+		switch opt := strings.ToUpper(string(args[i])); {
+		case opt == "WITHDIST":
+			withDist = true
+		case opt == "WITHHASH":
+			withHash = true
+		case opt == "WITHCOORD":
+			withCoord = true
+		case opt == "ANY":
+			anyMatch = true
+		case opt == "ASC":
+			sortDir = 1
+		case opt == "DESC":
+			sortDir = -1
+		case opt == "COUNT" && remaining >= 1:
+			var ok bool
+			if count, ok = resp.ParseInt(args[i+1]); !ok {
+				return w.WriteErrorAndFlush(ErrValueNotInteger)
+			}
+			if count <= 0 {
+				return w.WriteErrorAndFlush(ErrGeoCount)
+			}
+			i++
+		case (opt == "STORE" || opt == "STOREDIST") && remaining >= 1 && flags&(geoNoStore|geoSearch) == 0:
+			storeKey, store = string(args[i+1]), true
+			storeDist = opt == "STOREDIST"
+			i++
+		case opt == "STOREDIST" && flags&geoSearchStore != 0:
+			storeDist = true
+		case opt == "FROMMEMBER" && remaining >= 1 && flags&geoSearch != 0 && !fromLonLat:
+			if exists {
+				var ok bool
+				if shape.Long, shape.Lat, ok = geoMemberCoords(members, string(args[i+1])); !ok {
+					return w.WriteErrorAndFlush(ErrGeoMember)
+				}
+			}
+			fromMember = true
+			i++
+		case opt == "FROMLONLAT" && remaining >= 2 && flags&geoSearch != 0 && !fromMember:
+			if shape.Long, shape.Lat, err = parseLongLat(args[i+1], args[i+2]); err != nil {
+				return w.WriteErrorAndFlush(err)
+			}
+			fromLonLat = true
+			i += 2
+		case opt == "BYRADIUS" && remaining >= 2 && flags&geoSearch != 0 && !byBox:
+			if shape.Radius, shape.Conversion, err = parseGeoRadius(args[i+1], args[i+2]); err != nil {
+				return w.WriteErrorAndFlush(err)
+			}
+			shape.Box = false
+			byRadius = true
+			i += 2
+		case opt == "BYBOX" && remaining >= 3 && flags&geoSearch != 0 && !byRadius:
+			if shape.Width, shape.Height, shape.Conversion, err = parseGeoBox(args[i+1], args[i+2], args[i+3]); err != nil {
+				return w.WriteErrorAndFlush(err)
+			}
+			shape.Box = true
+			byBox = true
+			i += 3
+		default:
+			return w.WriteErrorAndFlush(ErrSyntax)
+		}
+	}
+
+	if store && (withDist || withHash || withCoord) {
+		name := "STORE option in GEORADIUS"
+		if flags&geoSearchStore != 0 {
+			name = "GEOSEARCHSTORE"
+		}
+		return w.WriteErrorAndFlush(fmt.Errorf("ERR %s is not compatible with WITHDIST, WITHHASH and WITHCOORD options", name))
+	}
+	if flags&geoSearch != 0 && fromMember == fromLonLat {
+		return w.WriteErrorAndFlush(fmt.Errorf("ERR exactly one of FROMMEMBER or FROMLONLAT can be specified for %s", r.args[0]))
+	}
+	if flags&geoSearch != 0 && byRadius == byBox {
+		return w.WriteErrorAndFlush(fmt.Errorf("ERR exactly one of BYRADIUS and BYBOX can be specified for %s", r.args[0]))
+	}
+	if anyMatch && count == 0 {
+		return w.WriteErrorAndFlush(ErrGeoAnyCount)
+	}
+
+	if !exists {
+		if store {
+			d.Del(now, storeKey)
+			if err := w.WriteInt(0); err != nil {
+				return err
+			}
+			return nil
+		}
+		if err := w.WriteEmptyArray(); err != nil {
+			return err
+		}
+		return nil
+	}
+
+	// Returning the closest entries needs sorting, unless ANY was given.
+	if count != 0 && sortDir == 0 && !anyMatch {
+		sortDir = 1
+	}
+	limit := 0
+	if anyMatch {
+		limit = int(count)
+	}
+	points := geoPointsInShape(members, shape, limit)
+	switch sortDir {
+	case 1:
+		slices.SortStableFunc(points, func(a, b geoPoint) int { return cmp.Compare(a.dist, b.dist) })
+	case -1:
+		slices.SortStableFunc(points, func(a, b geoPoint) int { return cmp.Compare(b.dist, a.dist) })
+	}
+	if count != 0 && int64(len(points)) > count {
+		points = points[:count]
+	}
+
+	if store {
+		stored := make([]db.ZMember, 0, len(points))
+		for _, p := range points {
+			score := p.score
+			if storeDist {
+				score = p.dist / shape.Conversion
+			}
+			stored = append(stored, db.ZMember{Member: p.member, Score: score})
+		}
+		d.ZStore(now, storeKey, stored)
+		if err := w.WriteInt(int64(len(points))); err != nil {
+			return err
+		}
+		return nil
+	}
+
+	extra := 0
+	for _, on := range []bool{withDist, withHash, withCoord} {
+		if on {
+			extra++
+		}
+	}
+	if err := w.WriteArrayHeader(len(points)); err != nil {
+		return err
+	}
+	for _, p := range points {
+		if extra > 0 {
+			if err := w.WriteArrayHeader(extra + 1); err != nil {
+				return err
+			}
+		}
+		if err := w.WriteBulkElem([]byte(p.member)); err != nil {
+			return err
+		}
+		if withDist {
+			if err := w.WriteBulkElem([]byte(formatGeoDist(p.dist / shape.Conversion))); err != nil {
+				return err
+			}
+		}
+		if withHash {
+			if err := w.WriteIntElem(int64(p.score)); err != nil {
+				return err
+			}
+		}
+		if withCoord {
+			if err := writeGeoCoords(w, p.long, p.lat); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// geoPointsInShape scans the geohash cells covering shape and returns the
+// members inside it in scan order. A positive limit stops the scan early.
+func geoPointsInShape(members []db.ZMember, shape geo.Shape, limit int) []geoPoint {
+	var points []geoPoint
+	for _, rng := range shape.Ranges() {
+		if limit > 0 && len(points) >= limit {
+			break
+		}
+		i := sort.Search(len(members), func(i int) bool { return members[i].Score >= rng.Min })
+		for ; i < len(members) && members[i].Score < rng.Max; i++ {
+			m := members[i]
+			long, lat, ok := geo.DecodeScore(m.Score)
+			if !ok {
+				continue
+			}
+			dist, ok := shape.Contains(long, lat)
+			if !ok {
+				continue
+			}
+			points = append(points, geoPoint{member: m.Member, score: m.Score, dist: dist, long: long, lat: lat})
+			if limit > 0 && len(points) >= limit {
+				break
+			}
+		}
+	}
+	return points
+}
+
+// geoMemberCoords returns the position stored for member.
+func geoMemberCoords(members []db.ZMember, member string) (float64, float64, bool) {
+	for _, m := range members {
+		if m.Member == member {
+			return geo.DecodeScore(m.Score)
+		}
+	}
+	return 0, 0, false
+}
+
+// parseGeoRadius parses a radius and its unit, returning the radius and the unit in meters.
+func parseGeoRadius(radiusArg, unitArg resp.Arg) (float64, float64, error) {
+	radius, ok := db.ParseFloat(string(radiusArg))
+	if !ok {
+		return 0, 0, ErrGeoRadius
+	}
+	if radius < 0 {
+		return 0, 0, ErrGeoNegativeRadius
+	}
+	toMeters, ok := parseGeoUnit(unitArg)
+	if !ok {
+		return 0, 0, ErrGeoUnit
+	}
+	return radius, toMeters, nil
+}
+
+// parseGeoBox parses a box width, height and unit, returning the unit in meters last.
+func parseGeoBox(widthArg, heightArg, unitArg resp.Arg) (float64, float64, float64, error) {
+	width, ok := db.ParseFloat(string(widthArg))
+	if !ok {
+		return 0, 0, 0, ErrGeoWidth
+	}
+	height, ok := db.ParseFloat(string(heightArg))
+	if !ok {
+		return 0, 0, 0, ErrGeoHeight
+	}
+	if width < 0 || height < 0 {
+		return 0, 0, 0, ErrGeoNegativeBox
+	}
+	toMeters, ok := parseGeoUnit(unitArg)
+	if !ok {
+		return 0, 0, 0, ErrGeoUnit
+	}
+	return width, height, toMeters, nil
+}
@@ -0,0 +1,491 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"math"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/mickamy/minivalkey/internal/clock"
+	"github.com/mickamy/minivalkey/internal/db"
+	"github.com/mickamy/minivalkey/internal/resp"
+	"github.com/mickamy/minivalkey/internal/session"
+)
+
+func TestServer_cmdGeoSearch(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+
+	tcs := []struct {
+		name    string
+		args    resp.Args
+		arrange func(*db.DB)
+		want    string
+	}{
+		{
+			name: "searches by radius",
+			args: resp.Args{
+				[]byte("geosearch"),
+				[]byte("Sicily"),
+				[]byte("FROMLONLAT"),
+				[]byte("15"),
+				[]byte("37"),
+				[]byte("BYRADIUS"),
+				[]byte("200"),
+				[]byte("km"),
+				[]byte("ASC"),
+			},
+			arrange: func(d *db.DB) {
+				d.ZAdd(now, "Sicily", db.ZAddOptions{}, db.ZMember{Member: "Palermo", Score: 3479099956230698}, db.ZMember{Member: "Catania", Score: 3479447370796909}, db.ZMember{Member: "edge1", Score: 3479273021651468}, db.ZMember{Member: "edge2", Score: 3481342659049484})
+			},
+			want: "*2\r\n$7\r\nCatania\r\n$7\r\nPalermo\r\n",
+		},
+		{
+			name: "searches by box with distance and coordinates",
+			args: resp.Args{
+				[]byte("geosearch"),
+				[]byte("Sicily"),
+				[]byte("FROMLONLAT"),
+				[]byte("15"),
+				[]byte("37"),
+				[]byte("BYBOX"),
+				[]byte("400"),
+				[]byte("400"),
+				[]byte("km"),
+				[]byte("ASC"),
+				[]byte("WITHCOORD"),
+				[]byte("WITHDIST"),
+			},
+			arrange: func(d *db.DB) {
+				d.ZAdd(now, "Sicily", db.ZAddOptions{}, db.ZMember{Member: "Palermo", Score: 3479099956230698}, db.ZMember{Member: "Catania", Score: 3479447370796909}, db.ZMember{Member: "edge1", Score: 3479273021651468}, db.ZMember{Member: "edge2", Score: 3481342659049484})
+			},
+			want: "*4\r\n*3\r\n$7\r\nCatania\r\n$7\r\n56.4413\r\n*2\r\n$20\r\n15.08726745843887329\r\n$20\r\n37.50266842333162032\r\n*3\r\n$7\r\nPalermo\r\n$8\r\n190.4424\r\n*2\r\n$20\r\n13.36138933897018433\r\n$20\r\n38.11555639549629859\r\n*3\r\n$5\r\nedge2\r\n$8\r\n279.7403\r\n*2\r\n$20\r\n17.24151045083999634\r\n$20\r\n38.78813451624225195\r\n*3\r\n$5\r\nedge1\r\n$8\r\n279.7405\r\n*2\r\n$19\r\n12.7584877610206604\r\n$20\r\n38.78813451624225195\r\n",
+		},
+		{
+			name: "searches from member sorted descending with hash",
+			args: resp.Args{
+				[]byte("geosearch"),
+				[]byte("Sicily"),
+				[]byte("FROMMEMBER"),
+				[]byte("Palermo"),
+				[]byte("BYRADIUS"),
+				[]byte("200"),
+				[]byte("km"),
+				[]byte("DESC"),
+				[]byte("WITHHASH"),
+			},
+			arrange: func(d *db.DB) {
+				d.ZAdd(now, "Sicily", db.ZAddOptions{}, db.ZMember{Member: "Palermo", Score: 3479099956230698}, db.ZMember{Member: "Catania", Score: 3479447370796909}, db.ZMember{Member: "edge1", Score: 3479273021651468}, db.ZMember{Member: "edge2", Score: 3481342659049484})
+			},
+			want: "*3\r\n*2\r\n$7\r\nCatania\r\n:3479447370796909\r\n*2\r\n$5\r\nedge1\r\n:3479273021651468\r\n*2\r\n$7\r\nPalermo\r\n:3479099956230698\r\n",
+		},
+		{
+			name: "limits results with COUNT",
+			args: resp.Args{
+				[]byte("geosearch"),
+				[]byte("Sicily"),
+				[]byte("FROMLONLAT"),
+				[]byte("15"),
+				[]byte("37"),
+				[]byte("BYBOX"),
+				[]byte("400"),
+				[]byte("400"),
+				[]byte("km"),
+				[]byte("COUNT"),
+				[]byte("2"),
+				[]byte("DESC"),
+			},
+			arrange: func(d *db.DB) {
+				d.ZAdd(now, "Sicily", db.ZAddOptions{}, db.ZMember{Member: "Palermo", Score: 3479099956230698}, db.ZMember{Member: "Catania", Score: 3479447370796909}, db.ZMember{Member: "edge1", Score: 3479273021651468}, db.ZMember{Member: "edge2", Score: 3481342659049484})
+			},
+			want: "*2\r\n$5\r\nedge1\r\n$5\r\nedge2\r\n",
+		},
+		{
+			name: "returns empty array for missing key",
+			args: resp.Args{
+				[]byte("geosearch"),
+				[]byte("Sicily"),
+				[]byte("FROMMEMBER"),
+				[]byte("Palermo"),
+				[]byte("BYRADIUS"),
+				[]byte("200"),
+				[]byte("km"),
+			},
+			want: "*0\r\n",
+		},
+		{
+			name: "rejects unknown member",
+			args: resp.Args{
+				[]byte("geosearch"),
+				[]byte("Sicily"),
+				[]byte("FROMMEMBER"),
+				[]byte("Rome"),
+				[]byte("BYRADIUS"),
+				[]byte("200"),
+				[]byte("km"),
+			},
+			arrange: func(d *db.DB) {
+				d.ZAdd(now, "Sicily", db.ZAddOptions{}, db.ZMember{Member: "Palermo", Score: 3479099956230698}, db.ZMember{Member: "Catania", Score: 3479447370796909})
+			},
+			want: "-ERR could not decode requested zset member\r\n",
+		},
+		{
+			name: "rejects both origins",
+			args: resp.Args{
+				[]byte("geosearch"),
+				[]byte("Sicily"),
+				[]byte("FROMLONLAT"),
+				[]byte("15"),
+				[]byte("37"),
+				[]byte("FROMMEMBER"),
+				[]byte("Palermo"),
+				[]byte("BYRADIUS"),
+				[]byte("200"),
+				[]byte("km"),
+			},
+			arrange: func(d *db.DB) {
+				d.ZAdd(now, "Sicily", db.ZAddOptions{}, db.ZMember{Member: "Palermo", Score: 3479099956230698}, db.ZMember{Member: "Catania", Score: 3479447370796909})
+			},
+			want: "-ERR syntax error\r\n",
+		},
+		{
+			name: "requires an origin",
+			args: resp.Args{
+				[]byte("geosearch"),
+				[]byte("Sicily"),
+				[]byte("BYRADIUS"),
+				[]byte("200"),
+				[]byte("km"),
+				[]byte("ASC"),
+				[]byte("WITHDIST"),
+			},
+			arrange: func(d *db.DB) {
+				d.ZAdd(now, "Sicily", db.ZAddOptions{}, db.ZMember{Member: "Palermo", Score: 3479099956230698}, db.ZMember{Member: "Catania", Score: 3479447370796909})
+			},
+			want: "-ERR exactly one of FROMMEMBER or FROMLONLAT can be specified for geosearch\r\n",
+		},
+		{
+			name: "requires a shape",
+			args: resp.Args{
+				[]byte("geosearch"),
+				[]byte("Sicily"),
+				[]byte("FROMLONLAT"),
+				[]byte("15"),
+				[]byte("37"),
+				[]byte("ASC"),
+				[]byte("WITHDIST"),
+			},
+			arrange: func(d *db.DB) {
+				d.ZAdd(now, "Sicily", db.ZAddOptions{}, db.ZMember{Member: "Palermo", Score: 3479099956230698}, db.ZMember{Member: "Catania", Score: 3479447370796909})
+			},
+			want: "-ERR exactly one of BYRADIUS and BYBOX can be specified for geosearch\r\n",
+		},
+		{
+			name: "rejects ANY without COUNT",
+			args: resp.Args{
+				[]byte("geosearch"),
+				[]byte("Sicily"),
+				[]byte("FROMLONLAT"),
+				[]byte("15"),
+				[]byte("37"),
+				[]byte("BYRADIUS"),
+				[]byte("200"),
+				[]byte("km"),
+				[]byte("ANY"),
+			},
+			arrange: func(d *db.DB) {
+				d.ZAdd(now, "Sicily", db.ZAddOptions{}, db.ZMember{Member: "Palermo", Score: 3479099956230698}, db.ZMember{Member: "Catania", Score: 3479447370796909})
+			},
+			want: "-ERR the ANY argument requires COUNT argument\r\n",
+		},
+		{
+			name: "rejects non positive COUNT",
+			args: resp.Args{
+				[]byte("geosearch"),
+				[]byte("Sicily"),
+				[]byte("FROMLONLAT"),
+				[]byte("15"),
+				[]byte("37"),
+				[]byte("BYRADIUS"),
+				[]byte("200"),
+				[]byte("km"),
+				[]byte("COUNT"),
+				[]byte("0"),
+			},
+			arrange: func(d *db.DB) {
+				d.ZAdd(now, "Sicily", db.ZAddOptions{}, db.ZMember{Member: "Palermo", Score: 3479099956230698}, db.ZMember{Member: "Catania", Score: 3479447370796909})
+			},
+			want: "-ERR COUNT must be > 0\r\n",
+		},
+		{
+			name: "rejects negative radius",
+			args: resp.Args{
+				[]byte("geosearch"),
+				[]byte("Sicily"),
+				[]byte("FROMLONLAT"),
+				[]byte("15"),
+				[]byte("37"),
+				[]byte("BYRADIUS"),
+				[]byte("-1"),
+				[]byte("km"),
+			},
+			arrange: func(d *db.DB) {
+				d.ZAdd(now, "Sicily", db.ZAddOptions{}, db.ZMember{Member: "Palermo", Score: 3479099956230698}, db.ZMember{Member: "Catania", Score: 3479447370796909})
+			},
+			want: "-ERR radius cannot be negative\r\n",
+		},
+		{
+			name: "rejects non numeric height",
+			args: resp.Args{
+				[]byte("geosearch"),
+				[]byte("Sicily"),
+				[]byte("FROMLONLAT"),
+				[]byte("15"),
+				[]byte("37"),
+				[]byte("BYBOX"),
+				[]byte("1"),
+				[]byte("x"),
+				[]byte("km"),
+			},
+			arrange: func(d *db.DB) {
+				d.ZAdd(now, "Sicily", db.ZAddOptions{}, db.ZMember{Member: "Palermo", Score: 3479099956230698}, db.ZMember{Member: "Catania", Score: 3479447370796909})
+			},
+			want: "-ERR need numeric height\r\n",
+		},
+		{
+			name: "rejects STORE",
+			args: resp.Args{
+				[]byte("geosearch"),
+				[]byte("Sicily"),
+				[]byte("FROMLONLAT"),
+				[]byte("15"),
+				[]byte("37"),
+				[]byte("BYRADIUS"),
+				[]byte("200"),
+				[]byte("km"),
+				[]byte("STORE"),
+				[]byte("dst"),
+			},
+			arrange: func(d *db.DB) {
+				d.ZAdd(now, "Sicily", db.ZAddOptions{}, db.ZMember{Member: "Palermo", Score: 3479099956230698}, db.ZMember{Member: "Catania", Score: 3479447370796909})
+			},
+			want: "-ERR syntax error\r\n",
+		},
+		{
+			name: "rejects wrong type",
+			args: resp.Args{
+				[]byte("geosearch"),
+				[]byte("Sicily"),
+				[]byte("FROMLONLAT"),
+				[]byte("15"),
+				[]byte("37"),
+				[]byte("BYRADIUS"),
+				[]byte("200"),
+				[]byte("km"),
+			},
+			arrange: func(d *db.DB) {
+				d.SetString("Sicily", "value", time.Time{})
+			},
+			want: "-WRONGTYPE Operation against a key holding the wrong kind of value\r\n",
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			d := db.New()
+			if tc.arrange != nil {
+				tc.arrange(d)
+			}
+			srv := &Server{
+				dbMap: map[int]*db.DB{0: d},
+				clock: clock.New(now),
+			}
+
+			buf := new(bytes.Buffer)
+			w := resp.NewWriter(bufio.NewWriter(buf))
+			req := newRequest(session.New(), "GEOSEARCH", tc.args)
+
+			if err := srv.cmdGeoSearch(w, req); err != nil {
+				t.Fatalf("cmdGeoSearch returned error: %v", err)
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("flush failed: %v", err)
+			}
+			if got := buf.String(); got != tc.want {
+				t.Fatalf("unexpected payload:\nwant %q\ngot  %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestServer_cmdGeoSearchStore(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+
+	tcs := []struct {
+		name    string
+		args    resp.Args
+		arrange func(*db.DB)
+		assert  func(*testing.T, *db.DB)
+		want    string
+	}{
+		{
+			name: "stores members with their scores",
+			args: resp.Args{
+				[]byte("geosearchstore"),
+				[]byte("dst"),
+				[]byte("Sicily"),
+				[]byte("FROMLONLAT"),
+				[]byte("15"),
+				[]byte("37"),
+				[]byte("BYBOX"),
+				[]byte("400"),
+				[]byte("400"),
+				[]byte("km"),
+				[]byte("ASC"),
+				[]byte("COUNT"),
+				[]byte("3"),
+			},
+			arrange: func(d *db.DB) {
+				d.ZAdd(now, "Sicily", db.ZAddOptions{}, db.ZMember{Member: "Palermo", Score: 3479099956230698}, db.ZMember{Member: "Catania", Score: 3479447370796909}, db.ZMember{Member: "edge1", Score: 3479273021651468}, db.ZMember{Member: "edge2", Score: 3481342659049484})
+			},
+			assert: func(t *testing.T, d *db.DB) {
+				ms, _, _ := d.ZMembers(now, "dst")
+				if want := []db.ZMember{{Member: "Palermo", Score: 3479099956230698}, {Member: "Catania", Score: 3479447370796909}, {Member: "edge2", Score: 3481342659049484}}; !slices.Equal(ms, want) {
+					t.Fatalf("expected %v, got %v", want, ms)
+				}
+			},
+			want: ":3\r\n",
+		},
+		{
+			name: "stores distances with STOREDIST",
+			args: resp.Args{
+				[]byte("geosearchstore"),
+				[]byte("dst"),
+				[]byte("Sicily"),
+				[]byte("FROMLONLAT"),
+				[]byte("15"),
+				[]byte("37"),
+				[]byte("BYRADIUS"),
+				[]byte("100"),
+				[]byte("km"),
+				[]byte("STOREDIST"),
+			},
+			arrange: func(d *db.DB) {
+				d.ZAdd(now, "Sicily", db.ZAddOptions{}, db.ZMember{Member: "Palermo", Score: 3479099956230698}, db.ZMember{Member: "Catania", Score: 3479447370796909}, db.ZMember{Member: "edge1", Score: 3479273021651468}, db.ZMember{Member: "edge2", Score: 3481342659049484})
+			},
+			assert: func(t *testing.T, d *db.DB) {
+				ms, _, _ := d.ZMembers(now, "dst")
+				if len(ms) != 1 || ms[0].Member != "Catania" || math.Abs(ms[0].Score-56.4413) > 0.0001 {
+					t.Fatalf("unexpected members %v", ms)
+				}
+			},
+			want: ":1\r\n",
+		},
+		{
+			name: "deletes destination when nothing matches",
+			args: resp.Args{
+				[]byte("geosearchstore"),
+				[]byte("dst"),
+				[]byte("Sicily"),
+				[]byte("FROMLONLAT"),
+				[]byte("0"),
+				[]byte("0"),
+				[]byte("BYRADIUS"),
+				[]byte("1"),
+				[]byte("km"),
+			},
+			arrange: func(d *db.DB) {
+				d.ZAdd(now, "Sicily", db.ZAddOptions{}, db.ZMember{Member: "Palermo", Score: 3479099956230698}, db.ZMember{Member: "Catania", Score: 3479447370796909}, db.ZMember{Member: "edge1", Score: 3479273021651468}, db.ZMember{Member: "edge2", Score: 3481342659049484})
+				d.SetString("dst", "value", time.Time{})
+			},
+			assert: func(t *testing.T, d *db.DB) {
+				if _, ok := d.Type(now, "dst"); ok {
+					t.Fatalf("expected dst to be deleted")
+				}
+			},
+			want: ":0\r\n",
+		},
+		{
+			name: "deletes destination when source is missing",
+			args: resp.Args{
+				[]byte("geosearchstore"),
+				[]byte("dst"),
+				[]byte("Sicily"),
+				[]byte("FROMMEMBER"),
+				[]byte("Palermo"),
+				[]byte("BYRADIUS"),
+				[]byte("1"),
+				[]byte("km"),
+			},
+			arrange: func(d *db.DB) {
+				d.SetString("dst", "value", time.Time{})
+			},
+			assert: func(t *testing.T, d *db.DB) {
+				if _, ok := d.Type(now, "dst"); ok {
+					t.Fatalf("expected dst to be deleted")
+				}
+			},
+			want: ":0\r\n",
+		},
+		{
+			name: "rejects WITHDIST",
+			args: resp.Args{
+				[]byte("geosearchstore"),
+				[]byte("dst"),
+				[]byte("Sicily"),
+				[]byte("FROMLONLAT"),
+				[]byte("15"),
+				[]byte("37"),
+				[]byte("BYRADIUS"),
+				[]byte("200"),
+				[]byte("km"),
+				[]byte("WITHDIST"),
+			},
+			arrange: func(d *db.DB) {
+				d.ZAdd(now, "Sicily", db.ZAddOptions{}, db.ZMember{Member: "Palermo", Score: 3479099956230698}, db.ZMember{Member: "Catania", Score: 3479447370796909})
+			},
+			want: "-ERR GEOSEARCHSTORE is not compatible with WITHDIST, WITHHASH and WITHCOORD options\r\n",
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			d := db.New()
+			if tc.arrange != nil {
+				tc.arrange(d)
+			}
+			srv := &Server{
+				dbMap: map[int]*db.DB{0: d},
+				clock: clock.New(now),
+			}
+
+			buf := new(bytes.Buffer)
+			w := resp.NewWriter(bufio.NewWriter(buf))
+			req := newRequest(session.New(), "GEOSEARCHSTORE", tc.args)
+
+			if err := srv.cmdGeoSearchStore(w, req); err != nil {
+				t.Fatalf("cmdGeoSearchStore returned error: %v", err)
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("flush failed: %v", err)
+			}
+			if got := buf.String(); got != tc.want {
+				t.Fatalf("unexpected payload:\nwant %q\ngot  %q", tc.want, got)
+			}
+			if tc.assert != nil {
+				tc.assert(t, d)
+			}
+		})
+	}
+}
@@ -9,7 +9,11 @@ func (s *Server) cmdGet(w *resp.Writer, r *request) error {
 		return w.WriteErrorAndFlush(err)
 	}
 	key := string(r.args[1])
-	if v, ok := s.db(r.session).GetString(s.Now(), key); ok {
+	v, ok, err := s.db(r.session).LookupString(s.Now(), key)
+	if err != nil {
+		return w.WriteErrorAndFlush(dbError(err))
+	}
+	if ok {
 		if err := w.WriteBulk([]byte(v)); err != nil {
 			return err
 		}
@@ -0,0 +1,28 @@
+package server
+
+import (
+	"github.com/mickamy/minivalkey/internal/db"
+	"github.com/mickamy/minivalkey/internal/resp"
+)
+
+func (s *Server) cmdGetBit(w *resp.Writer, r *request) error {
+	// GETBIT key offset
+	if err := validateCommand(r.cmd, r.args, validateArgCountExact(3)); err != nil {
+		return w.WriteErrorAndFlush(err)
+	}
+	key := string(r.args[1])
+	offset, ok := parseBitOffset(r.args[2], false, 0)
+	if !ok {
+		return w.WriteErrorAndFlush(ErrBitOffset)
+	}
+
+	v, _, err := s.db(r.session).LookupString(s.Now(), key)
+	if err != nil {
+		return w.WriteErrorAndFlush(dbError(err))
+	}
+	if err := w.WriteInt(int64(db.GetBit(v, offset))); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,111 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/mickamy/minivalkey/internal/clock"
+	"github.com/mickamy/minivalkey/internal/db"
+	"github.com/mickamy/minivalkey/internal/resp"
+	"github.com/mickamy/minivalkey/internal/session"
+)
+
+func TestServer_cmdGetBit(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+
+	tcs := []struct {
+		name    string
+		args    resp.Args
+		arrange func(*db.DB)
+		want    string
+	}{
+		{
+			name: "returns set bit",
+			args: resp.Args{
+				[]byte("getbit"),
+				[]byte("foo"),
+				[]byte("7"),
+			},
+			arrange: func(d *db.DB) {
+				d.SetString("foo", "\x01", time.Time{})
+			},
+			want: ":1\r\n",
+		},
+		{
+			name: "returns clear bit",
+			args: resp.Args{
+				[]byte("getbit"),
+				[]byte("foo"),
+				[]byte("0"),
+			},
+			arrange: func(d *db.DB) {
+				d.SetString("foo", "\x01", time.Time{})
+			},
+			want: ":0\r\n",
+		},
+		{
+			name: "offset past the end is zero",
+			args: resp.Args{
+				[]byte("getbit"),
+				[]byte("foo"),
+				[]byte("100"),
+			},
+			arrange: func(d *db.DB) {
+				d.SetString("foo", "\xff", time.Time{})
+			},
+			want: ":0\r\n",
+		},
+		{
+			name: "missing key is zero",
+			args: resp.Args{
+				[]byte("getbit"),
+				[]byte("foo"),
+				[]byte("3"),
+			},
+			want: ":0\r\n",
+		},
+		{
+			name: "rejects invalid offset",
+			args: resp.Args{
+				[]byte("getbit"),
+				[]byte("foo"),
+				[]byte("x"),
+			},
+			want: "-ERR bit offset is not an integer or out of range\r\n",
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			d := db.New()
+			if tc.arrange != nil {
+				tc.arrange(d)
+			}
+			srv := &Server{
+				dbMap: map[int]*db.DB{0: d},
+				clock: clock.New(now),
+			}
+
+			buf := new(bytes.Buffer)
+			w := resp.NewWriter(bufio.NewWriter(buf))
+			req := newRequest(session.New(), "GETBIT", tc.args)
+
+			if err := srv.cmdGetBit(w, req); err != nil {
+				t.Fatalf("cmdGetBit returned error: %v", err)
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("flush failed: %v", err)
+			}
+			if got := buf.String(); got != tc.want {
+				t.Fatalf("unexpected payload:\nwant %q\ngot  %q", tc.want, got)
+			}
+		})
+	}
+}
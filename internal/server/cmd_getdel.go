@@ -0,0 +1,27 @@
+package server
+
+import (
+	"github.com/mickamy/minivalkey/internal/resp"
+)
+
+func (s *Server) cmdGetDel(w *resp.Writer, r *request) error {
+	if err := validateCommand(r.cmd, r.args, validateArgCountExact(2)); err != nil {
+		return w.WriteErrorAndFlush(err)
+	}
+	key := string(r.args[1])
+	v, ok, err := s.db(r.session).GetDel(s.Now(), key)
+	if err != nil {
+		return w.WriteErrorAndFlush(dbError(err))
+	}
+	if ok {
+		if err := w.WriteBulk([]byte(v)); err != nil {
+			return err
+		}
+	} else {
+		if err := w.WriteNull(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,73 @@
+package server
+
+import (
+	"strings"
+	"time"
+
+	"github.com/mickamy/minivalkey/internal/db"
+	"github.com/mickamy/minivalkey/internal/resp"
+)
+
+func (s *Server) cmdGetEx(w *resp.Writer, r *request) error {
+	// GETEX key [EX seconds | PX milliseconds | EXAT unix-time-seconds | PXAT unix-time-milliseconds | PERSIST]
+	if err := validateCommand(r.cmd, r.args, validateArgCountAtLeast(2)); err != nil {
+		return w.WriteErrorAndFlush(err)
+	}
+	key := string(r.args[1])
+	now := s.Now()
+
+	opts := db.GetExOptions{}
+	for i := 2; i < len(r.args); i++ {
+		opt := strings.ToUpper(string(r.args[i]))
+		switch opt {
+		case "PERSIST":
+			if opts.HasExpire || opts.Persist {
+				return w.WriteErrorAndFlush(ErrSyntax)
+			}
+			opts.Persist = true
+		case "EX", "PX", "EXAT", "PXAT":
+			if opts.HasExpire || opts.Persist {
+				return w.WriteErrorAndFlush(ErrSyntax)
+			}
+			i++
+			if i >= len(r.args) {
+				return w.WriteErrorAndFlush(ErrSyntax)
+			}
+			n, ok := resp.ParseInt(r.args[i])
+			if !ok {
+				return w.WriteErrorAndFlush(ErrValueNotInteger)
+			}
+			if n <= 0 {
+				return w.WriteErrorAndFlush(invalidExpireTimeError(r.cmd))
+			}
+			unit := time.Second
+			if opt == "PX" || opt == "PXAT" {
+				unit = time.Millisecond
+			}
+			at, ok := expireAtFrom(now, n, unit, opt == "EXAT" || opt == "PXAT")
+			if !ok {
+				return w.WriteErrorAndFlush(invalidExpireTimeError(r.cmd))
+			}
+			opts.HasExpire = true
+			opts.ExpireAt = at
+		default:
+			return w.WriteErrorAndFlush(ErrSyntax)
+		}
+	}
+
+	v, ok, err := s.db(r.session).GetEx(now, key, opts)
+	if err != nil {
+		return w.WriteErrorAndFlush(dbError(err))
+	}
+	if ok {
+		if err := w.WriteBulk([]byte(v)); err != nil {
+			return err
+		}
+	} else {
+		if err := w.WriteNull(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,176 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/mickamy/minivalkey/internal/clock"
+	"github.com/mickamy/minivalkey/internal/db"
+	"github.com/mickamy/minivalkey/internal/resp"
+	"github.com/mickamy/minivalkey/internal/session"
+)
+
+func TestServer_cmdGetEx(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+
+	arrange := func(db *db.DB) {
+		db.SetString("foo", "bar", now.Add(time.Minute))
+	}
+
+	tcs := []struct {
+		name    string
+		args    resp.Args
+		arrange func(*db.DB)
+		assert  func(*testing.T, *db.DB)
+		want    string
+	}{
+		{
+			name: "returns value without touching ttl",
+			args: resp.Args{
+				[]byte("getex"),
+				[]byte("foo"),
+			},
+			arrange: arrange,
+			assert: func(t *testing.T, db *db.DB) {
+				if ttl := db.TTL(now, "foo"); ttl != 60 {
+					t.Fatalf("expected ttl 60, got %d", ttl)
+				}
+			},
+			want: "$3\r\nbar\r\n",
+		},
+		{
+			name: "sets ttl with EX",
+			args: resp.Args{
+				[]byte("getex"),
+				[]byte("foo"),
+				[]byte("ex"),
+				[]byte("10"),
+			},
+			arrange: arrange,
+			assert: func(t *testing.T, db *db.DB) {
+				if ttl := db.TTL(now, "foo"); ttl != 10 {
+					t.Fatalf("expected ttl 10, got %d", ttl)
+				}
+			},
+			want: "$3\r\nbar\r\n",
+		},
+		{
+			name: "sets ttl with PXAT",
+			args: resp.Args{
+				[]byte("getex"),
+				[]byte("foo"),
+				[]byte("PXAT"),
+				[]byte(strconv.FormatInt(now.Add(2500*time.Millisecond).UnixMilli(), 10)),
+			},
+			arrange: arrange,
+			assert: func(t *testing.T, db *db.DB) {
+				if ttl := db.PTTL(now, "foo"); ttl != 2500 {
+					t.Fatalf("expected pttl 2500, got %d", ttl)
+				}
+			},
+			want: "$3\r\nbar\r\n",
+		},
+		{
+			name: "removes ttl with PERSIST",
+			args: resp.Args{
+				[]byte("getex"),
+				[]byte("foo"),
+				[]byte("persist"),
+			},
+			arrange: arrange,
+			assert: func(t *testing.T, db *db.DB) {
+				if ttl := db.TTL(now, "foo"); ttl != -1 {
+					t.Fatalf("expected ttl -1, got %d", ttl)
+				}
+			},
+			want: "$3\r\nbar\r\n",
+		},
+		{
+			name: "deletes key when EXAT is in the past",
+			args: resp.Args{
+				[]byte("getex"),
+				[]byte("foo"),
+				[]byte("exat"),
+				[]byte("1"),
+			},
+			arrange: arrange,
+			assert: func(t *testing.T, db *db.DB) {
+				if ttl := db.TTL(now, "foo"); ttl != -2 {
+					t.Fatalf("expected ttl -2, got %d", ttl)
+				}
+			},
+			want: "$3\r\nbar\r\n",
+		},
+		{
+			name: "returns null for missing key",
+			args: resp.Args{
+				[]byte("getex"),
+				[]byte("missing"),
+				[]byte("ex"),
+				[]byte("10"),
+			},
+			want: "$-1\r\n",
+		},
+		{
+			name: "complains about non-positive expire",
+			args: resp.Args{
+				[]byte("getex"),
+				[]byte("foo"),
+				[]byte("ex"),
+				[]byte("0"),
+			},
+			arrange: arrange,
+			want:    "-ERR invalid expire time in 'getex' command\r\n",
+		},
+		{
+			name: "complains about conflicting options",
+			args: resp.Args{
+				[]byte("getex"),
+				[]byte("foo"),
+				[]byte("ex"),
+				[]byte("10"),
+				[]byte("persist"),
+			},
+			arrange: arrange,
+			want:    "-ERR syntax error\r\n",
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			d := db.New()
+			if tc.arrange != nil {
+				tc.arrange(d)
+			}
+			srv := &Server{
+				dbMap: map[int]*db.DB{0: d},
+				clock: clock.New(now),
+			}
+
+			buf := new(bytes.Buffer)
+			w := resp.NewWriter(bufio.NewWriter(buf))
+			req := newRequest(session.New(), "GETEX", tc.args)
+
+			if err := srv.cmdGetEx(w, req); err != nil {
+				t.Fatalf("cmdGetEx returned error: %v", err)
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("flush failed: %v", err)
+			}
+			if got := buf.String(); got != tc.want {
+				t.Fatalf("unexpected payload:\nwant %q\ngot  %q", tc.want, got)
+			}
+			if tc.assert != nil {
+				tc.assert(t, d)
+			}
+		})
+	}
+}
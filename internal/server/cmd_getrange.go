@@ -0,0 +1,55 @@
+package server
+
+import (
+	"github.com/mickamy/minivalkey/internal/resp"
+)
+
+func (s *Server) cmdGetRange(w *resp.Writer, r *request) error {
+	// GETRANGE key start end
+	if err := validateCommand(r.cmd, r.args, validateArgCountExact(4)); err != nil {
+		return w.WriteErrorAndFlush(err)
+	}
+	key := string(r.args[1])
+	start, ok := resp.ParseInt(r.args[2])
+	if !ok {
+		return w.WriteErrorAndFlush(ErrValueNotInteger)
+	}
+	end, ok := resp.ParseInt(r.args[3])
+	if !ok {
+		return w.WriteErrorAndFlush(ErrValueNotInteger)
+	}
+
+	v, _, err := s.db(r.session).LookupString(s.Now(), key)
+	if err != nil {
+		return w.WriteErrorAndFlush(dbError(err))
+	}
+	if err := w.WriteBulk([]byte(substr(v, start, end))); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// substr returns v[start:end] (inclusive) resolving negative indexes from the
+// end of the string the way Valkey's GETRANGE does.
+func substr(v string, start, end int64) string {
+	n := int64(len(v))
+	if start < 0 && end < 0 && start > end {
+		return ""
+	}
+	if start < 0 {
+		start += n
+	}
+	if end < 0 {
+		end += n
+	}
+	start = max(start, 0)
+	end = max(end, 0)
+	if end >= n {
+		end = n - 1
+	}
+	if start > end || n == 0 {
+		return ""
+	}
+	return v[start : end+1]
+}
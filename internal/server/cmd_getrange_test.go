@@ -0,0 +1,125 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/mickamy/minivalkey/internal/clock"
+	"github.com/mickamy/minivalkey/internal/db"
+	"github.com/mickamy/minivalkey/internal/resp"
+	"github.com/mickamy/minivalkey/internal/session"
+)
+
+func TestServer_cmdGetRange(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+
+	arrange := func(db *db.DB) {
+		db.SetString("foo", "This is a string", time.Time{})
+	}
+
+	tcs := []struct {
+		name    string
+		args    resp.Args
+		arrange func(*db.DB)
+		want    string
+	}{
+		{
+			name: "returns inclusive range",
+			args: resp.Args{
+				[]byte("getrange"),
+				[]byte("foo"),
+				[]byte("0"),
+				[]byte("3"),
+			},
+			arrange: arrange,
+			want:    "$4\r\nThis\r\n",
+		},
+		{
+			name: "resolves negative indexes",
+			args: resp.Args{
+				[]byte("getrange"),
+				[]byte("foo"),
+				[]byte("-3"),
+				[]byte("-1"),
+			},
+			arrange: arrange,
+			want:    "$3\r\ning\r\n",
+		},
+		{
+			name: "clamps end to string length",
+			args: resp.Args{
+				[]byte("getrange"),
+				[]byte("foo"),
+				[]byte("10"),
+				[]byte("100"),
+			},
+			arrange: arrange,
+			want:    "$6\r\nstring\r\n",
+		},
+		{
+			name: "returns empty string when start is after end",
+			args: resp.Args{
+				[]byte("getrange"),
+				[]byte("foo"),
+				[]byte("-1"),
+				[]byte("-5"),
+			},
+			arrange: arrange,
+			want:    "$0\r\n\r\n",
+		},
+		{
+			name: "returns empty string for missing key",
+			args: resp.Args{
+				[]byte("getrange"),
+				[]byte("missing"),
+				[]byte("0"),
+				[]byte("-1"),
+			},
+			want: "$0\r\n\r\n",
+		},
+		{
+			name: "complains when index is not an integer",
+			args: resp.Args{
+				[]byte("getrange"),
+				[]byte("foo"),
+				[]byte("a"),
+				[]byte("1"),
+			},
+			want: "-ERR value is not an integer or out of range\r\n",
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			d := db.New()
+			if tc.arrange != nil {
+				tc.arrange(d)
+			}
+			srv := &Server{
+				dbMap: map[int]*db.DB{0: d},
+				clock: clock.New(now),
+			}
+
+			buf := new(bytes.Buffer)
+			w := resp.NewWriter(bufio.NewWriter(buf))
+			req := newRequest(session.New(), "GETRANGE", tc.args)
+
+			if err := srv.cmdGetRange(w, req); err != nil {
+				t.Fatalf("cmdGetRange returned error: %v", err)
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("flush failed: %v", err)
+			}
+			if got := buf.String(); got != tc.want {
+				t.Fatalf("unexpected payload:\nwant %q\ngot  %q", tc.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package server
+
+import (
+	"github.com/mickamy/minivalkey/internal/resp"
+)
+
+func (s *Server) cmdGetSet(w *resp.Writer, r *request) error {
+	if err := validateCommand(r.cmd, r.args, validateArgCountExact(3)); err != nil {
+		return w.WriteErrorAndFlush(err)
+	}
+	key := string(r.args[1])
+	prev, ok, err := s.db(r.session).GetSet(s.Now(), key, string(r.args[2]))
+	if err != nil {
+		return w.WriteErrorAndFlush(dbError(err))
+	}
+	if ok {
+		if err := w.WriteBulk([]byte(prev)); err != nil {
+			return err
+		}
+	} else {
+		if err := w.WriteNull(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,90 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/mickamy/minivalkey/internal/clock"
+	"github.com/mickamy/minivalkey/internal/db"
+	"github.com/mickamy/minivalkey/internal/resp"
+	"github.com/mickamy/minivalkey/internal/session"
+)
+
+func TestServer_cmdGetSet(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+
+	tcs := []struct {
+		name    string
+		args    resp.Args
+		arrange func(*db.DB)
+		assert  func(*testing.T, *db.DB)
+		want    string
+	}{
+		{
+			name: "returns old value and clears ttl",
+			args: resp.Args{
+				[]byte("getset"),
+				[]byte("foo"),
+				[]byte("new"),
+			},
+			arrange: func(db *db.DB) {
+				db.SetString("foo", "old", now.Add(time.Minute))
+			},
+			assert: func(t *testing.T, db *db.DB) {
+				if got, _ := db.GetString(now, "foo"); got != "new" {
+					t.Fatalf("expected foo=new, got %q", got)
+				}
+				if ttl := db.TTL(now, "foo"); ttl != -1 {
+					t.Fatalf("expected ttl -1, got %d", ttl)
+				}
+			},
+			want: "$3\r\nold\r\n",
+		},
+		{
+			name: "returns null for missing key",
+			args: resp.Args{
+				[]byte("getset"),
+				[]byte("foo"),
+				[]byte("new"),
+			},
+			want: "$-1\r\n",
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			d := db.New()
+			if tc.arrange != nil {
+				tc.arrange(d)
+			}
+			srv := &Server{
+				dbMap: map[int]*db.DB{0: d},
+				clock: clock.New(now),
+			}
+
+			buf := new(bytes.Buffer)
+			w := resp.NewWriter(bufio.NewWriter(buf))
+			req := newRequest(session.New(), "GETSET", tc.args)
+
+			if err := srv.cmdGetSet(w, req); err != nil {
+				t.Fatalf("cmdGetSet returned error: %v", err)
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("flush failed: %v", err)
+			}
+			if got := buf.String(); got != tc.want {
+				t.Fatalf("unexpected payload:\nwant %q\ngot  %q", tc.want, got)
+			}
+			if tc.assert != nil {
+				tc.assert(t, d)
+			}
+		})
+	}
+}
@@ -26,6 +26,11 @@ func (s *Server) cmdHello(w *resp.Writer, r *request) error {
 		return err
 	}
 
+	mode := "standalone"
+	if s.cluster != nil {
+		mode = "cluster"
+	}
+
 	type emptyArray struct{}
 	fields := []struct {
 		key   string
@@ -35,7 +40,7 @@ func (s *Server) cmdHello(w *resp.Writer, r *request) error {
 		{"version", "0.0.0"},
 		{"proto", int64(2)},
 		{"id", int64(1)},
-		{"mode", "standalone"},
+		{"mode", mode},
 		{"role", "master"},
 		{"modules", emptyArray{}},
 	}
@@ -0,0 +1,111 @@
+package server
+
+import (
+	"strings"
+	"time"
+
+	"github.com/mickamy/minivalkey/internal/db"
+	"github.com/mickamy/minivalkey/internal/resp"
+)
+
+func (s *Server) cmdHExpire(w *resp.Writer, r *request) error {
+	return s.hexpireGeneric(w, r, time.Second, false)
+}
+
+func (s *Server) cmdHPExpire(w *resp.Writer, r *request) error {
+	return s.hexpireGeneric(w, r, time.Millisecond, false)
+}
+
+func (s *Server) cmdHExpireAt(w *resp.Writer, r *request) error {
+	return s.hexpireGeneric(w, r, time.Second, true)
+}
+
+func (s *Server) cmdHPExpireAt(w *resp.Writer, r *request) error {
+	return s.hexpireGeneric(w, r, time.Millisecond, true)
+}
+
+// hexpireGeneric implements HEXPIRE, HPEXPIRE, HEXPIREAT and HPEXPIREAT:
+// CMD key value [NX|XX|GT|LT] FIELDS numfields field [field ...]
+// unit and absolute are interpreted as in expireGeneric.
+func (s *Server) hexpireGeneric(w *resp.Writer, r *request, unit time.Duration, absolute bool) error {
+	if err := validateCommand(r.cmd, r.args, validateArgCountAtLeast(6)); err != nil {
+		return w.WriteErrorAndFlush(err)
+	}
+	key := string(r.args[1])
+
+	when, ok := resp.ParseInt(r.args[2])
+	if !ok {
+		return w.WriteErrorAndFlush(ErrValueNotInteger)
+	}
+	fieldsIdx := 3
+	var opts db.ExpireOptions
+	switch strings.ToUpper(string(r.args[3])) {
+	case "NX":
+		opts.NX = true
+	case "XX":
+		opts.XX = true
+	case "GT":
+		opts.GT = true
+	case "LT":
+		opts.LT = true
+	}
+	if opts != (db.ExpireOptions{}) {
+		fieldsIdx++
+	}
+	fields, err := parseHashFields(r.args, fieldsIdx, 1)
+	if err != nil {
+		return w.WriteErrorAndFlush(err)
+	}
+	now := s.Now()
+	at, ok := expireAtFrom(now, when, unit, absolute)
+	if !ok {
+		return w.WriteErrorAndFlush(invalidExpireTimeError(r.cmd))
+	}
+
+	codes, err := s.db(r.session).HExpireAt(now, key, at, opts, fields...)
+	if err != nil {
+		return w.WriteErrorAndFlush(dbError(err))
+	}
+	if err := writeIntArray(w, codes); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// parseHashFields parses the "FIELDS numfields ..." block starting at args[i],
+// which must end the command. Each field takes width arguments (2 for field/value pairs).
+func parseHashFields(args resp.Args, i, width int) ([]string, error) {
+	if i >= len(args) || !strings.EqualFold(string(args[i]), "FIELDS") {
+		return nil, ErrFieldsMissing
+	}
+	if i+1 >= len(args) {
+		return nil, ErrSyntax
+	}
+	n, ok := resp.ParseInt(args[i+1])
+	if !ok {
+		return nil, ErrValueNotInteger
+	}
+	rest := args[i+2:]
+	if n <= 0 || int64(len(rest)) != n*int64(width) {
+		return nil, ErrNumFields
+	}
+	out := make([]string, len(rest))
+	for j, a := range rest {
+		out[j] = string(a)
+	}
+	return out, nil
+}
+
+// writeIntArray writes ns as an array of integers.
+func writeIntArray(w *resp.Writer, ns []int64) error {
+	if err := w.WriteArrayHeader(len(ns)); err != nil {
+		return err
+	}
+	for _, n := range ns {
+		if err := w.WriteIntElem(n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
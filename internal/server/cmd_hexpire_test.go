@@ -0,0 +1,326 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/mickamy/minivalkey/internal/clock"
+	"github.com/mickamy/minivalkey/internal/db"
+	"github.com/mickamy/minivalkey/internal/resp"
+	"github.com/mickamy/minivalkey/internal/session"
+)
+
+func TestServer_cmdHExpire(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+
+	tcs := []struct {
+		name    string
+		args    resp.Args
+		arrange func(*db.DB)
+		assert  func(*testing.T, *db.DB)
+		want    string
+	}{
+		{
+			name: "sets field expiration",
+			args: resp.Args{
+				[]byte("hexpire"),
+				[]byte("h"),
+				[]byte("10"),
+				[]byte("FIELDS"),
+				[]byte("2"),
+				[]byte("b"),
+				[]byte("missing"),
+			},
+			arrange: func(d *db.DB) {
+				d.HSet(now, "h", "a", "1", "b", "2")
+				d.HExpireAt(now, "h", now.Add(100*time.Second), db.ExpireOptions{}, "a")
+			},
+			assert: func(t *testing.T, d *db.DB) {
+				if got, _ := d.HPTTL(now, "h", "b"); got[0] != 10000 {
+					t.Fatalf("expected pttl 10000, got %d", got[0])
+				}
+			},
+			want: "*2\r\n:1\r\n:-2\r\n",
+		},
+		{
+			name: "skips fields with expiration with NX",
+			args: resp.Args{
+				[]byte("hexpire"),
+				[]byte("h"),
+				[]byte("10"),
+				[]byte("NX"),
+				[]byte("FIELDS"),
+				[]byte("2"),
+				[]byte("a"),
+				[]byte("b"),
+			},
+			arrange: func(d *db.DB) {
+				d.HSet(now, "h", "a", "1", "b", "2")
+				d.HExpireAt(now, "h", now.Add(100*time.Second), db.ExpireOptions{}, "a")
+			},
+			assert: func(t *testing.T, d *db.DB) {
+				if got, _ := d.HPTTL(now, "h", "a"); got[0] != 100000 {
+					t.Fatalf("expected pttl 100000, got %d", got[0])
+				}
+			},
+			want: "*2\r\n:0\r\n:1\r\n",
+		},
+		{
+			name: "only updates fields with expiration with XX",
+			args: resp.Args{
+				[]byte("hexpire"),
+				[]byte("h"),
+				[]byte("10"),
+				[]byte("xx"),
+				[]byte("FIELDS"),
+				[]byte("2"),
+				[]byte("a"),
+				[]byte("b"),
+			},
+			arrange: func(d *db.DB) {
+				d.HSet(now, "h", "a", "1", "b", "2")
+				d.HExpireAt(now, "h", now.Add(100*time.Second), db.ExpireOptions{}, "a")
+			},
+			want: "*2\r\n:1\r\n:0\r\n",
+		},
+		{
+			name: "only extends with GT",
+			args: resp.Args{
+				[]byte("hexpire"),
+				[]byte("h"),
+				[]byte("50"),
+				[]byte("GT"),
+				[]byte("FIELDS"),
+				[]byte("2"),
+				[]byte("a"),
+				[]byte("b"),
+			},
+			arrange: func(d *db.DB) {
+				d.HSet(now, "h", "a", "1", "b", "2")
+				d.HExpireAt(now, "h", now.Add(100*time.Second), db.ExpireOptions{}, "a")
+			},
+			want: "*2\r\n:0\r\n:0\r\n",
+		},
+		{
+			name: "only shortens with LT",
+			args: resp.Args{
+				[]byte("hexpire"),
+				[]byte("h"),
+				[]byte("50"),
+				[]byte("LT"),
+				[]byte("FIELDS"),
+				[]byte("2"),
+				[]byte("a"),
+				[]byte("b"),
+			},
+			arrange: func(d *db.DB) {
+				d.HSet(now, "h", "a", "1", "b", "2")
+				d.HExpireAt(now, "h", now.Add(100*time.Second), db.ExpireOptions{}, "a")
+			},
+			want: "*2\r\n:1\r\n:1\r\n",
+		},
+		{
+			name: "deletes fields with past expiration",
+			args: resp.Args{
+				[]byte("hexpire"),
+				[]byte("h"),
+				[]byte("0"),
+				[]byte("FIELDS"),
+				[]byte("2"),
+				[]byte("a"),
+				[]byte("b"),
+			},
+			arrange: func(d *db.DB) {
+				d.HSet(now, "h", "a", "1", "b", "2")
+				d.HExpireAt(now, "h", now.Add(100*time.Second), db.ExpireOptions{}, "a")
+			},
+			assert: func(t *testing.T, d *db.DB) {
+				if _, ok := d.Type(now, "h"); ok {
+					t.Fatalf("expected h to be deleted")
+				}
+			},
+			want: "*2\r\n:2\r\n:2\r\n",
+		},
+		{
+			name: "replies -2 for missing key",
+			args: resp.Args{
+				[]byte("hexpire"),
+				[]byte("h"),
+				[]byte("10"),
+				[]byte("FIELDS"),
+				[]byte("1"),
+				[]byte("a"),
+			},
+			want: "*1\r\n:-2\r\n",
+		},
+		{
+			name: "rejects missing FIELDS",
+			args: resp.Args{
+				[]byte("hexpire"),
+				[]byte("h"),
+				[]byte("10"),
+				[]byte("NX"),
+				[]byte("1"),
+				[]byte("a"),
+			},
+			arrange: func(d *db.DB) {
+				d.HSet(now, "h", "a", "1", "b", "2")
+				d.HExpireAt(now, "h", now.Add(100*time.Second), db.ExpireOptions{}, "a")
+			},
+			want: "-ERR Mandatory argument FIELDS is missing or not at the right position\r\n",
+		},
+		{
+			name: "rejects numfields mismatch",
+			args: resp.Args{
+				[]byte("hexpire"),
+				[]byte("h"),
+				[]byte("10"),
+				[]byte("FIELDS"),
+				[]byte("2"),
+				[]byte("a"),
+			},
+			arrange: func(d *db.DB) {
+				d.HSet(now, "h", "a", "1", "b", "2")
+				d.HExpireAt(now, "h", now.Add(100*time.Second), db.ExpireOptions{}, "a")
+			},
+			want: "-ERR numfields should be greater than 0 and match the provided number of fields\r\n",
+		},
+		{
+			name: "rejects non integer time",
+			args: resp.Args{
+				[]byte("hexpire"),
+				[]byte("h"),
+				[]byte("x"),
+				[]byte("FIELDS"),
+				[]byte("1"),
+				[]byte("a"),
+			},
+			arrange: func(d *db.DB) {
+				d.HSet(now, "h", "a", "1", "b", "2")
+				d.HExpireAt(now, "h", now.Add(100*time.Second), db.ExpireOptions{}, "a")
+			},
+			want: "-ERR value is not an integer or out of range\r\n",
+		},
+		{
+			name: "rejects wrong type",
+			args: resp.Args{
+				[]byte("hexpire"),
+				[]byte("h"),
+				[]byte("10"),
+				[]byte("FIELDS"),
+				[]byte("1"),
+				[]byte("a"),
+			},
+			arrange: func(d *db.DB) {
+				d.SetString("h", "v", time.Time{})
+			},
+			want: "-WRONGTYPE Operation against a key holding the wrong kind of value\r\n",
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			d := db.New()
+			if tc.arrange != nil {
+				tc.arrange(d)
+			}
+			srv := &Server{
+				dbMap: map[int]*db.DB{0: d},
+				clock: clock.New(now),
+			}
+
+			buf := new(bytes.Buffer)
+			w := resp.NewWriter(bufio.NewWriter(buf))
+			req := newRequest(session.New(), "HEXPIRE", tc.args)
+
+			if err := srv.cmdHExpire(w, req); err != nil {
+				t.Fatalf("cmdHExpire returned error: %v", err)
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("flush failed: %v", err)
+			}
+			if got := buf.String(); got != tc.want {
+				t.Fatalf("unexpected payload:\nwant %q\ngot  %q", tc.want, got)
+			}
+			if tc.assert != nil {
+				tc.assert(t, d)
+			}
+		})
+	}
+}
+
+func TestServer_cmdHPExpireAt(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+
+	tcs := []struct {
+		name    string
+		args    resp.Args
+		arrange func(*db.DB)
+		assert  func(*testing.T, *db.DB)
+		want    string
+	}{
+		{
+			name: "sets absolute expiration",
+			args: resp.Args{
+				[]byte("hpexpireat"),
+				[]byte("h"),
+				[]byte("1005000"),
+				[]byte("FIELDS"),
+				[]byte("1"),
+				[]byte("b"),
+			},
+			arrange: func(d *db.DB) {
+				d.HSet(now, "h", "a", "1", "b", "2")
+				d.HExpireAt(now, "h", now.Add(100*time.Second), db.ExpireOptions{}, "a")
+			},
+			assert: func(t *testing.T, d *db.DB) {
+				if got, _ := d.HPTTL(now, "h", "b"); got[0] != 5000 {
+					t.Fatalf("expected pttl 5000, got %d", got[0])
+				}
+			},
+			want: "*1\r\n:1\r\n",
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			d := db.New()
+			if tc.arrange != nil {
+				tc.arrange(d)
+			}
+			srv := &Server{
+				dbMap: map[int]*db.DB{0: d},
+				clock: clock.New(now),
+			}
+
+			buf := new(bytes.Buffer)
+			w := resp.NewWriter(bufio.NewWriter(buf))
+			req := newRequest(session.New(), "HPEXPIREAT", tc.args)
+
+			if err := srv.cmdHPExpireAt(w, req); err != nil {
+				t.Fatalf("cmdHPExpireAt returned error: %v", err)
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("flush failed: %v", err)
+			}
+			if got := buf.String(); got != tc.want {
+				t.Fatalf("unexpected payload:\nwant %q\ngot  %q", tc.want, got)
+			}
+			if tc.assert != nil {
+				tc.assert(t, d)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package server
+
+import (
+	"strings"
+	"time"
+
+	"github.com/mickamy/minivalkey/internal/db"
+	"github.com/mickamy/minivalkey/internal/resp"
+)
+
+func (s *Server) cmdHGetEx(w *resp.Writer, r *request) error {
+	// HGETEX key [EX seconds | PX milliseconds | EXAT unix-time-seconds | PXAT unix-time-milliseconds | PERSIST]
+	//   FIELDS numfields field [field ...]
+	if err := validateCommand(r.cmd, r.args, validateArgCountAtLeast(5)); err != nil {
+		return w.WriteErrorAndFlush(err)
+	}
+	key := string(r.args[1])
+	now := s.Now()
+
+	opts := db.GetExOptions{}
+	i := 2
+	switch opt := strings.ToUpper(string(r.args[i])); opt {
+	case "PERSIST":
+		opts.Persist = true
+		i++
+	case "EX", "PX", "EXAT", "PXAT":
+		at, err := parseFieldExpire(r, opt, r.args[i+1], now)
+		if err != nil {
+			return w.WriteErrorAndFlush(err)
+		}
+		opts.HasExpire = true
+		opts.ExpireAt = at
+		i += 2
+	}
+	fields, err := parseHashFields(r.args, i, 1)
+	if err != nil {
+		return w.WriteErrorAndFlush(err)
+	}
+
+	vals, found, err := s.db(r.session).HGetEx(now, key, opts, fields...)
+	if err != nil {
+		return w.WriteErrorAndFlush(dbError(err))
+	}
+	if err := w.WriteArrayHeader(len(vals)); err != nil {
+		return err
+	}
+	for j, v := range vals {
+		var b []byte
+		if found[j] {
+			b = []byte(v)
+		}
+		if err := w.WriteBulkElem(b); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// parseFieldExpire parses the argument of an EX/PX/EXAT/PXAT option of
+// HGETEX and HSETEX into an absolute time, rejecting non positive values as
+// GETEX does.
+func parseFieldExpire(r *request, opt string, arg resp.Arg, now time.Time) (time.Time, error) {
+	n, ok := resp.ParseInt(arg)
+	if !ok {
+		return time.Time{}, ErrValueNotInteger
+	}
+	if n <= 0 {
+		return time.Time{}, invalidExpireTimeError(r.cmd)
+	}
+	unit := time.Second
+	if opt == "PX" || opt == "PXAT" {
+		unit = time.Millisecond
+	}
+	at, ok := expireAtFrom(now, n, unit, opt == "EXAT" || opt == "PXAT")
+	if !ok {
+		return time.Time{}, invalidExpireTimeError(r.cmd)
+	}
+	return at, nil
+}
@@ -0,0 +1,202 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/mickamy/minivalkey/internal/clock"
+	"github.com/mickamy/minivalkey/internal/db"
+	"github.com/mickamy/minivalkey/internal/resp"
+	"github.com/mickamy/minivalkey/internal/session"
+)
+
+func TestServer_cmdHGetEx(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+
+	tcs := []struct {
+		name    string
+		args    resp.Args
+		arrange func(*db.DB)
+		assert  func(*testing.T, *db.DB)
+		want    string
+	}{
+		{
+			name: "returns values",
+			args: resp.Args{
+				[]byte("hgetex"),
+				[]byte("h"),
+				[]byte("FIELDS"),
+				[]byte("3"),
+				[]byte("a"),
+				[]byte("b"),
+				[]byte("missing"),
+			},
+			arrange: func(d *db.DB) {
+				d.HSet(now, "h", "a", "1", "b", "2")
+				d.HExpireAt(now, "h", now.Add(100*time.Second), db.ExpireOptions{}, "a")
+			},
+			want: "*3\r\n$1\r\n1\r\n$1\r\n2\r\n$-1\r\n",
+		},
+		{
+			name: "sets expiration with EX",
+			args: resp.Args{
+				[]byte("hgetex"),
+				[]byte("h"),
+				[]byte("EX"),
+				[]byte("30"),
+				[]byte("FIELDS"),
+				[]byte("1"),
+				[]byte("b"),
+			},
+			arrange: func(d *db.DB) {
+				d.HSet(now, "h", "a", "1", "b", "2")
+				d.HExpireAt(now, "h", now.Add(100*time.Second), db.ExpireOptions{}, "a")
+			},
+			assert: func(t *testing.T, d *db.DB) {
+				if got, _ := d.HPTTL(now, "h", "b"); got[0] != 30000 {
+					t.Fatalf("expected pttl 30000, got %d", got[0])
+				}
+			},
+			want: "*1\r\n$1\r\n2\r\n",
+		},
+		{
+			name: "removes expiration with PERSIST",
+			args: resp.Args{
+				[]byte("hgetex"),
+				[]byte("h"),
+				[]byte("PERSIST"),
+				[]byte("FIELDS"),
+				[]byte("1"),
+				[]byte("a"),
+			},
+			arrange: func(d *db.DB) {
+				d.HSet(now, "h", "a", "1", "b", "2")
+				d.HExpireAt(now, "h", now.Add(100*time.Second), db.ExpireOptions{}, "a")
+			},
+			assert: func(t *testing.T, d *db.DB) {
+				if got, _ := d.HPTTL(now, "h", "a"); got[0] != -1 {
+					t.Fatalf("expected pttl -1, got %d", got[0])
+				}
+			},
+			want: "*1\r\n$1\r\n1\r\n",
+		},
+		{
+			name: "deletes fields with past PXAT",
+			args: resp.Args{
+				[]byte("hgetex"),
+				[]byte("h"),
+				[]byte("PXAT"),
+				[]byte("1"),
+				[]byte("FIELDS"),
+				[]byte("2"),
+				[]byte("a"),
+				[]byte("b"),
+			},
+			arrange: func(d *db.DB) {
+				d.HSet(now, "h", "a", "1", "b", "2")
+				d.HExpireAt(now, "h", now.Add(100*time.Second), db.ExpireOptions{}, "a")
+			},
+			assert: func(t *testing.T, d *db.DB) {
+				if _, ok := d.Type(now, "h"); ok {
+					t.Fatalf("expected h to be deleted")
+				}
+			},
+			want: "*2\r\n$1\r\n1\r\n$1\r\n2\r\n",
+		},
+		{
+			name: "returns nils for missing key",
+			args: resp.Args{
+				[]byte("hgetex"),
+				[]byte("h"),
+				[]byte("FIELDS"),
+				[]byte("1"),
+				[]byte("a"),
+			},
+			want: "*1\r\n$-1\r\n",
+		},
+		{
+			name: "rejects non positive expiration",
+			args: resp.Args{
+				[]byte("hgetex"),
+				[]byte("h"),
+				[]byte("EX"),
+				[]byte("0"),
+				[]byte("FIELDS"),
+				[]byte("1"),
+				[]byte("a"),
+			},
+			arrange: func(d *db.DB) {
+				d.HSet(now, "h", "a", "1", "b", "2")
+				d.HExpireAt(now, "h", now.Add(100*time.Second), db.ExpireOptions{}, "a")
+			},
+			want: "-ERR invalid expire time in 'hgetex' command\r\n",
+		},
+		{
+			name: "rejects missing FIELDS",
+			args: resp.Args{
+				[]byte("hgetex"),
+				[]byte("h"),
+				[]byte("EX"),
+				[]byte("10"),
+				[]byte("1"),
+				[]byte("a"),
+			},
+			arrange: func(d *db.DB) {
+				d.HSet(now, "h", "a", "1", "b", "2")
+				d.HExpireAt(now, "h", now.Add(100*time.Second), db.ExpireOptions{}, "a")
+			},
+			want: "-ERR Mandatory argument FIELDS is missing or not at the right position\r\n",
+		},
+		{
+			name: "rejects wrong type",
+			args: resp.Args{
+				[]byte("hgetex"),
+				[]byte("h"),
+				[]byte("FIELDS"),
+				[]byte("1"),
+				[]byte("a"),
+			},
+			arrange: func(d *db.DB) {
+				d.SetString("h", "v", time.Time{})
+			},
+			want: "-WRONGTYPE Operation against a key holding the wrong kind of value\r\n",
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			d := db.New()
+			if tc.arrange != nil {
+				tc.arrange(d)
+			}
+			srv := &Server{
+				dbMap: map[int]*db.DB{0: d},
+				clock: clock.New(now),
+			}
+
+			buf := new(bytes.Buffer)
+			w := resp.NewWriter(bufio.NewWriter(buf))
+			req := newRequest(session.New(), "HGETEX", tc.args)
+
+			if err := srv.cmdHGetEx(w, req); err != nil {
+				t.Fatalf("cmdHGetEx returned error: %v", err)
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("flush failed: %v", err)
+			}
+			if got := buf.String(); got != tc.want {
+				t.Fatalf("unexpected payload:\nwant %q\ngot  %q", tc.want, got)
+			}
+			if tc.assert != nil {
+				tc.assert(t, d)
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package server
+
+import (
+	"github.com/mickamy/minivalkey/internal/resp"
+)
+
+func (s *Server) cmdHPersist(w *resp.Writer, r *request) error {
+	// HPERSIST key FIELDS numfields field [field ...]
+	if err := validateCommand(r.cmd, r.args, validateArgCountAtLeast(5)); err != nil {
+		return w.WriteErrorAndFlush(err)
+	}
+	key := string(r.args[1])
+	fields, err := parseHashFields(r.args, 2, 1)
+	if err != nil {
+		return w.WriteErrorAndFlush(err)
+	}
+
+	codes, err := s.db(r.session).HPersist(s.Now(), key, fields...)
+	if err != nil {
+		return w.WriteErrorAndFlush(dbError(err))
+	}
+	if err := writeIntArray(w, codes); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,97 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/mickamy/minivalkey/internal/clock"
+	"github.com/mickamy/minivalkey/internal/db"
+	"github.com/mickamy/minivalkey/internal/resp"
+	"github.com/mickamy/minivalkey/internal/session"
+)
+
+func TestServer_cmdHPersist(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+
+	tcs := []struct {
+		name    string
+		args    resp.Args
+		arrange func(*db.DB)
+		assert  func(*testing.T, *db.DB)
+		want    string
+	}{
+		{
+			name: "removes field expiration",
+			args: resp.Args{
+				[]byte("hpersist"),
+				[]byte("h"),
+				[]byte("FIELDS"),
+				[]byte("3"),
+				[]byte("a"),
+				[]byte("b"),
+				[]byte("missing"),
+			},
+			arrange: func(d *db.DB) {
+				d.HSet(now, "h", "a", "1", "b", "2")
+				d.HExpireAt(now, "h", now.Add(100*time.Second), db.ExpireOptions{}, "a")
+			},
+			assert: func(t *testing.T, d *db.DB) {
+				if got, _ := d.HPTTL(now, "h", "a"); got[0] != -1 {
+					t.Fatalf("expected pttl -1, got %d", got[0])
+				}
+			},
+			want: "*3\r\n:1\r\n:-1\r\n:-2\r\n",
+		},
+		{
+			name: "rejects wrong type",
+			args: resp.Args{
+				[]byte("hpersist"),
+				[]byte("h"),
+				[]byte("FIELDS"),
+				[]byte("1"),
+				[]byte("a"),
+			},
+			arrange: func(d *db.DB) {
+				d.SetString("h", "v", time.Time{})
+			},
+			want: "-WRONGTYPE Operation against a key holding the wrong kind of value\r\n",
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			d := db.New()
+			if tc.arrange != nil {
+				tc.arrange(d)
+			}
+			srv := &Server{
+				dbMap: map[int]*db.DB{0: d},
+				clock: clock.New(now),
+			}
+
+			buf := new(bytes.Buffer)
+			w := resp.NewWriter(bufio.NewWriter(buf))
+			req := newRequest(session.New(), "HPERSIST", tc.args)
+
+			if err := srv.cmdHPersist(w, req); err != nil {
+				t.Fatalf("cmdHPersist returned error: %v", err)
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("flush failed: %v", err)
+			}
+			if got := buf.String(); got != tc.want {
+				t.Fatalf("unexpected payload:\nwant %q\ngot  %q", tc.want, got)
+			}
+			if tc.assert != nil {
+				tc.assert(t, d)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package server
+
+import (
+	"strings"
+
+	"github.com/mickamy/minivalkey/internal/db"
+	"github.com/mickamy/minivalkey/internal/resp"
+)
+
+func (s *Server) cmdHSetEx(w *resp.Writer, r *request) error {
+	// HSETEX key [FNX | FXX] [EX seconds | PX milliseconds | EXAT unix-time-seconds | PXAT unix-time-milliseconds | KEEPTTL]
+	//   FIELDS numfields field value [field value ...]
+	if err := validateCommand(r.cmd, r.args, validateArgCountAtLeast(6)); err != nil {
+		return w.WriteErrorAndFlush(err)
+	}
+	key := string(r.args[1])
+	now := s.Now()
+
+	opts := db.HSetExOptions{}
+	i := 2
+options:
+	for ; i < len(r.args); i++ {
+		switch opt := strings.ToUpper(string(r.args[i])); opt {
+		case "FNX", "FXX":
+			if opts.FNX || opts.FXX {
+				return w.WriteErrorAndFlush(ErrSyntax)
+			}
+			opts.FNX = opt == "FNX"
+			opts.FXX = opt == "FXX"
+		case "KEEPTTL":
+			if opts.HasExpire || opts.KeepTTL {
+				return w.WriteErrorAndFlush(ErrSyntax)
+			}
+			opts.KeepTTL = true
+		case "EX", "PX", "EXAT", "PXAT":
+			if opts.HasExpire || opts.KeepTTL || i+1 >= len(r.args) {
+				return w.WriteErrorAndFlush(ErrSyntax)
+			}
+			at, err := parseFieldExpire(r, opt, r.args[i+1], now)
+			if err != nil {
+				return w.WriteErrorAndFlush(err)
+			}
+			opts.HasExpire = true
+			opts.ExpireAt = at
+			i++
+		default:
+			break options
+		}
+	}
+	fieldValues, err := parseHashFields(r.args, i, 2)
+	if err != nil {
+		return w.WriteErrorAndFlush(err)
+	}
+
+	set, err := s.db(r.session).HSetEx(now, key, opts, fieldValues...)
+	if err != nil {
+		return w.WriteErrorAndFlush(dbError(err))
+	}
+	var n int64
+	if set {
+		n = 1
+	}
+	if err := w.WriteInt(n); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,256 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/mickamy/minivalkey/internal/clock"
+	"github.com/mickamy/minivalkey/internal/db"
+	"github.com/mickamy/minivalkey/internal/resp"
+	"github.com/mickamy/minivalkey/internal/session"
+)
+
+func TestServer_cmdHSetEx(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+
+	tcs := []struct {
+		name    string
+		args    resp.Args
+		arrange func(*db.DB)
+		assert  func(*testing.T, *db.DB)
+		want    string
+	}{
+		{
+			name: "sets fields with expiration",
+			args: resp.Args{
+				[]byte("hsetex"),
+				[]byte("h"),
+				[]byte("EX"),
+				[]byte("10"),
+				[]byte("FIELDS"),
+				[]byte("2"),
+				[]byte("a"),
+				[]byte("x"),
+				[]byte("c"),
+				[]byte("3"),
+			},
+			arrange: func(d *db.DB) {
+				d.HSet(now, "h", "a", "1", "b", "2")
+				d.HExpireAt(now, "h", now.Add(100*time.Second), db.ExpireOptions{}, "a")
+			},
+			assert: func(t *testing.T, d *db.DB) {
+				if got, _ := d.HPTTL(now, "h", "a"); got[0] != 10000 {
+					t.Fatalf("expected pttl 10000, got %d", got[0])
+				}
+				if got, _ := d.HPTTL(now, "h", "c"); got[0] != 10000 {
+					t.Fatalf("expected pttl 10000, got %d", got[0])
+				}
+			},
+			want: ":1\r\n",
+		},
+		{
+			name: "clears expiration without KEEPTTL",
+			args: resp.Args{
+				[]byte("hsetex"),
+				[]byte("h"),
+				[]byte("FIELDS"),
+				[]byte("1"),
+				[]byte("a"),
+				[]byte("x"),
+			},
+			arrange: func(d *db.DB) {
+				d.HSet(now, "h", "a", "1", "b", "2")
+				d.HExpireAt(now, "h", now.Add(100*time.Second), db.ExpireOptions{}, "a")
+			},
+			assert: func(t *testing.T, d *db.DB) {
+				if got, _ := d.HPTTL(now, "h", "a"); got[0] != -1 {
+					t.Fatalf("expected pttl -1, got %d", got[0])
+				}
+			},
+			want: ":1\r\n",
+		},
+		{
+			name: "keeps expiration with KEEPTTL",
+			args: resp.Args{
+				[]byte("hsetex"),
+				[]byte("h"),
+				[]byte("KEEPTTL"),
+				[]byte("FIELDS"),
+				[]byte("1"),
+				[]byte("a"),
+				[]byte("x"),
+			},
+			arrange: func(d *db.DB) {
+				d.HSet(now, "h", "a", "1", "b", "2")
+				d.HExpireAt(now, "h", now.Add(100*time.Second), db.ExpireOptions{}, "a")
+			},
+			assert: func(t *testing.T, d *db.DB) {
+				if got, _ := d.HPTTL(now, "h", "a"); got[0] != 100000 {
+					t.Fatalf("expected pttl 100000, got %d", got[0])
+				}
+			},
+			want: ":1\r\n",
+		},
+		{
+			name: "skips existing fields with FNX",
+			args: resp.Args{
+				[]byte("hsetex"),
+				[]byte("h"),
+				[]byte("FNX"),
+				[]byte("FIELDS"),
+				[]byte("2"),
+				[]byte("a"),
+				[]byte("x"),
+				[]byte("c"),
+				[]byte("3"),
+			},
+			arrange: func(d *db.DB) {
+				d.HSet(now, "h", "a", "1", "b", "2")
+				d.HExpireAt(now, "h", now.Add(100*time.Second), db.ExpireOptions{}, "a")
+			},
+			assert: func(t *testing.T, d *db.DB) {
+				if v, _, _ := d.HGet(now, "h", "a"); v != "1" {
+					t.Fatalf("expected a to remain 1, got %q", v)
+				}
+			},
+			want: ":0\r\n",
+		},
+		{
+			name: "requires existing fields with FXX",
+			args: resp.Args{
+				[]byte("hsetex"),
+				[]byte("h"),
+				[]byte("FXX"),
+				[]byte("PX"),
+				[]byte("500"),
+				[]byte("FIELDS"),
+				[]byte("2"),
+				[]byte("a"),
+				[]byte("x"),
+				[]byte("b"),
+				[]byte("y"),
+			},
+			arrange: func(d *db.DB) {
+				d.HSet(now, "h", "a", "1", "b", "2")
+				d.HExpireAt(now, "h", now.Add(100*time.Second), db.ExpireOptions{}, "a")
+			},
+			assert: func(t *testing.T, d *db.DB) {
+				if got, _ := d.HPTTL(now, "h", "b"); got[0] != 500 {
+					t.Fatalf("expected pttl 500, got %d", got[0])
+				}
+			},
+			want: ":1\r\n",
+		},
+		{
+			name: "creates hash",
+			args: resp.Args{
+				[]byte("hsetex"),
+				[]byte("h"),
+				[]byte("FIELDS"),
+				[]byte("1"),
+				[]byte("a"),
+				[]byte("1"),
+			},
+			assert: func(t *testing.T, d *db.DB) {
+				if got, _ := d.HPTTL(now, "h", "a"); got[0] != -1 {
+					t.Fatalf("expected pttl -1, got %d", got[0])
+				}
+			},
+			want: ":1\r\n",
+		},
+		{
+			name: "rejects FNX with FXX",
+			args: resp.Args{
+				[]byte("hsetex"),
+				[]byte("h"),
+				[]byte("FNX"),
+				[]byte("FXX"),
+				[]byte("FIELDS"),
+				[]byte("1"),
+				[]byte("a"),
+				[]byte("1"),
+			},
+			want: "-ERR syntax error\r\n",
+		},
+		{
+			name: "rejects KEEPTTL with EX",
+			args: resp.Args{
+				[]byte("hsetex"),
+				[]byte("h"),
+				[]byte("EX"),
+				[]byte("10"),
+				[]byte("KEEPTTL"),
+				[]byte("FIELDS"),
+				[]byte("1"),
+				[]byte("a"),
+				[]byte("1"),
+			},
+			want: "-ERR syntax error\r\n",
+		},
+		{
+			name: "rejects missing values",
+			args: resp.Args{
+				[]byte("hsetex"),
+				[]byte("h"),
+				[]byte("FIELDS"),
+				[]byte("2"),
+				[]byte("a"),
+				[]byte("1"),
+				[]byte("b"),
+			},
+			want: "-ERR numfields should be greater than 0 and match the provided number of fields\r\n",
+		},
+		{
+			name: "rejects wrong type",
+			args: resp.Args{
+				[]byte("hsetex"),
+				[]byte("h"),
+				[]byte("FIELDS"),
+				[]byte("1"),
+				[]byte("a"),
+				[]byte("1"),
+			},
+			arrange: func(d *db.DB) {
+				d.SetString("h", "v", time.Time{})
+			},
+			want: "-WRONGTYPE Operation against a key holding the wrong kind of value\r\n",
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			d := db.New()
+			if tc.arrange != nil {
+				tc.arrange(d)
+			}
+			srv := &Server{
+				dbMap: map[int]*db.DB{0: d},
+				clock: clock.New(now),
+			}
+
+			buf := new(bytes.Buffer)
+			w := resp.NewWriter(bufio.NewWriter(buf))
+			req := newRequest(session.New(), "HSETEX", tc.args)
+
+			if err := srv.cmdHSetEx(w, req); err != nil {
+				t.Fatalf("cmdHSetEx returned error: %v", err)
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("flush failed: %v", err)
+			}
+			if got := buf.String(); got != tc.want {
+				t.Fatalf("unexpected payload:\nwant %q\ngot  %q", tc.want, got)
+			}
+			if tc.assert != nil {
+				tc.assert(t, d)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package server
+
+import (
+	"github.com/mickamy/minivalkey/internal/resp"
+)
+
+func (s *Server) cmdHTTL(w *resp.Writer, r *request) error {
+	// HTTL key FIELDS numfields field [field ...]
+	return s.hfieldTimeGeneric(w, r, false, func(ms int64) int64 { return ms / 1000 })
+}
+
+func (s *Server) cmdHPTTL(w *resp.Writer, r *request) error {
+	// HPTTL key FIELDS numfields field [field ...]
+	return s.hfieldTimeGeneric(w, r, false, func(ms int64) int64 { return ms })
+}
+
+func (s *Server) cmdHExpireTime(w *resp.Writer, r *request) error {
+	// HEXPIRETIME key FIELDS numfields field [field ...]
+	return s.hfieldTimeGeneric(w, r, true, func(ms int64) int64 { return ms / 1000 })
+}
+
+func (s *Server) cmdHPExpireTime(w *resp.Writer, r *request) error {
+	// HPEXPIRETIME key FIELDS numfields field [field ...]
+	return s.hfieldTimeGeneric(w, r, true, func(ms int64) int64 { return ms })
+}
+
+// hfieldTimeGeneric implements the HTTL family. absolute selects expiration
+// timestamps rather than remaining TTLs; conv turns milliseconds into the
+// reply unit and is not applied to the -1/-2 codes.
+func (s *Server) hfieldTimeGeneric(w *resp.Writer, r *request, absolute bool, conv func(int64) int64) error {
+	if err := validateCommand(r.cmd, r.args, validateArgCountAtLeast(5)); err != nil {
+		return w.WriteErrorAndFlush(err)
+	}
+	key := string(r.args[1])
+	fields, err := parseHashFields(r.args, 2, 1)
+	if err != nil {
+		return w.WriteErrorAndFlush(err)
+	}
+
+	d := s.db(r.session)
+	now := s.Now()
+	var times []int64
+	if absolute {
+		times, err = d.HExpireTime(now, key, fields...)
+	} else {
+		times, err = d.HPTTL(now, key, fields...)
+	}
+	if err != nil {
+		return w.WriteErrorAndFlush(dbError(err))
+	}
+	for i, t := range times {
+		if t >= 0 {
+			times[i] = conv(t)
+		}
+	}
+	if err := writeIntArray(w, times); err != nil {
+		return err
+	}
+
+	return nil
+}
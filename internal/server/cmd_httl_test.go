@@ -0,0 +1,293 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/mickamy/minivalkey/internal/clock"
+	"github.com/mickamy/minivalkey/internal/db"
+	"github.com/mickamy/minivalkey/internal/resp"
+	"github.com/mickamy/minivalkey/internal/session"
+)
+
+func TestServer_cmdHTTL(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+
+	tcs := []struct {
+		name    string
+		args    resp.Args
+		arrange func(*db.DB)
+		want    string
+	}{
+		{
+			name: "returns field ttls",
+			args: resp.Args{
+				[]byte("httl"),
+				[]byte("h"),
+				[]byte("FIELDS"),
+				[]byte("3"),
+				[]byte("a"),
+				[]byte("b"),
+				[]byte("missing"),
+			},
+			arrange: func(d *db.DB) {
+				d.HSet(now, "h", "a", "1", "b", "2")
+				d.HExpireAt(now, "h", now.Add(100*time.Second), db.ExpireOptions{}, "a")
+			},
+			want: "*3\r\n:100\r\n:-1\r\n:-2\r\n",
+		},
+		{
+			name: "replies -2 for missing key",
+			args: resp.Args{
+				[]byte("httl"),
+				[]byte("h"),
+				[]byte("FIELDS"),
+				[]byte("1"),
+				[]byte("a"),
+			},
+			want: "*1\r\n:-2\r\n",
+		},
+		{
+			name: "rejects numfields mismatch",
+			args: resp.Args{
+				[]byte("httl"),
+				[]byte("h"),
+				[]byte("FIELDS"),
+				[]byte("0"),
+				[]byte("a"),
+			},
+			arrange: func(d *db.DB) {
+				d.HSet(now, "h", "a", "1", "b", "2")
+				d.HExpireAt(now, "h", now.Add(100*time.Second), db.ExpireOptions{}, "a")
+			},
+			want: "-ERR numfields should be greater than 0 and match the provided number of fields\r\n",
+		},
+		{
+			name: "rejects wrong type",
+			args: resp.Args{
+				[]byte("httl"),
+				[]byte("h"),
+				[]byte("FIELDS"),
+				[]byte("1"),
+				[]byte("a"),
+			},
+			arrange: func(d *db.DB) {
+				d.SetString("h", "v", time.Time{})
+			},
+			want: "-WRONGTYPE Operation against a key holding the wrong kind of value\r\n",
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			d := db.New()
+			if tc.arrange != nil {
+				tc.arrange(d)
+			}
+			srv := &Server{
+				dbMap: map[int]*db.DB{0: d},
+				clock: clock.New(now),
+			}
+
+			buf := new(bytes.Buffer)
+			w := resp.NewWriter(bufio.NewWriter(buf))
+			req := newRequest(session.New(), "HTTL", tc.args)
+
+			if err := srv.cmdHTTL(w, req); err != nil {
+				t.Fatalf("cmdHTTL returned error: %v", err)
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("flush failed: %v", err)
+			}
+			if got := buf.String(); got != tc.want {
+				t.Fatalf("unexpected payload:\nwant %q\ngot  %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestServer_cmdHPTTL(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+
+	tcs := []struct {
+		name    string
+		args    resp.Args
+		arrange func(*db.DB)
+		want    string
+	}{
+		{
+			name: "returns field ttls in milliseconds",
+			args: resp.Args{
+				[]byte("hpttl"),
+				[]byte("h"),
+				[]byte("FIELDS"),
+				[]byte("2"),
+				[]byte("a"),
+				[]byte("b"),
+			},
+			arrange: func(d *db.DB) {
+				d.HSet(now, "h", "a", "1", "b", "2")
+				d.HExpireAt(now, "h", now.Add(100*time.Second), db.ExpireOptions{}, "a")
+			},
+			want: "*2\r\n:100000\r\n:-1\r\n",
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			d := db.New()
+			if tc.arrange != nil {
+				tc.arrange(d)
+			}
+			srv := &Server{
+				dbMap: map[int]*db.DB{0: d},
+				clock: clock.New(now),
+			}
+
+			buf := new(bytes.Buffer)
+			w := resp.NewWriter(bufio.NewWriter(buf))
+			req := newRequest(session.New(), "HPTTL", tc.args)
+
+			if err := srv.cmdHPTTL(w, req); err != nil {
+				t.Fatalf("cmdHPTTL returned error: %v", err)
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("flush failed: %v", err)
+			}
+			if got := buf.String(); got != tc.want {
+				t.Fatalf("unexpected payload:\nwant %q\ngot  %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestServer_cmdHExpireTime(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+
+	tcs := []struct {
+		name    string
+		args    resp.Args
+		arrange func(*db.DB)
+		want    string
+	}{
+		{
+			name: "returns field expiration timestamps",
+			args: resp.Args{
+				[]byte("hexpiretime"),
+				[]byte("h"),
+				[]byte("FIELDS"),
+				[]byte("2"),
+				[]byte("a"),
+				[]byte("b"),
+			},
+			arrange: func(d *db.DB) {
+				d.HSet(now, "h", "a", "1", "b", "2")
+				d.HExpireAt(now, "h", now.Add(100*time.Second), db.ExpireOptions{}, "a")
+			},
+			want: "*2\r\n:1100\r\n:-1\r\n",
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			d := db.New()
+			if tc.arrange != nil {
+				tc.arrange(d)
+			}
+			srv := &Server{
+				dbMap: map[int]*db.DB{0: d},
+				clock: clock.New(now),
+			}
+
+			buf := new(bytes.Buffer)
+			w := resp.NewWriter(bufio.NewWriter(buf))
+			req := newRequest(session.New(), "HEXPIRETIME", tc.args)
+
+			if err := srv.cmdHExpireTime(w, req); err != nil {
+				t.Fatalf("cmdHExpireTime returned error: %v", err)
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("flush failed: %v", err)
+			}
+			if got := buf.String(); got != tc.want {
+				t.Fatalf("unexpected payload:\nwant %q\ngot  %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestServer_cmdHPExpireTime(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+
+	tcs := []struct {
+		name    string
+		args    resp.Args
+		arrange func(*db.DB)
+		want    string
+	}{
+		{
+			name: "returns field expiration timestamps in milliseconds",
+			args: resp.Args{
+				[]byte("hpexpiretime"),
+				[]byte("h"),
+				[]byte("FIELDS"),
+				[]byte("1"),
+				[]byte("a"),
+			},
+			arrange: func(d *db.DB) {
+				d.HSet(now, "h", "a", "1", "b", "2")
+				d.HExpireAt(now, "h", now.Add(100*time.Second), db.ExpireOptions{}, "a")
+			},
+			want: "*1\r\n:1100000\r\n",
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			d := db.New()
+			if tc.arrange != nil {
+				tc.arrange(d)
+			}
+			srv := &Server{
+				dbMap: map[int]*db.DB{0: d},
+				clock: clock.New(now),
+			}
+
+			buf := new(bytes.Buffer)
+			w := resp.NewWriter(bufio.NewWriter(buf))
+			req := newRequest(session.New(), "HPEXPIRETIME", tc.args)
+
+			if err := srv.cmdHPExpireTime(w, req); err != nil {
+				t.Fatalf("cmdHPExpireTime returned error: %v", err)
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("flush failed: %v", err)
+			}
+			if got := buf.String(); got != tc.want {
+				t.Fatalf("unexpected payload:\nwant %q\ngot  %q", tc.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package server
+
+import (
+	"math"
+
+	"github.com/mickamy/minivalkey/internal/resp"
+)
+
+func (s *Server) cmdIncr(w *resp.Writer, r *request) error {
+	if err := validateCommand(r.cmd, r.args, validateArgCountExact(2)); err != nil {
+		return w.WriteErrorAndFlush(err)
+	}
+	return s.incrGeneric(w, r, 1)
+}
+
+func (s *Server) cmdDecr(w *resp.Writer, r *request) error {
+	if err := validateCommand(r.cmd, r.args, validateArgCountExact(2)); err != nil {
+		return w.WriteErrorAndFlush(err)
+	}
+	return s.incrGeneric(w, r, -1)
+}
+
+func (s *Server) cmdIncrBy(w *resp.Writer, r *request) error {
+	if err := validateCommand(r.cmd, r.args, validateArgCountExact(3)); err != nil {
+		return w.WriteErrorAndFlush(err)
+	}
+	delta, ok := resp.ParseInt(r.args[2])
+	if !ok {
+		return w.WriteErrorAndFlush(ErrValueNotInteger)
+	}
+	return s.incrGeneric(w, r, delta)
+}
+
+func (s *Server) cmdDecrBy(w *resp.Writer, r *request) error {
+	if err := validateCommand(r.cmd, r.args, validateArgCountExact(3)); err != nil {
+		return w.WriteErrorAndFlush(err)
+	}
+	delta, ok := resp.ParseInt(r.args[2])
+	if !ok {
+		return w.WriteErrorAndFlush(ErrValueNotInteger)
+	}
+	if delta == math.MinInt64 {
+		return w.WriteErrorAndFlush(ErrDecrOverflow)
+	}
+	return s.incrGeneric(w, r, -delta)
+}
+
+func (s *Server) incrGeneric(w *resp.Writer, r *request, delta int64) error {
+	key := string(r.args[1])
+	n, err := s.db(r.session).IncrBy(s.Now(), key, delta)
+	if err != nil {
+		return w.WriteErrorAndFlush(dbError(err))
+	}
+	if err := w.WriteInt(n); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *Server) cmdIncrByFloat(w *resp.Writer, r *request) error {
+	if err := validateCommand(r.cmd, r.args, validateArgCountExact(3)); err != nil {
+		return w.WriteErrorAndFlush(err)
+	}
+	key := string(r.args[1])
+	v, err := s.db(r.session).IncrByFloat(s.Now(), key, string(r.args[2]))
+	if err != nil {
+		return w.WriteErrorAndFlush(dbError(err))
+	}
+	if err := w.WriteBulk([]byte(v)); err != nil {
+		return err
+	}
+
+	return nil
+}
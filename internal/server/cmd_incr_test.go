@@ -0,0 +1,193 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/mickamy/minivalkey/internal/clock"
+	"github.com/mickamy/minivalkey/internal/db"
+	"github.com/mickamy/minivalkey/internal/resp"
+	"github.com/mickamy/minivalkey/internal/session"
+)
+
+func TestServer_cmdIncr(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+
+	tcs := []struct {
+		name    string
+		cmd     resp.Command
+		args    resp.Args
+		arrange func(*db.DB)
+		want    string
+	}{
+		{
+			name: "INCR creates missing key",
+			cmd:  "INCR",
+			args: resp.Args{
+				[]byte("incr"),
+				[]byte("n"),
+			},
+			want: ":1\r\n",
+		},
+		{
+			name: "DECR decrements existing value",
+			cmd:  "DECR",
+			args: resp.Args{
+				[]byte("decr"),
+				[]byte("n"),
+			},
+			arrange: func(db *db.DB) {
+				db.SetString("n", "10", time.Time{})
+			},
+			want: ":9\r\n",
+		},
+		{
+			name: "INCRBY adds increment",
+			cmd:  "INCRBY",
+			args: resp.Args{
+				[]byte("incrby"),
+				[]byte("n"),
+				[]byte("5"),
+			},
+			arrange: func(db *db.DB) {
+				db.SetString("n", "10", time.Time{})
+			},
+			want: ":15\r\n",
+		},
+		{
+			name: "DECRBY subtracts decrement",
+			cmd:  "DECRBY",
+			args: resp.Args{
+				[]byte("decrby"),
+				[]byte("n"),
+				[]byte("15"),
+			},
+			arrange: func(db *db.DB) {
+				db.SetString("n", "10", time.Time{})
+			},
+			want: ":-5\r\n",
+		},
+		{
+			name: "INCR complains about non-integer value",
+			cmd:  "INCR",
+			args: resp.Args{
+				[]byte("incr"),
+				[]byte("n"),
+			},
+			arrange: func(db *db.DB) {
+				db.SetString("n", "007", time.Time{})
+			},
+			want: "-ERR value is not an integer or out of range\r\n",
+		},
+		{
+			name: "INCRBY complains about overflow",
+			cmd:  "INCRBY",
+			args: resp.Args{
+				[]byte("incrby"),
+				[]byte("n"),
+				[]byte("1"),
+			},
+			arrange: func(db *db.DB) {
+				db.SetString("n", "9223372036854775807", time.Time{})
+			},
+			want: "-ERR increment or decrement would overflow\r\n",
+		},
+		{
+			name: "DECRBY complains about min int64 decrement",
+			cmd:  "DECRBY",
+			args: resp.Args{
+				[]byte("decrby"),
+				[]byte("n"),
+				[]byte("-9223372036854775808"),
+			},
+			want: "-ERR decrement would overflow\r\n",
+		},
+		{
+			name: "INCRBYFLOAT formats like valkey",
+			cmd:  "INCRBYFLOAT",
+			args: resp.Args{
+				[]byte("incrbyfloat"),
+				[]byte("n"),
+				[]byte("0.1"),
+			},
+			arrange: func(db *db.DB) {
+				db.SetString("n", "10.50", time.Time{})
+			},
+			want: "$4\r\n10.6\r\n",
+		},
+		{
+			name: "INCRBYFLOAT accepts exponents",
+			cmd:  "INCRBYFLOAT",
+			args: resp.Args{
+				[]byte("incrbyfloat"),
+				[]byte("n"),
+				[]byte("2.0e3"),
+			},
+			arrange: func(db *db.DB) {
+				db.SetString("n", "5.0e3", time.Time{})
+			},
+			want: "$4\r\n7000\r\n",
+		},
+		{
+			name: "INCRBYFLOAT complains about invalid float",
+			cmd:  "INCRBYFLOAT",
+			args: resp.Args{
+				[]byte("incrbyfloat"),
+				[]byte("n"),
+				[]byte("abc"),
+			},
+			want: "-ERR value is not a valid float\r\n",
+		},
+		{
+			name: "INCRBYFLOAT complains about infinity",
+			cmd:  "INCRBYFLOAT",
+			args: resp.Args{
+				[]byte("incrbyfloat"),
+				[]byte("n"),
+				[]byte("inf"),
+			},
+			want: "-ERR increment would produce NaN or Infinity\r\n",
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			d := db.New()
+			if tc.arrange != nil {
+				tc.arrange(d)
+			}
+			srv := &Server{
+				dbMap: map[int]*db.DB{0: d},
+				clock: clock.New(now),
+			}
+			handlers := map[resp.Command]handleFunc{
+				"INCR":        srv.cmdIncr,
+				"DECR":        srv.cmdDecr,
+				"INCRBY":      srv.cmdIncrBy,
+				"DECRBY":      srv.cmdDecrBy,
+				"INCRBYFLOAT": srv.cmdIncrByFloat,
+			}
+
+			buf := new(bytes.Buffer)
+			w := resp.NewWriter(bufio.NewWriter(buf))
+			req := newRequest(session.New(), tc.cmd, tc.args)
+
+			if err := handlers[tc.cmd](w, req); err != nil {
+				t.Fatalf("%s returned error: %v", tc.cmd, err)
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("flush failed: %v", err)
+			}
+			if got := buf.String(); got != tc.want {
+				t.Fatalf("unexpected payload:\nwant %q\ngot  %q", tc.want, got)
+			}
+		})
+	}
+}
@@ -1,7 +1,6 @@
 package server
 
 import (
-	"fmt"
 	"strconv"
 	"strings"
 	"time"
@@ -12,7 +11,8 @@ import (
 
 func (s *Server) cmdInfo(w *resp.Writer, r *request) error {
 	// RESP2: INFO [section]
-	// We support sections: "server", "memory", "keyspace", plus "all"/"default".
+	// We support sections: "server", "memory", "keyspace", "replication",
+	// "cluster", plus "all"/"default".
 	// Unknown sections -> error (to match Redis/Valkey behavior).
 	section := "default"
 	if len(r.args) == 2 {
@@ -20,7 +20,7 @@ func (s *Server) cmdInfo(w *resp.Writer, r *request) error {
 	}
 	// Build content based on requested section.
 	now := s.Now()
-	txt, ok := buildInfo(section, now, s.db(r.session), s.uptimeSeconds(now))
+	txt, ok := buildInfo(section, now, s.db(r.session), s.uptimeSeconds(now), s.cluster != nil)
 	if !ok {
 		return w.WriteErrorAndFlush(ErrUnknownSection)
 	}
@@ -33,29 +33,31 @@ func (s *Server) cmdInfo(w *resp.Writer, r *request) error {
 
 // buildInfo builds an INFO string for a given section.
 // Returns (text, true) if section is supported; ("", false) otherwise.
-func buildInfo(section string, now time.Time, db *db.DB, uptimeSec int64) (string, bool) {
+func buildInfo(section string, now time.Time, db *db.DB, uptimeSec int64, clusterEnabled bool) (string, bool) {
 	switch section {
 	case "all", "default":
 		var b strings.Builder
-		b.WriteString(infoServer(now, uptimeSec))
+		b.WriteString(infoServer(now, uptimeSec, clusterEnabled))
 		b.WriteString(infoMemory(now, db))
+		b.WriteString(infoCluster(clusterEnabled))
 		b.WriteString(infoKeyspace(now, db))
 		return b.String(), true
 	case "server":
-		return infoServer(now, uptimeSec), true
+		return infoServer(now, uptimeSec, clusterEnabled), true
 	case "memory":
 		return infoMemory(now, db), true
 	case "keyspace":
 		return infoKeyspace(now, db), true
 	case "replication":
 		return infoReplication(), true
+	case "cluster":
+		return infoCluster(clusterEnabled), true
 	default:
-		fmt.Println("unknown info section:", section)
 		return "", false
 	}
 }
 
-func infoServer(now time.Time, uptimeSec int64) string {
+func infoServer(now time.Time, uptimeSec int64, clusterEnabled bool) string {
 	var b strings.Builder
 	b.WriteString("# Server\r\n")
 	// server: string identifier (we advertise "valkey" for compatibility)
@@ -70,8 +72,12 @@ func infoServer(now time.Time, uptimeSec int64) string {
 	b.WriteString("uptime_in_seconds:")
 	b.WriteString(strconv.FormatInt(uptimeSec, 10))
 	b.WriteString("\r\n")
-	// mode/role: single node master-like
-	b.WriteString("mode:standalone\r\n")
+	// mode/role: master-like, on its own or as a cluster node
+	if clusterEnabled {
+		b.WriteString("mode:cluster\r\n")
+	} else {
+		b.WriteString("mode:standalone\r\n")
+	}
 	b.WriteString("role:master\r\n")
 	// time_now: unix seconds (simulated clock)
 	b.WriteString("time_now:")
@@ -115,6 +121,13 @@ func infoKeyspace(now time.Time, db *db.DB) string {
 	return b.String()
 }
 
+func infoCluster(enabled bool) string {
+	if enabled {
+		return "# Cluster\r\ncluster_enabled:1\r\n\r\n"
+	}
+	return "# Cluster\r\ncluster_enabled:0\r\n\r\n"
+}
+
 func infoReplication() string {
 	// Minimal, master-only, no backlog. Enough for clients probing replication.
 	var b strings.Builder
@@ -32,7 +32,7 @@ func TestServer_cmdInfo(t *testing.T) {
 			},
 			wantFn: func(db *db.DB, srv *Server) string {
 				now := srv.Now()
-				txt, _ := buildInfo("default", now, db, srv.uptimeSeconds(now))
+				txt, _ := buildInfo("default", now, db, srv.uptimeSeconds(now), false)
 				return fmt.Sprintf("$%d\r\n%s\r\n", len(txt), txt)
 			},
 		},
@@ -48,10 +48,18 @@ func TestServer_cmdInfo(t *testing.T) {
 			},
 			wantFn: func(db *db.DB, srv *Server) string {
 				now := srv.Now()
-				txt, _ := buildInfo("memory", now, db, srv.uptimeSeconds(now))
+				txt, _ := buildInfo("memory", now, db, srv.uptimeSeconds(now), false)
 				return fmt.Sprintf("$%d\r\n%s\r\n", len(txt), txt)
 			},
 		},
+		{
+			name: "returns cluster section when requested",
+			args: resp.Args{
+				[]byte("info"),
+				[]byte("cluster"),
+			},
+			want: "$32\r\n# Cluster\r\ncluster_enabled:0\r\n\r\n\r\n",
+		},
 		{
 			name: "complains when section is unknown",
 			args: resp.Args{
@@ -0,0 +1,18 @@
+package server
+
+import (
+	"github.com/mickamy/minivalkey/internal/resp"
+)
+
+func (s *Server) cmdLastSave(w *resp.Writer, r *request) error {
+	// LASTSAVE
+	if err := validateCommand(r.cmd, r.args, validateArgCountExact(1)); err != nil {
+		return w.WriteErrorAndFlush(err)
+	}
+
+	if err := w.WriteInt(s.lastSave()); err != nil {
+		return err
+	}
+
+	return nil
+}
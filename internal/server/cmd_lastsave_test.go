@@ -0,0 +1,63 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/mickamy/minivalkey/internal/clock"
+	"github.com/mickamy/minivalkey/internal/db"
+	"github.com/mickamy/minivalkey/internal/resp"
+	"github.com/mickamy/minivalkey/internal/session"
+)
+
+func TestServer_cmdLastSave(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+
+	tcs := []struct {
+		name string
+		args resp.Args
+		want string
+	}{
+		{
+			name: "returns the time of the last save",
+			args: resp.Args{[]byte("lastsave")},
+			want: ":900\r\n",
+		},
+		{
+			name: "complains about wrong number of arguments",
+			args: resp.Args{[]byte("lastsave"), []byte("now")},
+			want: "-ERR wrong number of arguments for 'lastsave' command\r\n",
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := &Server{
+				dbMap: map[int]*db.DB{0: db.New()},
+				clock: clock.New(now),
+			}
+			srv.persistence.lastSave = 900
+
+			buf := new(bytes.Buffer)
+			w := resp.NewWriter(bufio.NewWriter(buf))
+			req := newRequest(session.New(), "LASTSAVE", tc.args)
+
+			if err := srv.cmdLastSave(w, req); err != nil {
+				t.Fatalf("cmdLastSave returned error: %v", err)
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("flush failed: %v", err)
+			}
+			if got := buf.String(); got != tc.want {
+				t.Fatalf("unexpected payload:\nwant %q\ngot  %q", tc.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,189 @@
+package server
+
+import (
+	"strings"
+
+	"github.com/mickamy/minivalkey/internal/resp"
+)
+
+func (s *Server) cmdLCS(w *resp.Writer, r *request) error {
+	// LCS key1 key2 [LEN] [IDX] [MINMATCHLEN min-match-len] [WITHMATCHLEN]
+	if err := validateCommand(r.cmd, r.args, validateArgCountAtLeast(3)); err != nil {
+		return w.WriteErrorAndFlush(err)
+	}
+
+	getLen, getIdx, withMatchLen := false, false, false
+	var minMatchLen int64
+	for i := 3; i < len(r.args); i++ {
+		opt := strings.ToUpper(string(r.args[i]))
+		switch opt {
+		case "LEN":
+			getLen = true
+		case "IDX":
+			getIdx = true
+		case "WITHMATCHLEN":
+			withMatchLen = true
+		case "MINMATCHLEN":
+			i++
+			if i >= len(r.args) {
+				return w.WriteErrorAndFlush(ErrSyntax)
+			}
+			n, ok := resp.ParseInt(r.args[i])
+			if !ok {
+				return w.WriteErrorAndFlush(ErrValueNotInteger)
+			}
+			minMatchLen = max(n, 0)
+		default:
+			return w.WriteErrorAndFlush(ErrSyntax)
+		}
+	}
+	if getLen && getIdx {
+		return w.WriteErrorString(msgLCSLenAndIdx)
+	}
+
+	now := s.Now()
+	d := s.db(r.session)
+	a, _, errA := d.LookupString(now, string(r.args[1]))
+	b, _, errB := d.LookupString(now, string(r.args[2]))
+	if errA != nil || errB != nil {
+		return w.WriteErrorAndFlush(ErrLCSNotString)
+	}
+
+	res := lcs(a, b, minMatchLen)
+	switch {
+	case getLen:
+		if err := w.WriteInt(int64(len(res.str))); err != nil {
+			return err
+		}
+	case getIdx:
+		if err := w.WriteArrayHeader(4); err != nil {
+			return err
+		}
+		if err := w.WriteBulkElem([]byte("matches")); err != nil {
+			return err
+		}
+		if err := w.WriteArrayHeader(len(res.matches)); err != nil {
+			return err
+		}
+		for _, m := range res.matches {
+			n := 2
+			if withMatchLen {
+				n = 3
+			}
+			if err := w.WriteArrayHeader(n); err != nil {
+				return err
+			}
+			for _, rng := range [][2]int{m.a, m.b} {
+				if err := w.WriteArrayHeader(2); err != nil {
+					return err
+				}
+				if err := w.WriteIntElem(int64(rng[0])); err != nil {
+					return err
+				}
+				if err := w.WriteIntElem(int64(rng[1])); err != nil {
+					return err
+				}
+			}
+			if withMatchLen {
+				if err := w.WriteIntElem(int64(m.length)); err != nil {
+					return err
+				}
+			}
+		}
+		if err := w.WriteBulkElem([]byte("len")); err != nil {
+			return err
+		}
+		if err := w.WriteIntElem(int64(len(res.str))); err != nil {
+			return err
+		}
+	default:
+		if err := w.WriteBulk([]byte(res.str)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// lcsMatch is one contiguous run shared by both strings, as inclusive ranges.
+type lcsMatch struct {
+	a, b   [2]int
+	length int
+}
+
+type lcsResult struct {
+	str     string
+	matches []lcsMatch
+}
+
+// lcs computes the longest common subsequence of a and b, together with the
+// matching ranges (longest offsets first) whose length is at least minMatchLen.
+// It follows Valkey's dynamic programming and backtracking so that the
+// reported ranges are identical.
+func lcs(a, b string, minMatchLen int64) lcsResult {
+	alen, blen := len(a), len(b)
+	dp := make([]int, (alen+1)*(blen+1))
+	at := func(i, j int) int { return dp[j+i*(blen+1)] }
+	for i := 1; i <= alen; i++ {
+		for j := 1; j <= blen; j++ {
+			if a[i-1] == b[j-1] {
+				dp[j+i*(blen+1)] = at(i-1, j-1) + 1
+			} else {
+				dp[j+i*(blen+1)] = max(at(i-1, j), at(i, j-1))
+			}
+		}
+	}
+
+	idx := at(alen, blen)
+	out := make([]byte, idx)
+	var matches []lcsMatch
+
+	aStart, aEnd, bStart, bEnd := alen, 0, 0, 0
+	i, j := alen, blen
+	for i > 0 && j > 0 {
+		emit := false
+		if a[i-1] == b[j-1] {
+			out[idx-1] = a[i-1]
+			if aStart == alen {
+				aStart, aEnd = i-1, i-1
+				bStart, bEnd = j-1, j-1
+			} else if aStart == i && bStart == j {
+				// Extend the current range backward since it is contiguous.
+				aStart--
+				bStart--
+			} else {
+				emit = true
+			}
+			// Emit the range once we matched the first byte of either string.
+			if aStart == 0 || bStart == 0 {
+				emit = true
+			}
+			idx--
+			i--
+			j--
+		} else {
+			if at(i-1, j) > at(i, j-1) {
+				i--
+			} else {
+				j--
+			}
+			if aStart != alen {
+				emit = true
+			}
+		}
+
+		if emit {
+			length := aEnd - aStart + 1
+			if minMatchLen == 0 || int64(length) >= minMatchLen {
+				matches = append(matches, lcsMatch{
+					a:      [2]int{aStart, aEnd},
+					b:      [2]int{bStart, bEnd},
+					length: length,
+				})
+			}
+			aStart = alen // Restart at the next match.
+		}
+	}
+
+	return lcsResult{str: string(out), matches: matches}
+}
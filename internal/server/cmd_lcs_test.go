@@ -0,0 +1,146 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/mickamy/minivalkey/internal/clock"
+	"github.com/mickamy/minivalkey/internal/db"
+	"github.com/mickamy/minivalkey/internal/resp"
+	"github.com/mickamy/minivalkey/internal/session"
+)
+
+func TestServer_cmdLCS(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+
+	arrange := func(db *db.DB) {
+		db.SetString("key1", "ohmytext", time.Time{})
+		db.SetString("key2", "mynewtext", time.Time{})
+	}
+
+	tcs := []struct {
+		name    string
+		args    resp.Args
+		arrange func(*db.DB)
+		want    string
+	}{
+		{
+			name: "returns longest common subsequence",
+			args: resp.Args{
+				[]byte("lcs"),
+				[]byte("key1"),
+				[]byte("key2"),
+			},
+			arrange: arrange,
+			want:    "$6\r\nmytext\r\n",
+		},
+		{
+			name: "returns length with LEN",
+			args: resp.Args{
+				[]byte("lcs"),
+				[]byte("key1"),
+				[]byte("key2"),
+				[]byte("len"),
+			},
+			arrange: arrange,
+			want:    ":6\r\n",
+		},
+		{
+			name: "returns match ranges with IDX",
+			args: resp.Args{
+				[]byte("lcs"),
+				[]byte("key1"),
+				[]byte("key2"),
+				[]byte("idx"),
+			},
+			arrange: arrange,
+			want: "*4\r\n$7\r\nmatches\r\n*2\r\n" +
+				"*2\r\n*2\r\n:4\r\n:7\r\n*2\r\n:5\r\n:8\r\n" +
+				"*2\r\n*2\r\n:2\r\n:3\r\n*2\r\n:0\r\n:1\r\n" +
+				"$3\r\nlen\r\n:6\r\n",
+		},
+		{
+			name: "filters short matches with MINMATCHLEN and reports WITHMATCHLEN",
+			args: resp.Args{
+				[]byte("lcs"),
+				[]byte("key1"),
+				[]byte("key2"),
+				[]byte("IDX"),
+				[]byte("MINMATCHLEN"),
+				[]byte("4"),
+				[]byte("WITHMATCHLEN"),
+			},
+			arrange: arrange,
+			want: "*4\r\n$7\r\nmatches\r\n*1\r\n" +
+				"*3\r\n*2\r\n:4\r\n:7\r\n*2\r\n:5\r\n:8\r\n:4\r\n" +
+				"$3\r\nlen\r\n:6\r\n",
+		},
+		{
+			name: "treats missing keys as empty strings",
+			args: resp.Args{
+				[]byte("lcs"),
+				[]byte("key1"),
+				[]byte("missing"),
+			},
+			arrange: arrange,
+			want:    "$0\r\n\r\n",
+		},
+		{
+			name: "complains about LEN combined with IDX",
+			args: resp.Args{
+				[]byte("lcs"),
+				[]byte("key1"),
+				[]byte("key2"),
+				[]byte("len"),
+				[]byte("idx"),
+			},
+			arrange: arrange,
+			want:    "-" + msgLCSLenAndIdx + "\r\n",
+		},
+		{
+			name: "complains about unknown option",
+			args: resp.Args{
+				[]byte("lcs"),
+				[]byte("key1"),
+				[]byte("key2"),
+				[]byte("nope"),
+			},
+			arrange: arrange,
+			want:    "-ERR syntax error\r\n",
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			d := db.New()
+			if tc.arrange != nil {
+				tc.arrange(d)
+			}
+			srv := &Server{
+				dbMap: map[int]*db.DB{0: d},
+				clock: clock.New(now),
+			}
+
+			buf := new(bytes.Buffer)
+			w := resp.NewWriter(bufio.NewWriter(buf))
+			req := newRequest(session.New(), "LCS", tc.args)
+
+			if err := srv.cmdLCS(w, req); err != nil {
+				t.Fatalf("cmdLCS returned error: %v", err)
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("flush failed: %v", err)
+			}
+			if got := buf.String(); got != tc.want {
+				t.Fatalf("unexpected payload:\nwant %q\ngot  %q", tc.want, got)
+			}
+		})
+	}
+}
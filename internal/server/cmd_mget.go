@@ -0,0 +1,30 @@
+package server
+
+import (
+	"github.com/mickamy/minivalkey/internal/resp"
+)
+
+func (s *Server) cmdMGet(w *resp.Writer, r *request) error {
+	if err := validateCommand(r.cmd, r.args, validateArgCountAtLeast(2)); err != nil {
+		return w.WriteErrorAndFlush(err)
+	}
+	now := s.Now()
+	d := s.db(r.session)
+	if err := w.WriteArrayHeader(len(r.args) - 1); err != nil {
+		return err
+	}
+	for _, a := range r.args[1:] {
+		// Keys holding other types are reported as missing, as in Valkey.
+		if v, ok := d.GetString(now, string(a)); ok {
+			if err := w.WriteBulkElem([]byte(v)); err != nil {
+				return err
+			}
+		} else {
+			if err := w.WriteBulkElem(nil); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,78 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/mickamy/minivalkey/internal/clock"
+	"github.com/mickamy/minivalkey/internal/db"
+	"github.com/mickamy/minivalkey/internal/resp"
+	"github.com/mickamy/minivalkey/internal/session"
+)
+
+func TestServer_cmdMGet(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+
+	tcs := []struct {
+		name    string
+		args    resp.Args
+		arrange func(*db.DB)
+		want    string
+	}{
+		{
+			name: "returns values in order with nulls for missing keys",
+			args: resp.Args{
+				[]byte("mget"),
+				[]byte("a"),
+				[]byte("missing"),
+				[]byte("b"),
+			},
+			arrange: func(db *db.DB) {
+				db.SetString("a", "1", time.Time{})
+				db.SetString("b", "2", time.Time{})
+			},
+			want: "*3\r\n$1\r\n1\r\n$-1\r\n$1\r\n2\r\n",
+		},
+		{
+			name: "complains when no keys are provided",
+			args: resp.Args{
+				[]byte("mget"),
+			},
+			want: "-ERR wrong number of arguments for 'mget' command\r\n",
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			d := db.New()
+			if tc.arrange != nil {
+				tc.arrange(d)
+			}
+			srv := &Server{
+				dbMap: map[int]*db.DB{0: d},
+				clock: clock.New(now),
+			}
+
+			buf := new(bytes.Buffer)
+			w := resp.NewWriter(bufio.NewWriter(buf))
+			req := newRequest(session.New(), "MGET", tc.args)
+
+			if err := srv.cmdMGet(w, req); err != nil {
+				t.Fatalf("cmdMGet returned error: %v", err)
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("flush failed: %v", err)
+			}
+			if got := buf.String(); got != tc.want {
+				t.Fatalf("unexpected payload:\nwant %q\ngot  %q", tc.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,19 @@
+package server
+
+import (
+	"github.com/mickamy/minivalkey/internal/resp"
+)
+
+func (s *Server) cmdMonitor(w *resp.Writer, r *request) error {
+	if err := validateCommand(r.cmd, r.args, validateArgCountExact(1)); err != nil {
+		return w.WriteErrorAndFlush(err)
+	}
+	// The connection switches to streaming once the reply is sent; see Server.monitor.
+	r.session.Monitor = true
+
+	if err := w.WriteString("OK"); err != nil {
+		return err
+	}
+
+	return nil
+}
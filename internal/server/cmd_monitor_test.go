@@ -0,0 +1,61 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/mickamy/minivalkey/internal/clock"
+	"github.com/mickamy/minivalkey/internal/resp"
+	"github.com/mickamy/minivalkey/internal/session"
+)
+
+func TestServer_cmdMonitor(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+
+	tcs := []struct {
+		name        string
+		args        resp.Args
+		wantMonitor bool
+		want        string
+	}{
+		{
+			name:        "switches the session to monitoring",
+			args:        resp.Args{[]byte("monitor")},
+			wantMonitor: true,
+			want:        "+OK\r\n",
+		},
+		{
+			name: "complains about wrong number of arguments",
+			args: resp.Args{[]byte("monitor"), []byte("now")},
+			want: "-ERR wrong number of arguments for 'monitor' command\r\n",
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := &Server{clock: clock.New(now)}
+			var buf bytes.Buffer
+			w := resp.NewWriter(bufio.NewWriter(&buf))
+			sess := session.New()
+			if err := srv.cmdMonitor(w, newRequest(sess, tc.args.Cmd(), tc.args)); err != nil {
+				t.Fatalf("cmdMonitor returned error: %v", err)
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("flush failed: %v", err)
+			}
+			if got := buf.String(); got != tc.want {
+				t.Fatalf("unexpected reply:\nwant %q\ngot  %q", tc.want, got)
+			}
+			if sess.Monitor != tc.wantMonitor {
+				t.Fatalf("expected Monitor=%v, got %v", tc.wantMonitor, sess.Monitor)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package server
+
+import (
+	"errors"
+
+	"github.com/mickamy/minivalkey/internal/resp"
+)
+
+func (s *Server) cmdMSet(w *resp.Writer, r *request) error {
+	if err := validateMSet(r); err != nil {
+		return w.WriteErrorAndFlush(err)
+	}
+	s.db(r.session).MSet(s.Now(), false, mSetPairs(r.args)...)
+	if err := w.WriteString("OK"); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *Server) cmdMSetNX(w *resp.Writer, r *request) error {
+	if err := validateMSet(r); err != nil {
+		return w.WriteErrorAndFlush(err)
+	}
+	if s.db(r.session).MSet(s.Now(), true, mSetPairs(r.args)...) {
+		if err := w.WriteInt(1); err != nil {
+			return err
+		}
+	} else {
+		if err := w.WriteInt(0); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateMSet checks that MSET-style commands carry at least one complete key/value pair.
+func validateMSet(r *request) error {
+	if err := validateCommand(r.cmd, r.args, validateArgCountAtLeast(3)); err != nil {
+		return err
+	}
+	if len(r.args)%2 == 0 {
+		return errors.New(resp.WrongNumberOfArgsError(r.cmd))
+	}
+	return nil
+}
+
+// mSetPairs returns the alternating key/value arguments of an MSET-style command.
+func mSetPairs(args resp.Args) []string {
+	kvs := make([]string, len(args)-1)
+	for i, a := range args[1:] {
+		kvs[i] = string(a)
+	}
+	return kvs
+}
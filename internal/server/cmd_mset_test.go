@@ -0,0 +1,174 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/mickamy/minivalkey/internal/clock"
+	"github.com/mickamy/minivalkey/internal/db"
+	"github.com/mickamy/minivalkey/internal/resp"
+	"github.com/mickamy/minivalkey/internal/session"
+)
+
+func TestServer_cmdMSet(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+
+	tcs := []struct {
+		name    string
+		args    resp.Args
+		arrange func(*db.DB)
+		assert  func(*testing.T, *db.DB)
+		want    string
+	}{
+		{
+			name: "sets all pairs and clears ttl",
+			args: resp.Args{
+				[]byte("mset"),
+				[]byte("a"),
+				[]byte("1"),
+				[]byte("b"),
+				[]byte("2"),
+			},
+			arrange: func(db *db.DB) {
+				db.SetString("a", "old", now.Add(time.Minute))
+			},
+			assert: func(t *testing.T, db *db.DB) {
+				if got, _ := db.GetString(now, "a"); got != "1" {
+					t.Fatalf("expected a=1, got %q", got)
+				}
+				if got, _ := db.GetString(now, "b"); got != "2" {
+					t.Fatalf("expected b=2, got %q", got)
+				}
+				if ttl := db.TTL(now, "a"); ttl != -1 {
+					t.Fatalf("expected ttl -1, got %d", ttl)
+				}
+			},
+			want: "+OK\r\n",
+		},
+		{
+			name: "complains about dangling key",
+			args: resp.Args{
+				[]byte("mset"),
+				[]byte("a"),
+				[]byte("1"),
+				[]byte("b"),
+			},
+			want: "-ERR wrong number of arguments for 'mset' command\r\n",
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			d := db.New()
+			if tc.arrange != nil {
+				tc.arrange(d)
+			}
+			srv := &Server{
+				dbMap: map[int]*db.DB{0: d},
+				clock: clock.New(now),
+			}
+
+			buf := new(bytes.Buffer)
+			w := resp.NewWriter(bufio.NewWriter(buf))
+			req := newRequest(session.New(), "MSET", tc.args)
+
+			if err := srv.cmdMSet(w, req); err != nil {
+				t.Fatalf("cmdMSet returned error: %v", err)
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("flush failed: %v", err)
+			}
+			if got := buf.String(); got != tc.want {
+				t.Fatalf("unexpected payload:\nwant %q\ngot  %q", tc.want, got)
+			}
+			if tc.assert != nil {
+				tc.assert(t, d)
+			}
+		})
+	}
+}
+
+func TestServer_cmdMSetNX(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+
+	tcs := []struct {
+		name    string
+		args    resp.Args
+		arrange func(*db.DB)
+		assert  func(*testing.T, *db.DB)
+		want    string
+	}{
+		{
+			name: "sets all pairs when none exist",
+			args: resp.Args{
+				[]byte("msetnx"),
+				[]byte("a"),
+				[]byte("1"),
+				[]byte("b"),
+				[]byte("2"),
+			},
+			want: ":1\r\n",
+		},
+		{
+			name: "sets nothing when any key exists",
+			args: resp.Args{
+				[]byte("msetnx"),
+				[]byte("a"),
+				[]byte("1"),
+				[]byte("b"),
+				[]byte("2"),
+			},
+			arrange: func(db *db.DB) {
+				db.SetString("b", "old", time.Time{})
+			},
+			assert: func(t *testing.T, db *db.DB) {
+				if _, ok := db.GetString(now, "a"); ok {
+					t.Fatalf("expected a to stay missing")
+				}
+			},
+			want: ":0\r\n",
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			d := db.New()
+			if tc.arrange != nil {
+				tc.arrange(d)
+			}
+			srv := &Server{
+				dbMap: map[int]*db.DB{0: d},
+				clock: clock.New(now),
+			}
+
+			buf := new(bytes.Buffer)
+			w := resp.NewWriter(bufio.NewWriter(buf))
+			req := newRequest(session.New(), "MSETNX", tc.args)
+
+			if err := srv.cmdMSetNX(w, req); err != nil {
+				t.Fatalf("cmdMSetNX returned error: %v", err)
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("flush failed: %v", err)
+			}
+			if got := buf.String(); got != tc.want {
+				t.Fatalf("unexpected payload:\nwant %q\ngot  %q", tc.want, got)
+			}
+			if tc.assert != nil {
+				tc.assert(t, d)
+			}
+		})
+	}
+}
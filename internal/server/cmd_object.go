@@ -0,0 +1,54 @@
+package server
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/mickamy/minivalkey/internal/resp"
+)
+
+func (s *Server) cmdObject(w *resp.Writer, r *request) error {
+	// OBJECT ENCODING|IDLETIME|FREQ|REFCOUNT key
+	if err := validateCommand(r.cmd, r.args, validateArgCountAtLeast(2)); err != nil {
+		return w.WriteErrorAndFlush(err)
+	}
+	sub := strings.ToUpper(string(r.args[1]))
+	switch sub {
+	case "ENCODING", "IDLETIME", "FREQ", "REFCOUNT":
+	default:
+		return w.WriteErrorString(unknownSubcommandMsg(r.cmd, r.args[1]))
+	}
+	if len(r.args) != 3 {
+		return w.WriteErrorAndFlush(errors.New(resp.WrongNumberOfArgsError(resp.Command("object|" + strings.ToLower(sub)))))
+	}
+
+	key := string(r.args[2])
+	info, ok := s.db(r.session).Object(s.Now(), key)
+	if !ok {
+		if err := w.WriteNull(); err != nil {
+			return err
+		}
+		return nil
+	}
+
+	switch sub {
+	case "ENCODING":
+		if err := w.WriteBulk([]byte(info.Encoding)); err != nil {
+			return err
+		}
+	case "IDLETIME":
+		if err := w.WriteInt(int64(info.Idle.Seconds())); err != nil {
+			return err
+		}
+	case "FREQ":
+		// Access frequency is only tracked under an LFU maxmemory policy,
+		// and minivalkey always runs with the default (noeviction).
+		return w.WriteErrorString(msgLFUNotSelected)
+	case "REFCOUNT":
+		if err := w.WriteInt(info.RefCount); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
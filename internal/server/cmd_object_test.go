@@ -0,0 +1,155 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/mickamy/minivalkey/internal/clock"
+	"github.com/mickamy/minivalkey/internal/db"
+	"github.com/mickamy/minivalkey/internal/resp"
+	"github.com/mickamy/minivalkey/internal/session"
+)
+
+func TestServer_cmdObject(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+
+	tcs := []struct {
+		name    string
+		args    resp.Args
+		arrange func(*db.DB)
+		want    string
+	}{
+		{
+			name: "reports int encoding",
+			args: resp.Args{
+				[]byte("object"),
+				[]byte("encoding"),
+				[]byte("foo"),
+			},
+			arrange: func(d *db.DB) {
+				d.SetStringWithOptions(now, "foo", "12345", db.SetOptions{})
+			},
+			want: "$3\r\nint\r\n",
+		},
+		{
+			name: "reports embstr encoding",
+			args: resp.Args{
+				[]byte("object"),
+				[]byte("ENCODING"),
+				[]byte("foo"),
+			},
+			arrange: func(d *db.DB) {
+				d.SetStringWithOptions(now, "foo", "bar", db.SetOptions{})
+			},
+			want: "$6\r\nembstr\r\n",
+		},
+		{
+			name: "reports idle time from simulated clock",
+			args: resp.Args{
+				[]byte("object"),
+				[]byte("idletime"),
+				[]byte("foo"),
+			},
+			arrange: func(d *db.DB) {
+				d.SetStringWithOptions(now.Add(-90*time.Second), "foo", "bar", db.SetOptions{})
+			},
+			want: ":90\r\n",
+		},
+		{
+			name: "reports shared refcount for small integers",
+			args: resp.Args{
+				[]byte("object"),
+				[]byte("refcount"),
+				[]byte("foo"),
+			},
+			arrange: func(d *db.DB) {
+				d.SetStringWithOptions(now, "foo", "7", db.SetOptions{})
+			},
+			want: ":2147483647\r\n",
+		},
+		{
+			name: "reports refcount one for plain strings",
+			args: resp.Args{
+				[]byte("object"),
+				[]byte("refcount"),
+				[]byte("foo"),
+			},
+			arrange: func(d *db.DB) {
+				d.SetStringWithOptions(now, "foo", "bar", db.SetOptions{})
+			},
+			want: ":1\r\n",
+		},
+		{
+			name: "complains about freq without lfu policy",
+			args: resp.Args{
+				[]byte("object"),
+				[]byte("freq"),
+				[]byte("foo"),
+			},
+			arrange: func(db *db.DB) {
+				db.SetString("foo", "bar", time.Time{})
+			},
+			want: "-" + msgLFUNotSelected + "\r\n",
+		},
+		{
+			name: "returns null for missing key",
+			args: resp.Args{
+				[]byte("object"),
+				[]byte("encoding"),
+				[]byte("missing"),
+			},
+			want: "$-1\r\n",
+		},
+		{
+			name: "complains about unknown subcommand",
+			args: resp.Args{
+				[]byte("object"),
+				[]byte("nope"),
+				[]byte("foo"),
+			},
+			want: "-ERR unknown subcommand 'nope'. Try OBJECT HELP.\r\n",
+		},
+		{
+			name: "complains when key is missing",
+			args: resp.Args{
+				[]byte("object"),
+				[]byte("encoding"),
+			},
+			want: "-ERR wrong number of arguments for 'object|encoding' command\r\n",
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			d := db.New()
+			if tc.arrange != nil {
+				tc.arrange(d)
+			}
+			srv := &Server{
+				dbMap: map[int]*db.DB{0: d},
+				clock: clock.New(now),
+			}
+
+			buf := new(bytes.Buffer)
+			w := resp.NewWriter(bufio.NewWriter(buf))
+			req := newRequest(session.New(), "OBJECT", tc.args)
+
+			if err := srv.cmdObject(w, req); err != nil {
+				t.Fatalf("cmdObject returned error: %v", err)
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("flush failed: %v", err)
+			}
+			if got := buf.String(); got != tc.want {
+				t.Fatalf("unexpected payload:\nwant %q\ngot  %q", tc.want, got)
+			}
+		})
+	}
+}
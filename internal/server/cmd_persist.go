@@ -0,0 +1,23 @@
+package server
+
+import (
+	"github.com/mickamy/minivalkey/internal/resp"
+)
+
+func (s *Server) cmdPersist(w *resp.Writer, r *request) error {
+	if err := validateCommand(r.cmd, r.args, validateArgCountExact(2)); err != nil {
+		return w.WriteErrorAndFlush(err)
+	}
+	key := string(r.args[1])
+	if s.db(r.session).Persist(s.Now(), key) {
+		if err := w.WriteInt(1); err != nil {
+			return err
+		}
+	} else {
+		if err := w.WriteInt(0); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
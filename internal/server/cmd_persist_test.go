@@ -0,0 +1,94 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/mickamy/minivalkey/internal/clock"
+	"github.com/mickamy/minivalkey/internal/db"
+	"github.com/mickamy/minivalkey/internal/resp"
+	"github.com/mickamy/minivalkey/internal/session"
+)
+
+func TestServer_cmdPersist(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+
+	tcs := []struct {
+		name    string
+		args    resp.Args
+		arrange func(*db.DB)
+		want    string
+	}{
+		{
+			name: "removes expiry",
+			args: resp.Args{
+				[]byte("persist"),
+				[]byte("foo"),
+			},
+			arrange: func(db *db.DB) {
+				db.SetString("foo", "bar", now.Add(10*time.Second))
+			},
+			want: ":1\r\n",
+		},
+		{
+			name: "returns zero when key has no expiry",
+			args: resp.Args{
+				[]byte("persist"),
+				[]byte("foo"),
+			},
+			arrange: func(db *db.DB) {
+				db.SetString("foo", "bar", time.Time{})
+			},
+			want: ":0\r\n",
+		},
+		{
+			name: "returns zero when key is missing",
+			args: resp.Args{
+				[]byte("persist"),
+				[]byte("missing"),
+			},
+			want: ":0\r\n",
+		},
+		{
+			name: "complains when key argument is missing",
+			args: resp.Args{
+				[]byte("persist"),
+			},
+			want: "-ERR wrong number of arguments for 'persist' command\r\n",
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			d := db.New()
+			if tc.arrange != nil {
+				tc.arrange(d)
+			}
+			srv := &Server{
+				dbMap: map[int]*db.DB{0: d},
+				clock: clock.New(now),
+			}
+
+			buf := new(bytes.Buffer)
+			w := resp.NewWriter(bufio.NewWriter(buf))
+			req := newRequest(session.New(), "PERSIST", tc.args)
+
+			if err := srv.cmdPersist(w, req); err != nil {
+				t.Fatalf("cmdPersist returned error: %v", err)
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("flush failed: %v", err)
+			}
+			if got := buf.String(); got != tc.want {
+				t.Fatalf("unexpected payload:\nwant %q\ngot  %q", tc.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package server
+
+import (
+	"github.com/mickamy/minivalkey/internal/resp"
+)
+
+func (s *Server) cmdPFAdd(w *resp.Writer, r *request) error {
+	// PFADD key [element [element ...]]
+	if err := validateCommand(r.cmd, r.args, validateArgCountAtLeast(2)); err != nil {
+		return w.WriteErrorAndFlush(err)
+	}
+	key := string(r.args[1])
+	elems := make([]string, 0, len(r.args)-2)
+	for _, a := range r.args[2:] {
+		elems = append(elems, string(a))
+	}
+
+	updated, err := s.db(r.session).PFAdd(s.Now(), key, elems...)
+	if err != nil {
+		return writeHLLError(w, err)
+	}
+	var n int64
+	if updated {
+		n = 1
+	}
+	if err := w.WriteInt(n); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,117 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/mickamy/minivalkey/internal/clock"
+	"github.com/mickamy/minivalkey/internal/db"
+	"github.com/mickamy/minivalkey/internal/resp"
+	"github.com/mickamy/minivalkey/internal/session"
+)
+
+func TestServer_cmdPFAdd(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+
+	tcs := []struct {
+		name    string
+		args    resp.Args
+		arrange func(*db.DB)
+		assert  func(*testing.T, *db.DB)
+		want    string
+	}{
+		{
+			name: "adds elements to new key",
+			args: resp.Args{
+				[]byte("pfadd"),
+				[]byte("hll"),
+				[]byte("a"),
+				[]byte("b"),
+				[]byte("c"),
+			},
+			want: ":1\r\n",
+		},
+		{
+			name: "creates empty sparse value without elements",
+			args: resp.Args{
+				[]byte("pfadd"),
+				[]byte("hll"),
+			},
+			assert: func(t *testing.T, d *db.DB) {
+				if got, _ := d.GetString(now, "hll"); got != "HYLL\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x80\x7f\xff" {
+					t.Fatalf("expected %q, got %q", "HYLL\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x80\x7f\xff", got)
+				}
+			},
+			want: ":1\r\n",
+		},
+		{
+			name: "returns zero when no register changes",
+			args: resp.Args{
+				[]byte("pfadd"),
+				[]byte("hll"),
+				[]byte("a"),
+				[]byte("b"),
+			},
+			arrange: func(d *db.DB) {
+				d.PFAdd(now, "hll", "a", "b")
+			},
+			want: ":0\r\n",
+		},
+		{
+			name: "rejects non hyperloglog string",
+			args: resp.Args{
+				[]byte("pfadd"),
+				[]byte("hll"),
+				[]byte("a"),
+			},
+			arrange: func(d *db.DB) {
+				d.SetString("hll", "value", time.Time{})
+			},
+			want: "-WRONGTYPE Key is not a valid HyperLogLog string value.\r\n",
+		},
+		{
+			name: "wrong number of arguments",
+			args: resp.Args{
+				[]byte("pfadd"),
+			},
+			want: "-ERR wrong number of arguments for 'pfadd' command\r\n",
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			d := db.New()
+			if tc.arrange != nil {
+				tc.arrange(d)
+			}
+			srv := &Server{
+				dbMap: map[int]*db.DB{0: d},
+				clock: clock.New(now),
+			}
+
+			buf := new(bytes.Buffer)
+			w := resp.NewWriter(bufio.NewWriter(buf))
+			req := newRequest(session.New(), "PFADD", tc.args)
+
+			if err := srv.cmdPFAdd(w, req); err != nil {
+				t.Fatalf("cmdPFAdd returned error: %v", err)
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("flush failed: %v", err)
+			}
+			if got := buf.String(); got != tc.want {
+				t.Fatalf("unexpected payload:\nwant %q\ngot  %q", tc.want, got)
+			}
+			if tc.assert != nil {
+				tc.assert(t, d)
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package server
+
+import (
+	"github.com/mickamy/minivalkey/internal/resp"
+)
+
+func (s *Server) cmdPFCount(w *resp.Writer, r *request) error {
+	// PFCOUNT key [key ...]
+	if err := validateCommand(r.cmd, r.args, validateArgCountAtLeast(2)); err != nil {
+		return w.WriteErrorAndFlush(err)
+	}
+	keys := make([]string, 0, len(r.args)-1)
+	for _, a := range r.args[1:] {
+		keys = append(keys, string(a))
+	}
+
+	n, err := s.db(r.session).PFCount(s.Now(), keys...)
+	if err != nil {
+		return writeHLLError(w, err)
+	}
+	if err := w.WriteInt(n); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,111 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/mickamy/minivalkey/internal/clock"
+	"github.com/mickamy/minivalkey/internal/db"
+	"github.com/mickamy/minivalkey/internal/resp"
+	"github.com/mickamy/minivalkey/internal/session"
+)
+
+func TestServer_cmdPFCount(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+
+	tcs := []struct {
+		name    string
+		args    resp.Args
+		arrange func(*db.DB)
+		want    string
+	}{
+		{
+			name: "counts distinct elements",
+			args: resp.Args{
+				[]byte("pfcount"),
+				[]byte("hll"),
+			},
+			arrange: func(d *db.DB) {
+				d.PFAdd(now, "hll", "foo", "bar", "zap", "zap", "zap")
+			},
+			want: ":3\r\n",
+		},
+		{
+			name: "counts union of keys",
+			args: resp.Args{
+				[]byte("pfcount"),
+				[]byte("hll"),
+				[]byte("other"),
+			},
+			arrange: func(d *db.DB) {
+				d.PFAdd(now, "hll", "foo", "bar", "zap")
+				d.PFAdd(now, "other", "1", "2", "3")
+			},
+			want: ":6\r\n",
+		},
+		{
+			name: "missing key",
+			args: resp.Args{
+				[]byte("pfcount"),
+				[]byte("hll"),
+			},
+			want: ":0\r\n",
+		},
+		{
+			name: "rejects non hyperloglog string",
+			args: resp.Args{
+				[]byte("pfcount"),
+				[]byte("hll"),
+			},
+			arrange: func(d *db.DB) {
+				d.SetString("hll", "HYLL\x00", time.Time{})
+			},
+			want: "-WRONGTYPE Key is not a valid HyperLogLog string value.\r\n",
+		},
+		{
+			name: "detects corrupted registers",
+			args: resp.Args{
+				[]byte("pfcount"),
+				[]byte("hll"),
+			},
+			arrange: func(d *db.DB) {
+				d.SetString("hll", "HYLL\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x80\x7f", time.Time{})
+			},
+			want: "-INVALIDOBJ Corrupted HLL object detected\r\n",
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			d := db.New()
+			if tc.arrange != nil {
+				tc.arrange(d)
+			}
+			srv := &Server{
+				dbMap: map[int]*db.DB{0: d},
+				clock: clock.New(now),
+			}
+
+			buf := new(bytes.Buffer)
+			w := resp.NewWriter(bufio.NewWriter(buf))
+			req := newRequest(session.New(), "PFCOUNT", tc.args)
+
+			if err := srv.cmdPFCount(w, req); err != nil {
+				t.Fatalf("cmdPFCount returned error: %v", err)
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("flush failed: %v", err)
+			}
+			if got := buf.String(); got != tc.want {
+				t.Fatalf("unexpected payload:\nwant %q\ngot  %q", tc.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package server
+
+import (
+	"github.com/mickamy/minivalkey/internal/resp"
+)
+
+func (s *Server) cmdPFMerge(w *resp.Writer, r *request) error {
+	// PFMERGE destkey [sourcekey [sourcekey ...]]
+	if err := validateCommand(r.cmd, r.args, validateArgCountAtLeast(2)); err != nil {
+		return w.WriteErrorAndFlush(err)
+	}
+	dst := string(r.args[1])
+	srcs := make([]string, 0, len(r.args)-2)
+	for _, a := range r.args[2:] {
+		srcs = append(srcs, string(a))
+	}
+
+	if err := s.db(r.session).PFMerge(s.Now(), dst, srcs...); err != nil {
+		return writeHLLError(w, err)
+	}
+	if err := w.WriteString("OK"); err != nil {
+		return err
+	}
+
+	return nil
+}
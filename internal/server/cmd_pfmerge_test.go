@@ -0,0 +1,105 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/mickamy/minivalkey/internal/clock"
+	"github.com/mickamy/minivalkey/internal/db"
+	"github.com/mickamy/minivalkey/internal/resp"
+	"github.com/mickamy/minivalkey/internal/session"
+)
+
+func TestServer_cmdPFMerge(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+
+	tcs := []struct {
+		name    string
+		args    resp.Args
+		arrange func(*db.DB)
+		assert  func(*testing.T, *db.DB)
+		want    string
+	}{
+		{
+			name: "merges sources into destination",
+			args: resp.Args{
+				[]byte("pfmerge"),
+				[]byte("dst"),
+				[]byte("a"),
+				[]byte("b"),
+			},
+			arrange: func(d *db.DB) {
+				d.PFAdd(now, "a", "foo", "bar", "zap", "a")
+				d.PFAdd(now, "b", "a", "b", "c", "foo")
+			},
+			assert: func(t *testing.T, d *db.DB) {
+				if got, _ := d.PFCount(now, "dst"); got != 6 {
+					t.Fatalf("expected merged cardinality 6, got %d", got)
+				}
+			},
+			want: "+OK\r\n",
+		},
+		{
+			name: "creates empty destination",
+			args: resp.Args{
+				[]byte("pfmerge"),
+				[]byte("dst"),
+			},
+			assert: func(t *testing.T, d *db.DB) {
+				if got, _ := d.GetString(now, "dst"); got != "HYLL\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x80\x7f\xff" {
+					t.Fatalf("expected %q, got %q", "HYLL\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x80\x7f\xff", got)
+				}
+			},
+			want: "+OK\r\n",
+		},
+		{
+			name: "rejects non hyperloglog source",
+			args: resp.Args{
+				[]byte("pfmerge"),
+				[]byte("dst"),
+				[]byte("a"),
+			},
+			arrange: func(d *db.DB) {
+				d.SetString("a", "value", time.Time{})
+			},
+			want: "-WRONGTYPE Key is not a valid HyperLogLog string value.\r\n",
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			d := db.New()
+			if tc.arrange != nil {
+				tc.arrange(d)
+			}
+			srv := &Server{
+				dbMap: map[int]*db.DB{0: d},
+				clock: clock.New(now),
+			}
+
+			buf := new(bytes.Buffer)
+			w := resp.NewWriter(bufio.NewWriter(buf))
+			req := newRequest(session.New(), "PFMERGE", tc.args)
+
+			if err := srv.cmdPFMerge(w, req); err != nil {
+				t.Fatalf("cmdPFMerge returned error: %v", err)
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("flush failed: %v", err)
+			}
+			if got := buf.String(); got != tc.want {
+				t.Fatalf("unexpected payload:\nwant %q\ngot  %q", tc.want, got)
+			}
+			if tc.assert != nil {
+				tc.assert(t, d)
+			}
+		})
+	}
+}
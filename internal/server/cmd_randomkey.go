@@ -0,0 +1,22 @@
+package server
+
+import (
+	"github.com/mickamy/minivalkey/internal/resp"
+)
+
+func (s *Server) cmdRandomKey(w *resp.Writer, r *request) error {
+	if err := validateCommand(r.cmd, r.args, validateArgCountExact(1)); err != nil {
+		return w.WriteErrorAndFlush(err)
+	}
+	if k, ok := s.db(r.session).RandomKey(s.Now()); ok {
+		if err := w.WriteBulk([]byte(k)); err != nil {
+			return err
+		}
+	} else {
+		if err := w.WriteNull(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,83 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/mickamy/minivalkey/internal/clock"
+	"github.com/mickamy/minivalkey/internal/db"
+	"github.com/mickamy/minivalkey/internal/resp"
+	"github.com/mickamy/minivalkey/internal/session"
+)
+
+func TestServer_cmdRandomKey(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+
+	tcs := []struct {
+		name    string
+		args    resp.Args
+		arrange func(*db.DB)
+		want    string
+	}{
+		{
+			name: "returns the only live key",
+			args: resp.Args{
+				[]byte("randomkey"),
+			},
+			arrange: func(db *db.DB) {
+				db.SetString("foo", "bar", time.Time{})
+				db.SetString("stale", "x", now.Add(-time.Second))
+			},
+			want: "$3\r\nfoo\r\n",
+		},
+		{
+			name: "returns null when db is empty",
+			args: resp.Args{
+				[]byte("randomkey"),
+			},
+			want: "$-1\r\n",
+		},
+		{
+			name: "complains about extra arguments",
+			args: resp.Args{
+				[]byte("randomkey"),
+				[]byte("foo"),
+			},
+			want: "-ERR wrong number of arguments for 'randomkey' command\r\n",
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			d := db.New()
+			if tc.arrange != nil {
+				tc.arrange(d)
+			}
+			srv := &Server{
+				dbMap: map[int]*db.DB{0: d},
+				clock: clock.New(now),
+			}
+
+			buf := new(bytes.Buffer)
+			w := resp.NewWriter(bufio.NewWriter(buf))
+			req := newRequest(session.New(), "RANDOMKEY", tc.args)
+
+			if err := srv.cmdRandomKey(w, req); err != nil {
+				t.Fatalf("cmdRandomKey returned error: %v", err)
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("flush failed: %v", err)
+			}
+			if got := buf.String(); got != tc.want {
+				t.Fatalf("unexpected payload:\nwant %q\ngot  %q", tc.want, got)
+			}
+		})
+	}
+}
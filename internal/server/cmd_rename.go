@@ -0,0 +1,44 @@
+package server
+
+import (
+	"github.com/mickamy/minivalkey/internal/resp"
+)
+
+func (s *Server) cmdRename(w *resp.Writer, r *request) error {
+	if err := validateCommand(r.cmd, r.args, validateArgCountExact(3)); err != nil {
+		return w.WriteErrorAndFlush(err)
+	}
+	src := string(r.args[1])
+	dst := string(r.args[2])
+	if _, ok := s.db(r.session).Rename(s.Now(), src, dst, false); !ok {
+		return w.WriteErrorAndFlush(ErrNoSuchKey)
+	}
+	if err := w.WriteString("OK"); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *Server) cmdRenameNX(w *resp.Writer, r *request) error {
+	if err := validateCommand(r.cmd, r.args, validateArgCountExact(3)); err != nil {
+		return w.WriteErrorAndFlush(err)
+	}
+	src := string(r.args[1])
+	dst := string(r.args[2])
+	renamed, ok := s.db(r.session).Rename(s.Now(), src, dst, true)
+	if !ok {
+		return w.WriteErrorAndFlush(ErrNoSuchKey)
+	}
+	if renamed {
+		if err := w.WriteInt(1); err != nil {
+			return err
+		}
+	} else {
+		if err := w.WriteInt(0); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,148 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/mickamy/minivalkey/internal/clock"
+	"github.com/mickamy/minivalkey/internal/db"
+	"github.com/mickamy/minivalkey/internal/resp"
+	"github.com/mickamy/minivalkey/internal/session"
+)
+
+func TestServer_cmdRename(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+
+	tcs := []struct {
+		name    string
+		cmd     resp.Command
+		args    resp.Args
+		arrange func(*db.DB)
+		assert  func(*testing.T, *db.DB)
+		want    string
+	}{
+		{
+			name: "renames key and keeps ttl",
+			cmd:  "RENAME",
+			args: resp.Args{
+				[]byte("rename"),
+				[]byte("foo"),
+				[]byte("bar"),
+			},
+			arrange: func(db *db.DB) {
+				db.SetString("foo", "v", now.Add(10*time.Second))
+			},
+			assert: func(t *testing.T, db *db.DB) {
+				if got, _ := db.GetString(now, "bar"); got != "v" {
+					t.Fatalf("expected bar=v, got %q", got)
+				}
+				if ttl := db.TTL(now, "bar"); ttl != 10 {
+					t.Fatalf("expected ttl 10, got %d", ttl)
+				}
+			},
+			want: "+OK\r\n",
+		},
+		{
+			name: "complains when source is missing",
+			cmd:  "RENAME",
+			args: resp.Args{
+				[]byte("rename"),
+				[]byte("missing"),
+				[]byte("bar"),
+			},
+			want: "-ERR no such key\r\n",
+		},
+		{
+			name: "renamenx returns one when destination is free",
+			cmd:  "RENAMENX",
+			args: resp.Args{
+				[]byte("renamenx"),
+				[]byte("foo"),
+				[]byte("bar"),
+			},
+			arrange: func(db *db.DB) {
+				db.SetString("foo", "v", time.Time{})
+			},
+			want: ":1\r\n",
+		},
+		{
+			name: "renamenx returns zero when destination exists",
+			cmd:  "RENAMENX",
+			args: resp.Args{
+				[]byte("renamenx"),
+				[]byte("foo"),
+				[]byte("bar"),
+			},
+			arrange: func(db *db.DB) {
+				db.SetString("foo", "v", time.Time{})
+				db.SetString("bar", "old", time.Time{})
+			},
+			assert: func(t *testing.T, db *db.DB) {
+				if got, _ := db.GetString(now, "bar"); got != "old" {
+					t.Fatalf("expected bar=old, got %q", got)
+				}
+			},
+			want: ":0\r\n",
+		},
+		{
+			name: "renamenx complains when source is missing",
+			cmd:  "RENAMENX",
+			args: resp.Args{
+				[]byte("renamenx"),
+				[]byte("missing"),
+				[]byte("bar"),
+			},
+			want: "-ERR no such key\r\n",
+		},
+		{
+			name: "complains when destination is missing",
+			cmd:  "RENAME",
+			args: resp.Args{
+				[]byte("rename"),
+				[]byte("foo"),
+			},
+			want: "-ERR wrong number of arguments for 'rename' command\r\n",
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			d := db.New()
+			if tc.arrange != nil {
+				tc.arrange(d)
+			}
+			srv := &Server{
+				dbMap: map[int]*db.DB{0: d},
+				clock: clock.New(now),
+			}
+
+			buf := new(bytes.Buffer)
+			w := resp.NewWriter(bufio.NewWriter(buf))
+			req := newRequest(session.New(), tc.cmd, tc.args)
+
+			handle := srv.cmdRename
+			if tc.cmd == "RENAMENX" {
+				handle = srv.cmdRenameNX
+			}
+			if err := handle(w, req); err != nil {
+				t.Fatalf("%s returned error: %v", tc.cmd, err)
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("flush failed: %v", err)
+			}
+			if got := buf.String(); got != tc.want {
+				t.Fatalf("unexpected payload:\nwant %q\ngot  %q", tc.want, got)
+			}
+			if tc.assert != nil {
+				tc.assert(t, d)
+			}
+		})
+	}
+}
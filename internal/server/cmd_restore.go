@@ -0,0 +1,97 @@
+package server
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/mickamy/minivalkey/internal/db"
+	"github.com/mickamy/minivalkey/internal/rdb"
+	"github.com/mickamy/minivalkey/internal/resp"
+)
+
+func (s *Server) cmdRestore(w *resp.Writer, r *request) error {
+	// RESTORE key ttl serialized-value [REPLACE] [ABSTTL] [IDLETIME seconds] [FREQ frequency]
+	if err := validateCommand(r.cmd, r.args, validateArgCountAtLeast(4)); err != nil {
+		return w.WriteErrorAndFlush(err)
+	}
+	d := s.db(r.session)
+	now := s.Now()
+	key := string(r.args[1])
+
+	var (
+		opts            db.RestoreOptions
+		absTTL          bool
+		hasIdle, hasLFU bool
+	)
+	for i := 4; i < len(r.args); i++ {
+		left := len(r.args) - i - 1
+		// IDLETIME and FREQ are mutually exclusive, so each is a syntax error after the other.
+		switch opt := strings.ToUpper(string(r.args[i])); {
+		case opt == "REPLACE":
+			opts.Replace = true
+		case opt == "ABSTTL":
+			absTTL = true
+		case opt == "IDLETIME" && left >= 1 && !hasLFU:
+			idle, ok := resp.ParseInt(r.args[i+1])
+			if !ok {
+				return w.WriteErrorAndFlush(ErrValueNotInteger)
+			}
+			if idle < 0 {
+				return w.WriteErrorAndFlush(ErrInvalidIdleTime)
+			}
+			opts.Idle = time.Duration(min(idle, math.MaxInt64/int64(time.Second))) * time.Second
+			hasIdle = true
+			i++
+		case opt == "FREQ" && left >= 1 && !hasIdle:
+			freq, ok := resp.ParseInt(r.args[i+1])
+			if !ok {
+				return w.WriteErrorAndFlush(ErrValueNotInteger)
+			}
+			if freq < 0 || freq > 255 {
+				return w.WriteErrorAndFlush(ErrInvalidFreq)
+			}
+			// Access frequency is only tracked under an LFU maxmemory policy,
+			// so the value is validated and then ignored.
+			hasLFU = true
+			i++
+		default:
+			return w.WriteErrorAndFlush(ErrSyntax)
+		}
+	}
+
+	if !opts.Replace && d.Exists(now, key) > 0 {
+		return w.WriteErrorString(msgBusyKey)
+	}
+
+	ttl, ok := resp.ParseInt(r.args[2])
+	if !ok {
+		return w.WriteErrorAndFlush(ErrValueNotInteger)
+	}
+	if ttl < 0 {
+		return w.WriteErrorAndFlush(ErrInvalidTTL)
+	}
+	if ttl > 0 {
+		if opts.ExpireAt, ok = expireAtFrom(now, ttl, time.Millisecond, absTTL); !ok {
+			return w.WriteErrorAndFlush(ErrInvalidTTL)
+		}
+	}
+
+	v, err := rdb.Undump(r.args[3])
+	if err != nil {
+		if errors.Is(err, rdb.ErrChecksum) {
+			return w.WriteErrorAndFlush(ErrDumpPayload)
+		}
+		return w.WriteErrorAndFlush(ErrBadDataFormat)
+	}
+
+	if !d.RestoreValue(now, key, v, opts) {
+		return w.WriteErrorString(msgBusyKey)
+	}
+	if err := w.WriteString("OK"); err != nil {
+		return err
+	}
+
+	return nil
+}
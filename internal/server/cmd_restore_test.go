@@ -0,0 +1,238 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/mickamy/minivalkey/internal/clock"
+	"github.com/mickamy/minivalkey/internal/db"
+	"github.com/mickamy/minivalkey/internal/rdb"
+	"github.com/mickamy/minivalkey/internal/resp"
+	"github.com/mickamy/minivalkey/internal/session"
+)
+
+func TestServer_cmdRestore(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+	payload := rdb.Dump(rdb.Value{Kind: rdb.KindString, String: "bar"})
+	corrupt := append([]byte(nil), payload...)
+	corrupt[len(corrupt)-1] ^= 0xff
+
+	assertString := func(key, want string) func(*testing.T, *db.DB) {
+		return func(t *testing.T, d *db.DB) {
+			got, ok := d.GetString(now, key)
+			if !ok || got != want {
+				t.Fatalf("expected %s=%s, got %q ok=%v", key, want, got, ok)
+			}
+		}
+	}
+
+	tcs := []struct {
+		name    string
+		args    resp.Args
+		arrange func(*db.DB)
+		assert  func(*testing.T, *db.DB)
+		want    string
+	}{
+		{
+			name:   "restores a payload",
+			args:   resp.Args{[]byte("restore"), []byte("foo"), []byte("0"), payload},
+			assert: assertString("foo", "bar"),
+			want:   "+OK\r\n",
+		},
+		{
+			name:   "restores a payload written by valkey",
+			args:   resp.Args{[]byte("restore"), []byte("foo"), []byte("0"), []byte("\x00\xc0\n\t\x00\xbem\x06\x89Z(\x00\n")},
+			assert: assertString("foo", "10"),
+			want:   "+OK\r\n",
+		},
+		{
+			name: "restores hash field expirations",
+			args: resp.Args{[]byte("restore"), []byte("foo"), []byte("0"), rdb.Dump(rdb.Value{
+				Kind: rdb.KindHash, Hash: []rdb.HashField{{Field: "a", Value: "1", ExpireAt: now.Add(10 * time.Second)}, {Field: "b", Value: "2"}},
+			})},
+			assert: func(t *testing.T, d *db.DB) {
+				if ttls, err := d.HPTTL(now, "foo", "a", "b"); err != nil || ttls[0] != 10_000 || ttls[1] != -1 {
+					t.Fatalf("expected field pttls [10000 -1], got %v (err %v)", ttls, err)
+				}
+			},
+			want: "+OK\r\n",
+		},
+		{
+			name: "restores a sorted set",
+			args: resp.Args{[]byte("restore"), []byte("foo"), []byte("0"), rdb.Dump(rdb.Value{
+				Kind: rdb.KindZSet, Encoding: "listpack", ZSet: []rdb.ZMember{{Member: "a", Score: 1.5}},
+			})},
+			assert: func(t *testing.T, d *db.DB) {
+				if score, ok, _ := d.ZScore(now, "foo", "a"); !ok || score != 1.5 {
+					t.Fatalf("expected a=1.5, got %v ok=%v", score, ok)
+				}
+			},
+			want: "+OK\r\n",
+		},
+		{
+			name: "sets a relative ttl in milliseconds",
+			args: resp.Args{[]byte("restore"), []byte("foo"), []byte("10000"), payload},
+			assert: func(t *testing.T, d *db.DB) {
+				if ttl := d.TTL(now, "foo"); ttl != 10 {
+					t.Fatalf("expected ttl 10, got %d", ttl)
+				}
+			},
+			want: "+OK\r\n",
+		},
+		{
+			name: "sets an absolute ttl with ABSTTL",
+			args: resp.Args{
+				[]byte("restore"), []byte("foo"),
+				[]byte(strconv.FormatInt(now.Add(15*time.Second).UnixMilli(), 10)),
+				payload, []byte("absttl"),
+			},
+			assert: func(t *testing.T, d *db.DB) {
+				if ttl := d.TTL(now, "foo"); ttl != 15 {
+					t.Fatalf("expected ttl 15, got %d", ttl)
+				}
+			},
+			want: "+OK\r\n",
+		},
+		{
+			name: "skips a key whose ABSTTL already passed",
+			args: resp.Args{[]byte("restore"), []byte("foo"), []byte("1"), payload, []byte("ABSTTL"), []byte("REPLACE")},
+			arrange: func(d *db.DB) {
+				d.SetString("foo", "old", time.Time{})
+			},
+			assert: func(t *testing.T, d *db.DB) {
+				if d.Exists(now, "foo") != 0 {
+					t.Fatalf("expected foo to be deleted")
+				}
+			},
+			want: "+OK\r\n",
+		},
+		{
+			name: "sets idle time with IDLETIME",
+			args: resp.Args{[]byte("restore"), []byte("foo"), []byte("0"), payload, []byte("IDLETIME"), []byte("60")},
+			assert: func(t *testing.T, d *db.DB) {
+				if info, _ := d.Object(now, "foo"); info.Idle != time.Minute {
+					t.Fatalf("expected idle 1m, got %v", info.Idle)
+				}
+			},
+			want: "+OK\r\n",
+		},
+		{
+			name:   "accepts FREQ",
+			args:   resp.Args{[]byte("restore"), []byte("foo"), []byte("0"), payload, []byte("FREQ"), []byte("255")},
+			assert: assertString("foo", "bar"),
+			want:   "+OK\r\n",
+		},
+		{
+			name: "refuses to overwrite an existing key",
+			args: resp.Args{[]byte("restore"), []byte("foo"), []byte("0"), payload},
+			arrange: func(d *db.DB) {
+				d.SetString("foo", "old", time.Time{})
+			},
+			assert: assertString("foo", "old"),
+			want:   "-BUSYKEY Target key name already exists.\r\n",
+		},
+		{
+			name: "overwrites an existing key with REPLACE",
+			args: resp.Args{[]byte("restore"), []byte("foo"), []byte("0"), payload, []byte("replace")},
+			arrange: func(d *db.DB) {
+				d.SetString("foo", "old", time.Time{})
+			},
+			assert: assertString("foo", "bar"),
+			want:   "+OK\r\n",
+		},
+		{
+			name: "checks for an existing key before the ttl",
+			args: resp.Args{[]byte("restore"), []byte("foo"), []byte("-1"), payload},
+			arrange: func(d *db.DB) {
+				d.SetString("foo", "old", time.Time{})
+			},
+			want: "-BUSYKEY Target key name already exists.\r\n",
+		},
+		{
+			name: "rejects a negative ttl",
+			args: resp.Args{[]byte("restore"), []byte("foo"), []byte("-1"), payload},
+			want: "-ERR Invalid TTL value, must be >= 0\r\n",
+		},
+		{
+			name: "rejects a non integer ttl",
+			args: resp.Args{[]byte("restore"), []byte("foo"), []byte("abc"), payload},
+			want: "-ERR value is not an integer or out of range\r\n",
+		},
+		{
+			name: "rejects a negative IDLETIME",
+			args: resp.Args{[]byte("restore"), []byte("foo"), []byte("0"), payload, []byte("IDLETIME"), []byte("-1")},
+			want: "-ERR Invalid IDLETIME value, must be >= 0\r\n",
+		},
+		{
+			name: "rejects an out of range FREQ",
+			args: resp.Args{[]byte("restore"), []byte("foo"), []byte("0"), payload, []byte("FREQ"), []byte("256")},
+			want: "-ERR Invalid FREQ value, must be >= 0 and <= 255\r\n",
+		},
+		{
+			name: "rejects IDLETIME combined with FREQ",
+			args: resp.Args{
+				[]byte("restore"), []byte("foo"), []byte("0"), payload,
+				[]byte("IDLETIME"), []byte("1"), []byte("FREQ"), []byte("1"),
+			},
+			want: "-ERR syntax error\r\n",
+		},
+		{
+			name: "rejects an unknown option",
+			args: resp.Args{[]byte("restore"), []byte("foo"), []byte("0"), payload, []byte("NX")},
+			want: "-ERR syntax error\r\n",
+		},
+		{
+			name: "rejects a payload with a wrong checksum",
+			args: resp.Args{[]byte("restore"), []byte("foo"), []byte("0"), corrupt},
+			want: "-ERR DUMP payload version or checksum are wrong\r\n",
+		},
+		{
+			name: "rejects a payload that cannot be decoded",
+			args: resp.Args{[]byte("restore"), []byte("foo"), []byte("0"), rdb.Dump(rdb.Value{Kind: rdb.KindList})},
+			want: "-ERR Bad data format\r\n",
+		},
+		{
+			name: "complains about wrong number of arguments",
+			args: resp.Args{[]byte("restore"), []byte("foo"), []byte("0")},
+			want: "-ERR wrong number of arguments for 'restore' command\r\n",
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			d := db.New()
+			if tc.arrange != nil {
+				tc.arrange(d)
+			}
+			srv := &Server{
+				dbMap: map[int]*db.DB{0: d},
+				clock: clock.New(now),
+			}
+
+			buf := new(bytes.Buffer)
+			w := resp.NewWriter(bufio.NewWriter(buf))
+			req := newRequest(session.New(), "RESTORE", tc.args)
+
+			if err := srv.cmdRestore(w, req); err != nil {
+				t.Fatalf("cmdRestore returned error: %v", err)
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("flush failed: %v", err)
+			}
+			if got := buf.String(); got != tc.want {
+				t.Fatalf("unexpected payload:\nwant %q\ngot  %q", tc.want, got)
+			}
+			if tc.assert != nil {
+				tc.assert(t, d)
+			}
+		})
+	}
+}
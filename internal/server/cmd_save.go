@@ -0,0 +1,25 @@
+package server
+
+import (
+	"github.com/mickamy/minivalkey/internal/resp"
+)
+
+func (s *Server) cmdSave(w *resp.Writer, r *request) error {
+	// SAVE
+	if err := validateCommand(r.cmd, r.args, validateArgCountExact(1)); err != nil {
+		return w.WriteErrorAndFlush(err)
+	}
+	if s.bgSaving() {
+		return w.WriteErrorAndFlush(ErrBgSaveInProgress)
+	}
+	if err := s.saveFile(); err != nil {
+		s.logger.Error("save failed", "err", err)
+		return w.WriteErrorAndFlush(ErrGeneric)
+	}
+
+	if err := w.WriteString("OK"); err != nil {
+		return err
+	}
+
+	return nil
+}
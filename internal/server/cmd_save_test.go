@@ -0,0 +1,119 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mickamy/minivalkey/internal/clock"
+	"github.com/mickamy/minivalkey/internal/db"
+	"github.com/mickamy/minivalkey/internal/rdb"
+	"github.com/mickamy/minivalkey/internal/resp"
+	"github.com/mickamy/minivalkey/internal/session"
+)
+
+func TestServer_cmdSave(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+
+	tcs := []struct {
+		name     string
+		args     resp.Args
+		filename func(dir string) string
+		bgSaving bool
+		wantFile bool
+		want     string
+	}{
+		{
+			name:     "writes the dataset to the rdb file",
+			args:     resp.Args{[]byte("save")},
+			wantFile: true,
+			want:     "+OK\r\n",
+		},
+		{
+			name:     "refuses while a background save runs",
+			args:     resp.Args{[]byte("save")},
+			bgSaving: true,
+			want:     "-ERR Background save already in progress\r\n",
+		},
+		{
+			name: "reports a failed save",
+			args: resp.Args{[]byte("save")},
+			filename: func(dir string) string {
+				return filepath.Join(dir, "missing", "dump.rdb")
+			},
+			want: "-ERR\r\n",
+		},
+		{
+			name: "complains about wrong number of arguments",
+			args: resp.Args{[]byte("save"), []byte("now")},
+			want: "-ERR wrong number of arguments for 'save' command\r\n",
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			filename := filepath.Join(dir, "dump.rdb")
+			if tc.filename != nil {
+				filename = tc.filename(dir)
+			}
+			d := db.New()
+			d.SetString("foo", "bar", time.Time{})
+			srv := &Server{
+				dbMap:      map[int]*db.DB{0: d},
+				clock:      clock.New(now),
+				dbFilename: filename,
+				logger:     slog.New(slog.DiscardHandler),
+			}
+			srv.persistence.bgSaving = tc.bgSaving
+
+			buf := new(bytes.Buffer)
+			w := resp.NewWriter(bufio.NewWriter(buf))
+			req := newRequest(session.New(), "SAVE", tc.args)
+
+			if err := srv.cmdSave(w, req); err != nil {
+				t.Fatalf("cmdSave returned error: %v", err)
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("flush failed: %v", err)
+			}
+			if got := buf.String(); got != tc.want {
+				t.Fatalf("unexpected payload:\nwant %q\ngot  %q", tc.want, got)
+			}
+
+			f, err := os.Open(filename)
+			if !tc.wantFile {
+				if err == nil {
+					_ = f.Close()
+					t.Fatalf("expected no rdb file")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected rdb file: %v", err)
+			}
+			defer func() {
+				_ = f.Close()
+			}()
+			dbs, err := rdb.Read(f, now)
+			if err != nil {
+				t.Fatalf("failed to read rdb file: %v", err)
+			}
+			if len(dbs) != 1 || len(dbs[0].Entries) != 1 || dbs[0].Entries[0].Key != "foo" {
+				t.Fatalf("unexpected rdb content %+v", dbs)
+			}
+			if got := srv.lastSave(); got != now.Unix() {
+				t.Fatalf("expected lastsave %d, got %d", now.Unix(), got)
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package server
+
+import (
+	"github.com/mickamy/minivalkey/internal/resp"
+)
+
+func (s *Server) cmdSelect(w *resp.Writer, r *request) error {
+	if err := validateCommand(r.cmd, r.args, validateArgCountExact(2)); err != nil {
+		return w.WriteErrorAndFlush(err)
+	}
+	idx, ok := resp.ParseInt(r.args[1])
+	if !ok {
+		return w.WriteErrorAndFlush(ErrValueNotInteger)
+	}
+	if s.cluster != nil && idx != 0 {
+		return w.WriteErrorAndFlush(ErrSelectInCluster)
+	}
+	if idx < 0 || idx >= numDatabases {
+		return w.WriteErrorAndFlush(ErrDBIndexOutOfRange)
+	}
+	r.session.SelectedDB = int(idx)
+	if err := w.WriteString("OK"); err != nil {
+		return err
+	}
+
+	return nil
+}
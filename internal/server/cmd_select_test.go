@@ -0,0 +1,80 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/mickamy/minivalkey/internal/clock"
+	"github.com/mickamy/minivalkey/internal/db"
+	"github.com/mickamy/minivalkey/internal/resp"
+	"github.com/mickamy/minivalkey/internal/session"
+)
+
+func TestServer_cmdSelect(t *testing.T) {
+	t.Parallel()
+
+	tcs := []struct {
+		name   string
+		args   resp.Args
+		want   string
+		wantDB int
+	}{
+		{
+			name: "switches database",
+			args: resp.Args{
+				[]byte("select"),
+				[]byte("5"),
+			},
+			want:   "+OK\r\n",
+			wantDB: 5,
+		},
+		{
+			name: "complains when index is out of range",
+			args: resp.Args{
+				[]byte("select"),
+				[]byte("16"),
+			},
+			want: "-ERR DB index is out of range\r\n",
+		},
+		{
+			name: "complains when index is not an integer",
+			args: resp.Args{
+				[]byte("select"),
+				[]byte("one"),
+			},
+			want: "-ERR value is not an integer or out of range\r\n",
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := &Server{
+				dbMap: map[int]*db.DB{},
+				clock: clock.New(time.Time{}),
+			}
+
+			buf := new(bytes.Buffer)
+			w := resp.NewWriter(bufio.NewWriter(buf))
+			sess := session.New()
+			req := newRequest(sess, "SELECT", tc.args)
+
+			if err := srv.cmdSelect(w, req); err != nil {
+				t.Fatalf("cmdSelect returned error: %v", err)
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("flush failed: %v", err)
+			}
+			if got := buf.String(); got != tc.want {
+				t.Fatalf("unexpected payload:\nwant %q\ngot  %q", tc.want, got)
+			}
+			if sess.SelectedDB != tc.wantDB {
+				t.Fatalf("expected selected db %d, got %d", tc.wantDB, sess.SelectedDB)
+			}
+		})
+	}
+}
@@ -115,7 +115,19 @@ func (s *Server) cmdSet(w *resp.Writer, r *request) error {
 		}
 	}
 
-	stored, prev, prevExists := s.db(r.session).SetStringWithOptions(now, key, val, opts)
+	var (
+		stored, prevExists bool
+		prev               string
+	)
+	if returnOld {
+		var err error
+		stored, prev, prevExists, err = s.db(r.session).SetStringAndGet(now, key, val, opts)
+		if err != nil {
+			return w.WriteErrorAndFlush(dbError(err))
+		}
+	} else {
+		stored, prev, prevExists = s.db(r.session).SetStringWithOptions(now, key, val, opts)
+	}
 	if !stored {
 		if err := w.WriteNull(); err != nil {
 			return err
@@ -101,8 +101,8 @@ func TestServer_cmdSet(t *testing.T) {
 				[]byte(strconv.FormatInt(now.Add(1500*time.Millisecond).UnixMilli(), 10)),
 			},
 			assert: func(t *testing.T, db *db.DB) {
-				if ttl := db.TTL(now, "foo"); ttl != 1 {
-					t.Fatalf("expected ttl 1, got %d", ttl)
+				if ttl := db.PTTL(now, "foo"); ttl != 1500 {
+					t.Fatalf("expected pttl 1500, got %d", ttl)
 				}
 			},
 			want: "+OK\r\n",
@@ -227,6 +227,24 @@ func TestServer_cmdSet(t *testing.T) {
 			},
 			want: "$-1\r\n",
 		},
+		{
+			name: "rejects GET when key holds another type",
+			args: resp.Args{
+				[]byte("set"),
+				[]byte("foo"),
+				[]byte("bar"),
+				[]byte("GET"),
+			},
+			arrange: func(d *db.DB) {
+				d.ZAdd(now, "foo", db.ZAddOptions{}, db.ZMember{Member: "m", Score: 1})
+			},
+			assert: func(t *testing.T, d *db.DB) {
+				if typ, _ := d.Type(now, "foo"); typ != db.TZSet {
+					t.Fatalf("expected foo to remain a zset")
+				}
+			},
+			want: "-WRONGTYPE Operation against a key holding the wrong kind of value\r\n",
+		},
 		{
 			name: "rejects duplicate GET option",
 			args: resp.Args{
@@ -0,0 +1,57 @@
+package server
+
+import (
+	"math"
+
+	"github.com/mickamy/minivalkey/internal/db"
+	"github.com/mickamy/minivalkey/internal/resp"
+)
+
+func (s *Server) cmdSetBit(w *resp.Writer, r *request) error {
+	// SETBIT key offset value
+	if err := validateCommand(r.cmd, r.args, validateArgCountExact(4)); err != nil {
+		return w.WriteErrorAndFlush(err)
+	}
+	key := string(r.args[1])
+	offset, ok := parseBitOffset(r.args[2], false, 0)
+	if !ok {
+		return w.WriteErrorAndFlush(ErrBitOffset)
+	}
+	var on bool
+	switch string(r.args[3]) {
+	case "0":
+	case "1":
+		on = true
+	default:
+		return w.WriteErrorAndFlush(ErrBitValue)
+	}
+
+	prev, err := s.db(r.session).SetBit(s.Now(), key, offset, on)
+	if err != nil {
+		return w.WriteErrorAndFlush(dbError(err))
+	}
+	if err := w.WriteInt(int64(prev)); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// parseBitOffset parses a bit offset bounded by the maximum string size.
+// When hash is true, a "#N" offset addresses the N-th field of the given width, as BITFIELD allows.
+func parseBitOffset(a resp.Arg, hash bool, bits uint) (uint64, bool) {
+	mul := int64(1)
+	if hash && len(a) > 0 && a[0] == '#' {
+		a = a[1:]
+		mul = int64(bits)
+	}
+	n, ok := db.ParseStrictInt(string(a))
+	if !ok || n < 0 || n > math.MaxInt64/mul {
+		return 0, false
+	}
+	n *= mul
+	if n > db.MaxBitOffset {
+		return 0, false
+	}
+	return uint64(n), true
+}
@@ -0,0 +1,133 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/mickamy/minivalkey/internal/clock"
+	"github.com/mickamy/minivalkey/internal/db"
+	"github.com/mickamy/minivalkey/internal/resp"
+	"github.com/mickamy/minivalkey/internal/session"
+)
+
+func TestServer_cmdSetBit(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+
+	tcs := []struct {
+		name    string
+		args    resp.Args
+		arrange func(*db.DB)
+		assert  func(*testing.T, *db.DB)
+		want    string
+	}{
+		{
+			name: "sets bit on missing key",
+			args: resp.Args{
+				[]byte("setbit"),
+				[]byte("foo"),
+				[]byte("7"),
+				[]byte("1"),
+			},
+			assert: func(t *testing.T, d *db.DB) {
+				if got, _ := d.GetString(now, "foo"); got != "\x01" {
+					t.Fatalf("expected %q, got %q", "\x01", got)
+				}
+			},
+			want: ":0\r\n",
+		},
+		{
+			name: "returns previous bit",
+			args: resp.Args{
+				[]byte("setbit"),
+				[]byte("foo"),
+				[]byte("0"),
+				[]byte("0"),
+			},
+			arrange: func(d *db.DB) {
+				d.SetString("foo", "\x80", time.Time{})
+			},
+			assert: func(t *testing.T, d *db.DB) {
+				if got, _ := d.GetString(now, "foo"); got != "\x00" {
+					t.Fatalf("expected %q, got %q", "\x00", got)
+				}
+			},
+			want: ":1\r\n",
+		},
+		{
+			name: "rejects invalid bit",
+			args: resp.Args{
+				[]byte("setbit"),
+				[]byte("foo"),
+				[]byte("0"),
+				[]byte("2"),
+			},
+			want: "-ERR bit is not an integer or out of range\r\n",
+		},
+		{
+			name: "rejects negative offset",
+			args: resp.Args{
+				[]byte("setbit"),
+				[]byte("foo"),
+				[]byte("-1"),
+				[]byte("1"),
+			},
+			want: "-ERR bit offset is not an integer or out of range\r\n",
+		},
+		{
+			name: "rejects offset beyond max string size",
+			args: resp.Args{
+				[]byte("setbit"),
+				[]byte("foo"),
+				[]byte("4294967296"),
+				[]byte("1"),
+			},
+			want: "-ERR bit offset is not an integer or out of range\r\n",
+		},
+		{
+			name: "wrong number of arguments",
+			args: resp.Args{
+				[]byte("setbit"),
+				[]byte("foo"),
+				[]byte("1"),
+			},
+			want: "-ERR wrong number of arguments for 'setbit' command\r\n",
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			d := db.New()
+			if tc.arrange != nil {
+				tc.arrange(d)
+			}
+			srv := &Server{
+				dbMap: map[int]*db.DB{0: d},
+				clock: clock.New(now),
+			}
+
+			buf := new(bytes.Buffer)
+			w := resp.NewWriter(bufio.NewWriter(buf))
+			req := newRequest(session.New(), "SETBIT", tc.args)
+
+			if err := srv.cmdSetBit(w, req); err != nil {
+				t.Fatalf("cmdSetBit returned error: %v", err)
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("flush failed: %v", err)
+			}
+			if got := buf.String(); got != tc.want {
+				t.Fatalf("unexpected payload:\nwant %q\ngot  %q", tc.want, got)
+			}
+			if tc.assert != nil {
+				tc.assert(t, d)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package server
+
+import (
+	"time"
+
+	"github.com/mickamy/minivalkey/internal/db"
+	"github.com/mickamy/minivalkey/internal/resp"
+)
+
+func (s *Server) cmdSetEx(w *resp.Writer, r *request) error {
+	return s.setExGeneric(w, r, time.Second)
+}
+
+func (s *Server) cmdPSetEx(w *resp.Writer, r *request) error {
+	return s.setExGeneric(w, r, time.Millisecond)
+}
+
+// setExGeneric implements SETEX and PSETEX: CMD key ttl value
+func (s *Server) setExGeneric(w *resp.Writer, r *request, unit time.Duration) error {
+	if err := validateCommand(r.cmd, r.args, validateArgCountExact(4)); err != nil {
+		return w.WriteErrorAndFlush(err)
+	}
+	key := string(r.args[1])
+	ttl, ok := resp.ParseInt(r.args[2])
+	if !ok {
+		return w.WriteErrorAndFlush(ErrValueNotInteger)
+	}
+	if ttl <= 0 {
+		return w.WriteErrorAndFlush(invalidExpireTimeError(r.cmd))
+	}
+	now := s.Now()
+	at, ok := expireAtFrom(now, ttl, unit, false)
+	if !ok {
+		return w.WriteErrorAndFlush(invalidExpireTimeError(r.cmd))
+	}
+
+	s.db(r.session).SetStringWithOptions(now, key, string(r.args[3]), db.SetOptions{HasExpire: true, ExpireAt: at})
+	if err := w.WriteString("OK"); err != nil {
+		return err
+	}
+
+	return nil
+}
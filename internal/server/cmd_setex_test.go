@@ -0,0 +1,110 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/mickamy/minivalkey/internal/clock"
+	"github.com/mickamy/minivalkey/internal/db"
+	"github.com/mickamy/minivalkey/internal/resp"
+	"github.com/mickamy/minivalkey/internal/session"
+)
+
+func TestServer_cmdSetEx(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+
+	tcs := []struct {
+		name    string
+		cmd     resp.Command
+		args    resp.Args
+		wantTTL int64
+		want    string
+	}{
+		{
+			name: "SETEX sets value with ttl in seconds",
+			cmd:  "SETEX",
+			args: resp.Args{
+				[]byte("setex"),
+				[]byte("foo"),
+				[]byte("10"),
+				[]byte("bar"),
+			},
+			wantTTL: 10_000,
+			want:    "+OK\r\n",
+		},
+		{
+			name: "PSETEX sets value with ttl in milliseconds",
+			cmd:  "PSETEX",
+			args: resp.Args{
+				[]byte("psetex"),
+				[]byte("foo"),
+				[]byte("1500"),
+				[]byte("bar"),
+			},
+			wantTTL: 1500,
+			want:    "+OK\r\n",
+		},
+		{
+			name: "complains about non-positive ttl",
+			cmd:  "SETEX",
+			args: resp.Args{
+				[]byte("setex"),
+				[]byte("foo"),
+				[]byte("0"),
+				[]byte("bar"),
+			},
+			wantTTL: -2,
+			want:    "-ERR invalid expire time in 'setex' command\r\n",
+		},
+		{
+			name: "complains about non-integer ttl",
+			cmd:  "PSETEX",
+			args: resp.Args{
+				[]byte("psetex"),
+				[]byte("foo"),
+				[]byte("soon"),
+				[]byte("bar"),
+			},
+			wantTTL: -2,
+			want:    "-ERR value is not an integer or out of range\r\n",
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			d := db.New()
+			srv := &Server{
+				dbMap: map[int]*db.DB{0: d},
+				clock: clock.New(now),
+			}
+
+			buf := new(bytes.Buffer)
+			w := resp.NewWriter(bufio.NewWriter(buf))
+			req := newRequest(session.New(), tc.cmd, tc.args)
+
+			handle := srv.cmdSetEx
+			if tc.cmd == "PSETEX" {
+				handle = srv.cmdPSetEx
+			}
+			if err := handle(w, req); err != nil {
+				t.Fatalf("%s returned error: %v", tc.cmd, err)
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("flush failed: %v", err)
+			}
+			if got := buf.String(); got != tc.want {
+				t.Fatalf("unexpected payload:\nwant %q\ngot  %q", tc.want, got)
+			}
+			if got := d.PTTL(now, "foo"); got != tc.wantTTL {
+				t.Fatalf("expected pttl %d, got %d", tc.wantTTL, got)
+			}
+		})
+	}
+}
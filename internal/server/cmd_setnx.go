@@ -0,0 +1,25 @@
+package server
+
+import (
+	"github.com/mickamy/minivalkey/internal/db"
+	"github.com/mickamy/minivalkey/internal/resp"
+)
+
+func (s *Server) cmdSetNX(w *resp.Writer, r *request) error {
+	if err := validateCommand(r.cmd, r.args, validateArgCountExact(3)); err != nil {
+		return w.WriteErrorAndFlush(err)
+	}
+	key := string(r.args[1])
+	val := string(r.args[2])
+	if stored, _, _ := s.db(r.session).SetStringWithOptions(s.Now(), key, val, db.SetOptions{NX: true}); stored {
+		if err := w.WriteInt(1); err != nil {
+			return err
+		}
+	} else {
+		if err := w.WriteInt(0); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,34 @@
+package server
+
+import (
+	"github.com/mickamy/minivalkey/internal/db"
+	"github.com/mickamy/minivalkey/internal/resp"
+)
+
+func (s *Server) cmdSetRange(w *resp.Writer, r *request) error {
+	// SETRANGE key offset value
+	if err := validateCommand(r.cmd, r.args, validateArgCountExact(4)); err != nil {
+		return w.WriteErrorAndFlush(err)
+	}
+	key := string(r.args[1])
+	offset, ok := resp.ParseInt(r.args[2])
+	if !ok {
+		return w.WriteErrorAndFlush(ErrValueNotInteger)
+	}
+	if offset < 0 {
+		return w.WriteErrorAndFlush(ErrOffsetOutOfRange)
+	}
+	if offset > db.MaxStringLen {
+		return w.WriteErrorAndFlush(ErrStringTooLong)
+	}
+
+	n, err := s.db(r.session).SetRange(s.Now(), key, int(offset), string(r.args[3]))
+	if err != nil {
+		return w.WriteErrorAndFlush(dbError(err))
+	}
+	if err := w.WriteInt(int64(n)); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,129 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/mickamy/minivalkey/internal/clock"
+	"github.com/mickamy/minivalkey/internal/db"
+	"github.com/mickamy/minivalkey/internal/resp"
+	"github.com/mickamy/minivalkey/internal/session"
+)
+
+func TestServer_cmdSetRange(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+
+	tcs := []struct {
+		name    string
+		args    resp.Args
+		arrange func(*db.DB)
+		assert  func(*testing.T, *db.DB)
+		want    string
+	}{
+		{
+			name: "overwrites part of the value",
+			args: resp.Args{
+				[]byte("setrange"),
+				[]byte("foo"),
+				[]byte("6"),
+				[]byte("Valkey"),
+			},
+			arrange: func(db *db.DB) {
+				db.SetString("foo", "Hello World", time.Time{})
+			},
+			assert: func(t *testing.T, db *db.DB) {
+				if got, _ := db.GetString(now, "foo"); got != "Hello Valkey" {
+					t.Fatalf("expected %q, got %q", "Hello Valkey", got)
+				}
+			},
+			want: ":12\r\n",
+		},
+		{
+			name: "zero pads missing key",
+			args: resp.Args{
+				[]byte("setrange"),
+				[]byte("foo"),
+				[]byte("2"),
+				[]byte("x"),
+			},
+			assert: func(t *testing.T, db *db.DB) {
+				if got, _ := db.GetString(now, "foo"); got != "\x00\x00x" {
+					t.Fatalf("expected zero padded value, got %q", got)
+				}
+			},
+			want: ":3\r\n",
+		},
+		{
+			name: "does not create key for empty value",
+			args: resp.Args{
+				[]byte("setrange"),
+				[]byte("foo"),
+				[]byte("2"),
+				[]byte(""),
+			},
+			assert: func(t *testing.T, db *db.DB) {
+				if _, ok := db.GetString(now, "foo"); ok {
+					t.Fatalf("expected foo to stay missing")
+				}
+			},
+			want: ":0\r\n",
+		},
+		{
+			name: "complains about negative offset",
+			args: resp.Args{
+				[]byte("setrange"),
+				[]byte("foo"),
+				[]byte("-1"),
+				[]byte("x"),
+			},
+			want: "-ERR offset is out of range\r\n",
+		},
+		{
+			name: "complains about offset beyond maximum size",
+			args: resp.Args{
+				[]byte("setrange"),
+				[]byte("foo"),
+				[]byte("536870912"),
+				[]byte("x"),
+			},
+			want: "-ERR string exceeds maximum allowed size (proto-max-bulk-len)\r\n",
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			d := db.New()
+			if tc.arrange != nil {
+				tc.arrange(d)
+			}
+			srv := &Server{
+				dbMap: map[int]*db.DB{0: d},
+				clock: clock.New(now),
+			}
+
+			buf := new(bytes.Buffer)
+			w := resp.NewWriter(bufio.NewWriter(buf))
+			req := newRequest(session.New(), "SETRANGE", tc.args)
+
+			if err := srv.cmdSetRange(w, req); err != nil {
+				t.Fatalf("cmdSetRange returned error: %v", err)
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("flush failed: %v", err)
+			}
+			if got := buf.String(); got != tc.want {
+				t.Fatalf("unexpected payload:\nwant %q\ngot  %q", tc.want, got)
+			}
+			if tc.assert != nil {
+				tc.assert(t, d)
+			}
+		})
+	}
+}
@@ -0,0 +1,292 @@
+package server
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mickamy/minivalkey/internal/db"
+	"github.com/mickamy/minivalkey/internal/resp"
+)
+
+// sortItem is an element being sorted with the weight it is compared by.
+type sortItem struct {
+	elem   string
+	score  float64
+	cmpKey *string // ALPHA with BY: nil when the weight key is missing
+}
+
+func (s *Server) cmdSort(w *resp.Writer, r *request) error {
+	// SORT key [BY pattern] [LIMIT offset count] [GET pattern [GET pattern ...]]
+	//   [ASC | DESC] [ALPHA] [STORE destination]
+	if err := validateCommand(r.cmd, r.args, validateArgCountAtLeast(2)); err != nil {
+		return w.WriteErrorAndFlush(err)
+	}
+	return s.sortGeneric(w, r, false)
+}
+
+func (s *Server) cmdSortRO(w *resp.Writer, r *request) error {
+	// SORT_RO key [BY pattern] [LIMIT offset count] [GET pattern [GET pattern ...]]
+	//   [ASC | DESC] [ALPHA]
+	if err := validateCommand(r.cmd, r.args, validateArgCountAtLeast(2)); err != nil {
+		return w.WriteErrorAndFlush(err)
+	}
+	return s.sortGeneric(w, r, true)
+}
+
+// sortGeneric implements SORT and SORT_RO, following Valkey's sortCommandGeneric.
+func (s *Server) sortGeneric(w *resp.Writer, r *request, readonly bool) error {
+	d := s.db(r.session)
+	now := s.Now()
+	key := string(r.args[1])
+
+	typ, exists := d.Type(now, key)
+	if exists && typ != db.TList && typ != db.TSet && typ != db.TZSet {
+		return w.WriteErrorAndFlush(ErrWrongType)
+	}
+
+	var (
+		desc, alpha, dontSort bool
+		by                    *string
+		gets                  []string
+		storeKey              string
+		store                 bool
+		limitStart            int64
+		limitCount            int64 = -1
+	)
+	for i := 2; i < len(r.args); i++ {
+		left := len(r.args) - i - 1
+		switch opt := strings.ToUpper(string(r.args[i])); {
+		case opt == "ASC":
+			desc = false
+		case opt == "DESC":
+			desc = true
+		case opt == "ALPHA":
+			alpha = true
+		case opt == "LIMIT" && left >= 2:
+			var ok bool
+			if limitStart, ok = resp.ParseInt(r.args[i+1]); !ok {
+				return w.WriteErrorAndFlush(ErrValueNotInteger)
+			}
+			if limitCount, ok = resp.ParseInt(r.args[i+2]); !ok {
+				return w.WriteErrorAndFlush(ErrValueNotInteger)
+			}
+			i += 2
+		case opt == "STORE" && left >= 1 && !readonly:
+			storeKey, store = string(r.args[i+1]), true
+			i++
+		case opt == "BY" && left >= 1:
+			pattern := string(r.args[i+1])
+			by = &pattern
+			// A constant pattern means there is nothing to sort by.
+			if !strings.Contains(pattern, "*") {
+				dontSort = true
+			}
+			i++
+		case opt == "GET" && left >= 1:
+			gets = append(gets, string(r.args[i+1]))
+			i++
+		default:
+			return w.WriteErrorAndFlush(ErrSyntax)
+		}
+	}
+
+	var elems []string
+	switch typ {
+	case db.TList:
+		elems, _, _ = d.ListItems(now, key)
+	case db.TSet:
+		elems, _, _ = d.SMembers(now, key)
+	case db.TZSet:
+		members, _, _ := d.ZMembers(now, key)
+		for _, m := range members {
+			elems = append(elems, m.Member)
+		}
+	}
+	if !exists {
+		typ = db.TList
+	}
+
+	// Sets have no natural order, so a stored result is sorted to stay deterministic.
+	if dontSort && typ == db.TSet && store {
+		dontSort, alpha, by = false, true, nil
+	}
+
+	n := int64(len(elems))
+	start := min(max(limitStart, 0), n)
+	count := min(max(limitCount, -1), n)
+	end := n - 1
+	if count >= 0 {
+		end = start + count - 1
+	}
+	if start >= n {
+		start, end = n-1, n-2
+	}
+	if end >= n {
+		end = n - 1
+	}
+
+	// Lists and sorted sets keep their own order when nothing is sorted,
+	// honoring DESC.
+	if dontSort && desc && typ != db.TSet {
+		slices.Reverse(elems)
+	}
+
+	if !dontSort {
+		items := make([]sortItem, len(elems))
+		convErr := false
+		for i, e := range elems {
+			items[i].elem = e
+			weight := e
+			if by != nil {
+				v, ok := sortLookup(d, now, *by, e)
+				if !ok {
+					continue
+				}
+				weight = v
+			}
+			if alpha {
+				if by != nil {
+					items[i].cmpKey = &weight
+				}
+				continue
+			}
+			score, ok := parseSortScore(weight)
+			if !ok {
+				convErr = true
+			}
+			items[i].score = score
+		}
+		if convErr {
+			return w.WriteErrorAndFlush(ErrSortScore)
+		}
+		slices.SortStableFunc(items, func(a, b sortItem) int {
+			c := compareSortItems(a, b, alpha, by != nil)
+			if desc {
+				return -c
+			}
+			return c
+		})
+		for i, it := range items {
+			elems[i] = it.elem
+		}
+	}
+
+	var picked []string
+	if end >= start {
+		picked = elems[start : end+1]
+	}
+
+	if store {
+		var out []string
+		for _, e := range picked {
+			if len(gets) == 0 {
+				out = append(out, e)
+				continue
+			}
+			for _, g := range gets {
+				v, _ := sortLookup(d, now, g, e)
+				out = append(out, v)
+			}
+		}
+		d.ListStore(now, storeKey, out)
+		if err := w.WriteInt(int64(len(out))); err != nil {
+			return err
+		}
+		return nil
+	}
+
+	size := len(picked)
+	if len(gets) > 0 {
+		size *= len(gets)
+	}
+	if err := w.WriteArrayHeader(size); err != nil {
+		return err
+	}
+	for _, e := range picked {
+		if len(gets) == 0 {
+			if err := w.WriteBulkElem([]byte(e)); err != nil {
+				return err
+			}
+			continue
+		}
+		for _, g := range gets {
+			var b []byte
+			if v, ok := sortLookup(d, now, g, e); ok {
+				b = []byte(v)
+			}
+			if err := w.WriteBulkElem(b); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// sortLookup resolves a BY or GET pattern for elem: "#" is elem itself, the
+// first "*" is replaced by elem, and a trailing "->field" reads a hash field.
+// Returns false when the pattern has no "*" or the value does not exist.
+func sortLookup(d *db.DB, now time.Time, pattern, elem string) (string, bool) {
+	if pattern == "#" {
+		return elem, true
+	}
+	star := strings.IndexByte(pattern, '*')
+	if star < 0 {
+		return "", false
+	}
+	rest := pattern[star+1:]
+	field := ""
+	if arrow := strings.Index(rest, "->"); arrow >= 0 && arrow+2 < len(rest) {
+		rest, field = rest[:arrow], rest[arrow+2:]
+	}
+	key := pattern[:star] + elem + rest
+
+	if field != "" {
+		v, ok, err := d.HGet(now, key, field)
+		return v, ok && err == nil
+	}
+	v, ok, err := d.LookupString(now, key)
+	return v, ok && err == nil
+}
+
+// parseSortScore converts a weight like strtod does in SORT: the whole string
+// must be consumed, and out of range values or NaN are rejected.
+func parseSortScore(v string) (float64, bool) {
+	v = strings.TrimLeft(v, " \t\n\v\f\r")
+	if v == "" {
+		return 0, true
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil || math.IsNaN(f) {
+		return 0, false
+	}
+	return f, true
+}
+
+// compareSortItems orders two items the way Valkey's sortCompare does (ascending).
+func compareSortItems(a, b sortItem, alpha, byPattern bool) int {
+	if !alpha {
+		if c := cmp.Compare(a.score, b.score); c != 0 {
+			return c
+		}
+		// Equal scores fall back to comparing the elements so the result is deterministic.
+		return strings.Compare(a.elem, b.elem)
+	}
+	if !byPattern {
+		return strings.Compare(a.elem, b.elem)
+	}
+	switch {
+	case a.cmpKey == nil && b.cmpKey == nil:
+		return 0
+	case a.cmpKey == nil:
+		return -1
+	case b.cmpKey == nil:
+		return 1
+	default:
+		return strings.Compare(*a.cmpKey, *b.cmpKey)
+	}
+}
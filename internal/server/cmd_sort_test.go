@@ -0,0 +1,455 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/mickamy/minivalkey/internal/clock"
+	"github.com/mickamy/minivalkey/internal/db"
+	"github.com/mickamy/minivalkey/internal/resp"
+	"github.com/mickamy/minivalkey/internal/session"
+)
+
+func TestServer_cmdSort(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+
+	tcs := []struct {
+		name    string
+		args    resp.Args
+		arrange func(*db.DB)
+		assert  func(*testing.T, *db.DB)
+		want    string
+	}{
+		{
+			name: "sorts list numerically",
+			args: resp.Args{
+				[]byte("sort"),
+				[]byte("nums"),
+			},
+			arrange: func(d *db.DB) {
+				d.RPush(now, "nums", "3", "1", "10", "2")
+			},
+			want: "*4\r\n$1\r\n1\r\n$1\r\n2\r\n$1\r\n3\r\n$2\r\n10\r\n",
+		},
+		{
+			name: "sorts descending with limit",
+			args: resp.Args{
+				[]byte("sort"),
+				[]byte("nums"),
+				[]byte("DESC"),
+				[]byte("LIMIT"),
+				[]byte("1"),
+				[]byte("2"),
+			},
+			arrange: func(d *db.DB) {
+				d.RPush(now, "nums", "3", "1", "10", "2")
+			},
+			want: "*2\r\n$1\r\n3\r\n$1\r\n2\r\n",
+		},
+		{
+			name: "sorts lexicographically with ALPHA",
+			args: resp.Args{
+				[]byte("sort"),
+				[]byte("nums"),
+				[]byte("ALPHA"),
+			},
+			arrange: func(d *db.DB) {
+				d.RPush(now, "nums", "3", "1", "10", "2")
+			},
+			want: "*4\r\n$1\r\n1\r\n$2\r\n10\r\n$1\r\n2\r\n$1\r\n3\r\n",
+		},
+		{
+			name: "sorts set members",
+			args: resp.Args{
+				[]byte("sort"),
+				[]byte("s"),
+			},
+			arrange: func(d *db.DB) {
+				d.SAdd(now, "s", "5", "-1", "3")
+			},
+			want: "*3\r\n$2\r\n-1\r\n$1\r\n3\r\n$1\r\n5\r\n",
+		},
+		{
+			name: "sorts sorted set members",
+			args: resp.Args{
+				[]byte("sort"),
+				[]byte("z"),
+				[]byte("ALPHA"),
+				[]byte("DESC"),
+			},
+			arrange: func(d *db.DB) {
+				d.ZAdd(now, "z", db.ZAddOptions{}, db.ZMember{Member: "a", Score: 3}, db.ZMember{Member: "b", Score: 1}, db.ZMember{Member: "c", Score: 2})
+			},
+			want: "*3\r\n$1\r\nc\r\n$1\r\nb\r\n$1\r\na\r\n",
+		},
+		{
+			name: "sorts by external weights",
+			args: resp.Args{
+				[]byte("sort"),
+				[]byte("uids"),
+				[]byte("BY"),
+				[]byte("weight_*"),
+			},
+			arrange: func(d *db.DB) {
+				d.RPush(now, "uids", "1", "2", "3")
+				d.SetString("weight_1", "30", time.Time{})
+				d.SetString("weight_2", "10", time.Time{})
+				d.SetString("weight_3", "20", time.Time{})
+				d.SetString("name_1", "alice", time.Time{})
+				d.SetString("name_2", "bob", time.Time{})
+				d.HSet(now, "user:1", "age", "41")
+				d.HSet(now, "user:2", "age", "25")
+				d.HSet(now, "user:3", "age", "33")
+			},
+			want: "*3\r\n$1\r\n2\r\n$1\r\n3\r\n$1\r\n1\r\n",
+		},
+		{
+			name: "sorts by hash field",
+			args: resp.Args{
+				[]byte("sort"),
+				[]byte("uids"),
+				[]byte("BY"),
+				[]byte("user:*->age"),
+				[]byte("DESC"),
+			},
+			arrange: func(d *db.DB) {
+				d.RPush(now, "uids", "1", "2", "3")
+				d.SetString("weight_1", "30", time.Time{})
+				d.SetString("weight_2", "10", time.Time{})
+				d.SetString("weight_3", "20", time.Time{})
+				d.SetString("name_1", "alice", time.Time{})
+				d.SetString("name_2", "bob", time.Time{})
+				d.HSet(now, "user:1", "age", "41")
+				d.HSet(now, "user:2", "age", "25")
+				d.HSet(now, "user:3", "age", "33")
+			},
+			want: "*3\r\n$1\r\n1\r\n$1\r\n3\r\n$1\r\n2\r\n",
+		},
+		{
+			name: "gets external values",
+			args: resp.Args{
+				[]byte("sort"),
+				[]byte("uids"),
+				[]byte("BY"),
+				[]byte("weight_*"),
+				[]byte("GET"),
+				[]byte("#"),
+				[]byte("GET"),
+				[]byte("name_*"),
+				[]byte("GET"),
+				[]byte("user:*->age"),
+			},
+			arrange: func(d *db.DB) {
+				d.RPush(now, "uids", "1", "2", "3")
+				d.SetString("weight_1", "30", time.Time{})
+				d.SetString("weight_2", "10", time.Time{})
+				d.SetString("weight_3", "20", time.Time{})
+				d.SetString("name_1", "alice", time.Time{})
+				d.SetString("name_2", "bob", time.Time{})
+				d.HSet(now, "user:1", "age", "41")
+				d.HSet(now, "user:2", "age", "25")
+				d.HSet(now, "user:3", "age", "33")
+			},
+			want: "*9\r\n$1\r\n2\r\n$3\r\nbob\r\n$2\r\n25\r\n$1\r\n3\r\n$-1\r\n$2\r\n33\r\n$1\r\n1\r\n$5\r\nalice\r\n$2\r\n41\r\n",
+		},
+		{
+			name: "keeps list order with constant BY",
+			args: resp.Args{
+				[]byte("sort"),
+				[]byte("nums"),
+				[]byte("BY"),
+				[]byte("nosort"),
+				[]byte("DESC"),
+			},
+			arrange: func(d *db.DB) {
+				d.RPush(now, "nums", "3", "1", "10", "2")
+			},
+			want: "*4\r\n$1\r\n2\r\n$2\r\n10\r\n$1\r\n1\r\n$1\r\n3\r\n",
+		},
+		{
+			name: "keeps sorted set order with constant BY",
+			args: resp.Args{
+				[]byte("sort"),
+				[]byte("z"),
+				[]byte("BY"),
+				[]byte("nosort"),
+				[]byte("LIMIT"),
+				[]byte("1"),
+				[]byte("5"),
+			},
+			arrange: func(d *db.DB) {
+				d.ZAdd(now, "z", db.ZAddOptions{}, db.ZMember{Member: "a", Score: 3}, db.ZMember{Member: "b", Score: 1}, db.ZMember{Member: "c", Score: 2})
+			},
+			want: "*2\r\n$1\r\nc\r\n$1\r\na\r\n",
+		},
+		{
+			name: "treats missing weights as zero",
+			args: resp.Args{
+				[]byte("sort"),
+				[]byte("uids"),
+				[]byte("BY"),
+				[]byte("missing_*"),
+			},
+			arrange: func(d *db.DB) {
+				d.RPush(now, "uids", "1", "2", "3")
+				d.SetString("weight_1", "30", time.Time{})
+				d.SetString("weight_2", "10", time.Time{})
+				d.SetString("weight_3", "20", time.Time{})
+				d.SetString("name_1", "alice", time.Time{})
+				d.SetString("name_2", "bob", time.Time{})
+				d.HSet(now, "user:1", "age", "41")
+				d.HSet(now, "user:2", "age", "25")
+				d.HSet(now, "user:3", "age", "33")
+			},
+			want: "*3\r\n$1\r\n1\r\n$1\r\n2\r\n$1\r\n3\r\n",
+		},
+		{
+			name: "stores result as list",
+			args: resp.Args{
+				[]byte("sort"),
+				[]byte("uids"),
+				[]byte("BY"),
+				[]byte("weight_*"),
+				[]byte("GET"),
+				[]byte("name_*"),
+				[]byte("STORE"),
+				[]byte("dst"),
+			},
+			arrange: func(d *db.DB) {
+				d.RPush(now, "uids", "1", "2", "3")
+				d.SetString("weight_1", "30", time.Time{})
+				d.SetString("weight_2", "10", time.Time{})
+				d.SetString("weight_3", "20", time.Time{})
+				d.SetString("name_1", "alice", time.Time{})
+				d.SetString("name_2", "bob", time.Time{})
+				d.HSet(now, "user:1", "age", "41")
+				d.HSet(now, "user:2", "age", "25")
+				d.HSet(now, "user:3", "age", "33")
+			},
+			assert: func(t *testing.T, d *db.DB) {
+				items, _, _ := d.ListItems(now, "dst")
+				if want := []string{"bob", "", "alice"}; !slices.Equal(items, want) {
+					t.Fatalf("expected %v, got %v", want, items)
+				}
+			},
+			want: ":3\r\n",
+		},
+		{
+			name: "deletes destination when result is empty",
+			args: resp.Args{
+				[]byte("sort"),
+				[]byte("missing"),
+				[]byte("STORE"),
+				[]byte("dst"),
+			},
+			arrange: func(d *db.DB) {
+				d.SetString("dst", "value", time.Time{})
+			},
+			assert: func(t *testing.T, d *db.DB) {
+				if _, ok := d.Type(now, "dst"); ok {
+					t.Fatalf("expected dst to be deleted")
+				}
+			},
+			want: ":0\r\n",
+		},
+		{
+			name: "returns empty array for missing key",
+			args: resp.Args{
+				[]byte("sort"),
+				[]byte("missing"),
+			},
+			want: "*0\r\n",
+		},
+		{
+			name: "returns empty array for offset past the end",
+			args: resp.Args{
+				[]byte("sort"),
+				[]byte("nums"),
+				[]byte("LIMIT"),
+				[]byte("10"),
+				[]byte("1"),
+			},
+			arrange: func(d *db.DB) {
+				d.RPush(now, "nums", "3", "1", "10", "2")
+			},
+			want: "*0\r\n",
+		},
+		{
+			name: "rejects elements that are not numbers",
+			args: resp.Args{
+				[]byte("sort"),
+				[]byte("l"),
+			},
+			arrange: func(d *db.DB) {
+				d.RPush(now, "l", "1", "a")
+			},
+			want: "-ERR One or more scores can't be converted into double\r\n",
+		},
+		{
+			name: "rejects weights that are not numbers",
+			args: resp.Args{
+				[]byte("sort"),
+				[]byte("uids"),
+				[]byte("BY"),
+				[]byte("name_*"),
+			},
+			arrange: func(d *db.DB) {
+				d.RPush(now, "uids", "1", "2", "3")
+				d.SetString("weight_1", "30", time.Time{})
+				d.SetString("weight_2", "10", time.Time{})
+				d.SetString("weight_3", "20", time.Time{})
+				d.SetString("name_1", "alice", time.Time{})
+				d.SetString("name_2", "bob", time.Time{})
+				d.HSet(now, "user:1", "age", "41")
+				d.HSet(now, "user:2", "age", "25")
+				d.HSet(now, "user:3", "age", "33")
+			},
+			want: "-ERR One or more scores can't be converted into double\r\n",
+		},
+		{
+			name: "rejects non integer limit",
+			args: resp.Args{
+				[]byte("sort"),
+				[]byte("nums"),
+				[]byte("LIMIT"),
+				[]byte("a"),
+				[]byte("1"),
+			},
+			arrange: func(d *db.DB) {
+				d.RPush(now, "nums", "3", "1", "10", "2")
+			},
+			want: "-ERR value is not an integer or out of range\r\n",
+		},
+		{
+			name: "rejects unknown option",
+			args: resp.Args{
+				[]byte("sort"),
+				[]byte("nums"),
+				[]byte("LIMIT"),
+				[]byte("1"),
+			},
+			arrange: func(d *db.DB) {
+				d.RPush(now, "nums", "3", "1", "10", "2")
+			},
+			want: "-ERR syntax error\r\n",
+		},
+		{
+			name: "rejects wrong type",
+			args: resp.Args{
+				[]byte("sort"),
+				[]byte("nums"),
+			},
+			arrange: func(d *db.DB) {
+				d.SetString("nums", "value", time.Time{})
+			},
+			want: "-WRONGTYPE Operation against a key holding the wrong kind of value\r\n",
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			d := db.New()
+			if tc.arrange != nil {
+				tc.arrange(d)
+			}
+			srv := &Server{
+				dbMap: map[int]*db.DB{0: d},
+				clock: clock.New(now),
+			}
+
+			buf := new(bytes.Buffer)
+			w := resp.NewWriter(bufio.NewWriter(buf))
+			req := newRequest(session.New(), "SORT", tc.args)
+
+			if err := srv.cmdSort(w, req); err != nil {
+				t.Fatalf("cmdSort returned error: %v", err)
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("flush failed: %v", err)
+			}
+			if got := buf.String(); got != tc.want {
+				t.Fatalf("unexpected payload:\nwant %q\ngot  %q", tc.want, got)
+			}
+			if tc.assert != nil {
+				tc.assert(t, d)
+			}
+		})
+	}
+}
+
+func TestServer_cmdSortRO(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+
+	tcs := []struct {
+		name    string
+		args    resp.Args
+		arrange func(*db.DB)
+		want    string
+	}{
+		{
+			name: "sorts list",
+			args: resp.Args{
+				[]byte("sort_ro"),
+				[]byte("nums"),
+				[]byte("DESC"),
+			},
+			arrange: func(d *db.DB) {
+				d.RPush(now, "nums", "3", "1", "10", "2")
+			},
+			want: "*4\r\n$2\r\n10\r\n$1\r\n3\r\n$1\r\n2\r\n$1\r\n1\r\n",
+		},
+		{
+			name: "rejects STORE",
+			args: resp.Args{
+				[]byte("sort_ro"),
+				[]byte("nums"),
+				[]byte("STORE"),
+				[]byte("dst"),
+			},
+			arrange: func(d *db.DB) {
+				d.RPush(now, "nums", "3", "1", "10", "2")
+			},
+			want: "-ERR syntax error\r\n",
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			d := db.New()
+			if tc.arrange != nil {
+				tc.arrange(d)
+			}
+			srv := &Server{
+				dbMap: map[int]*db.DB{0: d},
+				clock: clock.New(now),
+			}
+
+			buf := new(bytes.Buffer)
+			w := resp.NewWriter(bufio.NewWriter(buf))
+			req := newRequest(session.New(), "SORT_RO", tc.args)
+
+			if err := srv.cmdSortRO(w, req); err != nil {
+				t.Fatalf("cmdSortRO returned error: %v", err)
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("flush failed: %v", err)
+			}
+			if got := buf.String(); got != tc.want {
+				t.Fatalf("unexpected payload:\nwant %q\ngot  %q", tc.want, got)
+			}
+		})
+	}
+}
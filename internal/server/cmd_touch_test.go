@@ -0,0 +1,78 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/mickamy/minivalkey/internal/clock"
+	"github.com/mickamy/minivalkey/internal/db"
+	"github.com/mickamy/minivalkey/internal/resp"
+	"github.com/mickamy/minivalkey/internal/session"
+)
+
+func TestServer_cmdTouch(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+
+	tcs := []struct {
+		name    string
+		args    resp.Args
+		arrange func(*db.DB)
+		want    string
+	}{
+		{
+			name: "counts existing keys",
+			args: resp.Args{
+				[]byte("touch"),
+				[]byte("foo"),
+				[]byte("bar"),
+				[]byte("missing"),
+			},
+			arrange: func(db *db.DB) {
+				db.SetString("foo", "1", time.Time{})
+				db.SetString("bar", "2", time.Time{})
+			},
+			want: ":2\r\n",
+		},
+		{
+			name: "complains when no keys are provided",
+			args: resp.Args{
+				[]byte("touch"),
+			},
+			want: "-ERR wrong number of arguments for 'touch' command\r\n",
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			d := db.New()
+			if tc.arrange != nil {
+				tc.arrange(d)
+			}
+			srv := &Server{
+				dbMap: map[int]*db.DB{0: d},
+				clock: clock.New(now),
+			}
+
+			buf := new(bytes.Buffer)
+			w := resp.NewWriter(bufio.NewWriter(buf))
+			req := newRequest(session.New(), "TOUCH", tc.args)
+
+			if err := srv.cmdTouch(w, req); err != nil {
+				t.Fatalf("cmdTouch returned error: %v", err)
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("flush failed: %v", err)
+			}
+			if got := buf.String(); got != tc.want {
+				t.Fatalf("unexpected payload:\nwant %q\ngot  %q", tc.want, got)
+			}
+		})
+	}
+}
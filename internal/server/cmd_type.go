@@ -0,0 +1,24 @@
+package server
+
+import (
+	"github.com/mickamy/minivalkey/internal/resp"
+)
+
+func (s *Server) cmdType(w *resp.Writer, r *request) error {
+	if err := validateCommand(r.cmd, r.args, validateArgCountExact(2)); err != nil {
+		return w.WriteErrorAndFlush(err)
+	}
+	key := string(r.args[1])
+	typ, ok := s.db(r.session).Type(s.Now(), key)
+	if !ok {
+		if err := w.WriteString("none"); err != nil {
+			return err
+		}
+		return nil
+	}
+	if err := w.WriteString(typ.String()); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,94 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/mickamy/minivalkey/internal/clock"
+	"github.com/mickamy/minivalkey/internal/db"
+	"github.com/mickamy/minivalkey/internal/resp"
+	"github.com/mickamy/minivalkey/internal/session"
+)
+
+func TestServer_cmdType(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+
+	tcs := []struct {
+		name    string
+		args    resp.Args
+		arrange func(*db.DB)
+		want    string
+	}{
+		{
+			name: "reports string",
+			args: resp.Args{
+				[]byte("type"),
+				[]byte("foo"),
+			},
+			arrange: func(db *db.DB) {
+				db.SetString("foo", "bar", time.Time{})
+			},
+			want: "+string\r\n",
+		},
+		{
+			name: "reports none for missing key",
+			args: resp.Args{
+				[]byte("type"),
+				[]byte("missing"),
+			},
+			want: "+none\r\n",
+		},
+		{
+			name: "reports none for expired key",
+			args: resp.Args{
+				[]byte("type"),
+				[]byte("stale"),
+			},
+			arrange: func(db *db.DB) {
+				db.SetString("stale", "x", now.Add(-time.Second))
+			},
+			want: "+none\r\n",
+		},
+		{
+			name: "complains when key is missing",
+			args: resp.Args{
+				[]byte("type"),
+			},
+			want: "-ERR wrong number of arguments for 'type' command\r\n",
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			d := db.New()
+			if tc.arrange != nil {
+				tc.arrange(d)
+			}
+			srv := &Server{
+				dbMap: map[int]*db.DB{0: d},
+				clock: clock.New(now),
+			}
+
+			buf := new(bytes.Buffer)
+			w := resp.NewWriter(bufio.NewWriter(buf))
+			req := newRequest(session.New(), "TYPE", tc.args)
+
+			if err := srv.cmdType(w, req); err != nil {
+				t.Fatalf("cmdType returned error: %v", err)
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("flush failed: %v", err)
+			}
+			if got := buf.String(); got != tc.want {
+				t.Fatalf("unexpected payload:\nwant %q\ngot  %q", tc.want, got)
+			}
+		})
+	}
+}
@@ -2,12 +2,121 @@ package server
 
 import (
 	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mickamy/minivalkey/internal/db"
+	"github.com/mickamy/minivalkey/internal/resp"
 )
 
 var (
-	ErrEmptyCommand      = errors.New("ERR empty command")
-	ErrValueNotInteger   = errors.New("ERR value is not an integer or out of range")
-	ErrUnknownSection    = errors.New("ERR unknown section")
-	ErrInvalidExpireTime = errors.New("ERR invalid expire time in set")
-	ErrSyntax            = errors.New("ERR syntax error")
+	ErrEmptyCommand         = errors.New("ERR empty command")
+	ErrValueNotInteger      = errors.New("ERR value is not an integer or out of range")
+	ErrUnknownSection       = errors.New("ERR unknown section")
+	ErrInvalidExpireTime    = errors.New("ERR invalid expire time in set")
+	ErrSyntax               = errors.New("ERR syntax error")
+	ErrNoSuchKey            = errors.New("ERR no such key")
+	ErrSameObject           = errors.New("ERR source and destination objects are the same")
+	ErrDBIndexOutOfRange    = errors.New("ERR DB index is out of range")
+	ErrExpireNXCombo        = errors.New("ERR NX and XX, GT or LT options at the same time are not compatible")
+	ErrExpireGTLTCombo      = errors.New("ERR GT and LT options at the same time are not compatible")
+	ErrWrongType            = errors.New("WRONGTYPE Operation against a key holding the wrong kind of value")
+	ErrNotFloat             = errors.New("ERR value is not a valid float")
+	ErrOverflow             = errors.New("ERR increment or decrement would overflow")
+	ErrDecrOverflow         = errors.New("ERR decrement would overflow")
+	ErrNaNOrInf             = errors.New("ERR increment would produce NaN or Infinity")
+	ErrStringTooLong        = errors.New("ERR string exceeds maximum allowed size (proto-max-bulk-len)")
+	ErrOffsetOutOfRange     = errors.New("ERR offset is out of range")
+	ErrLCSNotString         = errors.New("ERR The specified keys must contain string values")
+	ErrBitOffset            = errors.New("ERR bit offset is not an integer or out of range")
+	ErrBitValue             = errors.New("ERR bit is not an integer or out of range")
+	ErrBitFieldOverflow     = errors.New("ERR Invalid OVERFLOW type specified")
+	ErrBitFieldReadOnly     = errors.New("ERR BITFIELD_RO only supports the GET subcommand")
+	ErrCorruptHLL           = errors.New("INVALIDOBJ Corrupted HLL object detected")
+	ErrGeoUnit              = errors.New("ERR unsupported unit provided. please use M, KM, FT, MI")
+	ErrGeoMember            = errors.New("ERR could not decode requested zset member")
+	ErrGeoRadius            = errors.New("ERR need numeric radius")
+	ErrGeoNegativeRadius    = errors.New("ERR radius cannot be negative")
+	ErrGeoWidth             = errors.New("ERR need numeric width")
+	ErrGeoHeight            = errors.New("ERR need numeric height")
+	ErrGeoNegativeBox       = errors.New("ERR height or width cannot be negative")
+	ErrGeoCount             = errors.New("ERR COUNT must be > 0")
+	ErrGeoAnyCount          = errors.New("ERR the ANY argument requires COUNT argument")
+	ErrFieldsMissing        = errors.New("ERR Mandatory argument FIELDS is missing or not at the right position")
+	ErrNumFields            = errors.New("ERR numfields should be greater than 0 and match the provided number of fields")
+	ErrSortScore            = errors.New("ERR One or more scores can't be converted into double")
+	ErrInvalidTTL           = errors.New("ERR Invalid TTL value, must be >= 0")
+	ErrInvalidIdleTime      = errors.New("ERR Invalid IDLETIME value, must be >= 0")
+	ErrInvalidFreq          = errors.New("ERR Invalid FREQ value, must be >= 0 and <= 255")
+	ErrDumpPayload          = errors.New("ERR DUMP payload version or checksum are wrong")
+	ErrBadDataFormat        = errors.New("ERR Bad data format")
+	ErrBgSaveInProgress     = errors.New("ERR Background save already in progress")
+	ErrAOFRewriteInProgress = errors.New("ERR Background append only file rewriting already in progress")
+	ErrPauseTimeout         = errors.New("ERR timeout is not an integer or out of range")
+	ErrCrossSlot            = errors.New("CROSSSLOT Keys in request don't hash to the same slot")
+	ErrClusterDisabled      = errors.New("ERR This instance has cluster support disabled")
+	ErrInvalidSlot          = errors.New("ERR Invalid or out of range slot")
+	ErrInvalidNumKeys       = errors.New("ERR Invalid number of keys")
+	ErrSelectInCluster      = errors.New("ERR SELECT is not allowed in cluster mode")
+	ErrCopyInCluster        = errors.New("ERR Copying to another database is not allowed in cluster mode")
+	ErrGeneric              = errors.New("ERR")
+)
+
+// Some Valkey replies end with punctuation, which error values should not carry,
+// so they are kept as plain strings and written with WriteErrorString.
+const (
+	msgLFUNotSelected = "ERR An LFU maxmemory policy is not selected, access frequency not tracked. Please note that when switching between policies at runtime LRU and LFU data will take some time to adjust."
+	msgLCSLenAndIdx   = "ERR If you want both the length and indexes, please just use IDX."
+	msgInvalidName    = "ERR Client names cannot contain spaces, newlines or special characters."
+	msgBitPosBit      = "ERR The bit argument must be 1 or 0."
+	msgBitFieldType   = "ERR Invalid bitfield type. Use something like i16 u8. Note that u64 is not supported but i64 is."
+	msgBitOpNot       = "ERR BITOP NOT must be called with a single source key."
+	msgBitOpDiff      = "ERR BITOP DIFF must be called with at least two source keys."
+	msgInvalidHLL     = "WRONGTYPE Key is not a valid HyperLogLog string value."
+	msgBusyKey        = "BUSYKEY Target key name already exists."
 )
+
+// dbError maps an error returned by the db package to the reply Valkey sends.
+func dbError(err error) error {
+	switch {
+	case errors.Is(err, db.ErrWrongType):
+		return ErrWrongType
+	case errors.Is(err, db.ErrNotInteger):
+		return ErrValueNotInteger
+	case errors.Is(err, db.ErrNotFloat):
+		return ErrNotFloat
+	case errors.Is(err, db.ErrOverflow):
+		return ErrOverflow
+	case errors.Is(err, db.ErrNaNOrInf):
+		return ErrNaNOrInf
+	case errors.Is(err, db.ErrStringTooLong):
+		return ErrStringTooLong
+	case errors.Is(err, db.ErrCorruptHLL):
+		return ErrCorruptHLL
+	default:
+		return err
+	}
+}
+
+// writeHLLError writes the reply for an error returned by the HyperLogLog db methods.
+func writeHLLError(w *resp.Writer, err error) error {
+	if errors.Is(err, db.ErrInvalidHLL) {
+		return w.WriteErrorString(msgInvalidHLL)
+	}
+	return w.WriteErrorAndFlush(dbError(err))
+}
+
+// unknownSubcommandMsg formats Valkey's reply for an unsupported subcommand.
+func unknownSubcommandMsg(cmd resp.Command, sub resp.Arg) string {
+	return fmt.Sprintf("ERR unknown subcommand '%s'. Try %s HELP.", string(sub), cmd.String())
+}
+
+// invalidExpireTimeError formats Valkey's reply for an out of range expiration.
+func invalidExpireTimeError(cmd resp.Command) error {
+	return fmt.Errorf("ERR invalid expire time in '%s' command", strings.ToLower(cmd.String()))
+}
+
+// invalidLongLatError formats Valkey's reply for coordinates outside the indexable area.
+func invalidLongLatError(long, lat float64) error {
+	return fmt.Errorf("ERR invalid longitude,latitude pair %f,%f", long, lat)
+}
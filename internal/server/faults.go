@@ -0,0 +1,43 @@
+package server
+
+import (
+	"errors"
+
+	"github.com/mickamy/minivalkey/internal/resp"
+)
+
+// FaultRule makes matching commands fail with Reply instead of running.
+type FaultRule struct {
+	CommandRule
+	Reply string // error reply, e.g. "LOADING Valkey is loading the dataset in memory"
+}
+
+// AddFault registers rule and returns an ID to remove it with.
+func (s *Server) AddFault(rule FaultRule) int {
+	return s.faults.add(rule.CommandRule, rule.Reply)
+}
+
+// RemoveFault unregisters the fault with the given ID, if it is still registered.
+func (s *Server) RemoveFault(id int) {
+	s.faults.remove(id)
+}
+
+// ClearFaults unregisters every fault.
+func (s *Server) ClearFaults() {
+	s.faults.clear()
+}
+
+// injectFault returns a handler replying with the error of the first fault rule
+// r trips, or handle itself when none does.
+func (s *Server) injectFault(handle handleFunc, r *request) handleFunc {
+	if s.faults.empty() {
+		return handle
+	}
+	reply, ok := s.faults.match(r, commandKeys(r.args), &s.random)
+	if !ok {
+		return handle
+	}
+	return func(w *resp.Writer, r *request) error {
+		return w.WriteErrorAndFlush(errors.New(reply))
+	}
+}
@@ -0,0 +1,126 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"slices"
+	"testing"
+
+	"github.com/mickamy/minivalkey/internal/resp"
+	"github.com/mickamy/minivalkey/internal/session"
+)
+
+func TestFaults_Match(t *testing.T) {
+	t.Parallel()
+
+	tcs := []struct {
+		name string
+		rule FaultRule
+		db   int
+		cl   string
+		args []string
+		want bool
+	}{
+		{name: "any command", rule: FaultRule{CommandRule: CommandRule{DB: -1}}, args: []string{"PING"}, want: true},
+		{name: "command matches", rule: FaultRule{CommandRule: CommandRule{Commands: []string{"GET", "SET"}, DB: -1}}, args: []string{"set", "k", "v"}, want: true},
+		{name: "command differs", rule: FaultRule{CommandRule: CommandRule{Commands: []string{"GET"}, DB: -1}}, args: []string{"SET", "k", "v"}, want: false},
+		{name: "key matches", rule: FaultRule{CommandRule: CommandRule{KeyPattern: "user:*", DB: -1}}, args: []string{"DEL", "a", "user:1"}, want: true},
+		{name: "key differs", rule: FaultRule{CommandRule: CommandRule{KeyPattern: "user:*", DB: -1}}, args: []string{"GET", "session:1"}, want: false},
+		{name: "keyless command", rule: FaultRule{CommandRule: CommandRule{KeyPattern: "*", DB: -1}}, args: []string{"PING"}, want: false},
+		{name: "db matches", rule: FaultRule{CommandRule: CommandRule{DB: 2}}, db: 2, args: []string{"PING"}, want: true},
+		{name: "db differs", rule: FaultRule{CommandRule: CommandRule{DB: 2}}, args: []string{"PING"}, want: false},
+		{name: "client matches", rule: FaultRule{CommandRule: CommandRule{ClientName: "worker", DB: -1}}, cl: "worker", args: []string{"PING"}, want: true},
+		{name: "client differs", rule: FaultRule{CommandRule: CommandRule{ClientName: "worker", DB: -1}}, cl: "web", args: []string{"PING"}, want: false},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := &Server{}
+			tc.rule.Reply = "LOADING Valkey is loading the dataset in memory"
+			srv.AddFault(tc.rule)
+
+			sess := session.New()
+			sess.SelectedDB, sess.Name = tc.db, tc.cl
+			args := testArgs(tc.args...)
+			reply, ok := srv.faults.match(newRequest(sess, args.Cmd(), args), commandKeys(args), &srv.random)
+			if ok != tc.want {
+				t.Fatalf("expected match=%v, got %v", tc.want, ok)
+			}
+			if ok && reply != tc.rule.Reply {
+				t.Fatalf("expected reply %q, got %q", tc.rule.Reply, reply)
+			}
+		})
+	}
+}
+
+func TestFaults_Times(t *testing.T) {
+	t.Parallel()
+
+	srv := &Server{}
+	srv.AddFault(FaultRule{CommandRule: CommandRule{DB: -1, Times: 2}, Reply: "ERR first"})
+	id := srv.AddFault(FaultRule{CommandRule: CommandRule{DB: -1}, Reply: "ERR second"})
+
+	var got []string
+	for range 4 {
+		got = append(got, runFaulted(t, srv, "PING"))
+	}
+	srv.RemoveFault(id)
+	got = append(got, runFaulted(t, srv, "PING"))
+
+	want := []string{"-ERR first\r\n", "-ERR first\r\n", "-ERR second\r\n", "-ERR second\r\n", "+PONG\r\n"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("expected replies %q, got %q", want, got)
+	}
+}
+
+func TestFaults_Probability(t *testing.T) {
+	t.Parallel()
+
+	run := func() []bool {
+		srv := &Server{}
+		srv.SeedRandom(42)
+		p := 0.5
+		srv.AddFault(FaultRule{CommandRule: CommandRule{DB: -1, Probability: &p}, Reply: "ERR flaky"})
+		var fired []bool
+		for range 32 {
+			fired = append(fired, runFaulted(t, srv, "PING") == "-ERR flaky\r\n")
+		}
+		return fired
+	}
+
+	first, second := run(), run()
+	if !slices.Equal(first, second) {
+		t.Fatalf("expected the same seed to fail the same commands:\n%v\n%v", first, second)
+	}
+	if !slices.Contains(first, true) || !slices.Contains(first, false) {
+		t.Fatalf("expected some commands to fail and some to pass, got %v", first)
+	}
+}
+
+// runFaulted runs args through fault injection and the real handler, returning the reply.
+func runFaulted(t *testing.T, srv *Server, args ...string) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+	w := resp.NewWriter(bufio.NewWriter(&buf))
+	in := testArgs(args...)
+	req := newRequest(session.New(), in.Cmd(), in)
+	if err := srv.injectFault(srv.cmdPing, req)(w, req); err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+	return buf.String()
+}
+
+func testArgs(args ...string) resp.Args {
+	out := make(resp.Args, len(args))
+	for i, a := range args {
+		out[i] = resp.Arg(a)
+	}
+	return out
+}
@@ -0,0 +1,25 @@
+package server
+
+import (
+	"io"
+
+	"github.com/mickamy/minivalkey/internal/fixture"
+)
+
+// LoadFixture replaces the whole dataset with the fixture read from r.
+// TTLs in the fixture count from the current simulated time.
+func (s *Server) LoadFixture(r io.Reader) error {
+	now := s.Now()
+	dbs, err := fixture.Read(r, now)
+	if err != nil {
+		return err
+	}
+	return s.loadDatabases(now, dbs)
+}
+
+// ExportFixture writes every database to w as a fixture, keys sorted and
+// TTLs relative to the current simulated time.
+func (s *Server) ExportFixture(w io.Writer) error {
+	now := s.Now()
+	return fixture.Write(w, s.rdbDatabases(now), now)
+}
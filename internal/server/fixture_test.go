@@ -0,0 +1,129 @@
+package server
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mickamy/minivalkey/internal/clock"
+	"github.com/mickamy/minivalkey/internal/db"
+)
+
+func TestServer_LoadFixture(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+
+	srv := &Server{dbMap: map[int]*db.DB{0: db.New()}, clock: clock.New(now)}
+	srv.dbAt(0).SetString("stale", "v", time.Time{})
+
+	in := `{
+  "0": {"s": {"string": "v", "ttl": "1m"}},
+  "2": {"h": {"hash": {"f": "1"}, "field_ttl": {"f": "10s"}}}
+}
+`
+	if err := srv.LoadFixture(strings.NewReader(in)); err != nil {
+		t.Fatalf("LoadFixture returned error: %v", err)
+	}
+
+	if srv.dbAt(0).Exists(now, "stale") != 0 {
+		t.Fatalf("expected stale key to be replaced")
+	}
+	if ttl := srv.dbAt(0).TTL(now, "s"); ttl != 60 {
+		t.Fatalf("expected ttl 60, got %d", ttl)
+	}
+	ttls, err := srv.dbAt(2).HPTTL(now, "h", "f")
+	if err != nil || len(ttls) != 1 || ttls[0] != 10_000 {
+		t.Fatalf("expected field ttl 10000, got %v (err %v)", ttls, err)
+	}
+
+	var out bytes.Buffer
+	if err := srv.ExportFixture(&out); err != nil {
+		t.Fatalf("ExportFixture returned error: %v", err)
+	}
+	want := `{
+  "0": {
+    "s": {
+      "string": "v",
+      "ttl": "1m0s"
+    }
+  },
+  "2": {
+    "h": {
+      "hash": {
+        "f": "1"
+      },
+      "field_ttl": {
+        "f": "10s"
+      }
+    }
+  }
+}
+`
+	if got := out.String(); got != want {
+		t.Fatalf("unexpected export:\nwant %s\ngot  %s", want, got)
+	}
+}
+
+func TestServer_LoadFixture_OutOfRange(t *testing.T) {
+	t.Parallel()
+
+	srv := &Server{dbMap: map[int]*db.DB{}, clock: clock.New(time.Unix(1_000, 0))}
+	if err := srv.LoadFixture(strings.NewReader(`{"16": {"k": {"string": "v"}}}`)); err == nil {
+		t.Fatalf("expected an error for database 16")
+	}
+}
+
+func TestServer_ExportFixture_Binary(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+
+	src := &Server{dbMap: map[int]*db.DB{0: db.New()}, clock: clock.New(now)}
+	if _, err := src.dbAt(0).PFAdd(now, "hll", "a", "b", "c"); err != nil {
+		t.Fatalf("PFAdd returned error: %v", err)
+	}
+	if _, err := src.dbAt(0).SetBit(now, "bits", 7, true); err != nil {
+		t.Fatalf("SetBit returned error: %v", err)
+	}
+	if _, err := src.dbAt(0).SetBit(now, "bits", 8, true); err != nil {
+		t.Fatalf("SetBit returned error: %v", err)
+	}
+	if _, err := src.dbAt(0).HSet(now, "h", "f\xff", "v\xfe", "plain", "v"); err != nil {
+		t.Fatalf("HSet returned error: %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := src.ExportFixture(&out); err != nil {
+		t.Fatalf("ExportFixture returned error: %v", err)
+	}
+	if !strings.Contains(out.String(), `"base64": true`) {
+		t.Fatalf("expected binary keys to be base64 encoded:\n%s", out.String())
+	}
+
+	dst := &Server{dbMap: map[int]*db.DB{0: db.New()}, clock: clock.New(now)}
+	if err := dst.LoadFixture(&out); err != nil {
+		t.Fatalf("LoadFixture returned error: %v", err)
+	}
+	if got, want := dst.dbAt(0).Entries(now), src.dbAt(0).Entries(now); !reflect.DeepEqual(got, want) {
+		t.Fatalf("round trip changed the data:\nwant %+v\ngot  %+v", want, got)
+	}
+	if n, err := dst.dbAt(0).PFCount(now, "hll"); err != nil || n != 3 {
+		t.Fatalf("expected PFCOUNT 3, got %d (err %v)", n, err)
+	}
+}
+
+func TestServer_ExportFixture_BinaryKeyName(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+
+	srv := &Server{dbMap: map[int]*db.DB{0: db.New()}, clock: clock.New(now)}
+	srv.dbAt(0).SetString("k\xff", "v", time.Time{})
+	if err := srv.ExportFixture(io.Discard); err == nil {
+		t.Fatalf("expected an error for a key name that is not valid UTF-8")
+	}
+}
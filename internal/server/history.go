@@ -0,0 +1,85 @@
+package server
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/mickamy/minivalkey/internal/resp"
+)
+
+// HistoryEntry is a command the server executed.
+type HistoryEntry struct {
+	Time     time.Time // simulated time the command ran at
+	ClientID int64
+	DB       int // database selected when the command ran
+	Name     string
+	Args     []string // arguments after the command name
+	Keys     []string
+	Err      string // error reply, empty on success
+}
+
+// history records executed commands, keeping the last limit of them when limit is positive.
+type history struct {
+	disabled bool
+	limit    int
+
+	mu      sync.Mutex
+	entries []HistoryEntry
+	start   int // index of the oldest entry once the ring is full
+}
+
+func newHistory(limit int) *history {
+	return &history{disabled: limit < 0, limit: max(limit, 0)}
+}
+
+func (h *history) add(e HistoryEntry) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.limit > 0 && len(h.entries) == h.limit {
+		h.entries[h.start] = e
+		h.start = (h.start + 1) % h.limit
+		return
+	}
+	h.entries = append(h.entries, e)
+}
+
+// recordCommand records the command in args as run by the session with the given
+// client ID on database dbIdx at now, failing with errMsg when it is not empty.
+func (h *history) recordCommand(now time.Time, clientID int64, dbIdx int, args resp.Args, errMsg string) {
+	if h.disabled {
+		return
+	}
+	strArgs := make([]string, len(args)-1)
+	for i, a := range args[1:] {
+		strArgs[i] = string(a)
+	}
+	h.add(HistoryEntry{
+		Time:     now,
+		ClientID: clientID,
+		DB:       dbIdx,
+		Name:     args.Cmd().String(),
+		Args:     strArgs,
+		Keys:     commandKeys(args),
+		Err:      errMsg,
+	})
+}
+
+// History returns the recorded commands, oldest first.
+func (s *Server) History() []HistoryEntry {
+	h := s.history
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return slices.Concat(h.entries[h.start:], h.entries[:h.start])
+}
+
+// ResetHistory forgets every recorded command.
+func (s *Server) ResetHistory() {
+	h := s.history
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.entries, h.start = nil, 0
+}
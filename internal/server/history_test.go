@@ -0,0 +1,115 @@
+package server
+
+import (
+	"bufio"
+	"log/slog"
+	"net"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/mickamy/minivalkey/internal/resp"
+)
+
+func TestServer_History(t *testing.T) {
+	t.Parallel()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen failed: %v", err)
+	}
+	srv, err := New(ln, Options{Logger: slog.New(slog.DiscardHandler)})
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+	go srv.Serve()
+	t.Cleanup(func() {
+		_ = srv.Close()
+	})
+
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+	r := bufio.NewReader(conn)
+	for _, cmd := range []string{
+		"*2\r\n$6\r\nSELECT\r\n$1\r\n3\r\n",
+		"*5\r\n$3\r\nset\r\n$1\r\nk\r\n$1\r\nv\r\n$2\r\nEX\r\n$3\r\n300\r\n",
+		"*2\r\n$4\r\nINCR\r\n$1\r\nk\r\n",
+		"*1\r\n$4\r\nNOPE\r\n",
+	} {
+		if _, err := conn.Write([]byte(cmd)); err != nil {
+			t.Fatalf("write failed: %v", err)
+		}
+		if _, err := r.ReadString('\n'); err != nil {
+			t.Fatalf("read failed: %v", err)
+		}
+	}
+
+	got := srv.History()
+	if len(got) != 4 {
+		t.Fatalf("expected 4 commands, got %+v", got)
+	}
+	if e := got[0]; e.Name != "SELECT" || e.DB != 0 || e.Err != "" {
+		t.Fatalf("unexpected SELECT entry %+v", e)
+	}
+	set := got[1]
+	if set.Name != "SET" || set.DB != 3 || set.ClientID != got[0].ClientID || set.Err != "" ||
+		!slices.Equal(set.Args, []string{"k", "v", "EX", "300"}) || !slices.Equal(set.Keys, []string{"k"}) {
+		t.Fatalf("unexpected SET entry %+v", set)
+	}
+	if set.Time.IsZero() || set.Time.After(srv.Now()) {
+		t.Fatalf("expected SET to run by %v, got %v", srv.Now(), set.Time)
+	}
+	if e := got[2]; e.Name != "INCR" || e.Err != ErrValueNotInteger.Error() {
+		t.Fatalf("unexpected INCR entry %+v", e)
+	}
+	if e := got[3]; e.Name != "NOPE" || e.Err == "" {
+		t.Fatalf("unexpected NOPE entry %+v", e)
+	}
+
+	srv.ResetHistory()
+	if got := srv.History(); len(got) != 0 {
+		t.Fatalf("expected an empty history after reset, got %+v", got)
+	}
+}
+
+func TestHistory_Limit(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+
+	tcs := []struct {
+		name  string
+		limit int
+		want  []string
+	}{
+		{name: "unlimited", limit: 0, want: []string{"0", "1", "2", "3", "4"}},
+		{name: "ring", limit: 3, want: []string{"2", "3", "4"}},
+		{name: "disabled", limit: -1, want: nil},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := &Server{history: newHistory(tc.limit)}
+			for i := range 5 {
+				args := resp.Args{[]byte("GET"), []byte(strconv.Itoa(i))}
+				srv.history.recordCommand(now, 1, 0, args, "")
+			}
+			var got []string
+			for _, e := range srv.History() {
+				got = append(got, e.Args[0])
+			}
+			if !slices.Equal(got, tc.want) {
+				t.Fatalf("expected %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
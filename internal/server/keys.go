@@ -0,0 +1,159 @@
+package server
+
+import (
+	"strings"
+
+	"github.com/mickamy/minivalkey/internal/resp"
+)
+
+// keySpec locates the key arguments of a command, like the first key, last key
+// and step of Valkey's COMMAND INFO. A negative last counts from the end.
+type keySpec struct {
+	first, last, step int
+}
+
+// keySpecs lists the commands that take keys. Commands missing from it take none.
+var keySpecs = map[string]keySpec{
+	"APPEND":               {1, 1, 1},
+	"BITCOUNT":             {1, 1, 1},
+	"BITFIELD":             {1, 1, 1},
+	"BITFIELD_RO":          {1, 1, 1},
+	"BITOP":                {2, -1, 1},
+	"BITPOS":               {1, 1, 1},
+	"COPY":                 {1, 2, 1},
+	"DECR":                 {1, 1, 1},
+	"DECRBY":               {1, 1, 1},
+	"DEL":                  {1, -1, 1},
+	"DUMP":                 {1, 1, 1},
+	"EXISTS":               {1, -1, 1},
+	"EXPIRE":               {1, 1, 1},
+	"EXPIREAT":             {1, 1, 1},
+	"EXPIRETIME":           {1, 1, 1},
+	"GEOADD":               {1, 1, 1},
+	"GEODIST":              {1, 1, 1},
+	"GEOHASH":              {1, 1, 1},
+	"GEOPOS":               {1, 1, 1},
+	"GEORADIUS":            {1, 1, 1},
+	"GEORADIUSBYMEMBER":    {1, 1, 1},
+	"GEORADIUSBYMEMBER_RO": {1, 1, 1},
+	"GEORADIUS_RO":         {1, 1, 1},
+	"GEOSEARCH":            {1, 1, 1},
+	"GEOSEARCHSTORE":       {1, 2, 1},
+	"GET":                  {1, 1, 1},
+	"GETBIT":               {1, 1, 1},
+	"GETDEL":               {1, 1, 1},
+	"GETEX":                {1, 1, 1},
+	"GETRANGE":             {1, 1, 1},
+	"GETSET":               {1, 1, 1},
+	"HEXPIRE":              {1, 1, 1},
+	"HEXPIREAT":            {1, 1, 1},
+	"HEXPIRETIME":          {1, 1, 1},
+	"HGETEX":               {1, 1, 1},
+	"HPERSIST":             {1, 1, 1},
+	"HPEXPIRE":             {1, 1, 1},
+	"HPEXPIREAT":           {1, 1, 1},
+	"HPEXPIRETIME":         {1, 1, 1},
+	"HPTTL":                {1, 1, 1},
+	"HSETEX":               {1, 1, 1},
+	"HTTL":                 {1, 1, 1},
+	"INCR":                 {1, 1, 1},
+	"INCRBY":               {1, 1, 1},
+	"INCRBYFLOAT":          {1, 1, 1},
+	"LCS":                  {1, 2, 1},
+	"MGET":                 {1, -1, 1},
+	"MSET":                 {1, -1, 2},
+	"MSETNX":               {1, -1, 2},
+	"OBJECT":               {2, 2, 1},
+	"PERSIST":              {1, 1, 1},
+	"PEXPIRE":              {1, 1, 1},
+	"PEXPIREAT":            {1, 1, 1},
+	"PEXPIRETIME":          {1, 1, 1},
+	"PFADD":                {1, 1, 1},
+	"PFCOUNT":              {1, -1, 1},
+	"PFMERGE":              {1, -1, 1},
+	"PSETEX":               {1, 1, 1},
+	"PTTL":                 {1, 1, 1},
+	"RENAME":               {1, 2, 1},
+	"RENAMENX":             {1, 2, 1},
+	"RESTORE":              {1, 1, 1},
+	"SET":                  {1, 1, 1},
+	"SETBIT":               {1, 1, 1},
+	"SETEX":                {1, 1, 1},
+	"SETNX":                {1, 1, 1},
+	"SETRANGE":             {1, 1, 1},
+	"SORT":                 {1, 1, 1},
+	"SORT_RO":              {1, 1, 1},
+	"STRLEN":               {1, 1, 1},
+	"TOUCH":                {1, -1, 1},
+	"TTL":                  {1, 1, 1},
+	"TYPE":                 {1, 1, 1},
+	"UNLINK":               {1, -1, 1},
+}
+
+// storeKeys finds the destination key of the commands that name it in an
+// option, past the keys their keySpec covers, like Valkey's getkeys procs.
+var storeKeys = map[string]func(args resp.Args) []string{
+	"GEORADIUS":         storeKey(5, "STORE", "STOREDIST"),
+	"GEORADIUSBYMEMBER": storeKey(4, "STORE", "STOREDIST"),
+	"SORT":              sortStoreKey,
+}
+
+// commandKeys returns the keys args refers to, args[0] being the command name.
+func commandKeys(args resp.Args) []string {
+	name := args.Cmd().String()
+	spec, ok := keySpecs[name]
+	if !ok {
+		return nil
+	}
+	last := spec.last
+	if last < 0 {
+		last += len(args)
+	}
+	var keys []string
+	for i := spec.first; i <= last && i < len(args); i += spec.step {
+		keys = append(keys, string(args[i]))
+	}
+	if store, ok := storeKeys[name]; ok {
+		keys = append(keys, store(args)...)
+	}
+	return keys
+}
+
+// storeKey returns a getKeys function finding the destination key that
+// follows one of the options in names, looking from args[from] on.
+// The last such option wins, as it does when the command runs.
+func storeKey(from int, names ...string) func(args resp.Args) []string {
+	return func(args resp.Args) []string {
+		var key []string
+		for i := from; i+1 < len(args); i++ {
+			for _, name := range names {
+				if strings.EqualFold(string(args[i]), name) {
+					key = []string{string(args[i+1])}
+					i++
+					break
+				}
+			}
+		}
+		return key
+	}
+}
+
+// sortStoreKey finds the STORE destination of SORT. The arguments of LIMIT,
+// BY and GET are skipped so a pattern named "store" is not taken for the option.
+func sortStoreKey(args resp.Args) []string {
+	var key []string
+	for i := 2; i < len(args); i++ {
+		switch strings.ToUpper(string(args[i])) {
+		case "LIMIT":
+			i += 2
+		case "BY", "GET":
+			i++
+		case "STORE":
+			if i+1 < len(args) {
+				key = []string{string(args[i+1])}
+				i++
+			}
+		}
+	}
+	return key
+}
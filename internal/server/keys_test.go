@@ -0,0 +1,40 @@
+package server
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestCommandKeys(t *testing.T) {
+	t.Parallel()
+
+	tcs := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{name: "single key", args: []string{"set", "k", "v", "EX", "10"}, want: []string{"k"}},
+		{name: "every argument", args: []string{"DEL", "a", "b", "c"}, want: []string{"a", "b", "c"}},
+		{name: "key value pairs", args: []string{"MSET", "a", "1", "b", "2"}, want: []string{"a", "b"}},
+		{name: "source and destination", args: []string{"COPY", "src", "dst", "REPLACE"}, want: []string{"src", "dst"}},
+		{name: "after subcommand", args: []string{"BITOP", "AND", "dst", "a", "b"}, want: []string{"dst", "a", "b"}},
+		{name: "sort destination", args: []string{"SORT", "src", "BY", "store", "LIMIT", "0", "1", "store", "dst"}, want: []string{"src", "dst"}},
+		{name: "sort without destination", args: []string{"SORT", "src", "GET", "store"}, want: []string{"src"}},
+		{name: "geo destination", args: []string{"GEORADIUS", "src", "0", "0", "1", "km", "STOREDIST", "dst"}, want: []string{"src", "dst"}},
+		{name: "geo by member destination", args: []string{"GEORADIUSBYMEMBER", "src", "m", "1", "km", "STORE", "dst"}, want: []string{"src", "dst"}},
+		{name: "missing key", args: []string{"GET"}, want: nil},
+		{name: "no keys", args: []string{"PING"}, want: nil},
+		{name: "unknown command", args: []string{"NOPE", "k"}, want: nil},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := commandKeys(testArgs(tc.args...)); !slices.Equal(got, tc.want) {
+				t.Fatalf("expected keys %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package server
+
+import (
+	"time"
+
+	"github.com/mickamy/minivalkey/internal/resp"
+)
+
+// LatencyRule stalls the server before matching commands run, in wall-clock time.
+// The delay is DelayFunc() when it is set, and otherwise Delay plus a random
+// extra of up to Jitter.
+type LatencyRule struct {
+	CommandRule
+	Delay     time.Duration
+	Jitter    time.Duration
+	DelayFunc func() time.Duration
+}
+
+// AddLatency registers rule and returns an ID to remove it with.
+func (s *Server) AddLatency(rule LatencyRule) int {
+	return s.latencies.add(rule.CommandRule, rule)
+}
+
+// RemoveLatency unregisters the latency rule with the given ID, if it is still registered.
+func (s *Server) RemoveLatency(id int) {
+	s.latencies.remove(id)
+}
+
+// ClearLatencies unregisters every latency rule.
+func (s *Server) ClearLatencies() {
+	s.latencies.clear()
+}
+
+// latencyFor returns how long to stall r, per the first latency rule it matches.
+func (s *Server) latencyFor(r *request) time.Duration {
+	if s.latencies.empty() {
+		return 0
+	}
+	rule, ok := s.latencies.match(r, commandKeys(r.args), &s.random)
+	if !ok {
+		return 0
+	}
+	if rule.DelayFunc != nil {
+		return rule.DelayFunc()
+	}
+	d := rule.Delay
+	if rule.Jitter > 0 {
+		d += time.Duration(s.random.Float64() * float64(rule.Jitter))
+	}
+	return d
+}
+
+// executeStalled runs r, stalling the whole server for d first.
+// Commands run holding stallMu for reading, and a stalled command holds it
+// for writing until it has run, so commands from other connections wait for
+// it as they would on a busy server. Since each connection runs its commands
+// in order, pipelined commands queue up behind the stalled one.
+// The reply is buffered and only written to w once stallMu is released, so
+// a client that stops reading cannot hold up the other connections.
+func (s *Server) executeStalled(d time.Duration, handle handleFunc, w *resp.Writer, r *request) error {
+	buf := resp.NewBuffer()
+	if err := s.executeLocked(d, handle, buf.Writer, r); err != nil {
+		return err
+	}
+	return buf.CopyTo(w)
+}
+
+// executeLocked runs r holding stallMu, for writing after stalling for d when d is positive.
+func (s *Server) executeLocked(d time.Duration, handle handleFunc, w *resp.Writer, r *request) error {
+	if d <= 0 {
+		s.stallMu.RLock()
+		defer s.stallMu.RUnlock()
+		return s.execute(handle, w, r)
+	}
+	s.stallMu.Lock()
+	defer s.stallMu.Unlock()
+	s.stall(d)
+	return s.execute(handle, w, r)
+}
+
+// stall sleeps for d, or until the server stops serving.
+func (s *Server) stall(d time.Duration) {
+	if d <= 0 {
+		return
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+	case <-s.Done():
+	}
+}
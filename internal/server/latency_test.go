@@ -0,0 +1,235 @@
+package server
+
+import (
+	"bufio"
+	"log/slog"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mickamy/minivalkey/internal/session"
+)
+
+func TestServer_latencyFor(t *testing.T) {
+	t.Parallel()
+
+	tcs := []struct {
+		name   string
+		rule   LatencyRule
+		args   []string
+		lo, hi time.Duration
+	}{
+		{
+			name: "fixed delay",
+			rule: LatencyRule{CommandRule: CommandRule{DB: -1}, Delay: time.Second},
+			args: []string{"GET", "k"},
+			lo:   time.Second, hi: time.Second,
+		},
+		{
+			name: "jitter stays in range",
+			rule: LatencyRule{CommandRule: CommandRule{DB: -1}, Delay: time.Second, Jitter: time.Second},
+			args: []string{"GET", "k"},
+			lo:   time.Second, hi: 2 * time.Second,
+		},
+		{
+			name: "delay func",
+			rule: LatencyRule{CommandRule: CommandRule{DB: -1}, DelayFunc: func() time.Duration { return time.Minute }},
+			args: []string{"GET", "k"},
+			lo:   time.Minute, hi: time.Minute,
+		},
+		{
+			name: "other commands run at once",
+			rule: LatencyRule{CommandRule: CommandRule{Commands: []string{"SET"}, DB: -1}, Delay: time.Second},
+			args: []string{"GET", "k"},
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := &Server{}
+			srv.SeedRandom(7)
+			srv.AddLatency(tc.rule)
+			args := testArgs(tc.args...)
+			for range 10 {
+				if d := srv.latencyFor(newRequest(session.New(), args.Cmd(), args)); d < tc.lo || d > tc.hi {
+					t.Fatalf("expected a delay in [%v, %v], got %v", tc.lo, tc.hi, d)
+				}
+			}
+		})
+	}
+}
+
+func TestServer_LatencyKeepsPipelineOrder(t *testing.T) {
+	t.Parallel()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen failed: %v", err)
+	}
+	srv, err := New(ln, Options{Logger: slog.New(slog.DiscardHandler)})
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+	go srv.Serve()
+	t.Cleanup(func() {
+		_ = srv.Close()
+	})
+
+	const delay = 50 * time.Millisecond
+	srv.AddLatency(LatencyRule{CommandRule: CommandRule{Commands: []string{"GET"}, DB: -1}, Delay: delay})
+
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+
+	start := time.Now()
+	pipeline := "*3\r\n$3\r\nSET\r\n$1\r\nk\r\n$1\r\n1\r\n" +
+		"*2\r\n$3\r\nGET\r\n$1\r\nk\r\n" +
+		"*2\r\n$4\r\nINCR\r\n$1\r\nk\r\n"
+	if _, err := conn.Write([]byte(pipeline)); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	r := bufio.NewReader(conn)
+	var got string
+	for range 4 {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("read failed: %v", err)
+		}
+		got += line
+	}
+
+	if want := "+OK\r\n$1\r\n1\r\n:2\r\n"; got != want {
+		t.Fatalf("unexpected replies:\nwant %q\ngot  %q", want, got)
+	}
+	if elapsed := time.Since(start); elapsed < delay {
+		t.Fatalf("expected the pipeline to take at least %v, took %v", delay, elapsed)
+	}
+}
+
+func TestServer_LatencyStallsOtherConnections(t *testing.T) {
+	t.Parallel()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen failed: %v", err)
+	}
+	srv, err := New(ln, Options{Logger: slog.New(slog.DiscardHandler)})
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+	go srv.Serve()
+	t.Cleanup(func() {
+		_ = srv.Close()
+	})
+
+	const delay = 100 * time.Millisecond
+	stalled := make(chan struct{})
+	srv.AddLatency(LatencyRule{
+		CommandRule: CommandRule{Commands: []string{"GET"}, DB: -1, Times: 1},
+		DelayFunc: func() time.Duration {
+			close(stalled)
+			return delay
+		},
+	})
+
+	dial := func() (net.Conn, *bufio.Reader) {
+		conn, err := net.Dial("tcp", ln.Addr().String())
+		if err != nil {
+			t.Fatalf("dial failed: %v", err)
+		}
+		t.Cleanup(func() {
+			_ = conn.Close()
+		})
+		return conn, bufio.NewReader(conn)
+	}
+	slow, slowR := dial()
+	fast, fastR := dial()
+
+	start := time.Now()
+	if _, err := slow.Write([]byte("*2\r\n$3\r\nGET\r\n$1\r\nk\r\n")); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	<-stalled
+	// Give the stalled command time to hold the server before the write comes in.
+	time.Sleep(10 * time.Millisecond)
+	if _, err := fast.Write([]byte("*3\r\n$3\r\nSET\r\n$1\r\nk\r\n$1\r\n1\r\n")); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	if line, err := fastR.ReadString('\n'); err != nil || line != "+OK\r\n" {
+		t.Fatalf("expected +OK, got %q (%v)", line, err)
+	}
+	if elapsed := time.Since(start); elapsed < delay {
+		t.Fatalf("expected the other connection to wait at least %v, took %v", delay, elapsed)
+	}
+	// The stalled GET ran before the SET that arrived during its delay.
+	if line, err := slowR.ReadString('\n'); err != nil || line != "$-1\r\n" {
+		t.Fatalf("expected a nil reply, got %q (%v)", line, err)
+	}
+}
+
+func TestServer_LatencyReplyToStalledReader(t *testing.T) {
+	t.Parallel()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen failed: %v", err)
+	}
+	srv, err := New(ln, Options{Logger: slog.New(slog.DiscardHandler)})
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+	go srv.Serve()
+	t.Cleanup(func() {
+		_ = srv.Close()
+	})
+
+	// The reply is far larger than the socket buffers, so writing it blocks
+	// until the client reads.
+	d, _ := srv.DB(0)
+	d.SetString("big", strings.Repeat("x", 64<<20), time.Time{})
+
+	ran := make(chan struct{})
+	srv.AddLatency(LatencyRule{
+		CommandRule: CommandRule{Commands: []string{"GET"}, DB: -1, Times: 1},
+		DelayFunc: func() time.Duration {
+			close(ran)
+			return time.Millisecond
+		},
+	})
+
+	dial := func() net.Conn {
+		conn, err := net.Dial("tcp", ln.Addr().String())
+		if err != nil {
+			t.Fatalf("dial failed: %v", err)
+		}
+		t.Cleanup(func() {
+			_ = conn.Close()
+		})
+		return conn
+	}
+	// The stuck client never reads its reply.
+	stuck := dial()
+	if _, err := stuck.Write([]byte("*2\r\n$3\r\nGET\r\n$3\r\nbig\r\n")); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	<-ran
+	time.Sleep(50 * time.Millisecond)
+
+	other := dial()
+	_ = other.SetDeadline(time.Now().Add(2 * time.Second))
+	if _, err := other.Write([]byte("*1\r\n$4\r\nPING\r\n")); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	if line, err := bufio.NewReader(other).ReadString('\n'); err != nil || line != "+PONG\r\n" {
+		t.Fatalf("expected +PONG while the stalled reply is stuck, got %q (%v)", line, err)
+	}
+}
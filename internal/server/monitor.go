@@ -0,0 +1,161 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mickamy/minivalkey/internal/resp"
+)
+
+// monitorBacklog is how many lines a MONITOR client may fall behind before it
+// is disconnected, like Valkey's output buffer limit.
+const monitorBacklog = 1024
+
+// monitors streams the commands the server processes to MONITOR clients.
+type monitors struct {
+	mu   sync.Mutex
+	byID map[int64]chan string
+}
+
+func (m *monitors) add(id int64) <-chan string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.byID == nil {
+		m.byID = make(map[int64]chan string)
+	}
+	ch := make(chan string, monitorBacklog)
+	m.byID[id] = ch
+	return ch
+}
+
+func (m *monitors) remove(id int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if ch, ok := m.byID[id]; ok {
+		close(ch)
+		delete(m.byID, id)
+	}
+}
+
+// feed sends the command in args, run from addr on database dbIdx at now, to every monitor.
+// Monitors that fall too far behind are dropped, which closes their channel.
+func (m *monitors) feed(now time.Time, dbIdx int, addr string, args resp.Args) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(m.byID) == 0 {
+		return
+	}
+	line := monitorLine(now, dbIdx, addr, args)
+	for id, ch := range m.byID {
+		select {
+		case ch <- line:
+		default:
+			close(ch)
+			delete(m.byID, id)
+		}
+	}
+}
+
+// monitorLine formats a command the way Valkey's MONITOR does:
+//
+//	1339518083.107412 [0 127.0.0.1:60866] "SET" "k" "v"
+func monitorLine(now time.Time, dbIdx int, addr string, args resp.Args) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d.%06d [%d %s]", now.Unix(), now.Nanosecond()/1_000, dbIdx, addr)
+	for i, a := range args {
+		b.WriteByte(' ')
+		if redactedArg(args, i) {
+			b.WriteString(`"(redacted)"`)
+		} else {
+			quoteArg(&b, a)
+		}
+	}
+	return b.String()
+}
+
+// redactedArg reports whether args[i] is a HELLO ... AUTH credential, which MONITOR must not show.
+func redactedArg(args resp.Args, i int) bool {
+	if args.Cmd() != "HELLO" {
+		return false
+	}
+	for j := 2; j < len(args); j++ {
+		if strings.EqualFold(string(args[j]), "AUTH") {
+			return i > j && i <= j+2
+		}
+	}
+	return false
+}
+
+// quoteArg writes a as a double-quoted string, escaping it like Valkey's sdscatrepr.
+func quoteArg(b *strings.Builder, a []byte) {
+	b.WriteByte('"')
+	for _, c := range a {
+		switch c {
+		case '\\', '"':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\a':
+			b.WriteString(`\a`)
+		case '\b':
+			b.WriteString(`\b`)
+		default:
+			if c >= 0x20 && c < 0x7f {
+				b.WriteByte(c)
+			} else {
+				fmt.Fprintf(b, `\x%02x`, c)
+			}
+		}
+	}
+	b.WriteByte('"')
+}
+
+// monitor turns the connection into a MONITOR stream until the client sends
+// QUIT, disconnects or falls too far behind.
+func (s *Server) monitor(r *resp.Reader, w *resp.Writer, id int64) {
+	lines := s.monitors.add(id)
+	defer s.monitors.remove(id)
+
+	if err := w.Flush(); err != nil {
+		return
+	}
+
+	// Monitors only listen; read the connection to notice QUIT and disconnects.
+	quit := make(chan struct{})
+	go func() {
+		defer close(quit)
+		for {
+			args, err := r.ReadArrayBulk()
+			if err != nil || args.Cmd() == "QUIT" {
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				return
+			}
+			if err := w.WriteString(line); err != nil {
+				return
+			}
+			if err := w.Flush(); err != nil {
+				return
+			}
+		case <-quit:
+			return
+		}
+	}
+}
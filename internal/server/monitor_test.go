@@ -0,0 +1,111 @@
+package server
+
+import (
+	"bufio"
+	"log/slog"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMonitorLine(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_339_518_083, 107_412_999)
+
+	tcs := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{
+			name: "quotes arguments as sent",
+			args: []string{"set", "k", "v"},
+			want: `1339518083.107412 [2 127.0.0.1:60866] "set" "k" "v"`,
+		},
+		{
+			name: "escapes special bytes",
+			args: []string{"SET", "a\"b\\", "\r\n\t\x00\xff"},
+			want: `1339518083.107412 [2 127.0.0.1:60866] "SET" "a\"b\\" "\r\n\t\x00\xff"`,
+		},
+		{
+			name: "redacts HELLO credentials",
+			args: []string{"HELLO", "3", "AUTH", "user", "secret", "SETNAME", "app"},
+			want: `1339518083.107412 [2 127.0.0.1:60866] "HELLO" "3" "AUTH" "(redacted)" "(redacted)" "SETNAME" "app"`,
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := monitorLine(now, 2, "127.0.0.1:60866", testArgs(tc.args...)); got != tc.want {
+				t.Fatalf("unexpected line:\nwant %s\ngot  %s", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestServer_Monitor(t *testing.T) {
+	t.Parallel()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen failed: %v", err)
+	}
+	srv, err := New(ln, Options{Logger: slog.New(slog.DiscardHandler)})
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+	go srv.Serve()
+	t.Cleanup(func() {
+		_ = srv.Close()
+	})
+
+	dial := func() (net.Conn, *bufio.Reader) {
+		conn, err := net.Dial("tcp", ln.Addr().String())
+		if err != nil {
+			t.Fatalf("dial failed: %v", err)
+		}
+		t.Cleanup(func() {
+			_ = conn.Close()
+		})
+		return conn, bufio.NewReader(conn)
+	}
+	send := func(conn net.Conn, r *bufio.Reader, cmd string) string {
+		if _, err := conn.Write([]byte(cmd)); err != nil {
+			t.Fatalf("write failed: %v", err)
+		}
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("read failed: %v", err)
+		}
+		return line
+	}
+
+	mon, monR := dial()
+	if got := send(mon, monR, "*1\r\n$7\r\nMONITOR\r\n"); got != "+OK\r\n" {
+		t.Fatalf("expected +OK, got %q", got)
+	}
+
+	conn, r := dial()
+	send(conn, r, "*2\r\n$6\r\nSELECT\r\n$1\r\n1\r\n")
+	send(conn, r, "*3\r\n$3\r\nset\r\n$1\r\nk\r\n$1\r\nv\r\n")
+
+	addr := conn.LocalAddr().String()
+	for _, want := range []string{
+		`[0 ` + addr + `] "SELECT" "1"`,
+		`[1 ` + addr + `] "set" "k" "v"`,
+	} {
+		_ = mon.SetReadDeadline(time.Now().Add(5 * time.Second))
+		line, err := monR.ReadString('\n')
+		if err != nil {
+			t.Fatalf("read monitor failed: %v", err)
+		}
+		if !strings.HasPrefix(line, "+") || !strings.HasSuffix(line, " "+want+"\r\n") {
+			t.Fatalf("expected a line ending in %s, got %q", want, line)
+		}
+	}
+}
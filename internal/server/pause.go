@@ -0,0 +1,130 @@
+package server
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// Client pause modes, as accepted by CLIENT PAUSE.
+const (
+	PauseWrite = "WRITE"
+	PauseAll   = "ALL"
+)
+
+// pause tracks a CLIENT PAUSE. Paused commands wait on wake, which is closed
+// and replaced whenever the pause or the clock changes.
+type pause struct {
+	mu   sync.Mutex
+	mode string // empty when clients are not paused
+	end  time.Time
+	wake chan struct{}
+}
+
+// set pauses clients until end. Like Valkey, a pause already in place is
+// only ever extended and made stricter, never shortened or relaxed.
+func (p *pause) set(mode string, end time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.mode == "" {
+		p.mode, p.end = mode, end
+	} else {
+		if mode == PauseAll {
+			p.mode = PauseAll
+		}
+		if end.After(p.end) {
+			p.end = end
+		}
+	}
+	p.notifyLocked()
+}
+
+// clear lifts the pause.
+func (p *pause) clear() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.mode = ""
+	p.notifyLocked()
+}
+
+// notify wakes the paused commands so they check whether the pause is over.
+func (p *pause) notify() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.notifyLocked()
+}
+
+func (p *pause) notifyLocked() {
+	if p.wake != nil {
+		close(p.wake)
+		p.wake = nil
+	}
+}
+
+// holds reports whether r must wait, along with a channel closed once the
+// pause or the clock changes. now is read after the channel is taken, so a
+// clock change between the two is never missed.
+func (p *pause) holds(r *request, now func() time.Time) (bool, <-chan struct{}) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.mode == "" {
+		return false, nil
+	}
+	if !now().Before(p.end) {
+		p.mode = ""
+		return false, nil
+	}
+	switch p.mode {
+	case PauseWrite:
+		if !isWriteCommand(r.cmd.String()) {
+			return false, nil
+		}
+	case PauseAll:
+		// Let CLIENT UNPAUSE through, or nothing could end the pause early.
+		if r.cmd == "CLIENT" && len(r.args) > 1 && strings.EqualFold(string(r.args[1]), "UNPAUSE") {
+			return false, nil
+		}
+	}
+	if p.wake == nil {
+		p.wake = make(chan struct{})
+	}
+	return true, p.wake
+}
+
+// isWriteCommand reports whether cmd may modify the dataset, which is what
+// a WRITE pause holds back.
+func isWriteCommand(cmd string) bool {
+	_, ok := aofRewrites[cmd]
+	return ok
+}
+
+// Pause holds client commands for timeout on the simulated clock, so
+// FastForward ends it. With PauseWrite only commands that may write wait;
+// with PauseAll every command does.
+func (s *Server) Pause(timeout time.Duration, mode string) {
+	s.pause.set(mode, s.Now().Add(timeout))
+}
+
+// Unpause lifts a pause, letting the held commands run.
+func (s *Server) Unpause() {
+	s.pause.clear()
+}
+
+// waitUnpaused blocks while a pause holds r. It returns false if the server
+// stopped serving first, in which case r must not run.
+func (s *Server) waitUnpaused(r *request) bool {
+	for {
+		held, wake := s.pause.holds(r, s.Now)
+		if !held {
+			return true
+		}
+		select {
+		case <-wake:
+		case <-s.Done():
+			return false
+		}
+	}
+}
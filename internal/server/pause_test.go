@@ -0,0 +1,125 @@
+package server
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/mickamy/minivalkey/internal/session"
+)
+
+func TestPause_Holds(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+
+	tcs := []struct {
+		name    string
+		mode    string
+		elapsed time.Duration
+		args    []string
+		want    bool
+	}{
+		{name: "write pause holds writes", mode: PauseWrite, args: []string{"SET", "k", "v"}, want: true},
+		{name: "write pause lets reads through", mode: PauseWrite, args: []string{"GET", "k"}, want: false},
+		{name: "all pause holds reads", mode: PauseAll, args: []string{"GET", "k"}, want: true},
+		{name: "all pause lets unpause through", mode: PauseAll, args: []string{"client", "unpause"}, want: false},
+		{name: "pause ends with the clock", mode: PauseAll, elapsed: time.Second, args: []string{"GET", "k"}, want: false},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var p pause
+			p.set(tc.mode, now.Add(time.Second))
+			args := testArgs(tc.args...)
+			held, _ := p.holds(newRequest(session.New(), args.Cmd(), args), func() time.Time { return now.Add(tc.elapsed) })
+			if held != tc.want {
+				t.Fatalf("expected held=%v, got %v", tc.want, held)
+			}
+		})
+	}
+}
+
+func TestPause_Set(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+
+	var p pause
+	p.set(PauseAll, now.Add(time.Minute))
+	p.set(PauseWrite, now.Add(time.Second))
+	if p.mode != PauseAll || !p.end.Equal(now.Add(time.Minute)) {
+		t.Fatalf("expected a shorter, laxer pause to be ignored, got %q until %v", p.mode, p.end)
+	}
+	p.set(PauseWrite, now.Add(time.Hour))
+	if p.mode != PauseAll || !p.end.Equal(now.Add(time.Hour)) {
+		t.Fatalf("expected the pause to be extended, got %q until %v", p.mode, p.end)
+	}
+}
+
+func TestServer_PauseHoldsUntilFastForward(t *testing.T) {
+	t.Parallel()
+
+	srv := startTestServer(t)
+	srv.Pause(10*time.Second, PauseWrite)
+
+	conn := dialTestServer(t, srv)
+	r := bufio.NewReader(conn)
+	if _, err := conn.Write([]byte("*3\r\n$3\r\nSET\r\n$1\r\nk\r\n$1\r\nv\r\n")); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+
+	other := dialTestServer(t, srv)
+	otherR := bufio.NewReader(other)
+	if _, err := other.Write([]byte("*2\r\n$3\r\nGET\r\n$1\r\nk\r\n")); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	if line, err := otherR.ReadString('\n'); err != nil || line != "$-1\r\n" {
+		t.Fatalf("expected reads to go through, got %q err=%v", line, err)
+	}
+
+	_ = conn.SetReadDeadline(time.Now().Add(50 * time.Millisecond))
+	if line, err := r.ReadString('\n'); err == nil {
+		t.Fatalf("expected the write to be held, got %q", line)
+	}
+
+	srv.FastForward(10 * time.Second)
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if line, err := r.ReadString('\n'); err != nil || line != "+OK\r\n" {
+		t.Fatalf("expected the write to run after the pause, got %q err=%v", line, err)
+	}
+}
+
+func TestServer_PauseDropsHeldCommandsOnClose(t *testing.T) {
+	t.Parallel()
+
+	srv := startTestServer(t)
+	srv.Pause(10*time.Second, PauseAll)
+
+	conn := dialTestServer(t, srv)
+	if _, err := conn.Write([]byte("*3\r\n$3\r\nSET\r\n$1\r\nk\r\n$1\r\nv\r\n")); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	_ = conn.SetReadDeadline(time.Now().Add(50 * time.Millisecond))
+	r := bufio.NewReader(conn)
+	if line, err := r.ReadString('\n'); err == nil {
+		t.Fatalf("expected the write to be held, got %q", line)
+	}
+
+	if err := srv.Close(); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if line, err := r.ReadString('\n'); !errors.Is(err, io.EOF) {
+		t.Fatalf("expected the connection to close without a reply, got %q err=%v", line, err)
+	}
+	d, _ := srv.DB(0)
+	if d.Exists(srv.Now(), "k") != 0 {
+		t.Fatal("expected the held write not to run")
+	}
+}
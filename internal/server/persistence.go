@@ -0,0 +1,195 @@
+package server
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/mickamy/minivalkey/internal/db"
+	"github.com/mickamy/minivalkey/internal/rdb"
+)
+
+// persistence tracks RDB saves and append-only file rewrites.
+type persistence struct {
+	saveMu sync.Mutex // serializes writes of the RDB file
+
+	mu           sync.Mutex
+	bgSaving     bool
+	lastSave     int64 // unix seconds of the last successful save
+	aofRewriting bool
+}
+
+// SaveRDB writes every database to w in RDB format.
+func (s *Server) SaveRDB(w io.Writer) error {
+	now := s.Now()
+	return rdb.Write(w, s.rdbDatabases(now), now)
+}
+
+// LoadRDB replaces the whole dataset with the databases read from r.
+// Keys that already expired are dropped, and so are streams, which are
+// logged as a warning since they are not supported.
+func (s *Server) LoadRDB(r io.Reader) error {
+	now := s.Now()
+	dbs, err := rdb.Read(r, now)
+	if err != nil {
+		return err
+	}
+	return s.loadDatabases(now, dbs)
+}
+
+// loadDatabases replaces the whole dataset with dbs.
+func (s *Server) loadDatabases(now time.Time, dbs []rdb.Database) error {
+	dbMap := make(map[int]*db.DB, len(dbs))
+	for _, d := range dbs {
+		if d.Index >= numDatabases {
+			return fmt.Errorf("database index %d is out of range", d.Index)
+		}
+		for _, k := range d.Streams {
+			s.logger.Warn("skipping stream key, streams are not supported", "db", d.Index, "key", k)
+		}
+		target, ok := dbMap[d.Index]
+		if !ok {
+			target = db.New()
+			dbMap[d.Index] = target
+		}
+		for _, e := range d.Entries {
+			target.RestoreValue(now, e.Key, e.Value, db.RestoreOptions{Replace: true, ExpireAt: e.ExpireAt, Idle: e.Idle})
+		}
+	}
+
+	s.dbMu.Lock()
+	s.dbMap = dbMap
+	s.dbMu.Unlock()
+	return nil
+}
+
+// rdbDatabases collects the keys of every database, ordered by index.
+func (s *Server) rdbDatabases(now time.Time) []rdb.Database {
+	s.dbMu.RLock()
+	dbs := make([]rdb.Database, 0, len(s.dbMap))
+	sources := make(map[int]*db.DB, len(s.dbMap))
+	for idx, d := range s.dbMap {
+		dbs = append(dbs, rdb.Database{Index: idx})
+		sources[idx] = d
+	}
+	s.dbMu.RUnlock()
+
+	slices.SortFunc(dbs, func(a, b rdb.Database) int { return a.Index - b.Index })
+	for i := range dbs {
+		dbs[i].Entries = sources[dbs[i].Index].Entries(now)
+	}
+	return dbs
+}
+
+// saveFile writes the dataset to the RDB file, through a temporary file
+// renamed into place so a failed save leaves the previous file intact.
+func (s *Server) saveFile() error {
+	s.persistence.saveMu.Lock()
+	defer s.persistence.saveMu.Unlock()
+
+	now := s.Now()
+	f, err := os.CreateTemp(filepath.Dir(s.dbFilename), "temp-*.rdb")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = os.Remove(f.Name())
+	}()
+
+	bw := bufio.NewWriter(f)
+	if err := rdb.Write(bw, s.rdbDatabases(now), now); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := bw.Flush(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(f.Name(), s.dbFilename); err != nil {
+		return err
+	}
+
+	s.persistence.mu.Lock()
+	s.persistence.lastSave = now.Unix()
+	s.persistence.mu.Unlock()
+	return nil
+}
+
+// startBgSave saves in the background.
+// Returns false if a background save is already running.
+func (s *Server) startBgSave() bool {
+	p := &s.persistence
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.bgSaving {
+		return false
+	}
+	p.bgSaving = true
+
+	go func() {
+		if err := s.saveFile(); err != nil {
+			s.logger.Error("background save failed", "err", err)
+		}
+		p.mu.Lock()
+		p.bgSaving = false
+		p.mu.Unlock()
+	}()
+	return true
+}
+
+// bgSaving reports whether a background save is running.
+func (s *Server) bgSaving() bool {
+	s.persistence.mu.Lock()
+	defer s.persistence.mu.Unlock()
+	return s.persistence.bgSaving
+}
+
+// lastSave returns the unix time of the last successful save.
+func (s *Server) lastSave() int64 {
+	s.persistence.mu.Lock()
+	defer s.persistence.mu.Unlock()
+	return s.persistence.lastSave
+}
+
+// startBgRewriteAOF rewrites the append-only file in the background.
+// Returns false if a rewrite is already running.
+func (s *Server) startBgRewriteAOF() bool {
+	p := &s.persistence
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.aofRewriting {
+		return false
+	}
+	p.aofRewriting = true
+
+	go func() {
+		if err := s.rewriteAOF(); err != nil {
+			s.logger.Error("background append only file rewrite failed", "err", err)
+		}
+		p.mu.Lock()
+		p.aofRewriting = false
+		p.mu.Unlock()
+	}()
+	return true
+}
+
+// aofRewriting reports whether an append-only file rewrite is running.
+func (s *Server) aofRewriting() bool {
+	s.persistence.mu.Lock()
+	defer s.persistence.mu.Unlock()
+	return s.persistence.aofRewriting
+}
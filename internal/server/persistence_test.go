@@ -0,0 +1,93 @@
+package server
+
+import (
+	"bytes"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mickamy/minivalkey/internal/clock"
+	"github.com/mickamy/minivalkey/internal/db"
+	"github.com/mickamy/minivalkey/internal/rdb"
+)
+
+func TestServer_SaveRDB(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+
+	src := &Server{dbMap: map[int]*db.DB{0: db.New(), 3: db.New(), 5: db.New()}, clock: clock.New(now)}
+	src.dbAt(0).SetString("s", "v", now.Add(time.Minute))
+	src.dbAt(0).SetString("gone", "v", now.Add(-time.Second))
+	src.dbAt(3).RPush(now, "l", "a", "b")
+	src.dbAt(3).HSet(now, "h", "f", "v", "g", "w")
+	src.dbAt(3).HExpireAt(now, "h", now.Add(time.Minute), db.ExpireOptions{}, "f")
+
+	var buf bytes.Buffer
+	if err := src.SaveRDB(&buf); err != nil {
+		t.Fatalf("SaveRDB returned error: %v", err)
+	}
+
+	dst := &Server{dbMap: map[int]*db.DB{0: db.New()}, clock: clock.New(now)}
+	dst.dbAt(0).SetString("stale", "v", time.Time{})
+	if err := dst.LoadRDB(&buf); err != nil {
+		t.Fatalf("LoadRDB returned error: %v", err)
+	}
+
+	if got := dst.dbAt(0).Exists(now, "stale", "gone"); got != 0 {
+		t.Fatalf("expected stale and expired keys to be gone, got %d", got)
+	}
+	if ttl := dst.dbAt(0).TTL(now, "s"); ttl != 60 {
+		t.Fatalf("expected ttl 60, got %d", ttl)
+	}
+	for _, idx := range []int{0, 3} {
+		want := src.dbAt(idx).Entries(now)
+		if got := dst.dbAt(idx).Entries(now); !reflect.DeepEqual(got, want) {
+			t.Fatalf("db %d: expected %+v, got %+v", idx, want, got)
+		}
+	}
+}
+
+func TestServer_LoadRDB_OutOfRange(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+
+	src := &Server{dbMap: map[int]*db.DB{numDatabases: db.New()}, clock: clock.New(now)}
+	src.dbAt(numDatabases).SetString("k", "v", time.Time{})
+	var buf bytes.Buffer
+	if err := src.SaveRDB(&buf); err != nil {
+		t.Fatalf("SaveRDB returned error: %v", err)
+	}
+
+	dst := &Server{dbMap: map[int]*db.DB{0: db.New()}, clock: clock.New(now)}
+	dst.dbAt(0).SetString("k", "v", time.Time{})
+	if err := dst.LoadRDB(&buf); err == nil {
+		t.Fatalf("expected an error for a database index out of range")
+	}
+	if dst.dbAt(0).Exists(now, "k") != 1 {
+		t.Fatalf("expected the dataset to be left untouched")
+	}
+}
+
+func TestServer_LoadRDB_SkipsStreams(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+
+	var logs bytes.Buffer
+	srv := &Server{dbMap: map[int]*db.DB{0: db.New()}, clock: clock.New(now), logger: slog.New(slog.NewTextHandler(&logs, nil))}
+	dbs := []rdb.Database{{Index: 2, Entries: []rdb.Entry{{Key: "k", Value: rdb.Value{Kind: rdb.KindString, String: "v"}}}, Streams: []string{"events"}}}
+	if err := srv.loadDatabases(now, dbs); err != nil {
+		t.Fatalf("loadDatabases returned error: %v", err)
+	}
+
+	if srv.dbAt(2).Exists(now, "k") != 1 {
+		t.Fatalf("expected the other keys to load")
+	}
+	if got := logs.String(); !strings.Contains(got, "level=WARN") || !strings.Contains(got, "key=events") {
+		t.Fatalf("expected a warning naming the stream key, got %q", got)
+	}
+}
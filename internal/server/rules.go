@@ -0,0 +1,144 @@
+package server
+
+import (
+	"math/rand/v2"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/mickamy/minivalkey/internal/glob"
+)
+
+// CommandRule selects the commands an injected fault, delay or truncation applies to, and how often.
+// A command matches when it satisfies every condition that is set.
+type CommandRule struct {
+	Commands   []string // upper-case command names; empty matches any command
+	KeyPattern string   // glob pattern at least one key must match; empty matches any
+	DB         int      // selected database; negative matches any
+	ClientID   int64    // client ID; 0 matches any
+	ClientName string   // name set by CLIENT SETNAME; empty matches any
+
+	Times       int      // how many times the rule applies before it is removed; 0 is forever
+	Probability *float64 // chance the rule applies to a matching command; nil is always
+}
+
+func (cr *CommandRule) matches(r *request, keys []string) bool {
+	if len(cr.Commands) > 0 && !slices.Contains(cr.Commands, r.cmd.String()) {
+		return false
+	}
+	if cr.DB >= 0 && cr.DB != r.session.SelectedDB {
+		return false
+	}
+	if cr.ClientID != 0 && cr.ClientID != r.session.ID {
+		return false
+	}
+	if cr.ClientName != "" && cr.ClientName != r.session.Name {
+		return false
+	}
+	if cr.KeyPattern != "" && !slices.ContainsFunc(keys, func(k string) bool { return glob.Match(cr.KeyPattern, k) }) {
+		return false
+	}
+	return true
+}
+
+// ruleSet holds registered rules, each carrying a value of type T.
+type ruleSet[T any] struct {
+	mu     sync.Mutex
+	rules  []*rule[T]
+	nextID int
+}
+
+type rule[T any] struct {
+	id    int
+	cr    CommandRule
+	value T
+	left  int
+}
+
+func (rs *ruleSet[T]) add(cr CommandRule, value T) int {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	rs.nextID++
+	cr.Commands = slices.Clone(cr.Commands)
+	rs.rules = append(rs.rules, &rule[T]{id: rs.nextID, cr: cr, value: value, left: cr.Times})
+	return rs.nextID
+}
+
+func (rs *ruleSet[T]) remove(id int) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	rs.rules = slices.DeleteFunc(rs.rules, func(r *rule[T]) bool { return r.id == id })
+}
+
+func (rs *ruleSet[T]) clear() {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	rs.rules = nil
+}
+
+func (rs *ruleSet[T]) empty() bool {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	return len(rs.rules) == 0
+}
+
+// match returns the value of the first rule that applies to r, counting it against the rule.
+func (rs *ruleSet[T]) match(r *request, keys []string, rng *random) (T, bool) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	for i, ru := range rs.rules {
+		if !ru.cr.matches(r, keys) {
+			continue
+		}
+		if p := ru.cr.Probability; p != nil && rng.Float64() >= *p {
+			continue
+		}
+		if ru.left > 0 {
+			ru.left--
+			if ru.left == 0 {
+				rs.rules = slices.Delete(rs.rules, i, i+1)
+			}
+		}
+		return ru.value, true
+	}
+	var zero T
+	return zero, false
+}
+
+// random is the source behind probabilistic rules and jitter, seeded from the
+// wall clock unless Seed is called.
+type random struct {
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+// Seed makes the sequence of numbers repeatable.
+func (r *random) Seed(seed uint64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.rng = rand.New(rand.NewPCG(seed, seed))
+}
+
+// Float64 returns a number in [0, 1).
+func (r *random) Float64() float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.rng == nil {
+		seed := uint64(time.Now().UnixNano())
+		r.rng = rand.New(rand.NewPCG(seed, seed))
+	}
+	return r.rng.Float64()
+}
+
+// SeedRandom seeds the random source of probabilistic rules and jitter,
+// making the injected faults and delays repeatable.
+func (s *Server) SeedRandom(seed uint64) {
+	s.random.Seed(seed)
+}
@@ -0,0 +1,107 @@
+package server
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/mickamy/minivalkey/internal/session"
+)
+
+func TestCommandRule_Matches(t *testing.T) {
+	t.Parallel()
+
+	tcs := []struct {
+		name string
+		rule CommandRule
+		db   int
+		id   int64
+		cl   string
+		args []string
+		want bool
+	}{
+		{name: "any command", rule: CommandRule{DB: -1}, args: []string{"PING"}, want: true},
+		{name: "command matches", rule: CommandRule{Commands: []string{"GET", "SET"}, DB: -1}, args: []string{"set", "k", "v"}, want: true},
+		{name: "command differs", rule: CommandRule{Commands: []string{"GET"}, DB: -1}, args: []string{"SET", "k", "v"}, want: false},
+		{name: "key matches", rule: CommandRule{KeyPattern: "user:*", DB: -1}, args: []string{"DEL", "a", "user:1"}, want: true},
+		{name: "key differs", rule: CommandRule{KeyPattern: "user:*", DB: -1}, args: []string{"GET", "session:1"}, want: false},
+		{name: "keyless command", rule: CommandRule{KeyPattern: "*", DB: -1}, args: []string{"PING"}, want: false},
+		{name: "db matches", rule: CommandRule{DB: 2}, db: 2, args: []string{"PING"}, want: true},
+		{name: "db differs", rule: CommandRule{DB: 2}, args: []string{"PING"}, want: false},
+		{name: "client ID matches", rule: CommandRule{ClientID: 7, DB: -1}, id: 7, args: []string{"PING"}, want: true},
+		{name: "client ID differs", rule: CommandRule{ClientID: 7, DB: -1}, id: 8, args: []string{"PING"}, want: false},
+		{name: "client matches", rule: CommandRule{ClientName: "worker", DB: -1}, cl: "worker", args: []string{"PING"}, want: true},
+		{name: "client differs", rule: CommandRule{ClientName: "worker", DB: -1}, cl: "web", args: []string{"PING"}, want: false},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			sess := session.New()
+			sess.SelectedDB, sess.ID, sess.Name = tc.db, tc.id, tc.cl
+			args := testArgs(tc.args...)
+			if got := tc.rule.matches(newRequest(sess, args.Cmd(), args), commandKeys(args)); got != tc.want {
+				t.Fatalf("expected match=%v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestRuleSet_Probability(t *testing.T) {
+	t.Parallel()
+
+	run := func() []bool {
+		var rng random
+		rng.Seed(42)
+		var rs ruleSet[string]
+		p := 0.5
+		rs.add(CommandRule{DB: -1, Probability: &p}, "flaky")
+		req := newRequest(session.New(), "PING", testArgs("PING"))
+		var fired []bool
+		for range 32 {
+			_, ok := rs.match(req, nil, &rng)
+			fired = append(fired, ok)
+		}
+		return fired
+	}
+
+	first, second := run(), run()
+	if !slices.Equal(first, second) {
+		t.Fatalf("expected the same seed to fail the same commands:\n%v\n%v", first, second)
+	}
+	if !slices.Contains(first, true) || !slices.Contains(first, false) {
+		t.Fatalf("expected some commands to fail and some to pass, got %v", first)
+	}
+}
+
+func TestRuleSet_ProbabilityBounds(t *testing.T) {
+	t.Parallel()
+
+	tcs := []struct {
+		name        string
+		probability float64
+		want        bool
+	}{
+		{name: "zero never applies", probability: 0, want: false},
+		{name: "one always applies", probability: 1, want: true},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var rng random
+			rng.Seed(42)
+			var rs ruleSet[string]
+			rs.add(CommandRule{DB: -1, Probability: &tc.probability}, "fault")
+			req := newRequest(session.New(), "PING", testArgs("PING"))
+			for range 32 {
+				if _, ok := rs.match(req, nil, &rng); ok != tc.want {
+					t.Fatalf("expected match=%v, got %v", tc.want, ok)
+				}
+			}
+		})
+	}
+}
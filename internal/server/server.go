@@ -4,22 +4,29 @@ import (
 	"bufio"
 	"errors"
 	"fmt"
+	"log/slog"
 	"net"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/mickamy/minivalkey/internal/clock"
+	"github.com/mickamy/minivalkey/internal/cluster"
 	"github.com/mickamy/minivalkey/internal/db"
-	"github.com/mickamy/minivalkey/internal/logger"
+	"github.com/mickamy/minivalkey/internal/rdb"
 	"github.com/mickamy/minivalkey/internal/resp"
 	"github.com/mickamy/minivalkey/internal/session"
 )
 
 type handleFunc func(w *resp.Writer, r *request) error
 
+// numDatabases matches Valkey's default "databases" setting.
+const numDatabases = 16
+
 // Server wraps a raw TCP listener and processes RESP2 commands.
 // One goroutine per accepted connection; each has its own bufio Reader/Writer.
 type Server struct {
+	lnMu           sync.Mutex
 	listener       net.Listener
 	doneCh         chan struct{}
 	dbMu           sync.RWMutex
@@ -27,13 +34,75 @@ type Server struct {
 	cleanUpBufPool sync.Pool
 	clock          *clock.Clock
 	handlers       map[string]handleFunc
+	lastClientID   atomic.Int64
+	dbFilename     string
+	persistence    persistence
+	appendFilename string
+	appendFsync    string
+	aof            *aof
+	logger         *slog.Logger
+	clients        clients
+	history        *history
+	monitors       monitors
+	random         random
+	faults         ruleSet[string]
+	latencies      ruleSet[LatencyRule]
+	stallMu        sync.RWMutex
+	truncations    ruleSet[int]
+	dropConns      atomic.Int64
+	pause          pause
+	cluster        *cluster.Topology
+	myself         *cluster.Node
+}
+
+// Options configures a Server.
+type Options struct {
+	// DBFilename is the RDB file SAVE and BGSAVE write to. Defaults to "dump.rdb".
+	DBFilename string
+	// AppendFilename is the append-only file OpenAOF and BGREWRITEAOF use.
+	// Defaults to "appendonly.aof".
+	AppendFilename string
+	// AppendFsync is how often the append-only file is synced to disk:
+	// AppendFsyncAlways, AppendFsyncEverySec (the default) or AppendFsyncNo.
+	AppendFsync string
+	// Logger receives the server logs. Defaults to discarding them.
+	Logger *slog.Logger
+	// HistoryLimit is how many executed commands History keeps, dropping the
+	// oldest first. Zero keeps every command and a negative limit disables recording.
+	HistoryLimit int
+	// Cluster makes the server the node ClusterNodeID of a cluster sharing
+	// this slot map. Commands on keys in slots the node does not own get MOVED.
+	Cluster       *cluster.Topology
+	ClusterNodeID string
 }
 
 // New wires a DB to a net.Listener and seeds the simulated clock.
-func New(ln net.Listener) (*Server, error) {
+func New(ln net.Listener, opts Options) (*Server, error) {
 	if ln == nil {
 		return nil, errors.New("listener is nil")
 	}
+	if opts.DBFilename == "" {
+		opts.DBFilename = "dump.rdb"
+	}
+	if opts.AppendFilename == "" {
+		opts.AppendFilename = "appendonly.aof"
+	}
+	if opts.Logger == nil {
+		opts.Logger = slog.New(slog.DiscardHandler)
+	}
+	var myself *cluster.Node
+	if opts.Cluster != nil {
+		if myself = opts.Cluster.Node(opts.ClusterNodeID); myself == nil {
+			return nil, fmt.Errorf("unknown cluster node %q", opts.ClusterNodeID)
+		}
+	}
+	switch opts.AppendFsync {
+	case "":
+		opts.AppendFsync = AppendFsyncEverySec
+	case AppendFsyncAlways, AppendFsyncEverySec, AppendFsyncNo:
+	default:
+		return nil, fmt.Errorf("invalid appendfsync policy %q", opts.AppendFsync)
+	}
 	s := &Server{
 		listener: ln,
 		doneCh:   make(chan struct{}),
@@ -44,20 +113,106 @@ func New(ln net.Listener) (*Server, error) {
 				return &buf
 			},
 		},
-		clock:    clock.New(time.Now()),
-		handlers: make(map[string]handleFunc),
+		clock:          clock.New(time.Now()),
+		handlers:       make(map[string]handleFunc),
+		dbFilename:     opts.DBFilename,
+		appendFilename: opts.AppendFilename,
+		appendFsync:    opts.AppendFsync,
+		logger:         opts.Logger,
+		history:        newHistory(opts.HistoryLimit),
+		cluster:        opts.Cluster,
+		myself:         myself,
 	}
+	s.persistence.lastSave = s.Now().Unix()
 
 	handlers := map[string]handleFunc{
-		"DEL":    s.cmdDel,
-		"EXISTS": s.cmdExists,
-		"EXPIRE": s.cmdExpire,
-		"GET":    s.cmdGet,
-		"HELLO":  s.cmdHello,
-		"INFO":   s.cmdInfo,
-		"PING":   s.cmdPing,
-		"SET":    s.cmdSet,
-		"TTL":    s.cmdTTL,
+		"APPEND":               s.cmdAppend,
+		"BGREWRITEAOF":         s.cmdBgRewriteAOF,
+		"BGSAVE":               s.cmdBgSave,
+		"BITCOUNT":             s.cmdBitCount,
+		"BITFIELD":             s.cmdBitField,
+		"BITFIELD_RO":          s.cmdBitFieldRO,
+		"BITOP":                s.cmdBitOp,
+		"BITPOS":               s.cmdBitPos,
+		"CLIENT":               s.cmdClient,
+		"CLUSTER":              s.cmdCluster,
+		"COPY":                 s.cmdCopy,
+		"DECR":                 s.cmdDecr,
+		"DECRBY":               s.cmdDecrBy,
+		"DEL":                  s.cmdDel,
+		"DUMP":                 s.cmdDump,
+		"ECHO":                 s.cmdEcho,
+		"EXISTS":               s.cmdExists,
+		"EXPIRE":               s.cmdExpire,
+		"EXPIREAT":             s.cmdExpireAt,
+		"EXPIRETIME":           s.cmdExpireTime,
+		"GEOADD":               s.cmdGeoAdd,
+		"GEODIST":              s.cmdGeoDist,
+		"GEOHASH":              s.cmdGeoHash,
+		"GEOPOS":               s.cmdGeoPos,
+		"GEORADIUS":            s.cmdGeoRadius,
+		"GEORADIUSBYMEMBER":    s.cmdGeoRadiusByMember,
+		"GEORADIUSBYMEMBER_RO": s.cmdGeoRadiusByMemberRO,
+		"GEORADIUS_RO":         s.cmdGeoRadiusRO,
+		"GEOSEARCH":            s.cmdGeoSearch,
+		"GEOSEARCHSTORE":       s.cmdGeoSearchStore,
+		"GET":                  s.cmdGet,
+		"GETBIT":               s.cmdGetBit,
+		"GETDEL":               s.cmdGetDel,
+		"GETEX":                s.cmdGetEx,
+		"GETRANGE":             s.cmdGetRange,
+		"GETSET":               s.cmdGetSet,
+		"HELLO":                s.cmdHello,
+		"HEXPIRE":              s.cmdHExpire,
+		"HEXPIREAT":            s.cmdHExpireAt,
+		"HEXPIRETIME":          s.cmdHExpireTime,
+		"HGETEX":               s.cmdHGetEx,
+		"HPERSIST":             s.cmdHPersist,
+		"HPEXPIRE":             s.cmdHPExpire,
+		"HPEXPIREAT":           s.cmdHPExpireAt,
+		"HPEXPIRETIME":         s.cmdHPExpireTime,
+		"HPTTL":                s.cmdHPTTL,
+		"HSETEX":               s.cmdHSetEx,
+		"HTTL":                 s.cmdHTTL,
+		"INCR":                 s.cmdIncr,
+		"INCRBY":               s.cmdIncrBy,
+		"INCRBYFLOAT":          s.cmdIncrByFloat,
+		"INFO":                 s.cmdInfo,
+		"LASTSAVE":             s.cmdLastSave,
+		"LCS":                  s.cmdLCS,
+		"MGET":                 s.cmdMGet,
+		"MONITOR":              s.cmdMonitor,
+		"MSET":                 s.cmdMSet,
+		"MSETNX":               s.cmdMSetNX,
+		"OBJECT":               s.cmdObject,
+		"PERSIST":              s.cmdPersist,
+		"PEXPIRE":              s.cmdPExpire,
+		"PEXPIREAT":            s.cmdPExpireAt,
+		"PEXPIRETIME":          s.cmdPExpireTime,
+		"PFADD":                s.cmdPFAdd,
+		"PFCOUNT":              s.cmdPFCount,
+		"PFMERGE":              s.cmdPFMerge,
+		"PING":                 s.cmdPing,
+		"PSETEX":               s.cmdPSetEx,
+		"PTTL":                 s.cmdPTTL,
+		"RANDOMKEY":            s.cmdRandomKey,
+		"RENAME":               s.cmdRename,
+		"RENAMENX":             s.cmdRenameNX,
+		"RESTORE":              s.cmdRestore,
+		"SAVE":                 s.cmdSave,
+		"SELECT":               s.cmdSelect,
+		"SET":                  s.cmdSet,
+		"SETBIT":               s.cmdSetBit,
+		"SETEX":                s.cmdSetEx,
+		"SETNX":                s.cmdSetNX,
+		"SETRANGE":             s.cmdSetRange,
+		"SORT":                 s.cmdSort,
+		"SORT_RO":              s.cmdSortRO,
+		"STRLEN":               s.cmdStrLen,
+		"TOUCH":                s.cmdTouch,
+		"TTL":                  s.cmdTTL,
+		"TYPE":                 s.cmdType,
+		"UNLINK":               s.cmdDel,
 	}
 	for cmd, handler := range handlers {
 		if err := s.register(cmd, handler); err != nil {
@@ -70,33 +225,85 @@ func New(ln net.Listener) (*Server, error) {
 
 // Serve accepts connections and spawns handlers until the listener is closed.
 func (s *Server) Serve() {
+	s.lnMu.Lock()
+	ln, done := s.listener, s.doneCh
+	s.lnMu.Unlock()
+
 	for {
-		conn, err := s.listener.Accept()
+		conn, err := ln.Accept()
 		if err != nil {
 			// Listener closed: exit loop.
 			break
 		}
-		go s.handleConn(conn)
+		if s.dropConn() {
+			_ = conn.Close()
+			continue
+		}
+		// Register the client before handing it off, so Stop sees every
+		// connection accepted before the listener closed.
+		id := s.lastClientID.Add(1)
+		cl := &client{conn: conn}
+		s.clients.add(id, cl)
+		go s.handleConn(conn, id, cl)
 	}
-	close(s.doneCh)
+	close(done)
 }
 
 // Done closes when Serve() exits (useful for coordinating shutdown).
-func (s *Server) Done() <-chan struct{} { return s.doneCh }
+func (s *Server) Done() <-chan struct{} {
+	s.lnMu.Lock()
+	defer s.lnMu.Unlock()
+	return s.doneCh
+}
 
-// Close stops accepting new connections and closes the listener.
+// Close stops accepting new connections, closes the listener and
+// flushes the append-only file.
 func (s *Server) Close() error {
-	return s.listener.Close()
+	s.lnMu.Lock()
+	err := s.listener.Close()
+	s.lnMu.Unlock()
+	if errors.Is(err, net.ErrClosed) {
+		// Already stopped.
+		err = nil
+	}
+	return errors.Join(err, s.closeAOF())
+}
+
+// Stop closes the listener and resets every client connection, then waits
+// for Serve to return. The dataset and the rest of the server state are kept,
+// so Listen and Serve can bring the server back.
+func (s *Server) Stop() error {
+	s.lnMu.Lock()
+	err := s.listener.Close()
+	done := s.doneCh
+	s.lnMu.Unlock()
+	if err != nil && !errors.Is(err, net.ErrClosed) {
+		return err
+	}
+	<-done
+	s.closeClients(nil, true)
+	return nil
+}
+
+// Listen makes a stopped server accept connections on ln once Serve runs again.
+func (s *Server) Listen(ln net.Listener) {
+	s.lnMu.Lock()
+	defer s.lnMu.Unlock()
+
+	s.listener = ln
+	s.doneCh = make(chan struct{})
 }
 
-func (s *Server) handleConn(c net.Conn) {
+func (s *Server) handleConn(c net.Conn, id int64, cl *client) {
 	defer func(c net.Conn) {
 		_ = c.Close()
 	}(c)
+	defer s.clients.remove(id)
 
 	r := resp.NewReader(bufio.NewReader(c))
 	w := resp.NewWriter(bufio.NewWriter(c))
 	sess := session.New()
+	sess.ID = id
 
 	for {
 		args, err := r.ReadArrayBulk()
@@ -106,7 +313,7 @@ func (s *Server) handleConn(c net.Conn) {
 		}
 		if len(args) == 0 || args[0] == nil {
 			if err := w.WriteErrorAndFlush(ErrEmptyCommand); err != nil {
-				logger.Error("failed to write and flush error", "err", err)
+				s.logger.Error("failed to write and flush error", "err", err)
 				return
 			}
 			continue
@@ -115,23 +322,59 @@ func (s *Server) handleConn(c net.Conn) {
 		cmd := args.Cmd()
 		handle, ok := s.handlers[cmd.String()]
 		if !ok {
-			logger.Warn("unknown command", "cmd", cmd)
+			s.logger.Warn("unknown command", "cmd", cmd)
+			cl.record(cmd.String(), sess.Name)
 
-			if err := w.WriteErrorAndFlush(errors.New(resp.UnknownCommandError(cmd, args))); err != nil {
-				logger.Error("failed to write and flush error", "err", err)
+			unknownErr := resp.UnknownCommandError(cmd, args)
+			s.history.recordCommand(s.Now(), sess.ID, sess.SelectedDB, args, unknownErr)
+			if err := w.WriteErrorAndFlush(errors.New(unknownErr)); err != nil {
+				s.logger.Error("failed to write and flush error", "err", err)
 				return
 			}
 			continue
 		}
 
 		req := newRequest(sess, cmd, args)
+		handle = s.routeCluster(handle, req)
+		if !s.waitUnpaused(req) {
+			// The server stopped while the command was held; drop it with the connection.
+			return
+		}
+		delay := s.latencyFor(req)
+		handle = s.injectFault(handle, req)
+		out := w
+		n, truncate := s.truncateAt(req)
+		var tw *truncatedWriter
+		if truncate {
+			tw = newTruncatedWriter(n)
+			out = tw.Writer
+		}
 
-		if err := handle(w, req); err != nil {
-			logger.Error("command handler error", "cmd", cmd.String(), "err", err)
+		now, dbIdx, errs := s.Now(), sess.SelectedDB, out.Errors()
+		err = s.executeStalled(delay, handle, out, req)
+		cl.record(cmd.String(), sess.Name)
+		errMsg := ""
+		if out.Errors() > errs {
+			errMsg = out.LastError()
+		}
+		s.history.recordCommand(now, sess.ID, dbIdx, args, errMsg)
+		s.monitors.feed(now, dbIdx, c.RemoteAddr().String(), args)
+		if err != nil {
+			s.logger.Error("command handler error", "cmd", cmd.String(), "err", err)
+			return
+		}
+		if tw != nil {
+			if err := tw.send(c, w); err != nil {
+				s.logger.Error("failed to send truncated reply", "err", err)
+			}
+			return
+		}
+		if sess.Monitor {
+			s.monitor(r, w, sess.ID)
 			return
 		}
 		if err := w.Flush(); err != nil {
-			logger.Error("failed to flush writer", "err", err)
+			s.logger.Error("failed to flush writer", "err", err)
 			return
 		}
 	}
@@ -159,6 +402,7 @@ func (s *Server) Now() time.Time {
 func (s *Server) FastForward(d time.Duration) {
 	now := s.clock.Advance(d)
 	s.CleanUpExpired(now)
+	s.pause.notify()
 }
 
 // CleanUpExpired removes expired keys based on the current simulated time.
@@ -188,10 +432,36 @@ func (s *Server) CleanUpExpired(now time.Time) {
 	s.cleanUpBufPool.Put(bufPtr)
 }
 
+// DB returns the database at idx, creating it on first use.
+// Returns false if idx is out of range.
+func (s *Server) DB(idx int) (*db.DB, bool) {
+	if idx < 0 || idx >= numDatabases {
+		return nil, false
+	}
+	return s.dbAt(idx), true
+}
+
+// Databases returns the keys of every database, ordered by index and then by key.
+func (s *Server) Databases() []rdb.Database {
+	return s.rdbDatabases(s.Now())
+}
+
+// FlushAll empties every database. Like Restore, it bypasses the append-only file.
+func (s *Server) FlushAll() {
+	s.dbMu.Lock()
+	s.dbMap = make(map[int]*db.DB)
+	s.dbMu.Unlock()
+}
+
 // db returns the DB instance for the selected database in the session.
 func (s *Server) db(sess *session.Session) *db.DB {
+	return s.dbAt(sess.SelectedDB)
+}
+
+// dbAt returns the DB instance at the given index, creating it on first use.
+func (s *Server) dbAt(idx int) *db.DB {
 	s.dbMu.RLock()
-	d, ok := s.dbMap[sess.SelectedDB]
+	d, ok := s.dbMap[idx]
 	s.dbMu.RUnlock()
 	if ok {
 		return d
@@ -200,12 +470,12 @@ func (s *Server) db(sess *session.Session) *db.DB {
 	s.dbMu.Lock()
 	defer s.dbMu.Unlock()
 
-	d, ok = s.dbMap[sess.SelectedDB]
+	d, ok = s.dbMap[idx]
 	if ok {
 		return d
 	}
 
 	d = db.New()
-	s.dbMap[sess.SelectedDB] = d
+	s.dbMap[idx] = d
 	return d
 }
@@ -0,0 +1,109 @@
+package server
+
+import (
+	"bufio"
+	"errors"
+	"log/slog"
+	"net"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestServer_StopAndListen(t *testing.T) {
+	t.Parallel()
+
+	srv := startTestServer(t)
+	addr := srv.listener.Addr().String()
+
+	conn := dialTestServer(t, srv)
+	r := bufio.NewReader(conn)
+	if _, err := conn.Write([]byte("*3\r\n$3\r\nSET\r\n$1\r\nk\r\n$1\r\nv\r\n")); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	if line, err := r.ReadString('\n'); err != nil || line != "+OK\r\n" {
+		t.Fatalf("expected +OK, got %q err=%v", line, err)
+	}
+
+	if err := srv.Stop(); err != nil {
+		t.Fatalf("Stop returned error: %v", err)
+	}
+	select {
+	case <-srv.Done():
+	default:
+		t.Fatalf("expected Serve to have returned")
+	}
+	if _, err := r.ReadString('\n'); !errors.Is(err, syscall.ECONNRESET) {
+		t.Fatalf("expected the connection to be reset, got %v", err)
+	}
+	if c, err := net.DialTimeout("tcp", addr, time.Second); err == nil {
+		_ = c.Close()
+		t.Fatalf("expected the stopped server to refuse connections")
+	}
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		t.Fatalf("listen again failed: %v", err)
+	}
+	srv.Listen(ln)
+	go srv.Serve()
+
+	conn = dialTestServer(t, srv)
+	r = bufio.NewReader(conn)
+	if _, err := conn.Write([]byte("*2\r\n$3\r\nGET\r\n$1\r\nk\r\n")); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	for _, want := range []string{"$1\r\n", "v\r\n"} {
+		if line, err := r.ReadString('\n'); err != nil || line != want {
+			t.Fatalf("expected %q after the restart, got %q err=%v", want, line, err)
+		}
+	}
+}
+
+func TestServer_FlushAll(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+
+	srv := startTestServer(t)
+	srv.dbAt(0).SetString("a", "1", time.Time{})
+	srv.dbAt(3).SetString("b", "2", time.Time{})
+
+	srv.FlushAll()
+
+	if dbs := srv.rdbDatabases(now); len(dbs) != 0 {
+		t.Fatalf("expected no keys left, got %+v", dbs)
+	}
+}
+
+func startTestServer(t *testing.T) *Server {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen failed: %v", err)
+	}
+	srv, err := New(ln, Options{Logger: slog.New(slog.DiscardHandler)})
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+	go srv.Serve()
+	t.Cleanup(func() {
+		_ = srv.Close()
+	})
+	return srv
+}
+
+func dialTestServer(t *testing.T, srv *Server) net.Conn {
+	t.Helper()
+
+	conn, err := net.Dial("tcp", srv.listener.Addr().String())
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+	return conn
+}
@@ -0,0 +1,38 @@
+package server
+
+import (
+	"time"
+
+	"github.com/mickamy/minivalkey/internal/db"
+)
+
+// Snapshot is a point-in-time copy of every database and the clock offset.
+type Snapshot struct {
+	dbs    map[int]*db.DB
+	offset time.Duration
+}
+
+// Snapshot copies the dataset at a single point in time: every database is
+// read-locked for the copy, so writes wait for it and reads go on.
+func (s *Server) Snapshot() Snapshot {
+	s.dbMu.RLock()
+	defer s.dbMu.RUnlock()
+
+	return Snapshot{dbs: db.CloneAll(s.dbMap), offset: s.clock.Offset()}
+}
+
+// Restore replaces the dataset and the clock offset with snap.
+// snap is left untouched, so it can be restored any number of times.
+// Like LoadRDB, Restore bypasses the append-only file.
+func (s *Server) Restore(snap Snapshot) {
+	dbMap := make(map[int]*db.DB, len(snap.dbs))
+	for idx, d := range snap.dbs {
+		dbMap[idx] = d.Clone()
+	}
+
+	s.dbMu.Lock()
+	s.dbMap = dbMap
+	s.clock.SetOffset(snap.offset)
+	s.dbMu.Unlock()
+	s.pause.notify()
+}
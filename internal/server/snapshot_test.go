@@ -0,0 +1,47 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mickamy/minivalkey/internal/clock"
+	"github.com/mickamy/minivalkey/internal/db"
+)
+
+func TestServer_Snapshot(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+
+	srv := &Server{dbMap: map[int]*db.DB{0: db.New()}, clock: clock.New(now)}
+	srv.dbAt(0).SetString("s", "v", now.Add(time.Minute))
+	srv.dbAt(4).SetString("other", "v", time.Time{})
+	srv.clock.Advance(10 * time.Second)
+
+	snap := srv.Snapshot()
+
+	for i := 0; i < 2; i++ {
+		srv.dbAt(0).SetString("s", "changed", time.Time{})
+		srv.dbAt(0).SetString("new", "v", time.Time{})
+		srv.dbAt(4).Del(now, "other")
+		srv.clock.Advance(time.Hour)
+
+		srv.Restore(snap)
+
+		if got, want := srv.Now(), now.Add(10*time.Second); !got.Equal(want) {
+			t.Fatalf("restore %d: expected clock at %v, got %v", i, want, got)
+		}
+		if v, _ := srv.dbAt(0).GetString(srv.Now(), "s"); v != "v" {
+			t.Fatalf("restore %d: expected s=v, got %q", i, v)
+		}
+		if ttl := srv.dbAt(0).TTL(srv.Now(), "s"); ttl != 50 {
+			t.Fatalf("restore %d: expected ttl 50, got %d", i, ttl)
+		}
+		if srv.dbAt(0).Exists(srv.Now(), "new") != 0 {
+			t.Fatalf("restore %d: expected new to be gone", i)
+		}
+		if srv.dbAt(4).Exists(srv.Now(), "other") != 1 {
+			t.Fatalf("restore %d: expected other in db 4", i)
+		}
+	}
+}
@@ -2,7 +2,10 @@ package session
 
 // Session holds all states for a single client connection.
 type Session struct {
+	ID         int64
+	Name       string
 	SelectedDB int
+	Monitor    bool // set by MONITOR
 }
 
 func New() *Session {
@@ -0,0 +1,52 @@
+package minivalkey
+
+import (
+	"time"
+
+	"github.com/mickamy/minivalkey/internal/server"
+)
+
+// Latency is a registered latency rule.
+type Latency struct {
+	s  *MiniValkey
+	id int
+}
+
+// InjectLatency stalls the server for d of wall-clock time before matching commands run:
+//
+//	s.InjectLatency(200*time.Millisecond, minivalkey.OnCommand("GET"), minivalkey.OnClient("worker"))
+//
+// A stalled command takes effect and replies only once the delay is over, and
+// runs as if the server were busy with it: commands from every connection wait
+// until it has run, and the commands pipelined after it keep their order.
+// The direct access methods are not held up. Rules are checked in the order
+// they were added and the first one that applies wins.
+func (s *MiniValkey) InjectLatency(d time.Duration, opts ...RuleOption) *Latency {
+	return s.addLatency(server.LatencyRule{CommandRule: commandRule(opts), Delay: d})
+}
+
+// InjectJitter is like InjectLatency, stalling each command for a random
+// duration between lo and hi. Use WithSeed to make the durations repeatable.
+func (s *MiniValkey) InjectJitter(lo, hi time.Duration, opts ...RuleOption) *Latency {
+	return s.addLatency(server.LatencyRule{CommandRule: commandRule(opts), Delay: lo, Jitter: max(hi-lo, 0)})
+}
+
+// InjectLatencyFunc is like InjectLatency, stalling each command for as long as delay returns.
+// delay is called from the connection goroutines, possibly concurrently.
+func (s *MiniValkey) InjectLatencyFunc(delay func() time.Duration, opts ...RuleOption) *Latency {
+	return s.addLatency(server.LatencyRule{CommandRule: commandRule(opts), DelayFunc: delay})
+}
+
+func (s *MiniValkey) addLatency(rule server.LatencyRule) *Latency {
+	return &Latency{s: s, id: s.srv.AddLatency(rule)}
+}
+
+// Remove unregisters the latency rule. Commands already stalled finish their delay.
+func (l *Latency) Remove() {
+	l.s.srv.RemoveLatency(l.id)
+}
+
+// ClearLatencies removes every injected latency.
+func (s *MiniValkey) ClearLatencies() {
+	s.srv.ClearLatencies()
+}
@@ -1,7 +1,13 @@
 package minivalkey
 
 import (
+	"errors"
+	"io"
+	"io/fs"
+	"log/slog"
 	"net"
+	"os"
+	"sync"
 	"time"
 
 	"github.com/mickamy/minivalkey/internal/server"
@@ -12,42 +18,166 @@ import (
 type MiniValkey struct {
 	addr string
 	srv  *server.Server
+
+	mu      sync.Mutex // guards stopped
+	stopped bool
+}
+
+// Option configures a server started with Run.
+type Option func(*options)
+
+type options struct {
+	rdbFile      string
+	aofFile      string
+	appendFsync  AppendFsync
+	fixtureFile  string
+	logger       *slog.Logger
+	logLevel     *slog.Level
+	historyLimit int
+	seed         *uint64
+}
+
+// AppendFsync is how often the append-only file is synced to disk.
+type AppendFsync string
+
+const (
+	// AppendFsyncAlways syncs after every write command.
+	AppendFsyncAlways AppendFsync = server.AppendFsyncAlways
+	// AppendFsyncEverySec syncs once per second. This is the default.
+	AppendFsyncEverySec AppendFsync = server.AppendFsyncEverySec
+	// AppendFsyncNo leaves syncing to the operating system.
+	AppendFsyncNo AppendFsync = server.AppendFsyncNo
+)
+
+// WithRDBFile loads the RDB file at path on startup, when it exists, and
+// makes it the file SAVE and BGSAVE write to.
+func WithRDBFile(path string) Option {
+	return func(o *options) {
+		o.rdbFile = path
+	}
+}
+
+// WithLogger sends the server logs to l. Logs are discarded by default.
+func WithLogger(l *slog.Logger) Option {
+	return func(o *options) {
+		o.logger = l
+	}
+}
+
+// WithLogLevel writes server logs at level and above to stderr as text.
+// It has no effect when WithLogger is also given.
+func WithLogLevel(level slog.Level) Option {
+	return func(o *options) {
+		o.logLevel = &level
+	}
+}
+
+// WithHistoryLimit keeps only the last n commands in the history.
+// The history keeps every command by default; a negative n turns it off.
+func WithHistoryLimit(n int) Option {
+	return func(o *options) {
+		o.historyLimit = n
+	}
+}
+
+// WithFixtureFile seeds the dataset from the fixture file at path on startup.
+// It is loaded instead of the RDB file, and only when there is no append-only
+// file to replay. See LoadFixture for the format.
+func WithFixtureFile(path string) Option {
+	return func(o *options) {
+		o.fixtureFile = path
+	}
+}
+
+// WithAppendOnly enables the append-only file at path: it is replayed on
+// startup, when it exists, and every write command is appended to it.
+// It takes precedence over an RDB file given with WithRDBFile, which is only
+// loaded to seed a new append-only file.
+func WithAppendOnly(path string) Option {
+	return func(o *options) {
+		o.aofFile = path
+	}
+}
+
+// WithAppendFsync sets how often the append-only file is synced to disk.
+func WithAppendFsync(policy AppendFsync) Option {
+	return func(o *options) {
+		o.appendFsync = policy
+	}
 }
 
 // Run starts a new in-memory Valkey server listening on an ephemeral port.
-func Run() (*MiniValkey, error) {
-	s := &MiniValkey{}
+func Run(opts ...Option) (*MiniValkey, error) {
+	o := newOptions(opts)
 
 	ln, err := net.Listen("tcp", "127.0.0.1:0")
 	if err != nil {
 		return nil, err
 	}
-	s.addr = ln.Addr().String()
+	return start(ln, o, server.Options{})
+}
+
+// newOptions applies opts, defaulting the logger from the log level.
+func newOptions(opts []Option) options {
+	o := options{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if o.logger == nil && o.logLevel != nil {
+		o.logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: *o.logLevel}))
+	}
+	return o
+}
+
+// start creates a server on ln from o and so, loads its data and serves it.
+// ln is closed if anything fails.
+func start(ln net.Listener, o options, so server.Options) (*MiniValkey, error) {
+	s := &MiniValkey{addr: ln.Addr().String()}
+
+	so.DBFilename = o.rdbFile
+	so.AppendFilename = o.aofFile
+	so.AppendFsync = string(o.appendFsync)
+	so.Logger = o.logger
+	so.HistoryLimit = o.historyLimit
 
 	// Start TCP server
-	s.srv, err = server.New(ln)
+	var err error
+	s.srv, err = server.New(ln, so)
 	if err != nil {
 		_ = ln.Close()
 		return nil, err
 	}
+	if o.seed != nil {
+		s.srv.SeedRandom(*o.seed)
+	}
+	if err := s.load(o); err != nil {
+		_ = s.srv.Close()
+		return nil, err
+	}
+
+	s.serve()
 
+	return s, nil
+}
+
+// serve runs the server and the background clean-up of expired keys until the server stops.
+func (s *MiniValkey) serve() {
 	go s.srv.Serve()
 
-	// Background clean-up for expired keys
+	done := s.srv.Done()
 	go func() {
 		ticker := time.NewTicker(200 * time.Millisecond)
 		defer ticker.Stop()
 		for {
 			select {
-			case <-s.srv.Done():
+			case <-done:
 				return
 			case <-ticker.C:
 				s.srv.CleanUpExpired(s.srv.Now())
 			}
 		}
 	}()
-
-	return s, nil
 }
 
 // Addr returns the TCP address of the running server.
@@ -65,6 +195,24 @@ func (s *MiniValkey) Port() string {
 	return port
 }
 
+// ClientInfo describes an open client connection.
+type ClientInfo struct {
+	ID          int64
+	Addr        string
+	Name        string // as set by CLIENT SETNAME
+	LastCommand string
+}
+
+// Clients returns the open client connections, ordered by ID.
+func (s *MiniValkey) Clients() []ClientInfo {
+	infos := s.srv.Clients()
+	out := make([]ClientInfo, len(infos))
+	for i, c := range infos {
+		out[i] = ClientInfo(c)
+	}
+	return out
+}
+
 // Close stops the server and releases resources.
 func (s *MiniValkey) Close() error {
 	if s.srv != nil {
@@ -77,3 +225,107 @@ func (s *MiniValkey) Close() error {
 func (s *MiniValkey) FastForward(d time.Duration) {
 	s.srv.FastForward(d)
 }
+
+// Snapshot is a point-in-time copy of a server's data, expirations and clock.
+type Snapshot struct {
+	snap server.Snapshot
+}
+
+// Snapshot captures every database, value and TTL along with the clock offset,
+// all at a single point in time. Writes wait while the data is copied; reads go on.
+func (s *MiniValkey) Snapshot() Snapshot {
+	return Snapshot{snap: s.srv.Snapshot()}
+}
+
+// Restore resets the server to snap without restarting it or changing its address.
+// The same snapshot can be restored any number of times.
+func (s *MiniValkey) Restore(snap Snapshot) {
+	s.srv.Restore(snap.snap)
+}
+
+// LoadFixture replaces the whole dataset with the JSON fixture read from r.
+// A fixture maps database indexes to keys, and each key to one value with an optional TTL:
+//
+//	{
+//	  "0": {
+//	    "greeting": {"string": "hello", "ttl": "1m30s"},
+//	    "queue": {"list": ["a", "b"]},
+//	    "tags": {"set": ["x", "y"]},
+//	    "scores": {"zset": {"alice": 1.5, "bob": "-inf"}},
+//	    "user:1": {"hash": {"name": "Ann", "token": "t"}, "field_ttl": {"token": "10s"}}
+//	  }
+//	}
+//
+// TTLs are Go durations counted from the current simulated time.
+func (s *MiniValkey) LoadFixture(r io.Reader) error {
+	return s.srv.LoadFixture(r)
+}
+
+// ExportFixture writes the whole dataset to w in the format LoadFixture reads.
+// Databases, keys, set members and hash fields are sorted, so the same dataset
+// always produces the same output. Keys holding bytes that are not valid UTF-8
+// are written base64 encoded; it fails if a key name is not valid UTF-8.
+func (s *MiniValkey) ExportFixture(w io.Writer) error {
+	return s.srv.ExportFixture(w)
+}
+
+// LoadRDB replaces the whole dataset with the contents of an RDB file read from r.
+// Keys that already expired are dropped, and so are streams, since they are
+// not supported; each skipped stream key is logged as a warning.
+func (s *MiniValkey) LoadRDB(r io.Reader) error {
+	return s.srv.LoadRDB(r)
+}
+
+// SaveRDB writes the whole dataset to w in RDB format.
+func (s *MiniValkey) SaveRDB(w io.Writer) error {
+	return s.srv.SaveRDB(w)
+}
+
+// load restores the dataset from the append-only file or, when there is none
+// yet, seeds it from the fixture or the RDB file.
+func (s *MiniValkey) load(o options) error {
+	_, err := os.Stat(o.aofFile)
+	if o.aofFile == "" || errors.Is(err, fs.ErrNotExist) {
+		switch {
+		case o.fixtureFile != "":
+			if err := s.loadFixtureFile(o.fixtureFile); err != nil {
+				return err
+			}
+		case o.rdbFile != "":
+			if err := s.loadRDBFile(o.rdbFile); err != nil {
+				return err
+			}
+		}
+	}
+	if o.aofFile != "" {
+		return s.srv.OpenAOF()
+	}
+	return nil
+}
+
+// loadFixtureFile loads the fixture file at path, which must exist.
+func (s *MiniValkey) loadFixtureFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	return s.LoadFixture(f)
+}
+
+// loadRDBFile loads the RDB file at path; a missing file leaves the dataset empty.
+func (s *MiniValkey) loadRDBFile(path string) error {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	return s.LoadRDB(f)
+}
@@ -0,0 +1,142 @@
+package minivalkey
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// logEvents makes s log a warning, for an unknown command, and an error, for
+// a SAVE into a directory that does not exist.
+func logEvents(t *testing.T, s *MiniValkey) {
+	t.Helper()
+
+	conn, err := net.Dial("tcp", s.Addr())
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	defer conn.Close()
+	br := bufio.NewReader(conn)
+	for _, cmd := range []string{"*1\r\n$4\r\nNOPE\r\n", "*1\r\n$4\r\nSAVE\r\n"} {
+		if _, err := conn.Write([]byte(cmd)); err != nil {
+			t.Fatalf("write failed: %v", err)
+		}
+		line, err := br.ReadString('\n')
+		if err != nil {
+			t.Fatalf("read failed: %v", err)
+		}
+		if !strings.HasPrefix(line, "-") {
+			t.Fatalf("expected an error reply, got %q", line)
+		}
+	}
+}
+
+// missingRDBFile returns an RDB path in a directory that does not exist, so SAVE fails.
+func missingRDBFile(t *testing.T) string {
+	t.Helper()
+	return filepath.Join(t.TempDir(), "missing", "dump.rdb")
+}
+
+// captureStderr runs fn with os.Stderr redirected and returns what was written to it.
+func captureStderr(t *testing.T, fn func()) string {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("pipe failed: %v", err)
+	}
+	orig := os.Stderr
+	os.Stderr = w
+	defer func() {
+		os.Stderr = orig
+	}()
+
+	out := make(chan string)
+	go func() {
+		b, _ := io.ReadAll(r)
+		out <- string(b)
+	}()
+	fn()
+	_ = w.Close()
+	return <-out
+}
+
+func TestWithLogger(t *testing.T) {
+	t.Parallel()
+
+	tcs := []struct {
+		name      string
+		level     slog.Level
+		wantWarn  bool
+		wantError bool
+	}{
+		{name: "debug", level: slog.LevelDebug, wantWarn: true, wantError: true},
+		{name: "warn", level: slog.LevelWarn, wantWarn: true, wantError: true},
+		{name: "error", level: slog.LevelError, wantWarn: false, wantError: true},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+			l := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: tc.level}))
+			// WithLogger wins over WithLogLevel, which would write to stderr.
+			s := newTestServer(t, WithLogger(l), WithLogLevel(slog.LevelDebug), WithRDBFile(missingRDBFile(t)))
+			logEvents(t, s)
+			_ = s.Close()
+
+			got := buf.String()
+			if gotWarn := strings.Contains(got, `level=WARN msg="unknown command"`); gotWarn != tc.wantWarn {
+				t.Fatalf("expected warning logged %v, got %q", tc.wantWarn, got)
+			}
+			if gotError := strings.Contains(got, `level=ERROR msg="save failed"`); gotError != tc.wantError {
+				t.Fatalf("expected error logged %v, got %q", tc.wantError, got)
+			}
+		})
+	}
+}
+
+// TestWithLogLevel redirects os.Stderr, so it does not run in parallel.
+func TestWithLogLevel(t *testing.T) {
+	tcs := []struct {
+		name      string
+		opts      []Option
+		wantWarn  bool
+		wantError bool
+	}{
+		{name: "silent by default"},
+		{name: "warn", opts: []Option{WithLogLevel(slog.LevelWarn)}, wantWarn: true, wantError: true},
+		{name: "error", opts: []Option{WithLogLevel(slog.LevelError)}, wantWarn: false, wantError: true},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			got := captureStderr(t, func() {
+				s, err := Run(append(tc.opts, WithRDBFile(missingRDBFile(t)))...)
+				if err != nil {
+					t.Fatalf("Run returned error: %v", err)
+				}
+				logEvents(t, s)
+				_ = s.Close()
+			})
+
+			if gotWarn := strings.Contains(got, `level=WARN msg="unknown command"`); gotWarn != tc.wantWarn {
+				t.Fatalf("expected warning logged %v, got %q", tc.wantWarn, got)
+			}
+			if gotError := strings.Contains(got, `level=ERROR msg="save failed"`); gotError != tc.wantError {
+				t.Fatalf("expected error logged %v, got %q", tc.wantError, got)
+			}
+			if tc.opts == nil && got != "" {
+				t.Fatalf("expected no output, got %q", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package minivalkeytest
+
+import (
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+
+	tcs := []struct {
+		name string
+		want []string
+		got  []string
+		diff string
+	}{
+		{name: "equal", want: []string{"a", "b"}, got: []string{"a", "b"}, diff: "  a\n  b\n"},
+		{name: "replaced", want: []string{"a"}, got: []string{"b"}, diff: "- a\n+ b\n"},
+		{name: "inserted", want: []string{"a", "c"}, got: []string{"a", "b", "c"}, diff: "  a\n+ b\n  c\n"},
+		{name: "removed", want: []string{"a", "b", "c"}, got: []string{"a", "c"}, diff: "  a\n- b\n  c\n"},
+		{name: "empty got", want: []string{"a"}, got: nil, diff: "- a\n"},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := diff(tc.want, tc.got); got != tc.diff {
+				t.Fatalf("unexpected diff:\nwant %q\ngot  %q", tc.diff, got)
+			}
+		})
+	}
+}
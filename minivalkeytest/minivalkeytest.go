@@ -0,0 +1,223 @@
+// Package minivalkeytest provides assertion helpers for tests that use minivalkey.
+//
+// Every helper takes a testing.TB and a Target, which is either a
+// *minivalkey.MiniValkey (database 0) or the *minivalkey.DB of any database.
+// Failures are reported with t.Errorf, as a diff of the expected and actual
+// state followed by a dump of the database:
+//
+//	db2, _ := s.DB(2)
+//	minivalkeytest.CheckGet(t, s, "greeting", "hello")
+//	minivalkeytest.CheckTTL(t, db2, "session", time.Minute, time.Second)
+//	minivalkeytest.CheckKeys(t, s, "user:*", "user:1", "user:2")
+package minivalkeytest
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/mickamy/minivalkey"
+	"github.com/mickamy/minivalkey/internal/glob"
+)
+
+// Target is a database the helpers inspect.
+// Both *minivalkey.MiniValkey and *minivalkey.DB implement it.
+type Target interface {
+	Get(k string) (string, error)
+	Exists(k string) bool
+	Type(k string) string
+	Keys() []string
+	TTL(k string) (time.Duration, error)
+	HGetAll(k string) (map[string]string, error)
+	List(k string) ([]string, error)
+	SMembers(k string) ([]string, error)
+	ZMembers(k string) ([]minivalkey.ZMember, error)
+	Dump() string
+}
+
+var (
+	_ Target = (*minivalkey.MiniValkey)(nil)
+	_ Target = (*minivalkey.DB)(nil)
+)
+
+// CheckGet checks that k holds the string want.
+func CheckGet(t testing.TB, m Target, k, want string) {
+	t.Helper()
+
+	got, err := m.Get(k)
+	if err != nil {
+		fail(t, m, fmt.Sprintf("GET %q", k), []string{strconv.Quote(want)}, describe(m, k, err))
+		return
+	}
+	if got != want {
+		fail(t, m, fmt.Sprintf("GET %q", k), []string{strconv.Quote(want)}, []string{strconv.Quote(got)})
+	}
+}
+
+// CheckNotExists checks that k does not exist.
+func CheckNotExists(t testing.TB, m Target, k string) {
+	t.Helper()
+
+	if m.Exists(k) {
+		fail(t, m, fmt.Sprintf("EXISTS %q", k), []string{"(no key)"}, []string{"(" + m.Type(k) + ")"})
+	}
+}
+
+// CheckTTL checks that k expires in want, give or take tolerance.
+func CheckTTL(t testing.TB, m Target, k string, want, tolerance time.Duration) {
+	t.Helper()
+
+	wantLines := []string{fmt.Sprintf("ttl %s ± %s", want, tolerance)}
+	got, err := m.TTL(k)
+	if err != nil {
+		fail(t, m, fmt.Sprintf("TTL %q", k), wantLines, describe(m, k, err))
+		return
+	}
+	if diff := got - want; diff < -tolerance || diff > tolerance {
+		fail(t, m, fmt.Sprintf("TTL %q", k), wantLines, []string{fmt.Sprintf("ttl %s", got)})
+	}
+}
+
+// CheckKeys checks that the keys matching the glob-style pattern are exactly want.
+// The pattern syntax is the one KEYS uses; the order of want does not matter.
+func CheckKeys(t testing.TB, m Target, pattern string, want ...string) {
+	t.Helper()
+
+	var got []string
+	for _, k := range m.Keys() {
+		if glob.Match(pattern, k) {
+			got = append(got, strconv.Quote(k))
+		}
+	}
+	// Both sides are sorted by their quoted form, which can differ from the
+	// order of the raw keys.
+	slices.Sort(got)
+	wantLines := quoteAll(want)
+	slices.Sort(wantLines)
+	if !slices.Equal(wantLines, got) {
+		fail(t, m, fmt.Sprintf("KEYS %q", pattern), wantLines, got)
+	}
+}
+
+// CheckHash checks that k holds a hash with exactly the fields in want.
+func CheckHash(t testing.TB, m Target, k string, want map[string]string) {
+	t.Helper()
+
+	wantLines := hashLines(want)
+	got, err := m.HGetAll(k)
+	if err != nil {
+		fail(t, m, fmt.Sprintf("HGETALL %q", k), wantLines, describe(m, k, err))
+		return
+	}
+	if gotLines := hashLines(got); !slices.Equal(wantLines, gotLines) {
+		fail(t, m, fmt.Sprintf("HGETALL %q", k), wantLines, gotLines)
+	}
+}
+
+// CheckList checks that k holds a list with exactly the elements in want, in order.
+func CheckList(t testing.TB, m Target, k string, want ...string) {
+	t.Helper()
+
+	wantLines := quoteAll(want)
+	got, err := m.List(k)
+	if err != nil {
+		fail(t, m, fmt.Sprintf("LRANGE %q", k), wantLines, describe(m, k, err))
+		return
+	}
+	if gotLines := quoteAll(got); !slices.Equal(wantLines, gotLines) {
+		fail(t, m, fmt.Sprintf("LRANGE %q", k), wantLines, gotLines)
+	}
+}
+
+// CheckSet checks that k holds a set with exactly the members in want, in any order.
+func CheckSet(t testing.TB, m Target, k string, want ...string) {
+	t.Helper()
+
+	wantLines := quoteAll(want)
+	slices.Sort(wantLines)
+	wantLines = slices.Compact(wantLines)
+	got, err := m.SMembers(k)
+	if err != nil {
+		fail(t, m, fmt.Sprintf("SMEMBERS %q", k), wantLines, describe(m, k, err))
+		return
+	}
+	gotLines := quoteAll(got)
+	slices.Sort(gotLines)
+	if !slices.Equal(wantLines, gotLines) {
+		fail(t, m, fmt.Sprintf("SMEMBERS %q", k), wantLines, gotLines)
+	}
+}
+
+// CheckZSet checks that k holds a sorted set with exactly the members and scores in want.
+// want may be in any order; the comparison follows score order.
+func CheckZSet(t testing.TB, m Target, k string, want ...minivalkey.ZMember) {
+	t.Helper()
+
+	want = slices.Clone(want)
+	slices.SortFunc(want, func(a, b minivalkey.ZMember) int {
+		return cmp.Or(cmp.Compare(a.Score, b.Score), cmp.Compare(a.Member, b.Member))
+	})
+	wantLines := zsetLines(want)
+	got, err := m.ZMembers(k)
+	if err != nil {
+		fail(t, m, fmt.Sprintf("ZRANGE %q", k), wantLines, describe(m, k, err))
+		return
+	}
+	if gotLines := zsetLines(got); !slices.Equal(wantLines, gotLines) {
+		fail(t, m, fmt.Sprintf("ZRANGE %q", k), wantLines, gotLines)
+	}
+}
+
+// fail reports a mismatch as a diff followed by a dump of the database.
+func fail(t testing.TB, m Target, what string, want, got []string) {
+	t.Helper()
+
+	dump := m.Dump()
+	if dump == "" {
+		dump = "(empty)\n"
+	}
+	t.Errorf("%s mismatch (-want +got):\n%skeyspace:\n%s", what, diff(want, got), dump)
+}
+
+// describe turns a lookup error into the "got" side of a diff.
+func describe(m Target, k string, err error) []string {
+	switch {
+	case errors.Is(err, minivalkey.ErrKeyNotFound):
+		return []string{"(no key)"}
+	case errors.Is(err, minivalkey.ErrWrongType):
+		return []string{"(" + m.Type(k) + ")"}
+	case errors.Is(err, minivalkey.ErrNoTTL):
+		return []string{"(no ttl)"}
+	default:
+		return []string{"(" + err.Error() + ")"}
+	}
+}
+
+func quoteAll(vals []string) []string {
+	out := make([]string, len(vals))
+	for i, v := range vals {
+		out[i] = strconv.Quote(v)
+	}
+	return out
+}
+
+func hashLines(h map[string]string) []string {
+	out := make([]string, 0, len(h))
+	for _, f := range slices.Sorted(maps.Keys(h)) {
+		out = append(out, strconv.Quote(f)+": "+strconv.Quote(h[f]))
+	}
+	return out
+}
+
+func zsetLines(members []minivalkey.ZMember) []string {
+	out := make([]string, len(members))
+	for i, zm := range members {
+		out[i] = strconv.Quote(zm.Member) + ": " + strconv.FormatFloat(zm.Score, 'g', -1, 64)
+	}
+	return out
+}
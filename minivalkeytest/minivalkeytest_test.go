@@ -0,0 +1,191 @@
+package minivalkeytest_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mickamy/minivalkey"
+	"github.com/mickamy/minivalkey/minivalkeytest"
+)
+
+// recorder captures failures instead of failing the test.
+type recorder struct {
+	testing.TB
+	msgs []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.msgs = append(r.msgs, fmt.Sprintf(format, args...))
+}
+
+func TestChecks(t *testing.T) {
+	t.Parallel()
+
+	s, err := minivalkey.Run()
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = s.Close()
+	})
+
+	s.Set("greeting", "hello")
+	s.SetTTL("greeting", time.Minute)
+	_ = s.HSet("user:1", "name", "Ann", "age", "30")
+	_, _ = s.RPush("queue", "a", "b")
+	_, _ = s.SAdd("tags", "x", "y")
+	_, _ = s.ZAdd("scores", 2, "bob")
+	_, _ = s.ZAdd("scores", 1, "alice")
+	db2, err := s.DB(2)
+	if err != nil {
+		t.Fatalf("DB returned error: %v", err)
+	}
+	db2.Set("other", "v")
+	db2.Set(`q"`, "v")
+	db2.Set("q#", "v")
+
+	tcs := []struct {
+		name  string
+		check func(t testing.TB)
+		want  []string // substrings of the failure message; nil means the check passes
+	}{
+		{
+			name:  "get",
+			check: func(t testing.TB) { minivalkeytest.CheckGet(t, s, "greeting", "hello") },
+		},
+		{
+			name:  "get mismatch",
+			check: func(t testing.TB) { minivalkeytest.CheckGet(t, s, "greeting", "bye") },
+			want:  []string{`GET "greeting" mismatch (-want +got):`, `- "bye"`, `+ "hello"`, "keyspace:\ndb 0\n", `"greeting" = "hello" (ttl 1m0s)`},
+		},
+		{
+			name:  "get missing key",
+			check: func(t testing.TB) { minivalkeytest.CheckGet(t, s, "nope", "x") },
+			want:  []string{"+ (no key)"},
+		},
+		{
+			name:  "get wrong type",
+			check: func(t testing.TB) { minivalkeytest.CheckGet(t, s, "queue", "x") },
+			want:  []string{"+ (list)"},
+		},
+		{
+			name:  "not exists",
+			check: func(t testing.TB) { minivalkeytest.CheckNotExists(t, s, "nope") },
+		},
+		{
+			name:  "not exists mismatch",
+			check: func(t testing.TB) { minivalkeytest.CheckNotExists(t, s, "tags") },
+			want:  []string{"- (no key)", "+ (set)"},
+		},
+		{
+			name:  "ttl within tolerance",
+			check: func(t testing.TB) { minivalkeytest.CheckTTL(t, s, "greeting", 59*time.Second, time.Second) },
+		},
+		{
+			name:  "ttl out of tolerance",
+			check: func(t testing.TB) { minivalkeytest.CheckTTL(t, s, "greeting", 30*time.Second, time.Second) },
+			want:  []string{"- ttl 30s ± 1s", "+ ttl 1m0s"},
+		},
+		{
+			name:  "ttl without expiry",
+			check: func(t testing.TB) { minivalkeytest.CheckTTL(t, s, "queue", time.Second, 0) },
+			want:  []string{"+ (no ttl)"},
+		},
+		{
+			name:  "ttl missing key",
+			check: func(t testing.TB) { minivalkeytest.CheckTTL(t, s, "nope", time.Second, 0) },
+			want:  []string{"+ (no key)"},
+		},
+		{
+			name:  "keys",
+			check: func(t testing.TB) { minivalkeytest.CheckKeys(t, s, "*e*", "queue", "greeting", "scores", "user:1") },
+		},
+		{
+			// `q"` sorts before "q#", but its quoted form sorts after.
+			name:  "keys that quote out of order",
+			check: func(t testing.TB) { minivalkeytest.CheckKeys(t, db2, "q*", "q#", `q"`) },
+		},
+		{
+			name:  "keys mismatch",
+			check: func(t testing.TB) { minivalkeytest.CheckKeys(t, s, "user:*", "user:1", "user:2") },
+			want:  []string{`  "user:1"`, `- "user:2"`},
+		},
+		{
+			name: "hash",
+			check: func(t testing.TB) {
+				minivalkeytest.CheckHash(t, s, "user:1", map[string]string{"name": "Ann", "age": "30"})
+			},
+		},
+		{
+			name: "hash mismatch",
+			check: func(t testing.TB) {
+				minivalkeytest.CheckHash(t, s, "user:1", map[string]string{"name": "Bob", "age": "30"})
+			},
+			want: []string{`  "age": "30"`, `- "name": "Bob"`, `+ "name": "Ann"`},
+		},
+		{
+			name:  "list",
+			check: func(t testing.TB) { minivalkeytest.CheckList(t, s, "queue", "a", "b") },
+		},
+		{
+			name:  "list order matters",
+			check: func(t testing.TB) { minivalkeytest.CheckList(t, s, "queue", "b", "a") },
+			want:  []string{`LRANGE "queue" mismatch`},
+		},
+		{
+			name:  "set in any order",
+			check: func(t testing.TB) { minivalkeytest.CheckSet(t, s, "tags", "y", "x") },
+		},
+		{
+			name:  "set mismatch",
+			check: func(t testing.TB) { minivalkeytest.CheckSet(t, s, "tags", "x") },
+			want:  []string{`+ "y"`},
+		},
+		{
+			name: "zset",
+			check: func(t testing.TB) {
+				minivalkeytest.CheckZSet(t, s, "scores", minivalkey.ZMember{Member: "bob", Score: 2}, minivalkey.ZMember{Member: "alice", Score: 1})
+			},
+		},
+		{
+			name: "zset mismatch",
+			check: func(t testing.TB) {
+				minivalkeytest.CheckZSet(t, s, "scores", minivalkey.ZMember{Member: "alice", Score: 1}, minivalkey.ZMember{Member: "bob", Score: 3})
+			},
+			want: []string{`- "bob": 3`, `+ "bob": 2`},
+		},
+		{
+			name:  "other database",
+			check: func(t testing.TB) { minivalkeytest.CheckGet(t, db2, "other", "x") },
+			want:  []string{"keyspace:\ndb 2\n"},
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := &recorder{TB: t}
+			tc.check(r)
+			if tc.want == nil {
+				if len(r.msgs) != 0 {
+					t.Fatalf("expected no failure, got:\n%s", strings.Join(r.msgs, "\n"))
+				}
+				return
+			}
+			if len(r.msgs) != 1 {
+				t.Fatalf("expected one failure, got %d: %q", len(r.msgs), r.msgs)
+			}
+			for _, w := range tc.want {
+				if !strings.Contains(r.msgs[0], w) {
+					t.Fatalf("expected failure to contain %q, got:\n%s", w, r.msgs[0])
+				}
+			}
+		})
+	}
+}
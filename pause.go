@@ -0,0 +1,32 @@
+package minivalkey
+
+import (
+	"time"
+
+	"github.com/mickamy/minivalkey/internal/server"
+)
+
+// PauseMode selects which commands PauseClients holds back.
+type PauseMode string
+
+const (
+	// PauseWrite holds commands that may modify the dataset and lets reads through.
+	PauseWrite PauseMode = server.PauseWrite
+	// PauseAll holds every command except CLIENT UNPAUSE.
+	PauseAll PauseMode = server.PauseAll
+)
+
+// PauseClients does what CLIENT PAUSE does: held commands get no reply
+// until the pause ends. The timeout runs on the simulated clock, so the
+// pause lasts until FastForward moves past it or UnpauseClients is called.
+// A pause in place is only ever extended or made stricter. Commands still
+// held when the server closes, stops or restarts never run, and their
+// connections are closed.
+func (s *MiniValkey) PauseClients(timeout time.Duration, mode PauseMode) {
+	s.srv.Pause(timeout, string(mode))
+}
+
+// UnpauseClients ends a pause, letting held commands run.
+func (s *MiniValkey) UnpauseClients() {
+	s.srv.Unpause()
+}
@@ -0,0 +1,79 @@
+package minivalkey
+
+import (
+	"errors"
+	"net"
+)
+
+// ErrRunning is returned by Start when the server is already running.
+var ErrRunning = errors.New("minivalkey: server is already running")
+
+// RestartOption configures Start and Restart.
+type RestartOption func(*restartOptions)
+
+type restartOptions struct {
+	wipe bool
+}
+
+// WipeData brings the server back with empty databases instead of the data it held when it stopped.
+// Persistence files are left as they are.
+func WipeData() RestartOption {
+	return func(o *restartOptions) {
+		o.wipe = true
+	}
+}
+
+// Stop shuts the server down without losing its state: the listener closes
+// and every client connection is reset, so clients see the server go away.
+// Data, the clock, history and injected faults are kept for Start.
+// Stopping a stopped server does nothing.
+func (s *MiniValkey) Stop() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stopped {
+		return nil
+	}
+	if err := s.srv.Stop(); err != nil {
+		return err
+	}
+	s.stopped = true
+	return nil
+}
+
+// Start brings a stopped server back on the same address.
+func (s *MiniValkey) Start(opts ...RestartOption) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.stopped {
+		return ErrRunning
+	}
+	var o restartOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	ln, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return err
+	}
+	if o.wipe {
+		s.srv.FlushAll()
+	}
+	s.srv.Listen(ln)
+	s.serve()
+	s.stopped = false
+	return nil
+}
+
+// Restart stops the server and starts it again on the same address:
+//
+//	_ = s.Restart()                       // keep the data
+//	_ = s.Restart(minivalkey.WipeData())  // come back empty
+func (s *MiniValkey) Restart(opts ...RestartOption) error {
+	if err := s.Stop(); err != nil {
+		return err
+	}
+	return s.Start(opts...)
+}
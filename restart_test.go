@@ -0,0 +1,129 @@
+package minivalkey
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// dial opens a connection to s that is closed when the test ends.
+func dial(t *testing.T, s *MiniValkey) (net.Conn, *bufio.Reader) {
+	t.Helper()
+
+	conn, err := net.Dial("tcp", s.Addr())
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+	return conn, bufio.NewReader(conn)
+}
+
+// do sends args as a command and returns the reply, with the value of a bulk
+// string in place of its length.
+func do(t *testing.T, conn net.Conn, r *bufio.Reader, args ...string) string {
+	t.Helper()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+	for _, a := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(a), a)
+	}
+	if _, err := conn.Write([]byte(b.String())); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	line, err := r.ReadString('\n')
+	if err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+	if strings.HasPrefix(line, "$") && line != "$-1\r\n" {
+		if line, err = r.ReadString('\n'); err != nil {
+			t.Fatalf("read failed: %v", err)
+		}
+	}
+	return strings.TrimSuffix(line, "\r\n")
+}
+
+func TestMiniValkey_Restart(t *testing.T) {
+	t.Parallel()
+
+	tcs := []struct {
+		name string
+		opts []RestartOption
+		want string
+	}{
+		{name: "keeps the data", want: "v"},
+		{name: "wipes the data", opts: []RestartOption{WipeData()}, want: "$-1"},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			s := newTestServer(t)
+			addr := s.Addr()
+			conn, r := dial(t, s)
+			if got := do(t, conn, r, "SET", "k", "v"); got != "+OK" {
+				t.Fatalf("expected +OK, got %q", got)
+			}
+
+			if err := s.Restart(tc.opts...); err != nil {
+				t.Fatalf("Restart returned error: %v", err)
+			}
+			if s.Addr() != addr {
+				t.Fatalf("expected address %s, got %s", addr, s.Addr())
+			}
+			conn, r = dial(t, s)
+			if got := do(t, conn, r, "GET", "k"); got != tc.want {
+				t.Fatalf("expected %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestMiniValkey_Start(t *testing.T) {
+	t.Parallel()
+
+	s := newTestServer(t)
+	if err := s.Start(); !errors.Is(err, ErrRunning) {
+		t.Fatalf("expected %v, got %v", ErrRunning, err)
+	}
+
+	if err := s.Stop(); err != nil {
+		t.Fatalf("Stop returned error: %v", err)
+	}
+	if err := s.Stop(); err != nil {
+		t.Fatalf("expected stopping a stopped server to do nothing, got %v", err)
+	}
+	if err := s.Start(); err != nil {
+		t.Fatalf("Start returned error: %v", err)
+	}
+	if err := s.Start(); !errors.Is(err, ErrRunning) {
+		t.Fatalf("expected %v, got %v", ErrRunning, err)
+	}
+}
+
+func TestMiniValkey_Stop(t *testing.T) {
+	t.Parallel()
+
+	s := newTestServer(t)
+	conn, r := dial(t, s)
+	if got := do(t, conn, r, "PING"); got != "+PONG" {
+		t.Fatalf("expected +PONG, got %q", got)
+	}
+
+	if err := s.Stop(); err != nil {
+		t.Fatalf("Stop returned error: %v", err)
+	}
+	var ne net.Error
+	if _, err := r.ReadString('\n'); err == nil || errors.As(err, &ne) && ne.Timeout() {
+		t.Fatalf("expected the server to close the connection, got %v", err)
+	}
+}
@@ -0,0 +1,84 @@
+package minivalkey
+
+import (
+	"strings"
+
+	"github.com/mickamy/minivalkey/internal/server"
+)
+
+// RuleOption narrows down which commands an injected fault, latency or truncation applies to, or how often.
+type RuleOption func(*server.CommandRule)
+
+// OnCommand applies the rule to the named commands only, in any case.
+func OnCommand(names ...string) RuleOption {
+	return func(r *server.CommandRule) {
+		for _, name := range names {
+			r.Commands = append(r.Commands, strings.ToUpper(name))
+		}
+	}
+}
+
+// OnKey applies the rule to commands with a key matching the glob-style pattern only.
+func OnKey(pattern string) RuleOption {
+	return func(r *server.CommandRule) {
+		r.KeyPattern = pattern
+	}
+}
+
+// OnDB applies the rule to commands run against database idx only.
+func OnDB(idx int) RuleOption {
+	return func(r *server.CommandRule) {
+		r.DB = idx
+	}
+}
+
+// OnClientID applies the rule to the client with the given ID only, as listed by Clients.
+func OnClientID(id int64) RuleOption {
+	return func(r *server.CommandRule) {
+		r.ClientID = id
+	}
+}
+
+// OnClient applies the rule to clients named name with CLIENT SETNAME only.
+func OnClient(name string) RuleOption {
+	return func(r *server.CommandRule) {
+		r.ClientName = name
+	}
+}
+
+// Times removes the rule after it applied n times.
+func Times(n int) RuleOption {
+	return func(r *server.CommandRule) {
+		r.Times = n
+	}
+}
+
+// Once removes the rule after it applied once.
+func Once() RuleOption {
+	return Times(1)
+}
+
+// Probability applies the rule to each matching command with chance p, between 0 and 1.
+// A rule with probability 0 never applies. Use WithSeed to make the outcome repeatable.
+func Probability(p float64) RuleOption {
+	return func(r *server.CommandRule) {
+		r.Probability = &p
+	}
+}
+
+// WithSeed seeds the random source behind Probability and latency jitter,
+// so the same commands fail or stall by the same amount on every run.
+func WithSeed(seed uint64) Option {
+	return func(o *options) {
+		o.seed = &seed
+	}
+}
+
+// commandRule builds the rule opts describe, matching every command by default.
+func commandRule(opts []RuleOption) server.CommandRule {
+	cr := server.CommandRule{DB: -1}
+	for _, opt := range opts {
+		opt(&cr)
+	}
+	return cr
+}
@@ -0,0 +1,90 @@
+package minivalkey
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// clientCloseGrace is how long RunT waits at cleanup for connections the
+// test closed to be noticed by the server.
+const clientCloseGrace = time.Second
+
+// RunT starts a server for the test t and closes it when the test ends.
+// A startup error fails the test. Server logs go to t.Log instead of stdout.
+// Connections still open when the test ends are reported as test errors,
+// since they usually point to a client or pool that was never closed.
+func RunT(t testing.TB, opts ...Option) *MiniValkey {
+	t.Helper()
+
+	w := &testLogWriter{t: t}
+	handler := slog.NewTextHandler(w, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			// t.Log adds its own context; timestamps only add noise.
+			if len(groups) == 0 && a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})
+	opts = append([]Option{WithLogger(slog.New(handler))}, opts...)
+
+	s, err := Run(opts...)
+	if err != nil {
+		t.Fatalf("minivalkey: failed to start: %v", err)
+	}
+	t.Cleanup(func() {
+		if open := s.waitForClients(clientCloseGrace); len(open) > 0 {
+			var b strings.Builder
+			fmt.Fprintf(&b, "minivalkey: %d client connection(s) still open at the end of the test; was a client or pool left unclosed?", len(open))
+			for _, c := range open {
+				fmt.Fprintf(&b, "\n  id=%d addr=%s name=%q last-cmd=%s", c.ID, c.Addr, c.Name, c.LastCommand)
+			}
+			t.Error(b.String())
+		}
+		_ = s.Close()
+		w.stop()
+	})
+	return s
+}
+
+// waitForClients waits up to grace for every client connection to close
+// and returns the ones still open.
+func (s *MiniValkey) waitForClients(grace time.Duration) []ClientInfo {
+	deadline := time.Now().Add(grace)
+	for {
+		open := s.Clients()
+		if len(open) == 0 || time.Now().After(deadline) {
+			return open
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// testLogWriter forwards log lines to t.Log until the test ends, after which
+// t.Log must no longer be called.
+type testLogWriter struct {
+	t    testing.TB
+	mu   sync.Mutex
+	done bool
+}
+
+func (w *testLogWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if !w.done {
+		w.t.Log(strings.TrimSuffix(string(p), "\n"))
+	}
+	return len(p), nil
+}
+
+func (w *testLogWriter) stop() {
+	w.mu.Lock()
+	w.done = true
+	w.mu.Unlock()
+}
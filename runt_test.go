@@ -0,0 +1,114 @@
+package minivalkey
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeT records what RunT reports, and runs its cleanups on demand.
+type fakeT struct {
+	testing.TB
+	mu       sync.Mutex
+	logs     []string
+	errs     []string
+	cleanups []func()
+}
+
+func (f *fakeT) Helper() {}
+
+func (f *fakeT) Log(args ...any) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.logs = append(f.logs, fmt.Sprint(args...))
+}
+
+func (f *fakeT) Error(args ...any) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.errs = append(f.errs, fmt.Sprint(args...))
+}
+
+func (f *fakeT) Fatalf(format string, args ...any) {
+	panic(fmt.Sprintf(format, args...))
+}
+
+func (f *fakeT) Cleanup(fn func()) {
+	f.cleanups = append(f.cleanups, fn)
+}
+
+// cleanup runs the registered cleanups, last first, as the testing package does.
+func (f *fakeT) cleanup() {
+	for _, fn := range slices.Backward(f.cleanups) {
+		fn()
+	}
+}
+
+func (f *fakeT) recorded() (logs, errs []string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return slices.Clone(f.logs), slices.Clone(f.errs)
+}
+
+func TestRunT(t *testing.T) {
+	t.Parallel()
+
+	tcs := []struct {
+		name      string
+		leaveOpen bool
+		wantErr   string
+	}{
+		{name: "closed client", leaveOpen: false},
+		{name: "leaked client", leaveOpen: true, wantErr: "1 client connection(s) still open at the end of the test"},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ft := &fakeT{}
+			s := RunT(ft)
+
+			conn, err := net.Dial("tcp", s.Addr())
+			if err != nil {
+				t.Fatalf("dial failed: %v", err)
+			}
+			defer conn.Close()
+			// An unknown command is logged as a warning.
+			if _, err := conn.Write([]byte("*1\r\n$4\r\nNOPE\r\n")); err != nil {
+				t.Fatalf("write failed: %v", err)
+			}
+			if _, err := bufio.NewReader(conn).ReadString('\n'); err != nil {
+				t.Fatalf("read failed: %v", err)
+			}
+			if !tc.leaveOpen {
+				_ = conn.Close()
+			}
+			ft.cleanup()
+
+			logs, errs := ft.recorded()
+			if !slices.ContainsFunc(logs, func(l string) bool {
+				return strings.Contains(l, "level=WARN") && strings.Contains(l, `msg="unknown command"`)
+			}) {
+				t.Fatalf("expected the server log to reach t.Log, got %q", logs)
+			}
+			if slices.ContainsFunc(logs, func(l string) bool { return strings.Contains(l, "time=") }) {
+				t.Fatalf("expected log lines without timestamps, got %q", logs)
+			}
+			if tc.wantErr == "" {
+				if len(errs) != 0 {
+					t.Fatalf("expected no errors, got %q", errs)
+				}
+				return
+			}
+			if len(errs) != 1 || !strings.Contains(errs[0], tc.wantErr) || !strings.Contains(errs[0], "last-cmd=NOPE") {
+				t.Fatalf("expected an error reporting the open connection, got %q", errs)
+			}
+		})
+	}
+}